@@ -86,7 +86,13 @@ func startServer(port, mode string) error {
 	}
 
 	// 创建依赖注入容器
-	container := container.NewContainer(cfg, database.GetDB(), redis.GetRedisClient())
+	container, err := container.NewContainer(cfg, database.GetDB(), redis.GetRedisClient())
+	if err != nil {
+		return fmt.Errorf("failed to initialize container: %v", err)
+	}
+
+	// 启动文章定时发布和定期清理任务，关闭容器时停止。通知检查仍通过接口手动触发
+	container.GetScheduler().StartBackgroundJobs()
 
 	// 设置路由
 	r := router.Setup(container)
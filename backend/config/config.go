@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -84,8 +85,9 @@ type RedisConfig struct {
 // JWTConfig JWT配置
 type JWTConfig struct {
 	Secret        string           `json:"secret" validate:"required,min=32"`
-	ExpireHours   int              `json:"expire_hours" validate:"min=1,max=720"`
-	RefreshExpire int              `json:"refresh_expire"`
+	ExpireHours   int              `json:"expire_hours" validate:"min=1,max=720"` // 旧的单一令牌有效期，访问令牌改用 AccessExpire
+	AccessExpire  time.Duration    `json:"access_expire"`                         // 访问令牌有效期
+	RefreshExpire int              `json:"refresh_expire"`                        // 刷新令牌有效期（小时）
 	Issuer        string           `json:"issuer"`
 	SigningMethod string           `json:"signing_method"`
 	TokenLookup   string           `json:"token_lookup"`
@@ -109,6 +111,39 @@ type AppConfig struct {
 	RateLimitBurst     int      `json:"rate_limit_burst"`
 	UploadMaxSize      int64    `json:"upload_max_size"`
 	UploadAllowedTypes []string `json:"upload_allowed_types"`
+	UploadUserQuota    int64    `json:"upload_user_quota"`
+	BaseURL            string   `json:"base_url"` // 对外访问地址，用于订阅源等绝对链接，为空时根据请求推断
+
+	// 客户端 IP：只有来自 TrustedProxies（IP 或 CIDR）的请求才采信 X-Forwarded-For，默认不信任任何代理；
+	// TrustedPlatform 为 CDN 或负载均衡写入真实 IP 的请求头，例如 CF-Connecting-IP
+	TrustedProxies  []string `json:"trusted_proxies"`
+	TrustedPlatform string   `json:"trusted_platform"`
+
+	// 上传文件存储：local 为本地目录，s3 为 S3 兼容的对象存储（多实例部署时使用）
+	StorageDriver       string        `json:"storage_driver" validate:"oneof=local s3"`
+	StorageLocalDir     string        `json:"storage_local_dir"`
+	StorageS3Endpoint   string        `json:"storage_s3_endpoint"`
+	StorageS3Region     string        `json:"storage_s3_region"`
+	StorageS3Bucket     string        `json:"storage_s3_bucket"`
+	StorageS3AccessKey  string        `json:"-"`
+	StorageS3SecretKey  string        `json:"-"`
+	StorageS3PathStyle  bool          `json:"storage_s3_path_style"`
+	StorageSignedURLTTL time.Duration `json:"storage_signed_url_ttl"` // 私有文件下载地址的有效期
+
+	// 邮件发送：smtp 用于生产环境；file 把邮件写入 MailFileDir，log 只写日志，用于本地开发
+	MailDriver       string `json:"mail_driver" validate:"oneof=smtp file log"`
+	MailFrom         string `json:"mail_from"`
+	MailSMTPHost     string `json:"mail_smtp_host"`
+	MailSMTPPort     int    `json:"mail_smtp_port"`
+	MailSMTPUsername string `json:"mail_smtp_username"`
+	MailSMTPPassword string `json:"-"`
+	MailFileDir      string `json:"mail_file_dir"`
+	FrontendURL      string `json:"frontend_url"` // 邮件中重置密码、验证邮箱链接指向的前端地址
+
+	// 登录防暴力破解：连续失败后逐步延迟，达到次数上限后临时锁定，同一账号再次锁定时时长翻倍
+	LoginMaxAttempts   int           `json:"login_max_attempts"`    // 同一账号锁定前允许的连续失败次数
+	LoginIPMaxAttempts int           `json:"login_ip_max_attempts"` // 同一 IP 锁定前允许的失败次数，不区分账号
+	LoginLockout       time.Duration `json:"login_lockout"`         // 首次锁定的时长
 }
 
 // TelemetryConfig OpenTelemetry配置
@@ -177,6 +212,7 @@ func Load() error {
 		JWT: JWTConfig{
 			Secret:        getEnv("JWT_SECRET", generateRandomSecret()),
 			ExpireHours:   getIntEnv("JWT_EXPIRE_HOURS", 24),
+			AccessExpire:  getDurationEnv("JWT_ACCESS_EXPIRE", "15m"),
 			RefreshExpire: getIntEnv("JWT_REFRESH_EXPIRE", 168), // 7 days
 			Issuer:        getEnv("JWT_ISSUER", "gin-web-framework"),
 			SigningMethod: getEnv("JWT_SIGNING_METHOD", "HS256"),
@@ -198,7 +234,34 @@ func Load() error {
 			RateLimitRPS:       getIntEnv("APP_RATE_LIMIT_RPS", 100),
 			RateLimitBurst:     getIntEnv("APP_RATE_LIMIT_BURST", 200),
 			UploadMaxSize:      getInt64Env("APP_UPLOAD_MAX_SIZE", 10<<20), // 10MB
-			UploadAllowedTypes: getSliceEnv("APP_UPLOAD_ALLOWED_TYPES", []string{"image/jpeg", "image/png", "image/gif"}),
+			UploadAllowedTypes: getSliceEnv("APP_UPLOAD_ALLOWED_TYPES", []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf", "text/plain", "text/markdown", "text/csv"}),
+			UploadUserQuota:    getInt64Env("APP_UPLOAD_USER_QUOTA", 200<<20), // 200MB
+			BaseURL:            strings.TrimRight(getEnv("APP_BASE_URL", ""), "/"),
+			TrustedProxies:     getSliceEnv("APP_TRUSTED_PROXIES", nil),
+			TrustedPlatform:    getEnv("APP_TRUSTED_PLATFORM", ""),
+
+			StorageDriver:       getEnv("APP_STORAGE_DRIVER", "local"),
+			StorageLocalDir:     getEnv("APP_STORAGE_LOCAL_DIR", "./uploads"),
+			StorageS3Endpoint:   getEnv("APP_STORAGE_S3_ENDPOINT", ""),
+			StorageS3Region:     getEnv("APP_STORAGE_S3_REGION", "us-east-1"),
+			StorageS3Bucket:     getEnv("APP_STORAGE_S3_BUCKET", ""),
+			StorageS3AccessKey:  getEnv("APP_STORAGE_S3_ACCESS_KEY", ""),
+			StorageS3SecretKey:  getEnv("APP_STORAGE_S3_SECRET_KEY", ""),
+			StorageS3PathStyle:  getBoolEnv("APP_STORAGE_S3_PATH_STYLE", true),
+			StorageSignedURLTTL: getDurationEnv("APP_STORAGE_SIGNED_URL_TTL", "15m"),
+
+			MailDriver:       getEnv("APP_MAIL_DRIVER", "log"),
+			MailFrom:         getEnv("APP_MAIL_FROM", "no-reply@localhost"),
+			MailSMTPHost:     getEnv("APP_MAIL_SMTP_HOST", ""),
+			MailSMTPPort:     getIntEnv("APP_MAIL_SMTP_PORT", 587),
+			MailSMTPUsername: getEnv("APP_MAIL_SMTP_USERNAME", ""),
+			MailSMTPPassword: getEnv("APP_MAIL_SMTP_PASSWORD", ""),
+			MailFileDir:      getEnv("APP_MAIL_FILE_DIR", "./data/mail"),
+			FrontendURL:      strings.TrimRight(getEnv("APP_FRONTEND_URL", "http://localhost:3000"), "/"),
+
+			LoginMaxAttempts:   getIntEnv("APP_LOGIN_MAX_ATTEMPTS", 5),
+			LoginIPMaxAttempts: getIntEnv("APP_LOGIN_IP_MAX_ATTEMPTS", 20),
+			LoginLockout:       getDurationEnv("APP_LOGIN_LOCKOUT", "15m"),
 		},
 		Telemetry: TelemetryConfig{
 			Enabled:        getBoolEnv("TELEMETRY_ENABLED", true),
@@ -263,6 +326,12 @@ func (c *Config) Validate() error {
 	if c.JWT.ExpireHours < 1 || c.JWT.ExpireHours > 720 {
 		errs = append(errs, "JWT expire hours must be between 1 and 720")
 	}
+	if c.JWT.AccessExpire < time.Minute || c.JWT.AccessExpire > 24*time.Hour {
+		errs = append(errs, "JWT access token expiry must be between 1m and 24h")
+	}
+	if c.JWT.RefreshExpire < 1 {
+		errs = append(errs, "JWT refresh token expiry must be at least 1 hour")
+	}
 
 	// 验证应用配置
 	if c.App.Name == "" {
@@ -274,6 +343,29 @@ func (c *Config) Validate() error {
 	if !contains([]string{"development", "production", "testing"}, c.App.Environment) {
 		errs = append(errs, "app environment must be development, production, or testing")
 	}
+	if !contains([]string{"local", "s3"}, c.App.StorageDriver) {
+		errs = append(errs, "storage driver must be local or s3")
+	}
+	if c.App.StorageDriver == "s3" && (c.App.StorageS3Endpoint == "" || c.App.StorageS3Bucket == "" ||
+		c.App.StorageS3AccessKey == "" || c.App.StorageS3SecretKey == "") {
+		errs = append(errs, "storage s3 endpoint, bucket, access key and secret key are required for the s3 driver")
+	}
+	if !contains([]string{"smtp", "file", "log"}, c.App.MailDriver) {
+		errs = append(errs, "mail driver must be smtp, file or log")
+	}
+	if c.App.MailDriver == "smtp" && c.App.MailSMTPHost == "" {
+		errs = append(errs, "mail smtp host is required for the smtp driver")
+	}
+	for _, proxy := range c.App.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fmt.Sprintf("trusted proxy %q must be an IP address or CIDR", proxy))
+			}
+		}
+	}
+	if c.App.LoginMaxAttempts <= 0 || c.App.LoginIPMaxAttempts <= 0 || c.App.LoginLockout <= 0 {
+		errs = append(errs, "login max attempts, ip max attempts and lockout must be positive")
+	}
 
 	if len(errs) > 0 {
 		return errors.New("configuration validation errors: " + strings.Join(errs, "; "))
@@ -4,13 +4,19 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"gin-web-framework/config"
 	"gin-web-framework/internal/database"
 	"gin-web-framework/internal/handler"
 	"gin-web-framework/internal/redis"
+	"gin-web-framework/internal/search"
 	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/auth"
+	"gin-web-framework/pkg/jwt"
 	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/mailer"
+	"gin-web-framework/pkg/storage"
 
 	"gorm.io/gorm"
 )
@@ -35,6 +41,11 @@ type ContainerInterface interface {
 
 	// 服务层 - 返回接口类型
 	GetUserService() service.UserServiceInterface
+	GetTokenService() *service.TokenService
+	GetSessionService() *service.SessionService
+	GetTwoFactorService() *service.TwoFactorService
+	GetAccountService() *service.AccountService
+	GetLoginGuard() *service.LoginGuard
 	GetTodoService() service.TodoServiceInterface
 	GetArticleService() service.ArticleServiceInterface
 	GetNotificationService() service.NotificationServiceInterface
@@ -52,6 +63,24 @@ type ContainerInterface interface {
 	GetEnglishLearningService() service.EnglishLearningServiceInterface
 	GetEnglishVideoService() service.EnglishVideoServiceInterface
 
+	// 共享清单服务
+	GetTodoListService() *service.TodoListService
+
+	// 看板服务
+	GetBoardService() *service.BoardService
+
+	// 日历服务
+	GetCalendarService() *service.CalendarService
+
+	// 全文检索服务
+	GetSearchService() *service.SearchService
+	GetSavedFilterService() *service.SavedFilterService
+	GetCommentService() *service.CommentService
+	GetTagService() *service.TagService
+	GetAttachmentService() *service.AttachmentService
+	GetArticleRenderService() *service.ArticleRenderService
+	GetScheduler() *service.Scheduler
+
 	// 处理器层
 	GetUserHandler() *handler.UserHandler
 	GetTodoHandler() *handler.TodoHandler
@@ -66,6 +95,19 @@ type ContainerInterface interface {
 	GetWebSocketHandler() *handler.WebSocketHandler
 	GetEnglishLearningHandler() *handler.EnglishLearningHandler
 	GetEnglishVideoHandler() *handler.EnglishVideoHandler
+	GetTodoListHandler() *handler.TodoListHandler
+	GetBoardHandler() *handler.BoardHandler
+	GetCalendarHandler() *handler.CalendarHandler
+	GetSearchHandler() *handler.SearchHandler
+	GetSavedFilterHandler() *handler.SavedFilterHandler
+	GetCommentHandler() *handler.CommentHandler
+	GetTagHandler() *handler.TagHandler
+	GetAttachmentHandler() *handler.AttachmentHandler
+	GetSessionHandler() *handler.SessionHandler
+	GetTwoFactorHandler() *handler.TwoFactorHandler
+	GetAccountHandler() *handler.AccountHandler
+	GetLoginGuardHandler() *handler.LoginGuardHandler
+	GetPublicArticleHandler() *handler.PublicArticleHandler
 
 	// 容器管理
 	Register(name string, service interface{})
@@ -74,8 +116,8 @@ type ContainerInterface interface {
 	Shutdown(ctx context.Context) error
 }
 
-// NewContainer 创建新的容器实例
-func NewContainer(cfg config.ConfigInterface, db *gorm.DB, redisClient redis.RedisClient) ContainerInterface {
+// NewContainer 创建新的容器实例，必需的外部依赖（如对象存储）初始化失败时返回错误
+func NewContainer(cfg config.ConfigInterface, db *gorm.DB, redisClient redis.RedisClient) (ContainerInterface, error) {
 	container := &Container{
 		services: make(map[string]interface{}),
 		config:   cfg,
@@ -84,29 +126,105 @@ func NewContainer(cfg config.ConfigInterface, db *gorm.DB, redisClient redis.Red
 	}
 
 	// 在创建时直接初始化所有服务，避免运行时死锁
-	container.initializeAllServices()
+	if err := container.initializeAllServices(); err != nil {
+		return nil, err
+	}
 
-	return container
+	return container, nil
 }
 
 // initializeAllServices 初始化所有服务，避免运行时死锁
-func (c *Container) initializeAllServices() {
+func (c *Container) initializeAllServices() error {
 	// 获取全局logger
 	globalLogger := logger.GetLogger()
 
+	// 注册全文索引插件，之后所有服务的数据库会话都能取到索引
+	if err := c.db.Use(search.New(c.config.GetDatabase().Driver, globalLogger)); err != nil {
+		logger.Error("Failed to initialize search index, falling back to LIKE queries: " + err.Error())
+	}
+
+	// 上传文件存储，签名下载地址和邮件令牌使用从 JWT 密钥派生的独立密钥
+	appConfig := c.config.GetApp()
+	jwtSecret := c.config.GetJWT().Secret
+	fileSigner := storage.NewURLSigner(auth.DeriveKey(jwtSecret, auth.KeyPurposeFileURL), "/api/v1/files")
+	fileStorage, err := storage.New(storage.Config{
+		Driver:   appConfig.StorageDriver,
+		LocalDir: appConfig.StorageLocalDir,
+		S3: storage.S3Config{
+			Endpoint:  appConfig.StorageS3Endpoint,
+			Region:    appConfig.StorageS3Region,
+			Bucket:    appConfig.StorageS3Bucket,
+			AccessKey: appConfig.StorageS3AccessKey,
+			SecretKey: appConfig.StorageS3SecretKey,
+			PathStyle: appConfig.StorageS3PathStyle,
+		},
+	}, fileSigner)
+	if err != nil {
+		// 退回本地存储会让文件写到错误的位置，直接启动失败
+		return fmt.Errorf("failed to initialize file storage: %v", err)
+	}
+
+	// 邮件发送，配置有误时退回到只写日志
+	mailSender, err := mailer.New(mailer.Config{
+		Driver: appConfig.MailDriver,
+		From:   appConfig.MailFrom,
+		SMTP: mailer.SMTPConfig{
+			Host:     appConfig.MailSMTPHost,
+			Port:     appConfig.MailSMTPPort,
+			Username: appConfig.MailSMTPUsername,
+			Password: appConfig.MailSMTPPassword,
+		},
+		FileDir: appConfig.MailFileDir,
+	}, globalLogger)
+	if err != nil {
+		logger.Error("Failed to initialize mailer, falling back to log driver: " + err.Error())
+		mailSender = mailer.NewLog(appConfig.MailFrom, globalLogger)
+	}
+
 	// 创建所有服务实例 - 逐步添加logger
-	userService := service.NewUserService(c.db, globalLogger)
+	imageService := service.NewImageService(fileStorage, globalLogger)
+	tokenService := service.NewTokenService(c.db, time.Duration(c.config.GetJWT().RefreshExpire)*time.Hour, globalLogger)
+	jwt.SetDenylist(tokenService)
+	sessionService := service.NewSessionService(c.db, tokenService, globalLogger)
+	twoFactorService := service.NewTwoFactorService(c.db, appConfig.Name, globalLogger)
+	accountService := service.NewAccountService(c.db, tokenService, mailSender, auth.NewTokenSigner(auth.DeriveKey(jwtSecret, auth.KeyPurposeAccountToken)), service.AccountOptions{
+		AppName:     appConfig.Name,
+		FrontendURL: appConfig.FrontendURL,
+		ResetTTL:    30 * time.Minute,
+		VerifyTTL:   24 * time.Hour,
+	}, globalLogger)
+	cacheService := service.NewCacheService(c.redis, globalLogger)
+	loginGuard := service.NewLoginGuard(c.db, cacheService, service.LoginGuardOptions{
+		MaxAttempts:   appConfig.LoginMaxAttempts,
+		IPMaxAttempts: appConfig.LoginIPMaxAttempts,
+		Lockout:       appConfig.LoginLockout,
+	}, globalLogger)
+	userService := service.NewUserService(c.db, tokenService, sessionService, twoFactorService, accountService, loginGuard, globalLogger)
 	todoService := service.NewTodoService(c.db, globalLogger)
-	articleService := service.NewArticleService(c.db, globalLogger)
+	articleService := service.NewArticleService(c.db, imageService, globalLogger)
 	notificationService := service.NewNotificationService(c.db, globalLogger)
 	statisticsService := service.NewStatisticsService(c.db, globalLogger)
 	categoryService := service.NewOptimizedCategoryService(c.db, globalLogger)
-	cacheService := service.NewCacheService(c.redis, globalLogger)
-	settingsService := service.NewSettingsService(c.db, globalLogger)
+	articleRenderService := service.NewArticleRenderService(c.db, cacheService, globalLogger)
+	settingsService := service.NewSettingsService(c.db, accountService, globalLogger)
 	toolsService := service.NewToolsService(globalLogger)
 	auditService := service.NewAuditService(c.db, globalLogger.(*logger.Logger))
 	englishLearningService := service.NewEnglishLearningService(c.db, globalLogger)
 	englishVideoService := service.NewEnglishVideoService(c.db)
+	todoListService := service.NewTodoListService(c.db, globalLogger)
+	boardService := service.NewBoardService(c.db, globalLogger)
+	calendarService := service.NewCalendarService(c.db, globalLogger)
+	searchService := service.NewSearchService(c.db, globalLogger)
+	savedFilterService := service.NewSavedFilterService(c.db, globalLogger)
+	commentService := service.NewCommentService(c.db, globalLogger)
+	tagService := service.NewTagService(c.db, globalLogger)
+	attachmentService := service.NewAttachmentService(c.db, fileStorage, service.AttachmentOptions{
+		MaxSize:      appConfig.UploadMaxSize,
+		UserQuota:    appConfig.UploadUserQuota,
+		AllowedTypes: appConfig.UploadAllowedTypes,
+		URLTTL:       appConfig.StorageSignedURLTTL,
+	}, globalLogger)
+	scheduler := service.NewScheduler(c.db, articleService, attachmentService, tokenService, sessionService, accountService, globalLogger)
 
 	// 创建依赖缓存服务的组件
 	queryOptimizer := service.NewQueryOptimizer(c.db, cacheService)
@@ -115,20 +233,38 @@ func (c *Container) initializeAllServices() {
 	// 创建所有处理器实例 - 逐步统一依赖注入模式
 	userHandler := handler.NewUserHandler(userService, globalLogger)
 	todoHandler := handler.NewTodoHandler(todoService, globalLogger)
-	articleHandler := handler.NewArticleHandler(articleService, globalLogger)
+	articleHandler := handler.NewArticleHandler(articleService, articleRenderService, globalLogger)
 	notificationHandler := handler.NewNotificationHandler(notificationService, globalLogger, c.db)
 	statisticsHandler := handler.NewStatisticsHandler(statisticsService, globalLogger)
 	categoryHandler := handler.NewCategoryHandler(categoryService, globalLogger)
 	settingsHandler := handler.NewSettingsHandler(settingsService, globalLogger)
 	networkHandler := handler.NewNetworkHandler(globalLogger, toolsService)
 	auditHandler := handler.NewAuditHandler(auditService, globalLogger.(*logger.Logger))
-	uploadHandler := handler.NewUploadHandler()
+	uploadHandler := handler.NewUploadHandler(imageService, fileStorage, fileSigner, globalLogger)
 	websocketHandler := handler.NewWebSocketHandler(globalLogger, c.db)
 	englishLearningHandler := handler.NewEnglishLearningHandler(englishLearningService, globalLogger)
 	englishVideoHandler := handler.NewEnglishVideoHandler(englishVideoService)
+	todoListHandler := handler.NewTodoListHandler(todoListService, globalLogger)
+	boardHandler := handler.NewBoardHandler(boardService, globalLogger)
+	calendarHandler := handler.NewCalendarHandler(calendarService, globalLogger)
+	searchHandler := handler.NewSearchHandler(searchService, globalLogger)
+	savedFilterHandler := handler.NewSavedFilterHandler(savedFilterService, globalLogger)
+	commentHandler := handler.NewCommentHandler(commentService, globalLogger)
+	tagHandler := handler.NewTagHandler(tagService, globalLogger)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, globalLogger)
+	sessionHandler := handler.NewSessionHandler(sessionService, globalLogger)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService, globalLogger)
+	accountHandler := handler.NewAccountHandler(accountService, globalLogger)
+	loginGuardHandler := handler.NewLoginGuardHandler(loginGuard, globalLogger)
+	publicArticleHandler := handler.NewPublicArticleHandler(articleService, articleRenderService, appConfig.Name, appConfig.BaseURL, globalLogger)
 
 	// 注册所有服务
 	c.services["user_service"] = userService
+	c.services["token_service"] = tokenService
+	c.services["session_service"] = sessionService
+	c.services["two_factor_service"] = twoFactorService
+	c.services["account_service"] = accountService
+	c.services["login_guard"] = loginGuard
 	c.services["todo_service"] = todoService
 	c.services["article_service"] = articleService
 	c.services["notification_service"] = notificationService
@@ -140,6 +276,18 @@ func (c *Container) initializeAllServices() {
 	c.services["audit_service"] = auditService
 	c.services["english_learning_service"] = englishLearningService
 	c.services["english_video_service"] = englishVideoService
+	c.services["todo_list_service"] = todoListService
+	c.services["board_service"] = boardService
+	c.services["calendar_service"] = calendarService
+	c.services["search_service"] = searchService
+	c.services["saved_filter_service"] = savedFilterService
+	c.services["comment_service"] = commentService
+	c.services["tag_service"] = tagService
+	c.services["image_service"] = imageService
+	c.services["file_storage"] = fileStorage
+	c.services["attachment_service"] = attachmentService
+	c.services["article_render_service"] = articleRenderService
+	c.services["scheduler"] = scheduler
 	c.services["query_optimizer"] = queryOptimizer
 	c.services["statistics_cache"] = statisticsCache
 
@@ -157,8 +305,36 @@ func (c *Container) initializeAllServices() {
 	c.services["websocket_handler"] = websocketHandler
 	c.services["english_learning_handler"] = englishLearningHandler
 	c.services["english_video_handler"] = englishVideoHandler
+	c.services["todo_list_handler"] = todoListHandler
+	c.services["board_handler"] = boardHandler
+	c.services["calendar_handler"] = calendarHandler
+	c.services["search_handler"] = searchHandler
+	c.services["saved_filter_handler"] = savedFilterHandler
+	c.services["comment_handler"] = commentHandler
+	c.services["tag_handler"] = tagHandler
+	c.services["attachment_handler"] = attachmentHandler
+	c.services["session_handler"] = sessionHandler
+	c.services["two_factor_handler"] = twoFactorHandler
+	c.services["account_handler"] = accountHandler
+	c.services["login_guard_handler"] = loginGuardHandler
+	c.services["public_article_handler"] = publicArticleHandler
+
+	// 索引为空时在后台从业务表重建，之后把旧的 JSON 标签转换到标签表（转换时会同步索引），
+	// 并为没有 slug 的旧文章生成 slug
+	go func() {
+		if err := searchService.EnsureIndexed(); err != nil {
+			logger.Error("Failed to build search index: " + err.Error())
+		}
+		if err := tagService.MigrateLegacyTags(); err != nil {
+			logger.Error("Failed to migrate legacy tags: " + err.Error())
+		}
+		if err := articleService.EnsureArticleSlugs(); err != nil {
+			logger.Error("Failed to generate article slugs: " + err.Error())
+		}
+	}()
 
 	logger.Info("All services initialized successfully")
+	return nil
 }
 
 // GetConfig 获取配置
@@ -181,6 +357,26 @@ func (c *Container) GetUserService() service.UserServiceInterface {
 	return c.services["user_service"].(service.UserServiceInterface)
 }
 
+func (c *Container) GetTokenService() *service.TokenService {
+	return c.services["token_service"].(*service.TokenService)
+}
+
+func (c *Container) GetSessionService() *service.SessionService {
+	return c.services["session_service"].(*service.SessionService)
+}
+
+func (c *Container) GetTwoFactorService() *service.TwoFactorService {
+	return c.services["two_factor_service"].(*service.TwoFactorService)
+}
+
+func (c *Container) GetAccountService() *service.AccountService {
+	return c.services["account_service"].(*service.AccountService)
+}
+
+func (c *Container) GetLoginGuard() *service.LoginGuard {
+	return c.services["login_guard"].(*service.LoginGuard)
+}
+
 func (c *Container) GetTodoService() service.TodoServiceInterface {
 	return c.services["todo_service"].(service.TodoServiceInterface)
 }
@@ -266,6 +462,98 @@ func (c *Container) GetAuditService() *service.AuditService {
 	return c.services["audit_service"].(*service.AuditService)
 }
 
+func (c *Container) GetTodoListService() *service.TodoListService {
+	return c.services["todo_list_service"].(*service.TodoListService)
+}
+
+func (c *Container) GetTodoListHandler() *handler.TodoListHandler {
+	return c.services["todo_list_handler"].(*handler.TodoListHandler)
+}
+
+func (c *Container) GetBoardService() *service.BoardService {
+	return c.services["board_service"].(*service.BoardService)
+}
+
+func (c *Container) GetBoardHandler() *handler.BoardHandler {
+	return c.services["board_handler"].(*handler.BoardHandler)
+}
+
+func (c *Container) GetCalendarService() *service.CalendarService {
+	return c.services["calendar_service"].(*service.CalendarService)
+}
+
+func (c *Container) GetCalendarHandler() *handler.CalendarHandler {
+	return c.services["calendar_handler"].(*handler.CalendarHandler)
+}
+
+func (c *Container) GetSearchService() *service.SearchService {
+	return c.services["search_service"].(*service.SearchService)
+}
+
+func (c *Container) GetSearchHandler() *handler.SearchHandler {
+	return c.services["search_handler"].(*handler.SearchHandler)
+}
+
+func (c *Container) GetSavedFilterService() *service.SavedFilterService {
+	return c.services["saved_filter_service"].(*service.SavedFilterService)
+}
+
+func (c *Container) GetCommentService() *service.CommentService {
+	return c.services["comment_service"].(*service.CommentService)
+}
+
+func (c *Container) GetTagService() *service.TagService {
+	return c.services["tag_service"].(*service.TagService)
+}
+
+func (c *Container) GetAttachmentService() *service.AttachmentService {
+	return c.services["attachment_service"].(*service.AttachmentService)
+}
+
+func (c *Container) GetArticleRenderService() *service.ArticleRenderService {
+	return c.services["article_render_service"].(*service.ArticleRenderService)
+}
+
+func (c *Container) GetScheduler() *service.Scheduler {
+	return c.services["scheduler"].(*service.Scheduler)
+}
+
+func (c *Container) GetSavedFilterHandler() *handler.SavedFilterHandler {
+	return c.services["saved_filter_handler"].(*handler.SavedFilterHandler)
+}
+
+func (c *Container) GetCommentHandler() *handler.CommentHandler {
+	return c.services["comment_handler"].(*handler.CommentHandler)
+}
+
+func (c *Container) GetTagHandler() *handler.TagHandler {
+	return c.services["tag_handler"].(*handler.TagHandler)
+}
+
+func (c *Container) GetAttachmentHandler() *handler.AttachmentHandler {
+	return c.services["attachment_handler"].(*handler.AttachmentHandler)
+}
+
+func (c *Container) GetSessionHandler() *handler.SessionHandler {
+	return c.services["session_handler"].(*handler.SessionHandler)
+}
+
+func (c *Container) GetTwoFactorHandler() *handler.TwoFactorHandler {
+	return c.services["two_factor_handler"].(*handler.TwoFactorHandler)
+}
+
+func (c *Container) GetAccountHandler() *handler.AccountHandler {
+	return c.services["account_handler"].(*handler.AccountHandler)
+}
+
+func (c *Container) GetLoginGuardHandler() *handler.LoginGuardHandler {
+	return c.services["login_guard_handler"].(*handler.LoginGuardHandler)
+}
+
+func (c *Container) GetPublicArticleHandler() *handler.PublicArticleHandler {
+	return c.services["public_article_handler"].(*handler.PublicArticleHandler)
+}
+
 // Register 注册服务
 func (c *Container) Register(name string, service interface{}) {
 	c.mu.Lock()
@@ -350,7 +638,10 @@ func InitializeContainer() error {
 	redisClient := redis.GetRedisClient()
 
 	// 创建容器（会自动初始化所有服务）
-	container := NewContainer(cfg, db, redisClient)
+	container, err := NewContainer(cfg, db, redisClient)
+	if err != nil {
+		return err
+	}
 
 	// 设置为全局容器
 	SetGlobalContainer(container)
@@ -211,14 +211,38 @@ func (dm *DatabaseManager) AutoMigrate() error {
 	if err := dm.db.AutoMigrate(
 		&models.User{},
 		&models.UserSettings{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.UserSession{},
+		&models.UserTwoFactor{},
+		&models.RecoveryCode{},
+		&models.LoginChallenge{},
+		&models.AccountToken{},
 		&models.Product{},
 		&models.TodoCategory{},
 		&models.TodoPriority{},
 		&models.Todo{},
+		&models.TodoRecurrence{},
+		&models.TodoDependency{},
+		&models.TodoList{},
+		&models.TodoListMember{},
+		&models.Board{},
+		&models.BoardColumn{},
+		&models.TodoTimeEntry{},
+		&models.CalendarFeedToken{},
+		&models.SavedFilter{},
 		&models.Notification{},
 		&models.Article{},
 		&models.ArticleLike{},
+		&models.ArticleRevision{},
+		&models.ArticleComment{},
 		&models.Category{},
+		&models.Attachment{},
+		// 标签
+		&models.Tag{},
+		&models.ArticleTag{},
+		&models.SongTag{},
+		&models.VideoSeriesTag{},
 		// 英文学习相关模型
 		&models.LearningCategory{},
 		&models.Song{},
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-web-framework/internal/middleware"
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler 找回密码和验证邮箱处理器
+type AccountHandler struct {
+	accountService *service.AccountService
+	logger         logger.LoggerInterface
+}
+
+// NewAccountHandler 创建账号处理器
+func NewAccountHandler(accountService *service.AccountService, logger logger.LoggerInterface) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		logger:         logger,
+	}
+}
+
+// ForgotPassword 发送重置密码邮件，邮箱未注册时同样返回成功
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var req service.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	req.Client = clientInfo(c)
+	if err := h.accountService.ForgotPassword(req); err != nil {
+		h.handleAccountError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+// ResetPassword 用邮件中的令牌设置新密码
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	req.Client = clientInfo(c)
+	if err := h.accountService.ResetPassword(req); err != nil {
+		h.handleAccountError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Password reset successfully, please log in again"})
+}
+
+// VerifyEmail 用邮件中的令牌验证邮箱
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var req service.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	req.Client = clientInfo(c)
+	if err := h.accountService.VerifyEmail(req); err != nil {
+		h.handleAccountError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Email verified successfully"})
+}
+
+// SendVerification 重新发送验证邮件到当前用户的邮箱
+func (h *AccountHandler) SendVerification(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.accountService.SendVerification(userID); err != nil {
+		h.handleAccountError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Verification email sent"})
+}
+
+// handleAccountError 将找回密码和验证邮箱相关错误映射为响应
+func (h *AccountHandler) handleAccountError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, "User not found")
+	case errors.Is(err, service.ErrAccountTokenThrottle):
+		response.TooManyRequests(c, err.Error())
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidAccountToken), errors.Is(err, service.ErrAccountTokenExpired):
+		response.BadRequest(c, err.Error())
+	default:
+		h.logger.Errorf("Account operation failed: %v", err)
+		response.InternalServerError(c, "Failed to process request")
+	}
+}
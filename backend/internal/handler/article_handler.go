@@ -1,23 +1,28 @@
 package handler
 
 import (
+	"errors"
 	"gin-web-framework/internal/service"
 	"gin-web-framework/pkg/logger"
 	"gin-web-framework/pkg/response"
+	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ArticleHandler struct {
 	articleService *service.ArticleService
+	renderService  *service.ArticleRenderService
 	logger         logger.LoggerInterface
 }
 
-func NewArticleHandler(articleService *service.ArticleService, logger logger.LoggerInterface) *ArticleHandler {
+func NewArticleHandler(articleService *service.ArticleService, renderService *service.ArticleRenderService, logger logger.LoggerInterface) *ArticleHandler {
 	return &ArticleHandler{
 		articleService: articleService,
+		renderService:  renderService,
 		logger:         logger,
 	}
 }
@@ -33,7 +38,14 @@ func (h *ArticleHandler) CreateArticle(c *gin.Context) {
 	userID := getUserIDFromContext(c)
 	article, err := h.articleService.CreateArticle(req, userID)
 	if err != nil {
-		response.InternalServerError(c, "Failed to create article")
+		switch {
+		case errors.Is(err, service.ErrInvalidArticleSchedule), errors.Is(err, service.ErrInvalidArticleSlug):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, service.ErrArticleSlugTaken):
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.InternalServerError(c, "Failed to create article")
+		}
 		return
 	}
 
@@ -107,7 +119,70 @@ func (h *ArticleHandler) GetArticleByID(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, article)
+	rendered, err := h.renderService.RenderArticle(article)
+	if err != nil {
+		h.logger.Errorf("Failed to render article %d: %v", article.ID, err)
+		response.InternalServerError(c, "Failed to render article")
+		return
+	}
+
+	response.Success(c, rendered)
+}
+
+// ImportArticles 从 zip 压缩包导入 Markdown 文章（multipart 的 file 字段）
+func (h *ArticleHandler) ImportArticles(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxArticleArchiveSize+1<<20)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "No file uploaded")
+		return
+	}
+	defer file.Close()
+
+	result, err := h.articleService.ImportArticles(userID, file, header.Size)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidArchive) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		h.logger.Errorf("Failed to import articles: %v", err)
+		response.InternalServerError(c, "Failed to import articles")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ExportArticles 导出当前用户的文章为 zip 压缩包，可按 status 筛选
+func (h *ArticleHandler) ExportArticles(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	var filter service.ArticleExportFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", "attachment; filename=articles-"+time.Now().Format("2006-01-02")+".zip")
+	c.Status(http.StatusOK)
+	// 压缩包直接写入响应，开始写入后无法再返回错误响应，只记录日志
+	if err := h.articleService.ExportArticles(userID, filter, c.Writer); err != nil {
+		h.logger.Errorf("Failed to export articles: %v", err)
+	}
+}
+
+// PreviewArticle 预览 Markdown 渲染结果
+func (h *ArticleHandler) PreviewArticle(c *gin.Context) {
+	var req service.PreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	response.Success(c, h.renderService.Preview(req))
 }
 
 // UpdateArticle 更新文章
@@ -128,7 +203,69 @@ func (h *ArticleHandler) UpdateArticle(c *gin.Context) {
 
 	article, err := h.articleService.UpdateArticle(uint(articleID), userID, req)
 	if err != nil {
-		response.InternalServerError(c, "Failed to update article")
+		switch {
+		case errors.Is(err, service.ErrInvalidArticleSchedule), errors.Is(err, service.ErrInvalidArticleSlug):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, service.ErrArticleSlugTaken):
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			response.InternalServerError(c, "Failed to update article")
+		}
+		return
+	}
+
+	response.Success(c, article)
+}
+
+// ScheduleArticle 设置定时发布和到期下线时间
+func (h *ArticleHandler) ScheduleArticle(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid article ID")
+		return
+	}
+
+	var req service.ScheduleArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	article, err := h.articleService.ScheduleArticle(uint(articleID), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrArticleNotFound):
+			response.NotFound(c, "Article not found")
+		case errors.Is(err, service.ErrInvalidArticleSchedule):
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalServerError(c, "Failed to schedule article")
+		}
+		return
+	}
+
+	response.Success(c, article)
+}
+
+// CancelArticleSchedule 取消定时发布和下线
+func (h *ArticleHandler) CancelArticleSchedule(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid article ID")
+		return
+	}
+
+	article, err := h.articleService.CancelArticleSchedule(uint(articleID), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrArticleNotFound) {
+			response.NotFound(c, "Article not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to cancel article schedule")
 		return
 	}
 
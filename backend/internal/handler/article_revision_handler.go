@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetArticleRevisions 获取文章修订历史
+func (h *ArticleHandler) GetArticleRevisions(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid article ID")
+		return
+	}
+
+	revisions, err := h.articleService.GetArticleRevisions(uint(articleID), userID)
+	if err != nil {
+		h.handleRevisionError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"revisions": revisions,
+	})
+}
+
+// GetArticleRevision 获取指定版本的完整内容
+func (h *ArticleHandler) GetArticleRevision(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid article ID")
+		return
+	}
+	number, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		response.BadRequest(c, "Invalid revision number")
+		return
+	}
+
+	revision, err := h.articleService.GetArticleRevision(uint(articleID), userID, number)
+	if err != nil {
+		h.handleRevisionError(c, err)
+		return
+	}
+
+	response.Success(c, revision)
+}
+
+// DiffArticleRevisions 比较两个版本，参数 from、to 为版本号，mode 为 line（默认）或 word
+func (h *ArticleHandler) DiffArticleRevisions(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid article ID")
+		return
+	}
+	from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+	if err != nil {
+		response.BadRequest(c, "Invalid from revision")
+		return
+	}
+	to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if err != nil {
+		response.BadRequest(c, "Invalid to revision")
+		return
+	}
+
+	result, err := h.articleService.DiffArticleRevisions(uint(articleID), userID, from, to, c.Query("mode"))
+	if err != nil {
+		h.handleRevisionError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// RestoreArticleRevision 恢复到指定版本
+func (h *ArticleHandler) RestoreArticleRevision(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	articleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid article ID")
+		return
+	}
+	number, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		response.BadRequest(c, "Invalid revision number")
+		return
+	}
+
+	article, err := h.articleService.RestoreArticleRevision(uint(articleID), userID, number)
+	if err != nil {
+		h.handleRevisionError(c, err)
+		return
+	}
+
+	response.Success(c, article)
+}
+
+// handleRevisionError 将修订历史相关错误映射为响应
+func (h *ArticleHandler) handleRevisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrArticleNotFound):
+		response.NotFound(c, "Article not found")
+	case errors.Is(err, service.ErrArticleRevisionNotFound):
+		response.NotFound(c, service.ErrArticleRevisionNotFound.Error())
+	case errors.Is(err, service.ErrInvalidRevisionDiff):
+		response.BadRequest(c, service.ErrInvalidRevisionDiff.Error())
+	default:
+		h.logger.Errorf("Article revision operation failed: %v", err)
+		response.InternalServerError(c, "Failed to process article revision")
+	}
+}
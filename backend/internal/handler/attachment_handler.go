@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-web-framework/internal/middleware"
+	"gin-web-framework/internal/models"
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttachmentHandler 任务和文章附件处理器
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+	logger            logger.LoggerInterface
+}
+
+// NewAttachmentHandler 创建附件处理器
+func NewAttachmentHandler(attachmentService *service.AttachmentService, logger logger.LoggerInterface) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		logger:            logger,
+	}
+}
+
+// GetTodoAttachments 获取任务的附件
+func (h *AttachmentHandler) GetTodoAttachments(c *gin.Context) {
+	h.listByTarget(c, models.AttachmentTargetTodo, "Invalid todo ID")
+}
+
+// UploadTodoAttachment 上传任务附件
+func (h *AttachmentHandler) UploadTodoAttachment(c *gin.Context) {
+	h.uploadToTarget(c, models.AttachmentTargetTodo, "Invalid todo ID")
+}
+
+// GetArticleAttachments 获取文章的附件
+func (h *AttachmentHandler) GetArticleAttachments(c *gin.Context) {
+	h.listByTarget(c, models.AttachmentTargetArticle, "Invalid article ID")
+}
+
+// UploadArticleAttachment 上传文章附件
+func (h *AttachmentHandler) UploadArticleAttachment(c *gin.Context) {
+	h.uploadToTarget(c, models.AttachmentTargetArticle, "Invalid article ID")
+}
+
+// GetMyAttachments 获取当前用户上传的附件及存储用量
+func (h *AttachmentHandler) GetMyAttachments(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	result, err := h.attachmentService.ListByUser(userID)
+	if err != nil {
+		h.handleAttachmentError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// UploadAttachment 上传暂不关联内容的附件，之后通过 UpdateAttachment 关联，
+// 超过保留时长仍未关联的附件会被自动清理
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	h.upload(c, nil)
+}
+
+// GetAttachment 获取附件信息和限时有效的下载地址
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "id", "Invalid attachment ID")
+	if !ok {
+		return
+	}
+
+	attachment, err := h.attachmentService.GetAttachment(attachmentID, userID)
+	if err != nil {
+		h.handleAttachmentError(c, err)
+		return
+	}
+
+	response.Success(c, attachment)
+}
+
+// UpdateAttachment 把附件关联到任务或文章，target_type 为空时取消关联
+func (h *AttachmentHandler) UpdateAttachment(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "id", "Invalid attachment ID")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	attachment, err := h.attachmentService.UpdateAttachment(attachmentID, userID, req)
+	if err != nil {
+		h.handleAttachmentError(c, err)
+		return
+	}
+
+	response.Success(c, attachment)
+}
+
+// DeleteAttachment 删除附件
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	attachmentID, ok := parseIDParam(c, "id", "Invalid attachment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.DeleteAttachment(attachmentID, userID); err != nil {
+		h.handleAttachmentError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Attachment deleted successfully"})
+}
+
+func (h *AttachmentHandler) listByTarget(c *gin.Context, targetType, invalidIDMessage string) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	targetID, ok := parseIDParam(c, "id", invalidIDMessage)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachmentService.ListByTarget(service.AttachmentTarget{Type: targetType, ID: targetID}, userID)
+	if err != nil {
+		h.handleAttachmentError(c, err)
+		return
+	}
+
+	response.Success(c, attachments)
+}
+
+func (h *AttachmentHandler) uploadToTarget(c *gin.Context, targetType, invalidIDMessage string) {
+	targetID, ok := parseIDParam(c, "id", invalidIDMessage)
+	if !ok {
+		return
+	}
+	h.upload(c, &service.AttachmentTarget{Type: targetType, ID: targetID})
+}
+
+// upload 读取 multipart 表单中的 file 字段并保存
+func (h *AttachmentHandler) upload(c *gin.Context, target *service.AttachmentTarget) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "File is required")
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(userID, header.Filename, file, target)
+	if err != nil {
+		h.handleAttachmentError(c, err)
+		return
+	}
+
+	response.Success(c, attachment)
+}
+
+// handleAttachmentError 将附件相关错误映射为响应
+func (h *AttachmentHandler) handleAttachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTodoNotFound):
+		response.NotFound(c, "Todo not found")
+	case errors.Is(err, service.ErrArticleNotFound):
+		response.NotFound(c, "Article not found")
+	case errors.Is(err, service.ErrAttachmentNotFound):
+		response.NotFound(c, service.ErrAttachmentNotFound.Error())
+	case errors.Is(err, service.ErrAttachmentForbidden):
+		response.Forbidden(c, service.ErrAttachmentForbidden.Error())
+	case errors.Is(err, service.ErrAttachmentTooLarge), errors.Is(err, service.ErrAttachmentQuotaExceeded):
+		response.Error(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, service.ErrAttachmentTypeNotAllowed):
+		response.Error(c, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, service.ErrAttachmentEmpty), errors.Is(err, service.ErrInvalidAttachmentTarget):
+		response.BadRequest(c, err.Error())
+	default:
+		h.logger.Errorf("Attachment operation failed: %v", err)
+		response.InternalServerError(c, "Failed to process attachment")
+	}
+}
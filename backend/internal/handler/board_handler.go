@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BoardHandler 看板处理器
+type BoardHandler struct {
+	boardService *service.BoardService
+	logger       logger.LoggerInterface
+}
+
+// NewBoardHandler 创建看板处理器
+func NewBoardHandler(boardService *service.BoardService, logger logger.LoggerInterface) *BoardHandler {
+	return &BoardHandler{
+		boardService: boardService,
+		logger:       logger,
+	}
+}
+
+// GetBoards 获取当前用户可访问的看板
+func (h *BoardHandler) GetBoards(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	boards, err := h.boardService.GetBoards(userID.(uint))
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"boards": boards,
+	})
+}
+
+// CreateBoard 创建看板
+func (h *BoardHandler) CreateBoard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreateBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	board, err := h.boardService.CreateBoard(userID.(uint), req)
+	if err != nil {
+		h.handleBoardError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Board created successfully",
+		"board":   board,
+	})
+}
+
+// GetBoard 获取看板及各列任务
+func (h *BoardHandler) GetBoard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid board ID")
+		return
+	}
+
+	board, err := h.boardService.GetBoard(uint(id), userID.(uint))
+	if err != nil {
+		h.handleBoardError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"board": board,
+	})
+}
+
+// UpdateBoard 更新看板
+func (h *BoardHandler) UpdateBoard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid board ID")
+		return
+	}
+
+	var req service.UpdateBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	board, err := h.boardService.UpdateBoard(uint(id), userID.(uint), req)
+	if err != nil {
+		h.handleBoardError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Board updated successfully",
+		"board":   board,
+	})
+}
+
+// DeleteBoard 删除看板
+func (h *BoardHandler) DeleteBoard(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid board ID")
+		return
+	}
+
+	if err := h.boardService.DeleteBoard(uint(id), userID.(uint)); err != nil {
+		h.handleBoardError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Board deleted successfully",
+	})
+}
+
+// CreateColumn 添加看板列
+func (h *BoardHandler) CreateColumn(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid board ID")
+		return
+	}
+
+	var req service.CreateBoardColumnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	column, err := h.boardService.CreateColumn(uint(id), userID.(uint), req)
+	if err != nil {
+		h.handleBoardError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Column created successfully",
+		"column":  column,
+	})
+}
+
+// UpdateColumn 更新看板列或调整列顺序
+func (h *BoardHandler) UpdateColumn(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid board ID")
+		return
+	}
+
+	columnID, err := strconv.ParseUint(c.Param("columnId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid column ID")
+		return
+	}
+
+	var req service.UpdateBoardColumnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	column, err := h.boardService.UpdateColumn(uint(id), uint(columnID), userID.(uint), req)
+	if err != nil {
+		h.handleBoardError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Column updated successfully",
+		"column":  column,
+	})
+}
+
+// DeleteColumn 删除看板列
+func (h *BoardHandler) DeleteColumn(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid board ID")
+		return
+	}
+
+	columnID, err := strconv.ParseUint(c.Param("columnId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid column ID")
+		return
+	}
+
+	if err := h.boardService.DeleteColumn(uint(id), uint(columnID), userID.(uint)); err != nil {
+		h.handleBoardError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Column deleted successfully",
+	})
+}
+
+// MoveTodo 拖拽移动任务到指定列和位置
+func (h *BoardHandler) MoveTodo(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid board ID")
+		return
+	}
+
+	var req service.MoveBoardTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	todo, err := h.boardService.MoveTodo(uint(id), userID.(uint), req)
+	if err != nil {
+		h.handleBoardError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Todo moved successfully",
+		"todo":    todo,
+	})
+}
+
+// handleBoardError 将看板服务错误映射为响应，事务中返回的业务错误会被包装，需要用 errors.Is 判断
+func (h *BoardHandler) handleBoardError(c *gin.Context, err error) {
+	for _, target := range []error{service.ErrBoardNotFound, service.ErrBoardColumnNotFound, service.ErrTodoListNotFound} {
+		if errors.Is(err, target) {
+			response.NotFound(c, target.Error())
+			return
+		}
+	}
+
+	switch {
+	case errors.Is(err, service.ErrTodoListDenied):
+		response.Forbidden(c, service.ErrTodoListDenied.Error())
+	case errors.Is(err, service.ErrBoardWipLimit):
+		response.Error(c, http.StatusConflict, service.ErrBoardWipLimit.Error())
+	case errors.Is(err, service.ErrTodoBlocked):
+		response.BadRequest(c, service.ErrTodoBlocked.Error())
+	case errors.Is(err, service.ErrInvalidBoardPosition):
+		response.BadRequest(c, service.ErrInvalidBoardPosition.Error())
+	default:
+		response.BadRequest(c, err.Error())
+	}
+}
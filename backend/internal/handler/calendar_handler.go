@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/ical"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxICSUploadSize 导入文件大小上限
+const maxICSUploadSize = 5 << 20
+
+// CalendarHandler 日历订阅处理器
+type CalendarHandler struct {
+	calendarService *service.CalendarService
+	logger          logger.LoggerInterface
+}
+
+// NewCalendarHandler 创建日历订阅处理器
+func NewCalendarHandler(calendarService *service.CalendarService, logger logger.LoggerInterface) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+		logger:          logger,
+	}
+}
+
+// GetFeed 输出ICS订阅源，日历客户端无法携带JWT，通过路径中的令牌鉴权
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	feedType := c.DefaultQuery("type", service.CalendarFeedTodo)
+	switch feedType {
+	case service.CalendarFeedTodo, service.CalendarFeedEvent, service.CalendarFeedBoth:
+	default:
+		response.BadRequest(c, "Invalid feed type")
+		return
+	}
+
+	cal, err := h.calendarService.RenderFeed(token, feedType)
+	if err != nil {
+		if errors.Is(err, service.ErrCalendarFeedNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		h.logger.Errorf("Failed to render calendar feed: %v", err)
+		response.InternalServerError(c, "Failed to render calendar feed")
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("Content-Disposition", `inline; filename="todos.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(cal.String()))
+}
+
+// GetFeedToken 获取订阅地址
+func (h *CalendarHandler) GetFeedToken(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	token, err := h.calendarService.GetFeedToken(userID.(uint))
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"token":    token,
+		"feed_url": feedURL(c, token.Token),
+	})
+}
+
+// RegenerateFeedToken 重新生成订阅地址
+func (h *CalendarHandler) RegenerateFeedToken(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	token, err := h.calendarService.RegenerateFeedToken(userID.(uint))
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":  "Calendar feed token regenerated successfully",
+		"token":    token,
+		"feed_url": feedURL(c, token.Token),
+	})
+}
+
+// RevokeFeedToken 关闭日历订阅
+func (h *CalendarHandler) RevokeFeedToken(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.calendarService.RevokeFeedToken(userID.(uint)); err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Calendar feed revoked successfully",
+	})
+}
+
+// ImportICS 导入ICS文件，支持 multipart 的 file 字段或直接提交 text/calendar 请求体
+func (h *CalendarHandler) ImportICS(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var opts service.CalendarImportOptions
+	if listID := c.Query("list_id"); listID != "" {
+		id, err := strconv.ParseUint(listID, 10, 32)
+		if err != nil {
+			response.BadRequest(c, "Invalid list ID")
+			return
+		}
+		if id > 0 {
+			value := uint(id)
+			opts.ListID = &value
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxICSUploadSize)
+
+	var reader io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, _, err := c.Request.FormFile("file")
+		if err != nil {
+			response.BadRequest(c, "No file uploaded")
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	result, err := h.calendarService.ImportICS(userID.(uint), reader, opts)
+	if err != nil {
+		if errors.Is(err, ical.ErrInvalidCalendar) {
+			response.BadRequest(c, "Invalid ICS file: "+err.Error())
+			return
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Calendar imported successfully",
+		"result":  result,
+	})
+}
+
+// feedURL 根据当前请求生成订阅地址
+func feedURL(c *gin.Context, token string) string {
+	return requestBaseURL(c) + "/api/v1/calendar/feed/" + token + ".ics"
+}
+
+// requestBaseURL 根据请求推断站点地址，支持反向代理设置的 X-Forwarded-Proto
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
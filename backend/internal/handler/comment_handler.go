@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-web-framework/internal/middleware"
+	"gin-web-framework/internal/models"
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommentHandler 文章评论处理器
+type CommentHandler struct {
+	commentService *service.CommentService
+	logger         logger.LoggerInterface
+}
+
+// NewCommentHandler 创建文章评论处理器
+func NewCommentHandler(commentService *service.CommentService, logger logger.LoggerInterface) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		logger:         logger,
+	}
+}
+
+// GetComments 获取文章评论树，分页以顶层评论为单位
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	viewer, ok := commentViewer(c)
+	if !ok {
+		return
+	}
+	articleID, ok := parseIDParam(c, "id", "Invalid article ID")
+	if !ok {
+		return
+	}
+
+	var filter service.CommentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := h.commentService.GetComments(articleID, viewer, filter)
+	if err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// CreateComment 发表评论或回复
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	viewer, ok := commentViewer(c)
+	if !ok {
+		return
+	}
+	articleID, ok := parseIDParam(c, "id", "Invalid article ID")
+	if !ok {
+		return
+	}
+
+	var req service.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(articleID, viewer, req)
+	if err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Comment created successfully",
+		"comment": comment,
+	})
+}
+
+// UpdateComment 修改评论
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	viewer, ok := commentViewer(c)
+	if !ok {
+		return
+	}
+	articleID, ok := parseIDParam(c, "id", "Invalid article ID")
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "commentId", "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(articleID, commentID, viewer, req)
+	if err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	response.Success(c, comment)
+}
+
+// DeleteComment 删除评论
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	viewer, ok := commentViewer(c)
+	if !ok {
+		return
+	}
+	articleID, ok := parseIDParam(c, "id", "Invalid article ID")
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "commentId", "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	if err := h.commentService.DeleteComment(articleID, commentID, viewer); err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Comment deleted successfully",
+	})
+}
+
+// GetModerationQueue 获取评论审核队列（管理员）
+func (h *CommentHandler) GetModerationQueue(c *gin.Context) {
+	var filter service.CommentFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := h.commentService.GetModerationQueue(filter)
+	if err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ApproveComment 通过评论（管理员）
+func (h *CommentHandler) ApproveComment(c *gin.Context) {
+	h.moderate(c, h.commentService.ApproveComment)
+}
+
+// HideComment 隐藏评论（管理员）
+func (h *CommentHandler) HideComment(c *gin.Context) {
+	h.moderate(c, h.commentService.HideComment)
+}
+
+func (h *CommentHandler) moderate(c *gin.Context, action func(commentID, moderatorID uint, req service.ModerateCommentRequest) (*models.ArticleComment, error)) {
+	viewer, ok := commentViewer(c)
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "commentId", "Invalid comment ID")
+	if !ok {
+		return
+	}
+
+	// 审核原因可选，允许不带请求体
+	var req service.ModerateCommentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request data: "+err.Error())
+			return
+		}
+	}
+
+	comment, err := action(commentID, viewer.UserID, req)
+	if err != nil {
+		h.handleCommentError(c, err)
+		return
+	}
+
+	response.Success(c, comment)
+}
+
+// handleCommentError 将评论相关错误映射为响应
+func (h *CommentHandler) handleCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrArticleNotFound):
+		response.NotFound(c, "Article not found")
+	case errors.Is(err, service.ErrCommentNotFound):
+		response.NotFound(c, service.ErrCommentNotFound.Error())
+	case errors.Is(err, service.ErrCommentForbidden):
+		response.Forbidden(c, service.ErrCommentForbidden.Error())
+	case errors.Is(err, service.ErrCommentEditWindow):
+		response.Error(c, http.StatusConflict, service.ErrCommentEditWindow.Error())
+	case errors.Is(err, service.ErrInvalidComment):
+		response.BadRequest(c, err.Error())
+	default:
+		h.logger.Errorf("Comment operation failed: %v", err)
+		response.InternalServerError(c, "Failed to process comment")
+	}
+}
+
+// commentViewer 从认证信息中取出当前用户及其是否为管理员
+func commentViewer(c *gin.Context) (service.CommentViewer, bool) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return service.CommentViewer{}, false
+	}
+	role, _ := middleware.GetCurrentUserRole(c)
+	return service.CommentViewer{UserID: userID, IsAdmin: role == models.RoleAdmin}, true
+}
+
+func parseIDParam(c *gin.Context, name, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		response.BadRequest(c, message)
+		return 0, false
+	}
+	return uint(id), true
+}
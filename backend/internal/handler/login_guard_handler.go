@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"errors"
+
+	"gin-web-framework/internal/middleware"
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginGuardHandler 登录保护管理处理器
+type LoginGuardHandler struct {
+	loginGuard *service.LoginGuard
+	logger     logger.LoggerInterface
+}
+
+// NewLoginGuardHandler 创建登录保护管理处理器
+func NewLoginGuardHandler(loginGuard *service.LoginGuard, logger logger.LoggerInterface) *LoginGuardHandler {
+	return &LoginGuardHandler{
+		loginGuard: loginGuard,
+		logger:     logger,
+	}
+}
+
+// Unlock 管理员解除因登录失败次数过多而被锁定的账号
+func (h *LoginGuardHandler) Unlock(c *gin.Context) {
+	adminID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	userID, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.loginGuard.Unlock(adminID, userID, clientInfo(c)); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			response.NotFound(c, "User not found")
+			return
+		}
+		h.logger.Errorf("Failed to unlock account: %v", err)
+		response.InternalServerError(c, "Failed to unlock account")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Account unlocked successfully"})
+}
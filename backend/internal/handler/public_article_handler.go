@@ -0,0 +1,246 @@
+package handler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/feed"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 订阅源格式
+const (
+	feedFormatRSS  = "rss"
+	feedFormatAtom = "atom"
+)
+
+// PublicArticleHandler 公开文章处理器，无需登录即可访问已发布的文章和订阅源
+type PublicArticleHandler struct {
+	articleService *service.ArticleService
+	renderService  *service.ArticleRenderService
+	siteName       string
+	baseURL        string
+	logger         logger.LoggerInterface
+}
+
+// NewPublicArticleHandler 创建公开文章处理器，baseURL 为空时根据请求推断站点地址
+func NewPublicArticleHandler(articleService *service.ArticleService, renderService *service.ArticleRenderService, siteName, baseURL string, logger logger.LoggerInterface) *PublicArticleHandler {
+	return &PublicArticleHandler{
+		articleService: articleService,
+		renderService:  renderService,
+		siteName:       siteName,
+		baseURL:        strings.TrimRight(baseURL, "/"),
+		logger:         logger,
+	}
+}
+
+// GetArticles 全站已发布文章列表，支持 tag 筛选
+func (h *PublicArticleHandler) GetArticles(c *gin.Context) {
+	var filter service.PublicArticleFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := h.articleService.GetPublicArticles(filter)
+	if err != nil {
+		h.handlePublicError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetAuthorArticles 指定作者已发布的文章列表
+func (h *PublicArticleHandler) GetAuthorArticles(c *gin.Context) {
+	var filter service.PublicArticleFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+	filter.Author = c.Param("username")
+
+	result, err := h.articleService.GetPublicArticles(filter)
+	if err != nil {
+		h.handlePublicError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetArticle 按 slug 获取已发布的文章
+func (h *PublicArticleHandler) GetArticle(c *gin.Context) {
+	article, err := h.articleService.GetPublicArticleBySlug(c.Param("slug"))
+	if err != nil {
+		h.handlePublicError(c, err)
+		return
+	}
+	doc, err := h.renderService.Render(article.ID, article.Content)
+	if err != nil {
+		h.handlePublicError(c, err)
+		return
+	}
+	article.ContentHTML = doc.HTML
+	article.TOC = doc.TOC
+	article.ReadingTime = doc.ReadingTime
+
+	response.Success(c, article)
+}
+
+// GetSiteFeed 全站订阅源
+func (h *PublicArticleHandler) GetSiteFeed(c *gin.Context) {
+	h.serveFeed(c, service.PublicArticleFilter{}, h.siteName, "/")
+}
+
+// GetAuthorFeed 作者订阅源
+func (h *PublicArticleHandler) GetAuthorFeed(c *gin.Context) {
+	username := c.Param("username")
+	h.serveFeed(c, service.PublicArticleFilter{Author: username}, h.siteName+" - "+username, "/authors/"+username)
+}
+
+// GetTagFeed 标签订阅源
+func (h *PublicArticleHandler) GetTagFeed(c *gin.Context) {
+	tag := c.Param("tag")
+	h.serveFeed(c, service.PublicArticleFilter{Tag: tag}, h.siteName+" - #"+tag, "/tags/"+tag)
+}
+
+// serveFeed 输出订阅源。先只查询版本信息，命中 If-None-Match / If-Modified-Since 时直接返回 304
+func (h *PublicArticleHandler) serveFeed(c *gin.Context, filter service.PublicArticleFilter, title, page string) {
+	format := feedFormatRSS
+	if strings.HasSuffix(c.Request.URL.Path, ".atom") {
+		format = feedFormatAtom
+	}
+
+	state, err := h.articleService.GetArticleFeedState(filter)
+	if err != nil {
+		h.handlePublicError(c, err)
+		return
+	}
+
+	etag := feedETag(format, filter, state)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=300")
+	if !state.LastModified.IsZero() {
+		c.Header("Last-Modified", state.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if feedNotModified(c.Request, etag, state.LastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	articles, err := h.articleService.GetArticleFeed(filter)
+	if err != nil {
+		h.handlePublicError(c, err)
+		return
+	}
+
+	base := h.baseURL
+	if base == "" {
+		base = requestBaseURL(c)
+	}
+	f := &feed.Feed{
+		Title:       title,
+		Link:        base + page,
+		Self:        base + c.Request.URL.Path,
+		Description: title,
+		Updated:     state.LastModified,
+	}
+	if articles.Author != nil {
+		f.Author = articles.Author.Nickname
+		if f.Author == "" {
+			f.Author = articles.Author.Username
+		}
+	}
+	for _, article := range articles.Articles {
+		doc, err := h.renderService.Render(article.ID, article.Content)
+		if err != nil {
+			h.handlePublicError(c, err)
+			return
+		}
+		link := base + "/posts/" + article.Slug
+		author := article.Author.Nickname
+		if author == "" {
+			author = article.Author.Username
+		}
+		f.Items = append(f.Items, feed.Item{
+			ID:         link,
+			Title:      article.Title,
+			Link:       link,
+			Summary:    article.Summary,
+			Content:    doc.HTML,
+			Author:     author,
+			Categories: article.Tags,
+			Published:  article.PublishedAt,
+			Updated:    article.UpdatedAt,
+		})
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	if format == feedFormatAtom {
+		body, err = feed.Atom(f)
+		contentType = feed.ContentTypeAtom
+	} else {
+		body, err = feed.RSS(f)
+		contentType = feed.ContentTypeRSS
+	}
+	if err != nil {
+		h.logger.Errorf("Failed to render feed: %v", err)
+		response.InternalServerError(c, "Failed to render feed")
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// handlePublicError 将公开接口的错误映射为响应
+func (h *PublicArticleHandler) handlePublicError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrArticleNotFound):
+		response.NotFound(c, "Article not found")
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, "Author not found")
+	default:
+		h.logger.Errorf("Public article request failed: %v", err)
+		response.InternalServerError(c, "Failed to get articles")
+	}
+}
+
+// feedETag 根据格式、筛选条件和文章版本信息生成弱 ETag
+func feedETag(format string, filter service.PublicArticleFilter, state *service.ArticleFeedState) string {
+	key := fmt.Sprintf("%s|%s|%s|%d|%d|%d", format, filter.Author, filter.Tag,
+		state.Count, state.LatestID, state.LastModified.UnixNano())
+	sum := sha1.Sum([]byte(key))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// feedNotModified 判断条件请求是否命中缓存，If-None-Match 优先于 If-Modified-Since
+func feedNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
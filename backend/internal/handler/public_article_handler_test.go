@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestFeedNotModified(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 8, 30, 15, 500_000_000, time.UTC)
+	etag := feedETag("rss", service.PublicArticleFilter{}, &service.ArticleFeedState{
+		Count:        3,
+		LatestID:     7,
+		LastModified: lastModified,
+	})
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{name: "no conditional headers", want: false},
+		{name: "matching etag", headers: map[string]string{"If-None-Match": etag}, want: true},
+		{name: "weak comparison ignores W/ prefix", headers: map[string]string{"If-None-Match": etag[2:]}, want: true},
+		{name: "etag in list", headers: map[string]string{"If-None-Match": `"other", ` + etag}, want: true},
+		{name: "wildcard", headers: map[string]string{"If-None-Match": "*"}, want: true},
+		{name: "different etag", headers: map[string]string{"If-None-Match": `W/"other"`}, want: false},
+		{
+			name: "etag takes precedence over If-Modified-Since",
+			headers: map[string]string{
+				"If-None-Match":     `W/"other"`,
+				"If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat),
+			},
+			want: false,
+		},
+		{
+			name:    "If-Modified-Since truncated to seconds",
+			headers: map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)},
+			want:    true,
+		},
+		{
+			name:    "modified after If-Modified-Since",
+			headers: map[string]string{"If-Modified-Since": lastModified.Add(-time.Second).Format(http.TimeFormat)},
+			want:    false,
+		},
+		{name: "invalid If-Modified-Since", headers: map[string]string{"If-Modified-Since": "yesterday"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/feed.xml", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			assert.Equal(t, tt.want, feedNotModified(req, etag, lastModified))
+		})
+	}
+}
+
+func TestFeedETag(t *testing.T) {
+	state := &service.ArticleFeedState{Count: 3, LatestID: 7, LastModified: time.Unix(1714552215, 0)}
+	etag := feedETag("rss", service.PublicArticleFilter{}, state)
+
+	assert.Regexp(t, `^W/"[0-9a-f]{16}"$`, etag)
+	assert.Equal(t, etag, feedETag("rss", service.PublicArticleFilter{Page: 2}, state))
+	assert.NotEqual(t, etag, feedETag("atom", service.PublicArticleFilter{}, state))
+	assert.NotEqual(t, etag, feedETag("rss", service.PublicArticleFilter{Tag: "go"}, state))
+
+	updated := *state
+	updated.LastModified = updated.LastModified.Add(time.Second)
+	assert.NotEqual(t, etag, feedETag("rss", service.PublicArticleFilter{}, &updated))
+}
+
+func TestPublicArticleHandler_SiteFeedNotModified(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Article{}, &models.ArticleRevision{}))
+	require.NoError(t, db.Create(&models.User{Username: "alice", Email: "alice@example.com", Password: "x"}).Error)
+
+	now := time.Now()
+	slug := "hello"
+	require.NoError(t, db.Create(&models.Article{
+		Title: "Hello", Slug: &slug, Content: "hello", Status: service.ArticleStatusPublished, PublishedAt: &now, CreatedBy: 1,
+	}).Error)
+
+	log := logger.NewLogger(logger.DefaultLoggerConfig())
+	h := NewPublicArticleHandler(service.NewArticleService(db, nil, log), service.NewArticleRenderService(db, nil, log),
+		"Todo", "https://example.com", log)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/feed.xml", h.GetSiteFeed)
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/feed.xml", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := get("", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Contains(t, first.Body.String(), "/posts/hello")
+
+	notModified := get("If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+	assert.Equal(t, http.StatusNotModified, get("If-Modified-Since", first.Header().Get("Last-Modified")).Code)
+
+	// 草稿和定时文章不改变订阅源
+	draft, scheduled := "draft", "scheduled"
+	later := now.Add(time.Hour)
+	require.NoError(t, db.Create(&models.Article{
+		Title: "Draft", Slug: &draft, Content: "draft", Status: service.ArticleStatusDraft, CreatedBy: 1,
+	}).Error)
+	require.NoError(t, db.Create(&models.Article{
+		Title: "Scheduled", Slug: &scheduled, Content: "scheduled", Status: service.ArticleStatusScheduled, PublishAt: &later, CreatedBy: 1,
+	}).Error)
+	assert.Equal(t, http.StatusNotModified, get("If-None-Match", etag).Code)
+	body := get("", "").Body.String()
+	assert.NotContains(t, body, "/posts/draft")
+	assert.NotContains(t, body, "/posts/scheduled")
+}
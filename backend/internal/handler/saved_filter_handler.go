@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SavedFilterHandler 保存的任务过滤器处理器
+type SavedFilterHandler struct {
+	savedFilterService *service.SavedFilterService
+	logger             logger.LoggerInterface
+}
+
+// NewSavedFilterHandler 创建保存的任务过滤器处理器
+func NewSavedFilterHandler(savedFilterService *service.SavedFilterService, logger logger.LoggerInterface) *SavedFilterHandler {
+	return &SavedFilterHandler{
+		savedFilterService: savedFilterService,
+		logger:             logger,
+	}
+}
+
+// GetSavedFilters 获取过滤器列表，默认附带任务数供侧边栏展示，counts=false 时不统计
+func (h *SavedFilterHandler) GetSavedFilters(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	filters, err := h.savedFilterService.GetSavedFilters(userID.(uint), c.Query("counts") != "false")
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"filters": filters,
+	})
+}
+
+// GetSavedFilter 获取单个过滤器
+func (h *SavedFilterHandler) GetSavedFilter(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("filterId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid filter ID")
+		return
+	}
+
+	filter, err := h.savedFilterService.GetSavedFilter(uint(id), userID.(uint))
+	if err != nil {
+		h.handleSavedFilterError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"filter": filter,
+	})
+}
+
+// CreateSavedFilter 创建过滤器
+func (h *SavedFilterHandler) CreateSavedFilter(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.SavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	filter, err := h.savedFilterService.CreateSavedFilter(userID.(uint), req)
+	if err != nil {
+		h.handleSavedFilterError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Filter created successfully",
+		"filter":  filter,
+	})
+}
+
+// UpdateSavedFilter 更新过滤器
+func (h *SavedFilterHandler) UpdateSavedFilter(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("filterId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid filter ID")
+		return
+	}
+
+	var req service.UpdateSavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	filter, err := h.savedFilterService.UpdateSavedFilter(uint(id), userID.(uint), req)
+	if err != nil {
+		h.handleSavedFilterError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Filter updated successfully",
+		"filter":  filter,
+	})
+}
+
+// DeleteSavedFilter 删除过滤器
+func (h *SavedFilterHandler) DeleteSavedFilter(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("filterId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid filter ID")
+		return
+	}
+
+	if err := h.savedFilterService.DeleteSavedFilter(uint(id), userID.(uint)); err != nil {
+		h.handleSavedFilterError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Filter deleted successfully",
+	})
+}
+
+// handleSavedFilterError 将过滤器服务错误映射为响应
+func (h *SavedFilterHandler) handleSavedFilterError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSavedFilterNotFound):
+		response.NotFound(c, service.ErrSavedFilterNotFound.Error())
+	case errors.Is(err, service.ErrInvalidTodoQuery):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrSavedFilterLimit):
+		response.Error(c, http.StatusConflict, service.ErrSavedFilterLimit.Error())
+	default:
+		h.logger.Errorf("Saved filter operation failed: %v", err)
+		response.InternalServerError(c, err.Error())
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler 全局搜索处理器
+type SearchHandler struct {
+	searchService *service.SearchService
+	logger        logger.LoggerInterface
+}
+
+// NewSearchHandler 创建全局搜索处理器
+func NewSearchHandler(searchService *service.SearchService, logger logger.LoggerInterface) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		logger:        logger,
+	}
+}
+
+// Search 跨任务、文章、分类、歌曲和视频的全局搜索
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.GlobalSearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	result, err := h.searchService.Search(userID.(uint), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSearchType):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, service.ErrSearchUnavailable):
+			response.Error(c, http.StatusServiceUnavailable, err.Error())
+		default:
+			h.logger.Errorf("Failed to search: %v", err)
+			response.InternalServerError(c, "Failed to search")
+		}
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+
+	"gin-web-framework/internal/middleware"
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler 登录会话和设备管理处理器
+type SessionHandler struct {
+	sessionService *service.SessionService
+	logger         logger.LoggerInterface
+}
+
+// NewSessionHandler 创建会话处理器
+func NewSessionHandler(sessionService *service.SessionService, logger logger.LoggerInterface) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+		logger:         logger,
+	}
+}
+
+// GetSessions 获取当前用户仍有效的登录会话，当前请求所在的会话带有 current 标记
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	claims, exists := middleware.GetCurrentClaims(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	sessions, err := h.sessionService.List(claims.UserID, claims.SessionID)
+	if err != nil {
+		h.logger.Errorf("Failed to get sessions: %v", err)
+		response.InternalServerError(c, "Failed to get sessions")
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// RevokeSession 移除登录会话，该设备需要重新登录
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	sessionID, ok := parseIDParam(c, "id", "Invalid session ID")
+	if !ok {
+		return
+	}
+
+	if err := h.sessionService.Revoke(userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		h.logger.Errorf("Failed to revoke session: %v", err)
+		response.InternalServerError(c, "Failed to revoke session")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Session revoked successfully"})
+}
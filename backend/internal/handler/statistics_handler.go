@@ -92,3 +92,26 @@ func (h *StatisticsHandler) GetTrends(c *gin.Context) {
 
 	response.Success(c, trends)
 }
+
+// GetEstimateReport 获取预估与实际工时偏差报表
+func (h *StatisticsHandler) GetEstimateReport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
+		response.Error(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	weeks, err := service.ValidateWeeks(c.DefaultQuery("weeks", "8"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.statisticsService.GetEstimateReport(userID, weeks)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "获取工时偏差报表失败: "+err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-web-framework/internal/middleware"
+	"gin-web-framework/internal/models"
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler 标签处理器
+type TagHandler struct {
+	tagService *service.TagService
+	logger     logger.LoggerInterface
+}
+
+// NewTagHandler 创建标签处理器
+func NewTagHandler(tagService *service.TagService, logger logger.LoggerInterface) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+		logger:     logger,
+	}
+}
+
+// GetTags 标签云和自动补全，参数 q 为名称前缀，type 限定内容类型（article、song、video_series）
+func (h *TagHandler) GetTags(c *gin.Context) {
+	var filter service.TagFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		response.BadRequest(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	userID, _ := middleware.GetCurrentUserID(c)
+	role, _ := middleware.GetCurrentUserRole(c)
+	tags, err := h.tagService.GetTags(filter, userID, role == models.RoleAdmin)
+	if err != nil {
+		h.handleTagError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"tags": tags,
+	})
+}
+
+// RenameTag 重命名标签（管理员）
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	id, ok := parseIDParam(c, "tagId", "Invalid tag ID")
+	if !ok {
+		return
+	}
+
+	var req service.RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	tag, err := h.tagService.RenameTag(id, req)
+	if err != nil {
+		h.handleTagError(c, err)
+		return
+	}
+
+	response.Success(c, tag)
+}
+
+// MergeTags 合并标签（管理员）
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	var req service.MergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	tag, err := h.tagService.MergeTags(req)
+	if err != nil {
+		h.handleTagError(c, err)
+		return
+	}
+
+	response.Success(c, tag)
+}
+
+// handleTagError 将标签相关错误映射为响应
+func (h *TagHandler) handleTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTagNotFound):
+		response.NotFound(c, service.ErrTagNotFound.Error())
+	case errors.Is(err, service.ErrTagConflict):
+		response.Error(c, http.StatusConflict, service.ErrTagConflict.Error())
+	case errors.Is(err, service.ErrInvalidTag):
+		response.BadRequest(c, err.Error())
+	default:
+		h.logger.Errorf("Tag operation failed: %v", err)
+		response.InternalServerError(c, "Failed to process tags")
+	}
+}
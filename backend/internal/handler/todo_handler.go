@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"gin-web-framework/internal/service"
 	"gin-web-framework/pkg/logger"
 	"gin-web-framework/pkg/response"
@@ -96,15 +97,46 @@ func (h *TodoHandler) GetTodos(c *gin.Context) {
 			filter.CategoryID = &uid
 		}
 	}
+	if listID := c.Query("list_id"); listID != "" {
+		if id, err := strconv.ParseUint(listID, 10, 32); err == nil {
+			uid := uint(id)
+			filter.ListID = &uid
+		}
+	}
+	if assigneeID := c.Query("assignee_id"); assigneeID != "" {
+		if id, err := strconv.ParseUint(assigneeID, 10, 32); err == nil {
+			uid := uint(id)
+			filter.AssigneeID = &uid
+		}
+	}
+	filter.Search = c.Query("search")
+	filter.Query = c.Query("q")
+	if view := c.Query("view"); view != "" {
+		id, err := strconv.ParseUint(view, 10, 32)
+		if err != nil {
+			response.BadRequest(c, "Invalid view ID")
+			return
+		}
+		viewID := uint(id)
+		filter.ViewID = &viewID
+	}
 
 	todos, err := h.todoService.GetTodos(userID.(uint), filter)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		switch {
+		case errors.Is(err, service.ErrSavedFilterNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, service.ErrInvalidTodoQuery):
+			response.BadRequest(c, err.Error())
+		default:
+			response.InternalServerError(c, err.Error())
+		}
 		return
 	}
 
 	response.Success(c, gin.H{
 		"todos": todos.Todos,
+		"total": todos.Total,
 	})
 }
 
@@ -208,3 +240,412 @@ func (h *TodoHandler) GetTodoStats(c *gin.Context) {
 		"stats": stats,
 	})
 }
+
+// GetRecurrence 获取任务的重复规则
+func (h *TodoHandler) GetRecurrence(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	detail, err := h.todoService.GetRecurrence(uint(id), userID.(uint))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"recurrence": detail,
+	})
+}
+
+// SetRecurrence 设置或修改任务的重复规则
+func (h *TodoHandler) SetRecurrence(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	var req service.SetRecurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	detail, err := h.todoService.SetRecurrence(uint(id), userID.(uint), req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":    "Recurrence saved successfully",
+		"recurrence": detail,
+	})
+}
+
+// StopRecurrence 停止任务的重复系列
+func (h *TodoHandler) StopRecurrence(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	if err := h.todoService.StopRecurrence(uint(id), userID.(uint)); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Recurrence stopped successfully",
+	})
+}
+
+// GetSubtasks 获取子任务列表
+func (h *TodoHandler) GetSubtasks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	subtasks, err := h.todoService.GetSubtasks(uint(id), userID.(uint))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"subtasks": subtasks.Subtasks,
+		"progress": subtasks.Progress,
+	})
+}
+
+// CreateSubtask 创建子任务
+func (h *TodoHandler) CreateSubtask(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	var req service.CreateTodoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	todo, err := h.todoService.CreateSubtask(uint(id), userID.(uint), req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Subtask created successfully",
+		"todo":    todo,
+	})
+}
+
+// GetDependencies 获取任务依赖
+func (h *TodoHandler) GetDependencies(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	deps, err := h.todoService.GetDependencies(uint(id), userID.(uint))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"dependencies": deps,
+	})
+}
+
+// AddDependency 添加前置任务
+func (h *TodoHandler) AddDependency(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	var req service.AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	if err := h.todoService.AddDependency(uint(id), req.BlockedByID, userID.(uint)); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Dependency added successfully",
+	})
+}
+
+// RemoveDependency 移除前置任务
+func (h *TodoHandler) RemoveDependency(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	blockedByID, err := strconv.ParseUint(c.Param("blockedById"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid dependency ID")
+		return
+	}
+
+	if err := h.todoService.RemoveDependency(uint(id), uint(blockedByID), userID.(uint)); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Dependency removed successfully",
+	})
+}
+
+// StartTimer 开始计时，已有计时会被自动停止
+func (h *TodoHandler) StartTimer(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	var req service.StartTimerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request data: "+err.Error())
+			return
+		}
+	}
+
+	result, err := h.todoService.StartTimer(uint(id), userID.(uint), req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Timer started successfully",
+		"timer":   result,
+	})
+}
+
+// StopTimer 停止当前计时
+func (h *TodoHandler) StopTimer(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	entry, err := h.todoService.StopTimer(userID.(uint))
+	if err != nil {
+		if errors.Is(err, service.ErrNoRunningTimer) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Timer stopped successfully",
+		"entry":   entry,
+	})
+}
+
+// GetRunningTimer 获取当前计时
+func (h *TodoHandler) GetRunningTimer(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	entry, err := h.todoService.GetRunningTimer(userID.(uint))
+	if err != nil && !errors.Is(err, service.ErrNoRunningTimer) {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"entry": entry,
+	})
+}
+
+// GetTimeEntries 获取计时记录
+func (h *TodoHandler) GetTimeEntries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	entries, err := h.todoService.GetTimeEntries(uint(id), userID.(uint))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, entries)
+}
+
+// AddTimeEntry 手动补录计时
+func (h *TodoHandler) AddTimeEntry(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	var req service.ManualTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	entry, err := h.todoService.AddTimeEntry(uint(id), userID.(uint), req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Time entry added successfully",
+		"entry":   entry,
+	})
+}
+
+// DeleteTimeEntry 删除计时记录
+func (h *TodoHandler) DeleteTimeEntry(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid todo ID")
+		return
+	}
+
+	entryID, err := strconv.ParseUint(c.Param("entryId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid time entry ID")
+		return
+	}
+
+	if err := h.todoService.DeleteTimeEntry(uint(id), uint(entryID), userID.(uint)); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Time entry deleted successfully",
+	})
+}
+
+// QuickAdd 快速添加：解析自然语言文本并返回预览，create 为 true 时直接创建
+func (h *TodoHandler) QuickAdd(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	preview, err := h.todoService.QuickAdd(userID.(uint), req)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Success(c, preview)
+}
@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TodoListHandler 共享清单处理器
+type TodoListHandler struct {
+	todoListService *service.TodoListService
+	logger          logger.LoggerInterface
+}
+
+// NewTodoListHandler 创建共享清单处理器
+func NewTodoListHandler(todoListService *service.TodoListService, logger logger.LoggerInterface) *TodoListHandler {
+	return &TodoListHandler{
+		todoListService: todoListService,
+		logger:          logger,
+	}
+}
+
+// GetLists 获取当前用户参与的清单
+func (h *TodoListHandler) GetLists(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	lists, err := h.todoListService.GetLists(userID.(uint))
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"lists": lists,
+	})
+}
+
+// CreateList 创建清单
+func (h *TodoListHandler) CreateList(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.CreateTodoListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	list, err := h.todoListService.CreateList(userID.(uint), req)
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Todo list created successfully",
+		"list":    list,
+	})
+}
+
+// GetList 获取清单详情
+func (h *TodoListHandler) GetList(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid list ID")
+		return
+	}
+
+	list, err := h.todoListService.GetList(uint(id), userID.(uint))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list": list,
+	})
+}
+
+// UpdateList 更新清单
+func (h *TodoListHandler) UpdateList(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid list ID")
+		return
+	}
+
+	var req service.UpdateTodoListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	list, err := h.todoListService.UpdateList(uint(id), userID.(uint), req)
+	if err != nil {
+		h.handleListError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Todo list updated successfully",
+		"list":    list,
+	})
+}
+
+// DeleteList 删除清单
+func (h *TodoListHandler) DeleteList(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid list ID")
+		return
+	}
+
+	if err := h.todoListService.DeleteList(uint(id), userID.(uint)); err != nil {
+		h.handleListError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Todo list deleted successfully",
+	})
+}
+
+// AddMember 添加清单成员
+func (h *TodoListHandler) AddMember(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid list ID")
+		return
+	}
+
+	var req service.AddTodoListMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	member, err := h.todoListService.AddMember(uint(id), userID.(uint), req)
+	if err != nil {
+		h.handleListError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Member added successfully",
+		"member":  member,
+	})
+}
+
+// UpdateMember 修改成员角色
+func (h *TodoListHandler) UpdateMember(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid list ID")
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	var req service.UpdateTodoListMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	if err := h.todoListService.UpdateMemberRole(uint(id), userID.(uint), uint(memberID), req); err != nil {
+		h.handleListError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Member updated successfully",
+	})
+}
+
+// RemoveMember 移除成员或退出清单
+func (h *TodoListHandler) RemoveMember(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid list ID")
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID")
+		return
+	}
+
+	if err := h.todoListService.RemoveMember(uint(id), userID.(uint), uint(memberID)); err != nil {
+		h.handleListError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Member removed successfully",
+	})
+}
+
+// handleListError 将清单服务错误映射为响应
+func (h *TodoListHandler) handleListError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTodoListNotFound):
+		response.NotFound(c, err.Error())
+	case errors.Is(err, service.ErrTodoListDenied):
+		response.Forbidden(c, err.Error())
+	default:
+		response.BadRequest(c, err.Error())
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"gin-web-framework/internal/middleware"
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler 两步验证处理器
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+	logger           logger.LoggerInterface
+}
+
+// NewTwoFactorHandler 创建两步验证处理器
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService, logger logger.LoggerInterface) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		logger:           logger,
+	}
+}
+
+// GetStatus 获取当前用户的两步验证状态
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	status, err := h.twoFactorService.Status(userID)
+	if err != nil {
+		h.handleTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// Setup 生成密钥和二维码内容，提交验证码后才会启用
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	setup, err := h.twoFactorService.Setup(userID)
+	if err != nil {
+		h.handleTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, setup)
+}
+
+// Enable 提交验证码启用两步验证，返回的恢复码只展示这一次
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	req.Client = clientInfo(c)
+	codes, err := h.twoFactorService.Enable(userID, req)
+	if err != nil {
+		h.handleTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+// Disable 关闭两步验证
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	req.Client = clientInfo(c)
+	if err := h.twoFactorService.Disable(userID, req); err != nil {
+		h.handleTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	req.Client = clientInfo(c)
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(userID, req)
+	if err != nil {
+		h.handleTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// AdminReset 管理员重置用户的两步验证
+func (h *TwoFactorHandler) AdminReset(c *gin.Context) {
+	adminID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+	userID, ok := parseIDParam(c, "id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.AdminReset(adminID, userID, clientInfo(c)); err != nil {
+		h.handleTwoFactorError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Two-factor authentication reset successfully"})
+}
+
+// handleTwoFactorError 将两步验证相关错误映射为响应
+func (h *TwoFactorHandler) handleTwoFactorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		response.NotFound(c, "User not found")
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrTwoFactorNotEnabled), errors.Is(err, service.ErrTwoFactorNotSetUp),
+		errors.Is(err, service.ErrInvalidTwoFactorCode), errors.Is(err, service.ErrInvalidPassword):
+		response.BadRequest(c, err.Error())
+	default:
+		h.logger.Errorf("Two-factor operation failed: %v", err)
+		response.InternalServerError(c, "Failed to process two-factor authentication")
+	}
+}
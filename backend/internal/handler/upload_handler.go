@@ -1,25 +1,43 @@
 package handler
 
 import (
-	"fmt"
+	"errors"
 	"io"
+	"mime"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
+	"strings"
+	"time"
 
+	"gin-web-framework/internal/service"
+	"gin-web-framework/pkg/logger"
 	"gin-web-framework/pkg/response"
+	"gin-web-framework/pkg/storage"
 	"gin-web-framework/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
-type UploadHandler struct{}
+// maxImageUploadMB 上传图片的大小上限
+const maxImageUploadMB = 5
 
-func NewUploadHandler() *UploadHandler {
-	return &UploadHandler{}
+type UploadHandler struct {
+	imageService *service.ImageService
+	storage      storage.Storage
+	signer       *storage.URLSigner
+	logger       logger.LoggerInterface
 }
 
-// UploadImage 上传图片
+func NewUploadHandler(imageService *service.ImageService, store storage.Storage, signer *storage.URLSigner, logger logger.LoggerInterface) *UploadHandler {
+	return &UploadHandler{
+		imageService: imageService,
+		storage:      store,
+		signer:       signer,
+		logger:       logger,
+	}
+}
+
+// UploadImage 上传图片，服务端解码校验并去除元数据，返回原图和各尺寸版本的地址
 func (h *UploadHandler) UploadImage(c *gin.Context) {
 	// 获取上传的文件
 	file, header, err := c.Request.FormFile("file")
@@ -35,60 +53,89 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 		return
 	}
 
-	// 检查文件大小 (5MB)
-	if err := utils.ValidateFileSize(header, 5); err != nil {
+	// 检查文件大小
+	if err := utils.ValidateFileSize(header, maxImageUploadMB); err != nil {
 		response.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// 创建上传目录
-	uploadDir := "uploads/images"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		response.Error(c, http.StatusInternalServerError, "创建目录失败")
-		return
-	}
-
-	// 生成唯一文件名
-	filename := utils.GenerateUniqueFilename(header.Filename)
-	filepath := filepath.Join(uploadDir, filename)
-
-	// 创建文件
-	dst, err := os.Create(filepath)
+	data, err := io.ReadAll(io.LimitReader(file, maxImageUploadMB<<20))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "创建文件失败")
+		response.Error(c, http.StatusInternalServerError, "读取文件失败")
 		return
 	}
-	defer dst.Close()
 
-	// 复制文件内容
-	if _, err := io.Copy(dst, file); err != nil {
+	image, err := h.imageService.SaveImage(data)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImage) || errors.Is(err, service.ErrImageTooLarge) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.Errorf("Failed to save image: %v", err)
 		response.Error(c, http.StatusInternalServerError, "保存文件失败")
 		return
 	}
 
-	// 返回文件URL
-	fileURL := fmt.Sprintf("/uploads/images/%s", filename)
-	contentType := header.Header.Get("Content-Type")
-
 	response.Success(c, gin.H{
-		"url":      fileURL,
-		"filename": filename,
-		"size":     utils.FormatFileSize(header.Size),
-		"size_bytes": header.Size,
-		"type":     contentType,
+		"url":        image.URL,
+		"filename":   image.Filename,
+		"size":       utils.FormatFileSize(image.SizeBytes),
+		"size_bytes": image.SizeBytes,
+		"type":       image.ContentType,
+		"width":      image.Width,
+		"height":     image.Height,
+		"hash":       image.Hash,
+		"duplicate":  image.Duplicate,
+		"variants":   image.Variants,
 	})
 }
 
-// ServeImage 提供图片服务
+// ServeImage 提供图片服务，从配置的存储后端读取，多实例部署时各实例返回相同的内容
 func (h *UploadHandler) ServeImage(c *gin.Context) {
-	filename := c.Param("filename")
-	filepath := filepath.Join("uploads/images", filename)
+	rc, obj, err := h.imageService.OpenImage(c.Param("filename"))
+	if err != nil {
+		h.handleStorageError(c, err)
+		return
+	}
+	// 文件名在上传时生成且内容不会变化，可以长期缓存
+	serveObject(c, rc, obj, "public, max-age=31536000, immutable")
+}
+
+// ServeSignedFile 通过签名地址下载不公开的文件，地址由存储后端的 SignedURL 生成
+func (h *UploadHandler) ServeSignedFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := h.signer.Verify(key, c.Query("expires"), c.Query("signature"), time.Now()); err != nil {
+		response.Error(c, http.StatusForbidden, "下载链接无效或已过期")
+		return
+	}
+
+	rc, obj, err := h.storage.Get(c.Request.Context(), key)
+	if err != nil {
+		h.handleStorageError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(key)}))
+	serveObject(c, rc, obj, "private, no-store")
+}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(filepath); os.IsNotExist(err) {
+func (h *UploadHandler) handleStorageError(c *gin.Context, err error) {
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
 		c.Status(http.StatusNotFound)
 		return
 	}
+	h.logger.Errorf("Failed to read file: %v", err)
+	c.Status(http.StatusInternalServerError)
+}
 
-	c.File(filepath)
+// serveObject 输出存储中的文件，本地文件支持 Range 和条件请求
+func serveObject(c *gin.Context, rc io.ReadCloser, obj *storage.Object, cacheControl string) {
+	defer rc.Close()
+	c.Header("Cache-Control", cacheControl)
+	c.Header("X-Content-Type-Options", "nosniff")
+	if rs, ok := rc.(io.ReadSeeker); ok {
+		c.Header("Content-Type", obj.ContentType)
+		http.ServeContent(c.Writer, c.Request, path.Base(obj.Key), obj.ModTime, rs)
+		return
+	}
+	c.DataFromReader(http.StatusOK, obj.Size, obj.ContentType, rc, nil)
 }
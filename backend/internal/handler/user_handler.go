@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"gin-web-framework/internal/middleware"
 	"gin-web-framework/internal/service"
 	"gin-web-framework/pkg/logger"
 	"gin-web-framework/pkg/response"
@@ -53,16 +55,33 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	req.Client = clientInfo(c)
 	loginResponse, err := h.userService.Login(req)
 	if err != nil {
+		var locked *service.LoginLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+			response.TooManyRequests(c, err.Error())
+			return
+		}
 		response.Unauthorized(c, err.Error())
 		return
 	}
+	if loginResponse.TwoFactorRequired {
+		response.Success(c, gin.H{
+			"message":             "Two-factor verification required",
+			"two_factor_required": true,
+			"challenge_token":     loginResponse.ChallengeToken,
+		})
+		return
+	}
 
 	response.Success(c, gin.H{
-		"message": "Login successful",
-		"token":   loginResponse.Token,
-		"user":    loginResponse.User,
+		"message":       "Login successful",
+		"token":         loginResponse.Token,
+		"refresh_token": loginResponse.RefreshToken,
+		"expires_in":    loginResponse.ExpiresIn,
+		"user":          loginResponse.User,
 	})
 }
 
@@ -124,6 +143,9 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		response.BadRequest(c, "Invalid request data: "+err.Error())
 		return
 	}
+	if claims, exists := middleware.GetCurrentClaims(c); exists {
+		req.SessionID = claims.SessionID
+	}
 
 	err = h.userService.ChangePassword(userID, req)
 	if err != nil {
@@ -136,7 +158,43 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	})
 }
 
-// RefreshToken 刷新token
+// VerifyTwoFactorLogin 登录第二步，提交挑战令牌和验证码（或恢复码）
+func (h *UserHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	var req service.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	req.Client = clientInfo(c)
+	loginResponse, err := h.userService.VerifyTwoFactorLogin(req)
+	if err != nil {
+		var locked *service.LoginLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(locked.RetryAfterSeconds()))
+			response.TooManyRequests(c, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrInvalidLoginChallenge) || errors.Is(err, service.ErrInvalidTwoFactorCode) ||
+			errors.Is(err, service.ErrTwoFactorNotEnabled) {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		h.logger.Errorf("Failed to verify two-factor login: %v", err)
+		response.InternalServerError(c, "Failed to verify two-factor login")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message":       "Login successful",
+		"token":         loginResponse.Token,
+		"refresh_token": loginResponse.RefreshToken,
+		"expires_in":    loginResponse.ExpiresIn,
+		"user":          loginResponse.User,
+	})
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌和刷新令牌
 func (h *UserHandler) RefreshToken(c *gin.Context) {
 	var req service.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -144,19 +202,61 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	tokenResponse, err := h.userService.RefreshToken(req.Token)
+	req.Client = clientInfo(c)
+	tokenResponse, err := h.userService.RefreshToken(req)
 	if err != nil {
-		response.Unauthorized(c, err.Error())
+		if errors.Is(err, service.ErrInvalidRefreshToken) || errors.Is(err, service.ErrRefreshTokenReused) {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		h.logger.Errorf("Failed to refresh token: %v", err)
+		response.InternalServerError(c, "Failed to refresh token")
 		return
 	}
 
 	response.Success(c, gin.H{
-		"message": "Token refreshed successfully",
-		"token":   tokenResponse.Token,
-		"user":    tokenResponse.User,
+		"message":       "Token refreshed successfully",
+		"token":         tokenResponse.Token,
+		"refresh_token": tokenResponse.RefreshToken,
+		"expires_in":    tokenResponse.ExpiresIn,
+		"user":          tokenResponse.User,
 	})
 }
 
+// Logout 退出当前会话
+func (h *UserHandler) Logout(c *gin.Context) {
+	claims, exists := middleware.GetCurrentClaims(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.userService.Logout(claims); err != nil {
+		h.logger.Errorf("Failed to log out: %v", err)
+		response.InternalServerError(c, "Failed to log out")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Logged out successfully"})
+}
+
+// LogoutAll 退出所有设备上的登录，包括当前会话
+func (h *UserHandler) LogoutAll(c *gin.Context) {
+	userID, exists := middleware.GetCurrentUserID(c)
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	if err := h.userService.LogoutAll(userID); err != nil {
+		h.logger.Errorf("Failed to log out from all devices: %v", err)
+		response.InternalServerError(c, "Failed to log out")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "Logged out from all devices"})
+}
+
 // GetUsers 获取用户列表
 func (h *UserHandler) GetUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -172,3 +272,8 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		"users": users,
 	})
 }
+
+// clientInfo 请求方的 IP 和 User-Agent，用于记录登录会话和审计
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
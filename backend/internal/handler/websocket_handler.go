@@ -46,7 +46,7 @@ func (h *WebSocketHandler) WebSocket(c *gin.Context) {
 	}
 
 	// 验证token并获取用户ID
-	claims, err := jwt.ParseToken(token)
+	claims, err := jwt.ValidateToken(token)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -228,6 +228,7 @@ func (a *OptimizedAuditMiddleware) sanitizeRequestBody(body string) string {
 	sensitiveFields := []string{
 		"password", "token", "secret", "key", "auth",
 		"oldPassword", "newPassword", "confirmPassword",
+		"old_password", "new_password", "refresh_token", "challenge_token", "code",
 	}
 	
 	for _, field := range sensitiveFields {
@@ -257,7 +258,8 @@ func (a *OptimizedAuditMiddleware) sanitizeResponseBody(body []byte) string {
 
 	// 清理敏感字段
 	if dataField, ok := data["data"].(map[string]interface{}); ok {
-		sensitiveFields := []string{"token", "password", "secret", "key"}
+		sensitiveFields := []string{"token", "password", "secret", "key",
+			"refresh_token", "challenge_token", "provisioning_uri", "recovery_codes"}
 		for _, field := range sensitiveFields {
 			if _, exists := dataField[field]; exists {
 				dataField[field] = "***REDACTED***"
@@ -37,7 +37,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		token := parts[1]
 
 		// 验证JWT token
-		claims, err := jwt.ParseToken(token)
+		claims, err := jwt.ValidateToken(token)
 		if err != nil {
 			logger.Warnf("Invalid JWT token: %v from IP: %s", err, c.ClientIP())
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
@@ -51,7 +51,8 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
-		c.Set("role", "user") // 默认角色，实际项目中应该从数据库获取
+		c.Set("role", claimsRole(claims))
+		c.Set("claims", claims)
 
 		logger.Debugf("User authenticated: %s (ID: %d) from IP: %s",
 			claims.Username, claims.UserID, c.ClientIP())
@@ -83,7 +84,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		token := parts[1]
 
 		// 验证JWT token
-		claims, err := jwt.ParseToken(token)
+		claims, err := jwt.ValidateToken(token)
 		if err != nil {
 			// token无效，但不阻止请求
 			logger.Debugf("Invalid JWT token in optional auth: %v from IP: %s", err, c.ClientIP())
@@ -95,7 +96,7 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
-		c.Set("role", "user") // 默认角色，实际项目中应该从数据库获取
+		c.Set("role", claimsRole(claims))
 
 		logger.Debugf("User authenticated (optional): %s (ID: %d) from IP: %s",
 			claims.Username, claims.UserID, c.ClientIP())
@@ -104,6 +105,14 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 	})
 }
 
+// claimsRole 返回令牌中的角色，旧令牌没有角色时按普通用户处理
+func claimsRole(claims *jwt.Claims) string {
+	if claims.Role == "" {
+		return "user"
+	}
+	return claims.Role
+}
+
 // RoleMiddleware 角色授权中间件
 func RoleMiddleware(requiredRoles ...string) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
@@ -194,6 +203,15 @@ func GetCurrentUserRole(c *gin.Context) (string, bool) {
 	return role.(string), true
 }
 
+// GetCurrentClaims 获取当前访问令牌的声明，退出登录时用于吊销该令牌和所属会话
+func GetCurrentClaims(c *gin.Context) (*jwt.Claims, bool) {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return nil, false
+	}
+	return claims.(*jwt.Claims), true
+}
+
 // RequireAuth 要求认证的辅助函数
 func RequireAuth(c *gin.Context) (uint, error) {
 	userID, exists := GetCurrentUserID(c)
@@ -63,7 +63,7 @@ func Auth() gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// 验证JWT token
-		claims, err := jwt.ParseToken(tokenString)
+		claims, err := jwt.ValidateToken(tokenString)
 		if err != nil {
 			response.Unauthorized(c, "Invalid token: "+err.Error())
 			c.Abort()
@@ -138,7 +138,7 @@ func CORS() gin.HandlerFunc {
 		AllowOrigins:     appCfg.CORSOrigins,
 		AllowMethods:     appCfg.CORSMethods,
 		AllowHeaders:     appCfg.CORSHeaders,
-		ExposeHeaders:    []string{"Content-Length", "X-Total-Count", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count", "X-Request-ID", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
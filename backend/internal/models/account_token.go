@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// 账号令牌用途
+const (
+	AccountTokenPasswordReset     = "password_reset"
+	AccountTokenEmailVerification = "email_verification"
+)
+
+// AccountToken 邮件中发出的签名令牌（重置密码、验证邮箱）的使用记录。
+// 令牌本身由签名保证有效性，这里按 Nonce 记录，保证每个令牌只能使用一次
+type AccountToken struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Purpose   string     `json:"purpose" gorm:"size:32;not null"`
+	Nonce     string     `json:"-" gorm:"size:32;not null;uniqueIndex"`
+	Email     string     `json:"email" gorm:"size:255"` // 签发时的邮箱，验证邮箱时必须与用户当前邮箱一致
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (AccountToken) TableName() string {
+	return "account_tokens"
+}
@@ -8,26 +8,31 @@ import (
 
 // Article 文章模型
 type Article struct {
-	ID         uint           `json:"id" gorm:"primaryKey"`
-	Title      string         `json:"title" gorm:"not null"`
-	Content    string         `json:"content" gorm:"type:text"`
-	Summary    string         `json:"summary" gorm:"type:text"`
-	CoverImage string         `json:"cover_image"`
-	Status     string         `json:"status" gorm:"default:'draft'"` // draft, published, archived
-	Tags       string         `json:"tags" gorm:"type:text"`         // JSON格式存储标签
-	ViewCount  int            `json:"view_count" gorm:"default:0"`
-	LikeCount  int            `json:"like_count" gorm:"default:0"`
-	CreatedBy  uint           `json:"created_by" gorm:"not null"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	Title       string         `json:"title" gorm:"not null"`
+	Slug        *string        `json:"slug" gorm:"size:200;uniqueIndex"` // 公开访问地址中的标识，全站唯一
+	Content     string         `json:"content" gorm:"type:text"`
+	Summary     string         `json:"summary" gorm:"type:text"`
+	CoverImage  string         `json:"cover_image"`
+	Status      string         `json:"status" gorm:"default:'draft'"` // draft, scheduled, published, archived
+	Tags        string         `json:"tags" gorm:"type:text"`         // JSON格式存储标签
+	ViewCount   int            `json:"view_count" gorm:"default:0"`
+	LikeCount   int            `json:"like_count" gorm:"default:0"`
+	PublishAt   *time.Time     `json:"publish_at" gorm:"index"`   // 定时发布时间，status 为 scheduled 时有效
+	UnpublishAt *time.Time     `json:"unpublish_at" gorm:"index"` // 到期自动归档时间
+	PublishedAt *time.Time     `json:"published_at"`              // 首次发布时间
+	CreatedBy   uint           `json:"created_by" gorm:"not null"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	// 关联
 	User      User          `json:"user" gorm:"foreignKey:CreatedBy"`
 	UserLikes []ArticleLike `json:"-" gorm:"foreignKey:ArticleID"`
 
 	// 虚拟字段 - 不存储在数据库中
-	IsLikedByUser bool `json:"is_liked_by_user" gorm:"-"`
+	IsLikedByUser bool             `json:"is_liked_by_user" gorm:"-"`
+	Search        *SearchHighlight `json:"search,omitempty" gorm:"-"` // 全文检索时的命中信息
 }
 
 // ArticleLike 文章点赞关系模型
@@ -47,3 +52,57 @@ type ArticleLike struct {
 func (ArticleLike) TableName() string {
 	return "article_likes"
 }
+
+// ArticleRevision 文章修订版本，每次保存标题、摘要或正文时生成，创建后不再修改
+type ArticleRevision struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	ArticleID    uint      `json:"article_id" gorm:"not null;uniqueIndex:idx_article_revision"`
+	Number       int       `json:"number" gorm:"not null;uniqueIndex:idx_article_revision"` // 文章内从1开始递增的版本号
+	Title        string    `json:"title" gorm:"not null"`
+	Summary      string    `json:"summary,omitempty" gorm:"type:text"`
+	Content      string    `json:"content,omitempty" gorm:"type:text"`
+	AuthorID     uint      `json:"author_id" gorm:"not null"`
+	RestoredFrom *int      `json:"restored_from,omitempty"` // 由恢复操作生成时，对应的源版本号
+	CreatedAt    time.Time `json:"created_at"`
+
+	// 关联
+	Author *User `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+}
+
+// TableName 指定表名
+func (ArticleRevision) TableName() string {
+	return "article_revisions"
+}
+
+// ArticleComment 文章评论。回复通过 ParentID 指向被回复的评论，RootID 指向所在讨论串的顶层评论，
+// 便于按讨论串一次加载全部回复
+type ArticleComment struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	ArticleID        uint           `json:"article_id" gorm:"not null;index"`
+	ParentID         *uint          `json:"parent_id"`
+	RootID           *uint          `json:"root_id" gorm:"index"`
+	Depth            int            `json:"depth" gorm:"default:0"` // 顶层评论为0
+	UserID           uint           `json:"user_id" gorm:"not null;index"`
+	Content          string         `json:"content" gorm:"type:text;not null"`
+	Status           string         `json:"status" gorm:"size:20;default:'approved';index"` // approved, pending, hidden
+	EditedAt         *time.Time     `json:"edited_at"`
+	ModeratedBy      *uint          `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time     `json:"moderated_at,omitempty"`
+	ModerationReason string         `json:"moderation_reason,omitempty" gorm:"size:500"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联
+	User    *User    `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Article *Article `json:"article,omitempty" gorm:"foreignKey:ArticleID"` // 仅审核队列加载
+
+	// 虚拟字段 - 不存储在数据库中
+	IsDeleted bool              `json:"is_deleted,omitempty" gorm:"-"` // 已删除或已隐藏但仍有回复时作为占位保留
+	Replies   []*ArticleComment `json:"replies,omitempty" gorm:"-"`
+}
+
+// TableName 指定表名
+func (ArticleComment) TableName() string {
+	return "article_comments"
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// 附件可关联的内容类型
+const (
+	AttachmentTargetTodo    = "todo"
+	AttachmentTargetArticle = "article"
+)
+
+// Attachment 上传的附件。TargetType 为空表示尚未关联到任务或文章，
+// 长期未关联或关联的内容已被删除的附件由定时任务清理
+type Attachment struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"` // 上传者，占用其存储配额
+	TargetType string    `json:"target_type" gorm:"size:20;index:idx_attachment_target"`
+	TargetID   *uint     `json:"target_id" gorm:"index:idx_attachment_target"`
+	Filename   string    `json:"filename" gorm:"size:255;not null"` // 原始文件名
+	StorageKey string    `json:"-" gorm:"size:500;not null;uniqueIndex"`
+	MimeType   string    `json:"mime_type" gorm:"size:100;not null"`
+	Size       int64     `json:"size" gorm:"not null"`
+	Checksum   string    `json:"checksum" gorm:"size:64;not null;index"` // SHA-256
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// 虚拟字段 - 限时有效的下载地址
+	URL string `json:"url,omitempty" gorm:"-"`
+}
+
+// TableName 指定表名
+func (Attachment) TableName() string {
+	return "attachments"
+}
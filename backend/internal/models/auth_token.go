@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken 服务端保存的刷新令牌，只存哈希。每次刷新都会轮换出新令牌，
+// 同一次登录轮换出的令牌属于同一个 FamilyID（即登录会话），已轮换的令牌再次使用时整个令牌族被吊销
+type RefreshToken struct {
+	ID              uint       `json:"id" gorm:"primarykey"`
+	UserID          uint       `json:"user_id" gorm:"not null;index"`
+	FamilyID        string     `json:"family_id" gorm:"size:32;not null;index"`
+	TokenHash       string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	AccessTokenID   string     `json:"-" gorm:"size:32"` // 与该刷新令牌一同签发的访问令牌 jti，吊销时一并加入吊销列表
+	AccessExpiresAt time.Time  `json:"-"`
+	ExpiresAt       time.Time  `json:"expires_at" gorm:"not null;index"`
+	RotatedAt       *time.Time `json:"rotated_at"`
+	RevokedAt       *time.Time `json:"revoked_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// RevokedToken 已吊销但尚未过期的访问令牌，过期后由定时任务清理
+type RevokedToken struct {
+	TokenID   string    `gorm:"primaryKey;size:32"` // jti
+	UserID    uint      `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
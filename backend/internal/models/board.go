@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Board 看板
+type Board struct {
+	ID          uint           `json:"id" gorm:"primarykey"`
+	Name        string         `json:"name" gorm:"size:100;not null"`
+	Description string         `json:"description" gorm:"size:500"`
+	ListID      *uint          `json:"list_id" gorm:"index"` // 共享清单看板，为空时为个人看板
+	CreatedBy   uint           `json:"created_by" gorm:"not null;index"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联
+	Columns []BoardColumn `json:"columns,omitempty" gorm:"foreignKey:BoardID"`
+}
+
+// BoardColumn 看板列
+type BoardColumn struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	BoardID   uint      `json:"board_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	Status    string    `json:"status" gorm:"size:20"`            // 映射的任务状态，为空表示自定义泳道
+	Position  string    `json:"position" gorm:"size:64;not null"` // 排序键（lexorank）
+	WipLimit  int       `json:"wip_limit" gorm:"default:0"`       // 在制品上限，0表示不限
+	Color     string    `json:"color" gorm:"size:20"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 非数据库字段
+	Todos []*Todo `json:"todos,omitempty" gorm:"-"`
+}
+
+// TableName 指定表名
+func (Board) TableName() string {
+	return "boards"
+}
+
+func (BoardColumn) TableName() string {
+	return "board_columns"
+}
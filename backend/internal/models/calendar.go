@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// CalendarFeedToken 日历订阅令牌，日历客户端通过令牌访问用户的ICS订阅源
+type CalendarFeedToken struct {
+	ID             uint       `json:"id" gorm:"primarykey"`
+	UserID         uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	Token          string     `json:"token" gorm:"size:64;not null;uniqueIndex"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CalendarFeedToken) TableName() string {
+	return "calendar_feed_tokens"
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// SavedFilter 用户保存的任务过滤器（智能清单），Query 为过滤表达式，
+// 例如 `status:pending priority:>=3 due:next7d 周报`
+type SavedFilter struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	Query     string    `json:"query" gorm:"size:1000;not null"`
+	Icon      string    `json:"icon" gorm:"size:50"`
+	Color     string    `json:"color" gorm:"size:20"`
+	Sort      int       `json:"sort" gorm:"default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 虚拟字段 - 匹配的任务数，用于侧边栏展示
+	Count *int64 `json:"count,omitempty" gorm:"-"`
+}
+
+// TableName 指定表名
+func (SavedFilter) TableName() string {
+	return "todo_saved_filters"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// SearchDocument 全文索引文档，保存被索引内容的原文，用于生成高亮摘要
+type SearchDocument struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	DocType   string    `json:"doc_type" gorm:"size:20;not null;uniqueIndex:idx_search_document"` // todo, article
+	DocID     uint      `json:"doc_id" gorm:"not null;uniqueIndex:idx_search_document"`
+	Title     string    `json:"title" gorm:"type:text"`
+	Body      string    `json:"body" gorm:"type:text"`
+	Tags      string    `json:"tags" gorm:"type:text"` // 空格分隔
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SearchDocument) TableName() string {
+	return "search_documents"
+}
+
+// SearchHighlight 全文检索的命中信息，Title 和 Snippet 为带 <mark> 标记的HTML转义文本
+type SearchHighlight struct {
+	Score   float64 `json:"score"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+}
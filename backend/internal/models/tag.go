@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Tag 标签，文章、歌曲和视频系列共用。Slug 为规范化（小写）后的名称，用于去重和查找
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:50;not null"`
+	Slug      string    `json:"slug" gorm:"size:50;not null;uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// ArticleTag 文章与标签的关联，Position 保留标签在文章中的顺序
+type ArticleTag struct {
+	ArticleID uint `json:"article_id" gorm:"primaryKey;autoIncrement:false"`
+	TagID     uint `json:"tag_id" gorm:"primaryKey;autoIncrement:false;index"`
+	Position  int  `json:"position" gorm:"default:0"`
+}
+
+// TableName 指定表名
+func (ArticleTag) TableName() string {
+	return "article_tags"
+}
+
+// SongTag 歌曲与标签的关联
+type SongTag struct {
+	SongID   uint `json:"song_id" gorm:"primaryKey;autoIncrement:false"`
+	TagID    uint `json:"tag_id" gorm:"primaryKey;autoIncrement:false;index"`
+	Position int  `json:"position" gorm:"default:0"`
+}
+
+// TableName 指定表名
+func (SongTag) TableName() string {
+	return "song_tags"
+}
+
+// VideoSeriesTag 视频系列与标签的关联
+type VideoSeriesTag struct {
+	SeriesID uint `json:"series_id" gorm:"primaryKey;autoIncrement:false"`
+	TagID    uint `json:"tag_id" gorm:"primaryKey;autoIncrement:false;index"`
+	Position int  `json:"position" gorm:"default:0"`
+}
+
+// TableName 指定表名
+func (VideoSeriesTag) TableName() string {
+	return "video_series_tags"
+}
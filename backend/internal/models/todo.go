@@ -32,20 +32,74 @@ type Todo struct {
 	Description    string         `json:"description"`
 	Status         string         `json:"status" gorm:"default:'pending'"` // pending, in_progress, completed, cancelled
 	PriorityID     uint           `json:"priority_id" gorm:"not null"`
-	CategoryID     *uint          `json:"category_id"`     // 改为可选，因为可能没有分类
-	StartDate      *time.Time     `json:"start_date"`      // 开始时间
-	DueDate        *time.Time     `json:"due_date"`        // 截止时间
-	CompletedAt    *time.Time     `json:"completed_at"`    // 完成时间
-	EstimatedHours float64        `json:"estimated_hours"` // 预估工时（小时）
-	ActualHours    float64        `json:"actual_hours"`    // 实际工时（小时）
+	CategoryID     *uint          `json:"category_id"`                                              // 改为可选，因为可能没有分类
+	StartDate      *time.Time     `json:"start_date"`                                               // 开始时间
+	DueDate        *time.Time     `json:"due_date"`                                                 // 截止时间
+	CompletedAt    *time.Time     `json:"completed_at"`                                             // 完成时间
+	EstimatedHours float64        `json:"estimated_hours"`                                          // 预估工时（小时）
+	ActualHours    float64        `json:"actual_hours"`                                             // 实际工时（小时），有计时记录时由记录汇总
+	RecurrenceID   *uint          `json:"recurrence_id" gorm:"index"`                               // 所属重复系列
+	ParentID       *uint          `json:"parent_id" gorm:"index"`                                   // 父任务（子任务时非空）
+	ListID         *uint          `json:"list_id" gorm:"index"`                                     // 所属共享清单
+	AssigneeID     *uint          `json:"assignee_id" gorm:"index"`                                 // 负责人
+	BoardColumnID  *uint          `json:"board_column_id" gorm:"index"`                             // 所在看板列
+	BoardPosition  string         `json:"board_position" gorm:"size:64"`                            // 看板列内排序键（lexorank）
+	ICalUID        string         `json:"ical_uid,omitempty" gorm:"column:ical_uid;size:255;index"` // 从日历导入时的原始UID
 	CreatedBy      uint           `json:"created_by" gorm:"not null"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// 关联
-	Priority TodoPriority `json:"priority" gorm:"foreignKey:PriorityID"`
-	Category *Category    `json:"category" gorm:"foreignKey:CategoryID"`
+	Priority   TodoPriority    `json:"priority" gorm:"foreignKey:PriorityID"`
+	Category   *Category       `json:"category" gorm:"foreignKey:CategoryID"`
+	Recurrence *TodoRecurrence `json:"recurrence,omitempty" gorm:"foreignKey:RecurrenceID"`
+	Assignee   *User           `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID"`
+
+	// 虚拟字段 - 全文检索时的命中信息
+	Search *SearchHighlight `json:"search,omitempty" gorm:"-"`
+}
+
+// TodoRecurrence TODO重复规则（RFC 5545 RRULE子集）
+type TodoRecurrence struct {
+	ID              uint           `json:"id" gorm:"primarykey"`
+	RRule           string         `json:"rrule" gorm:"size:255;not null"`    // 例如 FREQ=WEEKLY;INTERVAL=1;BYDAY=MO
+	OccurrenceCount int            `json:"occurrence_count" gorm:"default:1"` // 已生成的实例数（含首个）
+	IsActive        bool           `json:"is_active" gorm:"default:true"`     // 停止后不再生成新实例
+	CreatedBy       uint           `json:"created_by" gorm:"not null;index"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TodoDependency TODO依赖关系：TodoID 被 BlockedByID 阻塞
+type TodoDependency struct {
+	ID          uint      `json:"id" gorm:"primarykey"`
+	TodoID      uint      `json:"todo_id" gorm:"not null;uniqueIndex:idx_todo_dependency"`
+	BlockedByID uint      `json:"blocked_by_id" gorm:"not null;uniqueIndex:idx_todo_dependency;index"`
+	CreatedBy   uint      `json:"created_by" gorm:"not null"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// 关联
+	BlockedBy Todo `json:"blocked_by" gorm:"foreignKey:BlockedByID"`
+}
+
+// TodoTimeEntry TODO计时记录，EndedAt 为空表示计时进行中
+type TodoTimeEntry struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	TodoID    uint           `json:"todo_id" gorm:"not null;index"`
+	UserID    uint           `json:"user_id" gorm:"not null;index"`
+	StartedAt time.Time      `json:"started_at" gorm:"not null;index"`
+	EndedAt   *time.Time     `json:"ended_at" gorm:"index"`
+	Duration  int64          `json:"duration"`                    // 时长（秒），计时结束后写入
+	Manual    bool           `json:"manual" gorm:"default:false"` // 是否手动补录
+	Note      string         `json:"note" gorm:"size:500"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联
+	Todo *Todo `json:"todo,omitempty" gorm:"foreignKey:TodoID"`
 }
 
 // TodoNotification TODO通知
@@ -76,6 +130,18 @@ func (Todo) TableName() string {
 	return "todos"
 }
 
+func (TodoRecurrence) TableName() string {
+	return "todo_recurrences"
+}
+
+func (TodoDependency) TableName() string {
+	return "todo_dependencies"
+}
+
+func (TodoTimeEntry) TableName() string {
+	return "todo_time_entries"
+}
+
 func (TodoNotification) TableName() string {
 	return "todo_notifications"
 }
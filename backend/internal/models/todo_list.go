@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 共享清单成员角色
+const (
+	TodoListRoleOwner  = "owner"
+	TodoListRoleEditor = "editor"
+	TodoListRoleViewer = "viewer"
+)
+
+// TodoList 可共享的任务清单（项目）
+type TodoList struct {
+	ID          uint           `json:"id" gorm:"primarykey"`
+	Name        string         `json:"name" gorm:"size:100;not null"`
+	Description string         `json:"description" gorm:"size:500"`
+	Color       string         `json:"color" gorm:"size:20;default:'#3B82F6'"`
+	OwnerID     uint           `json:"owner_id" gorm:"not null;index"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// 关联
+	Owner   User             `json:"owner" gorm:"foreignKey:OwnerID"`
+	Members []TodoListMember `json:"members,omitempty" gorm:"foreignKey:ListID"`
+}
+
+// TodoListMember 清单成员
+type TodoListMember struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	ListID    uint      `json:"list_id" gorm:"not null;uniqueIndex:idx_list_member"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_list_member;index"`
+	Role      string    `json:"role" gorm:"size:20;not null;default:'viewer'"` // owner, editor, viewer
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// 关联
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// TableName 指定表名
+func (TodoList) TableName() string {
+	return "todo_lists"
+}
+
+func (TodoListMember) TableName() string {
+	return "todo_list_members"
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// UserTwoFactor 用户的 TOTP 两步验证配置。EnabledAt 为空表示已生成密钥但尚未完成验证
+type UserTwoFactor struct {
+	ID           uint       `json:"id" gorm:"primarykey"`
+	UserID       uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	Secret       string     `json:"-" gorm:"size:64;not null"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `json:"-"` // 最近一次通过校验的时间步，不大于它的验证码视为重放
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserTwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+// RecoveryCode 两步验证的一次性恢复码，只保存 bcrypt 哈希
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primarykey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"size:100;not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// LoginChallenge 密码校验通过后等待第二步验证的登录，令牌只保存哈希
+type LoginChallenge struct {
+	ID        uint      `gorm:"primarykey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	Attempts  int       `gorm:"not null;default:0"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName 指定表名
+func (LoginChallenge) TableName() string {
+	return "login_challenges"
+}
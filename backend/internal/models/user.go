@@ -16,6 +16,8 @@ type User struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"` // 为空表示邮箱尚未验证，修改邮箱后需要重新验证
 }
 
 // UserRole 定义用户角色常量
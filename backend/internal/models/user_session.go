@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// UserSession 一次登录产生的会话，SessionID 即刷新令牌的 FamilyID。
+// 会话是否有效由令牌族中是否还有未吊销、未过期的刷新令牌决定，这里只保存设备和最近活动信息
+type UserSession struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	SessionID  string    `json:"-" gorm:"size:32;not null;uniqueIndex"`
+	IPAddress  string    `json:"ip_address" gorm:"size:45"`
+	UserAgent  string    `json:"user_agent" gorm:"size:512"`
+	DeviceName string    `json:"device_name" gorm:"size:100;index"` // 浏览器和操作系统，不含版本号，用于判断新设备
+	Location   string    `json:"location" gorm:"size:100"`          // 不依赖地理库的位置描述，如“本机”“局域网”或打码的 IP
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" gorm:"index"`
+
+	Current bool `json:"current" gorm:"-"` // 是否为发起请求的会话
+}
+
+// TableName 指定表名
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
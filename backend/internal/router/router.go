@@ -21,6 +21,15 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 
 	r := gin.New()
 
+	// 只采信受信任代理转发的 X-Forwarded-For，默认不信任任何代理，ClientIP 取连接的远端地址，
+	// 避免客户端伪造 IP 绕过按 IP 的登录限制
+	appCfg := cfg.GetApp()
+	if err := r.SetTrustedProxies(appCfg.TrustedProxies); err != nil {
+		logger.Error("Invalid trusted proxies, no proxy will be trusted: " + err.Error())
+		_ = r.SetTrustedProxies(nil)
+	}
+	r.TrustedPlatform = appCfg.TrustedPlatform
+
 	// 使用中间件
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
@@ -39,7 +48,7 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -60,6 +69,9 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 	userHandler := container.GetUserHandler()
 	todoHandler := container.GetTodoHandler()
 	articleHandler := container.GetArticleHandler()
+	commentHandler := container.GetCommentHandler()
+	tagHandler := container.GetTagHandler()
+	publicArticleHandler := container.GetPublicArticleHandler()
 	notificationHandler := container.GetNotificationHandler()
 	statisticsHandler := container.GetStatisticsHandler()
 	categoryHandler := container.GetCategoryHandler()
@@ -67,6 +79,11 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 	networkHandler := container.GetNetworkHandler()
 	auditHandler := container.GetAuditHandler()
 	uploadHandler := container.GetUploadHandler()
+	attachmentHandler := container.GetAttachmentHandler()
+	sessionHandler := container.GetSessionHandler()
+	twoFactorHandler := container.GetTwoFactorHandler()
+	accountHandler := container.GetAccountHandler()
+	loginGuardHandler := container.GetLoginGuardHandler()
 
 	// API路由组
 	apiGroup := r.Group("/api/v1")
@@ -86,12 +103,37 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 			users.POST("/login", userHandler.Login)
 			users.GET("/profile", middleware.AuthMiddleware(), userHandler.GetProfile)
 			users.PUT("/profile", middleware.AuthMiddleware(), userHandler.UpdateProfile)
+			users.GET("/sessions", middleware.AuthMiddleware(), sessionHandler.GetSessions)
+			users.DELETE("/sessions/:id", middleware.AuthMiddleware(), sessionHandler.RevokeSession)
 		}
 
 		// 认证相关路由
 		auth := apiGroup.Group("/auth")
 		{
 			auth.POST("/refresh", userHandler.RefreshToken)
+			auth.POST("/logout", middleware.AuthMiddleware(), userHandler.Logout)
+			auth.POST("/logout-all", middleware.AuthMiddleware(), userHandler.LogoutAll)
+
+			// 找回密码和验证邮箱，令牌来自邮件中的链接
+			auth.POST("/forgot-password", accountHandler.ForgotPassword)
+			auth.POST("/reset-password", accountHandler.ResetPassword)
+			auth.POST("/verify-email", accountHandler.VerifyEmail)
+			auth.POST("/verify-email/send", middleware.AuthMiddleware(), accountHandler.SendVerification)
+
+			// 两步验证，/2fa/login 是登录第二步，使用登录接口返回的挑战令牌而非访问令牌
+			auth.POST("/2fa/login", userHandler.VerifyTwoFactorLogin)
+			auth.GET("/2fa", middleware.AuthMiddleware(), twoFactorHandler.GetStatus)
+			auth.POST("/2fa/setup", middleware.AuthMiddleware(), twoFactorHandler.Setup)
+			auth.POST("/2fa/enable", middleware.AuthMiddleware(), twoFactorHandler.Enable)
+			auth.POST("/2fa/disable", middleware.AuthMiddleware(), twoFactorHandler.Disable)
+			auth.POST("/2fa/recovery-codes", middleware.AuthMiddleware(), twoFactorHandler.RegenerateRecoveryCodes)
+		}
+
+		// 用户管理路由（管理员）
+		userAdmin := apiGroup.Group("/admin/users", middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			userAdmin.DELETE("/:id/2fa", twoFactorHandler.AdminReset)
+			userAdmin.POST("/:id/unlock", loginGuardHandler.Unlock)
 		}
 
 		// TODO相关路由
@@ -99,11 +141,82 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 		{
 			todos.GET("", middleware.AuthMiddleware(), todoHandler.GetTodos)
 			todos.POST("", middleware.AuthMiddleware(), todoHandler.CreateTodo)
+			todos.POST("/quick", middleware.AuthMiddleware(), todoHandler.QuickAdd)
+
+			// 保存的过滤器（智能清单），通过 GET /todos?view=<id> 执行
+			savedFilterHandler := container.GetSavedFilterHandler()
+			todos.GET("/filters", middleware.AuthMiddleware(), savedFilterHandler.GetSavedFilters)
+			todos.POST("/filters", middleware.AuthMiddleware(), savedFilterHandler.CreateSavedFilter)
+			todos.GET("/filters/:filterId", middleware.AuthMiddleware(), savedFilterHandler.GetSavedFilter)
+			todos.PUT("/filters/:filterId", middleware.AuthMiddleware(), savedFilterHandler.UpdateSavedFilter)
+			todos.DELETE("/filters/:filterId", middleware.AuthMiddleware(), savedFilterHandler.DeleteSavedFilter)
+
 			todos.GET("/:id", middleware.AuthMiddleware(), todoHandler.GetTodo)
 			todos.PUT("/:id", middleware.AuthMiddleware(), todoHandler.UpdateTodo)
 			todos.DELETE("/:id", middleware.AuthMiddleware(), todoHandler.DeleteTodo)
+			todos.PUT("/:id/complete", middleware.AuthMiddleware(), todoHandler.MarkCompleted)
+			todos.GET("/:id/recurrence", middleware.AuthMiddleware(), todoHandler.GetRecurrence)
+			todos.PUT("/:id/recurrence", middleware.AuthMiddleware(), todoHandler.SetRecurrence)
+			todos.DELETE("/:id/recurrence", middleware.AuthMiddleware(), todoHandler.StopRecurrence)
+			todos.GET("/:id/subtasks", middleware.AuthMiddleware(), todoHandler.GetSubtasks)
+			todos.POST("/:id/subtasks", middleware.AuthMiddleware(), todoHandler.CreateSubtask)
+			todos.GET("/:id/dependencies", middleware.AuthMiddleware(), todoHandler.GetDependencies)
+			todos.POST("/:id/dependencies", middleware.AuthMiddleware(), todoHandler.AddDependency)
+			todos.DELETE("/:id/dependencies/:blockedById", middleware.AuthMiddleware(), todoHandler.RemoveDependency)
+			todos.GET("/timer", middleware.AuthMiddleware(), todoHandler.GetRunningTimer)
+			todos.POST("/timer/stop", middleware.AuthMiddleware(), todoHandler.StopTimer)
+			todos.POST("/:id/timer", middleware.AuthMiddleware(), todoHandler.StartTimer)
+			todos.GET("/:id/time-entries", middleware.AuthMiddleware(), todoHandler.GetTimeEntries)
+			todos.POST("/:id/time-entries", middleware.AuthMiddleware(), todoHandler.AddTimeEntry)
+			todos.DELETE("/:id/time-entries/:entryId", middleware.AuthMiddleware(), todoHandler.DeleteTimeEntry)
+			todos.GET("/:id/attachments", middleware.AuthMiddleware(), attachmentHandler.GetTodoAttachments)
+			todos.POST("/:id/attachments", middleware.AuthMiddleware(), attachmentHandler.UploadTodoAttachment)
+		}
+
+		// 共享清单相关路由
+		todoLists := apiGroup.Group("/todo-lists")
+		{
+			todoListHandler := container.GetTodoListHandler()
+			todoLists.GET("", middleware.AuthMiddleware(), todoListHandler.GetLists)
+			todoLists.POST("", middleware.AuthMiddleware(), todoListHandler.CreateList)
+			todoLists.GET("/:id", middleware.AuthMiddleware(), todoListHandler.GetList)
+			todoLists.PUT("/:id", middleware.AuthMiddleware(), todoListHandler.UpdateList)
+			todoLists.DELETE("/:id", middleware.AuthMiddleware(), todoListHandler.DeleteList)
+			todoLists.POST("/:id/members", middleware.AuthMiddleware(), todoListHandler.AddMember)
+			todoLists.PUT("/:id/members/:userId", middleware.AuthMiddleware(), todoListHandler.UpdateMember)
+			todoLists.DELETE("/:id/members/:userId", middleware.AuthMiddleware(), todoListHandler.RemoveMember)
+		}
+
+		// 看板相关路由
+		boards := apiGroup.Group("/boards")
+		{
+			boardHandler := container.GetBoardHandler()
+			boards.GET("", middleware.AuthMiddleware(), boardHandler.GetBoards)
+			boards.POST("", middleware.AuthMiddleware(), boardHandler.CreateBoard)
+			boards.GET("/:id", middleware.AuthMiddleware(), boardHandler.GetBoard)
+			boards.PUT("/:id", middleware.AuthMiddleware(), boardHandler.UpdateBoard)
+			boards.DELETE("/:id", middleware.AuthMiddleware(), boardHandler.DeleteBoard)
+			boards.POST("/:id/columns", middleware.AuthMiddleware(), boardHandler.CreateColumn)
+			boards.PUT("/:id/columns/:columnId", middleware.AuthMiddleware(), boardHandler.UpdateColumn)
+			boards.DELETE("/:id/columns/:columnId", middleware.AuthMiddleware(), boardHandler.DeleteColumn)
+			boards.POST("/:id/move", middleware.AuthMiddleware(), boardHandler.MoveTodo)
+		}
+
+		// 日历订阅相关路由，订阅源通过令牌鉴权
+		calendar := apiGroup.Group("/calendar")
+		{
+			calendarHandler := container.GetCalendarHandler()
+			calendar.GET("/feed/:token", calendarHandler.GetFeed)
+			calendar.GET("/token", middleware.AuthMiddleware(), calendarHandler.GetFeedToken)
+			calendar.POST("/token", middleware.AuthMiddleware(), calendarHandler.RegenerateFeedToken)
+			calendar.DELETE("/token", middleware.AuthMiddleware(), calendarHandler.RevokeFeedToken)
+			calendar.POST("/import", middleware.AuthMiddleware(), calendarHandler.ImportICS)
 		}
 
+		// 全局搜索
+		searchHandler := container.GetSearchHandler()
+		apiGroup.GET("/search", middleware.AuthMiddleware(), searchHandler.Search)
+
 		// 通知相关路由
 		notifications := apiGroup.Group("/notifications")
 		{
@@ -123,12 +236,57 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 			articles.POST("", middleware.AuthMiddleware(), articleHandler.CreateArticle)
 			articles.GET("", middleware.AuthMiddleware(), articleHandler.GetUserArticles)
 			articles.GET("/stats", middleware.AuthMiddleware(), articleHandler.GetArticleStats)
+			articles.POST("/preview", middleware.AuthMiddleware(), articleHandler.PreviewArticle)
+			articles.POST("/import", middleware.AuthMiddleware(), articleHandler.ImportArticles)
+			articles.GET("/export", middleware.AuthMiddleware(), articleHandler.ExportArticles)
 			articles.GET("/:id", middleware.AuthMiddleware(), articleHandler.GetArticleByID)
 			articles.PUT("/:id", middleware.AuthMiddleware(), articleHandler.UpdateArticle)
 			articles.DELETE("/:id", middleware.AuthMiddleware(), articleHandler.DeleteArticle)
+			articles.PUT("/:id/schedule", middleware.AuthMiddleware(), articleHandler.ScheduleArticle)
+			articles.DELETE("/:id/schedule", middleware.AuthMiddleware(), articleHandler.CancelArticleSchedule)
 			articles.POST("/:id/like", middleware.AuthMiddleware(), articleHandler.LikeArticle)
 			articles.DELETE("/:id/like", middleware.AuthMiddleware(), articleHandler.UnlikeArticle)
+			articles.GET("/:id/revisions", middleware.AuthMiddleware(), articleHandler.GetArticleRevisions)
+			articles.GET("/:id/revisions/diff", middleware.AuthMiddleware(), articleHandler.DiffArticleRevisions)
+			articles.GET("/:id/revisions/:revision", middleware.AuthMiddleware(), articleHandler.GetArticleRevision)
+			articles.POST("/:id/revisions/:revision/restore", middleware.AuthMiddleware(), articleHandler.RestoreArticleRevision)
 			articles.POST("/:id/view", articleHandler.IncrementViewCount) // 浏览量不需要认证
+			articles.GET("/:id/comments", middleware.AuthMiddleware(), commentHandler.GetComments)
+			articles.POST("/:id/comments", middleware.AuthMiddleware(), commentHandler.CreateComment)
+			articles.PUT("/:id/comments/:commentId", middleware.AuthMiddleware(), commentHandler.UpdateComment)
+			articles.DELETE("/:id/comments/:commentId", middleware.AuthMiddleware(), commentHandler.DeleteComment)
+			articles.GET("/:id/attachments", middleware.AuthMiddleware(), attachmentHandler.GetArticleAttachments)
+			articles.POST("/:id/attachments", middleware.AuthMiddleware(), attachmentHandler.UploadArticleAttachment)
+		}
+
+		// 评论审核路由（管理员）
+		commentAdmin := apiGroup.Group("/admin/comments", middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			commentAdmin.GET("", commentHandler.GetModerationQueue)
+			commentAdmin.PUT("/:commentId/approve", commentHandler.ApproveComment)
+			commentAdmin.PUT("/:commentId/hide", commentHandler.HideComment)
+		}
+
+		// 标签路由，标签云对未登录用户只统计公开内容
+		apiGroup.GET("/tags", middleware.OptionalAuthMiddleware(), tagHandler.GetTags)
+		tagAdmin := apiGroup.Group("/admin/tags", middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			tagAdmin.PUT("/:tagId", tagHandler.RenameTag)
+			tagAdmin.POST("/merge", tagHandler.MergeTags)
+		}
+
+		// 公开文章路由，无需登录，订阅源支持 ETag / Last-Modified 条件请求
+		public := apiGroup.Group("/public")
+		{
+			public.GET("/articles", publicArticleHandler.GetArticles)
+			public.GET("/articles/:slug", publicArticleHandler.GetArticle)
+			public.GET("/authors/:username/articles", publicArticleHandler.GetAuthorArticles)
+			public.GET("/feed.rss", publicArticleHandler.GetSiteFeed)
+			public.GET("/feed.atom", publicArticleHandler.GetSiteFeed)
+			public.GET("/authors/:username/feed.rss", publicArticleHandler.GetAuthorFeed)
+			public.GET("/authors/:username/feed.atom", publicArticleHandler.GetAuthorFeed)
+			public.GET("/tags/:tag/feed.rss", publicArticleHandler.GetTagFeed)
+			public.GET("/tags/:tag/feed.atom", publicArticleHandler.GetTagFeed)
 		}
 
 		// 上传相关路由
@@ -137,11 +295,25 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 			upload.POST("/image", middleware.AuthMiddleware(), uploadHandler.UploadImage)
 		}
 
+		// 私有文件的签名下载地址，签名本身即授权，不需要登录
+		apiGroup.GET("/files/*key", uploadHandler.ServeSignedFile)
+
+		// 附件相关路由，关联到任务和文章的附件也可以通过 /todos/:id/attachments 和 /articles/:id/attachments 访问
+		attachments := apiGroup.Group("/attachments", middleware.AuthMiddleware())
+		{
+			attachments.GET("", attachmentHandler.GetMyAttachments)
+			attachments.POST("", attachmentHandler.UploadAttachment)
+			attachments.GET("/:id", attachmentHandler.GetAttachment)
+			attachments.PUT("/:id", attachmentHandler.UpdateAttachment)
+			attachments.DELETE("/:id", attachmentHandler.DeleteAttachment)
+		}
+
 		// 统计相关路由
 		statistics := apiGroup.Group("/statistics")
 		{
 			statistics.GET("", middleware.AuthMiddleware(), statisticsHandler.GetStatistics)
 			statistics.GET("/trends", middleware.AuthMiddleware(), statisticsHandler.GetTrends)
+			statistics.GET("/estimates", middleware.AuthMiddleware(), statisticsHandler.GetEstimateReport)
 		}
 
 		// 分类相关路由
@@ -273,8 +445,9 @@ func Setup(container container.ContainerInterface) *gin.Engine {
 		}
 	}
 
-	// 静态文件服务
-	r.Static("/uploads", "./uploads")
+	// 上传的图片，从存储后端读取
+	r.GET("/uploads/images/:filename", uploadHandler.ServeImage)
+	r.HEAD("/uploads/images/:filename", uploadHandler.ServeImage)
 
 	return r
 }
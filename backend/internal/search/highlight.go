@@ -0,0 +1,120 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	markOpen  = "<mark>"
+	markClose = "</mark>"
+	ellipsis  = "…"
+)
+
+// span 命中区间 [start, end)，以 rune 为单位
+type span struct {
+	start, end int
+}
+
+// findSpans 查找所有命中区间（不区分大小写），重叠或相邻的区间会合并
+func findSpans(runes []rune, needles [][]rune) []span {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	var spans []span
+	for _, needle := range needles {
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if runesEqual(lower[i:i+len(needle)], needle) {
+				spans = append(spans, span{i, i + len(needle)})
+				i += len(needle) - 1
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			if s.end > last.end {
+				last.end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// countMatches 统计命中次数，用于 LIKE 降级时的相关度计算
+func countMatches(text string, needle []rune) int {
+	return len(findSpans([]rune(text), [][]rune{needle}))
+}
+
+// highlight 对命中词加 <mark> 标记并做HTML转义。maxRunes 大于 0 时截取包含首个命中的片段
+func highlight(text string, needles [][]rune, maxRunes int) string {
+	runes := []rune(text)
+	spans := findSpans(runes, needles)
+
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		if len(spans) > 0 {
+			// 命中词前保留约四分之一的上下文
+			start = spans[0].start - maxRunes/4
+			if start < 0 {
+				start = 0
+			}
+		}
+		end = start + maxRunes
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	pos := start
+	for _, s := range spans {
+		if s.end <= start || s.start >= end {
+			continue
+		}
+		if s.start < start {
+			s.start = start
+		}
+		if s.end > end {
+			s.end = end
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:s.start])))
+		b.WriteString(markOpen)
+		b.WriteString(html.EscapeString(string(runes[s.start:s.end])))
+		b.WriteString(markClose)
+		pos = s.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
@@ -0,0 +1,312 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// PluginName 全文索引在 gorm 中注册的插件名
+const PluginName = "search"
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+	snippetRunes = 160
+)
+
+// Document 待索引的文档
+type Document struct {
+	Type  string
+	ID    uint
+	Title string
+	Body  string
+	Tags  []string
+}
+
+// Scope 参与检索的文档类型及其可见范围
+type Scope struct {
+	Type string
+	IDs  *gorm.DB // 可选，返回可见文档ID的单列子查询，为空时不限制
+}
+
+// Query 搜索条件
+type Query struct {
+	Text   string   // 用户输入的查询文本，双引号包裹的内容按短语匹配
+	Scopes []Scope  // 参与检索的文档类型，Facets 按这些类型统计
+	Types  []string // 可选，只返回这些类型的命中
+	Offset int
+	Limit  int
+}
+
+// Hit 搜索命中，Title 和 Snippet 已做HTML转义并用 <mark> 标记命中词
+type Hit struct {
+	Type    string  `json:"type"`
+	ID      uint    `json:"id"`
+	Score   float64 `json:"score"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+}
+
+// Result 搜索结果，按相关度降序。Facets 为各类型的命中数，不受 Query.Types 限制
+type Result struct {
+	Hits   []Hit            `json:"hits"`
+	Total  int64            `json:"total"`
+	Facets map[string]int64 `json:"facets"`
+}
+
+// Index 可插拔的全文索引。索引实现为 gorm 插件，通过 db.Use 注册后可在任意会话中用 FromDB 取得，
+// 方法接收调用方的 db 以便在业务事务内写入（内部使用嵌套事务，索引失败不会中断外层事务）
+type Index interface {
+	gorm.Plugin
+	Driver() string
+	Index(db *gorm.DB, docs ...Document) error
+	Remove(db *gorm.DB, docType string, ids ...uint) error
+	Clear(db *gorm.DB, docType string) error
+	Count(db *gorm.DB, docType string) (int64, error)
+	Search(db *gorm.DB, q Query) (*Result, error)
+}
+
+// dialect 各数据库的全文检索实现
+type dialect interface {
+	name() string
+	// setup 创建索引结构，不支持时可返回降级实现
+	setup(db *gorm.DB) (dialect, error)
+	write(tx *gorm.DB, doc *models.SearchDocument) error
+	remove(tx *gorm.DB, rowIDs []uint) error
+	// match 返回命中检索词的文档查询，search_documents 的别名为 d
+	match(db *gorm.DB, terms []term) *gorm.DB
+	// rank 在命中的文档中按相关度取一页
+	rank(query *gorm.DB, terms []term, offset, limit int) ([]row, error)
+}
+
+// row 检索返回的文档原文和得分
+type row struct {
+	DocType string
+	DocID   uint
+	Title   string
+	Body    string
+	Tags    string
+	Score   float64
+}
+
+// engine 基于 search_documents 表的通用索引实现，具体检索语法由 dialect 决定
+type engine struct {
+	dialect dialect
+	logger  logger.LoggerInterface
+}
+
+// New 按数据库驱动创建全文索引：sqlite 使用 FTS5（未编译 FTS5 时降级为 LIKE），
+// postgres 使用 tsvector，mysql 使用 ngram FULLTEXT，其他驱动使用 LIKE
+func New(driver string, logger logger.LoggerInterface) Index {
+	var d dialect
+	switch driver {
+	case "sqlite":
+		d = &sqliteDialect{}
+	case "postgres":
+		d = &postgresDialect{}
+	case "mysql":
+		d = &mysqlDialect{}
+	default:
+		d = &likeDialect{}
+	}
+	return &engine{dialect: d, logger: logger}
+}
+
+// FromDB 获取已注册到 db 的全文索引
+func FromDB(db *gorm.DB) (Index, bool) {
+	if db == nil || db.Config == nil {
+		return nil, false
+	}
+	plugin, ok := db.Config.Plugins[PluginName]
+	if !ok {
+		return nil, false
+	}
+	idx, ok := plugin.(Index)
+	return idx, ok
+}
+
+// Name 实现 gorm.Plugin
+func (e *engine) Name() string {
+	return PluginName
+}
+
+// Initialize 实现 gorm.Plugin，创建索引表及数据库特定的全文索引结构
+func (e *engine) Initialize(db *gorm.DB) error {
+	if err := db.AutoMigrate(&models.SearchDocument{}); err != nil {
+		return fmt.Errorf("failed to migrate search documents: %w", err)
+	}
+
+	d, err := e.dialect.setup(db)
+	if err != nil {
+		return fmt.Errorf("failed to setup %s search index: %w", e.dialect.name(), err)
+	}
+	if d != e.dialect {
+		e.logger.Warnf("Full-text search %s is unavailable, falling back to %s", e.dialect.name(), d.name())
+		e.dialect = d
+	}
+	e.logger.Infof("Full-text search index initialized: %s", e.dialect.name())
+	return nil
+}
+
+// Driver 返回实际使用的检索实现
+func (e *engine) Driver() string {
+	return e.dialect.name()
+}
+
+// Index 新增或更新文档
+func (e *engine) Index(db *gorm.DB, docs ...Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, d := range docs {
+			var doc models.SearchDocument
+			if err := tx.Where("doc_type = ? AND doc_id = ?", d.Type, d.ID).Limit(1).Find(&doc).Error; err != nil {
+				return err
+			}
+
+			doc.DocType = d.Type
+			doc.DocID = d.ID
+			doc.Title = d.Title
+			doc.Body = d.Body
+			doc.Tags = strings.Join(d.Tags, " ")
+			if err := tx.Save(&doc).Error; err != nil {
+				return err
+			}
+			if err := e.dialect.write(tx, &doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Remove 从索引中删除文档
+func (e *engine) Remove(db *gorm.DB, docType string, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return e.removeWhere(db, "doc_type = ? AND doc_id IN ?", docType, ids)
+}
+
+// Clear 清空某类文档的索引，用于重建
+func (e *engine) Clear(db *gorm.DB, docType string) error {
+	return e.removeWhere(db, "doc_type = ?", docType)
+}
+
+func (e *engine) removeWhere(db *gorm.DB, cond string, args ...interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rowIDs []uint
+		if err := tx.Model(&models.SearchDocument{}).Where(cond, args...).Pluck("id", &rowIDs).Error; err != nil {
+			return err
+		}
+		if len(rowIDs) == 0 {
+			return nil
+		}
+		if err := e.dialect.remove(tx, rowIDs); err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", rowIDs).Delete(&models.SearchDocument{}).Error
+	})
+}
+
+// Count 统计某类文档的索引数量
+func (e *engine) Count(db *gorm.DB, docType string) (int64, error) {
+	var count int64
+	err := db.Model(&models.SearchDocument{}).Where("doc_type = ?", docType).Count(&count).Error
+	return count, err
+}
+
+// Search 检索文档，返回按相关度排序的命中及高亮摘要
+func (e *engine) Search(db *gorm.DB, q Query) (*Result, error) {
+	result := &Result{Hits: []Hit{}, Facets: make(map[string]int64, len(q.Scopes))}
+	for _, scope := range q.Scopes {
+		result.Facets[scope.Type] = 0
+	}
+	terms := parseQuery(q.Text)
+	if len(terms) == 0 || len(q.Scopes) == 0 {
+		return result, nil
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	}
+	if q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	query := scoped(e.dialect.match(db, terms), q.Scopes)
+
+	var facets []struct {
+		DocType string
+		Count   int64
+	}
+	if err := query.Session(&gorm.Session{}).
+		Select("d.doc_type AS doc_type, COUNT(*) AS count").
+		Group("d.doc_type").
+		Scan(&facets).Error; err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	selected := make(map[string]bool, len(q.Types))
+	for _, t := range q.Types {
+		selected[t] = true
+	}
+	for _, f := range facets {
+		result.Facets[f.DocType] = f.Count
+		if len(selected) == 0 || selected[f.DocType] {
+			result.Total += f.Count
+		}
+	}
+	if result.Total <= int64(q.Offset) {
+		return result, nil
+	}
+
+	if len(selected) > 0 {
+		query = query.Where("d.doc_type IN ?", q.Types)
+	}
+	rows, err := e.dialect.rank(query, terms, q.Offset, q.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+
+	needles := needlesOf(terms)
+	for _, r := range rows {
+		result.Hits = append(result.Hits, Hit{
+			Type:    r.DocType,
+			ID:      r.DocID,
+			Score:   r.Score,
+			Title:   highlight(r.Title, needles, 0),
+			Snippet: highlight(strings.Join(strings.Fields(r.Body), " "), needles, snippetRunes),
+		})
+	}
+	return result, nil
+}
+
+// scoped 限定参与检索的文档类型及各自的可见范围
+func scoped(query *gorm.DB, scopes []Scope) *gorm.DB {
+	conds := make([]string, 0, len(scopes))
+	args := make([]interface{}, 0, len(scopes)*2)
+	for _, scope := range scopes {
+		if scope.IDs == nil {
+			conds = append(conds, "d.doc_type = ?")
+			args = append(args, scope.Type)
+			continue
+		}
+		conds = append(conds, "(d.doc_type = ? AND d.doc_id IN (?))")
+		args = append(args, scope.Type, scope.IDs)
+	}
+	return query.Where("("+strings.Join(conds, " OR ")+")", args...)
+}
+
+// rankColumns 检索结果需要的文档列
+const rankColumns = "d.doc_type, d.doc_id, d.title, d.body, d.tags"
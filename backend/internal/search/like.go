@@ -0,0 +1,87 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// maxLikeCandidates LIKE 降级模式下参与打分的最大候选数
+const maxLikeCandidates = 1000
+
+// likeDialect 不依赖数据库全文检索能力的降级实现：LIKE 筛选候选后在内存中打分
+type likeDialect struct{}
+
+func (d *likeDialect) name() string {
+	return "like"
+}
+
+func (d *likeDialect) setup(db *gorm.DB) (dialect, error) {
+	return d, nil
+}
+
+func (d *likeDialect) write(tx *gorm.DB, doc *models.SearchDocument) error {
+	return nil
+}
+
+func (d *likeDialect) remove(tx *gorm.DB, rowIDs []uint) error {
+	return nil
+}
+
+func (d *likeDialect) match(db *gorm.DB, terms []term) *gorm.DB {
+	query := db.Table("search_documents d")
+	for _, needle := range needlesOf(terms) {
+		pattern := "%" + escapeLike(string(needle)) + "%"
+		query = query.Where("(LOWER(d.title) LIKE ? ESCAPE '!' OR LOWER(d.body) LIKE ? ESCAPE '!' OR LOWER(d.tags) LIKE ? ESCAPE '!')",
+			pattern, pattern, pattern)
+	}
+	return query
+}
+
+// rank 取最近更新的候选在内存中打分排序
+func (d *likeDialect) rank(query *gorm.DB, terms []term, offset, limit int) ([]row, error) {
+	var candidates []row
+	if err := query.Select(rankColumns).
+		Order("d.updated_at DESC").
+		Limit(maxLikeCandidates).
+		Scan(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	needles := needlesOf(terms)
+	for i := range candidates {
+		candidates[i].Score = likeScore(&candidates[i], needles)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	if offset >= len(candidates) {
+		return []row{}, nil
+	}
+	end := offset + limit
+	if end > len(candidates) {
+		end = len(candidates)
+	}
+	return candidates[offset:end], nil
+}
+
+// likeScore 标题命中权重最高，标签次之，正文命中次数按对数衰减
+func likeScore(r *row, needles [][]rune) float64 {
+	score := 0.0
+	for _, needle := range needles {
+		score += 10 * float64(countMatches(r.Title, needle))
+		score += 4 * float64(countMatches(r.Tags, needle))
+		score += math.Log1p(float64(countMatches(r.Body, needle)))
+	}
+	return score
+}
+
+// escapeLike 转义 LIKE 通配符，配合 ESCAPE '!' 使用
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	mysqlFullTextIndex      = "idx_search_documents_fulltext"
+	mysqlTitleFullTextIndex = "idx_search_documents_title_fulltext"
+)
+
+// mysqlDialect 基于 InnoDB FULLTEXT 的实现，使用 ngram 解析器以支持中文，
+// 标题单独建索引用于加权
+type mysqlDialect struct{}
+
+func (d *mysqlDialect) name() string {
+	return "mysql-fulltext"
+}
+
+func (d *mysqlDialect) setup(db *gorm.DB) (dialect, error) {
+	indexes := map[string]string{
+		mysqlFullTextIndex:      "title, body, tags",
+		mysqlTitleFullTextIndex: "title",
+	}
+	for name, columns := range indexes {
+		if db.Migrator().HasIndex(&models.SearchDocument{}, name) {
+			continue
+		}
+		if err := db.Exec("CREATE FULLTEXT INDEX " + name + " ON search_documents (" + columns + ") WITH PARSER ngram").Error; err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+func (d *mysqlDialect) write(tx *gorm.DB, doc *models.SearchDocument) error {
+	return nil
+}
+
+func (d *mysqlDialect) remove(tx *gorm.DB, rowIDs []uint) error {
+	return nil
+}
+
+func (d *mysqlDialect) match(db *gorm.DB, terms []term) *gorm.DB {
+	return db.Table("search_documents d").
+		Where("MATCH (d.title, d.body, d.tags) AGAINST (? IN BOOLEAN MODE)", booleanModeExpr(terms))
+}
+
+func (d *mysqlDialect) rank(query *gorm.DB, terms []term, offset, limit int) ([]row, error) {
+	expr := booleanModeExpr(terms)
+	var rows []row
+	err := query.Select(rankColumns+", "+
+		"MATCH (d.title) AGAINST (? IN BOOLEAN MODE) * 3 + "+
+		"MATCH (d.title, d.body, d.tags) AGAINST (? IN BOOLEAN MODE) AS score", expr, expr).
+		Order("score DESC, d.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,52 @@
+package search
+
+import (
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// postgresDialect 基于 tsvector 的实现。使用 simple 配置避免词干化，
+// 中日韩文本在写入前已逐字切分，短语查询通过 <-> 保证相邻
+type postgresDialect struct{}
+
+func (d *postgresDialect) name() string {
+	return "postgres-tsvector"
+}
+
+func (d *postgresDialect) setup(db *gorm.DB) (dialect, error) {
+	if err := db.Exec("ALTER TABLE search_documents ADD COLUMN IF NOT EXISTS tsv tsvector").Error; err != nil {
+		return nil, err
+	}
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_search_documents_tsv ON search_documents USING GIN (tsv)").Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *postgresDialect) write(tx *gorm.DB, doc *models.SearchDocument) error {
+	return tx.Exec("UPDATE search_documents SET tsv = "+
+		"setweight(to_tsvector('simple', ?), 'A') || "+
+		"setweight(to_tsvector('simple', ?), 'B') || "+
+		"setweight(to_tsvector('simple', ?), 'C') WHERE id = ?",
+		normalize(doc.Title), normalize(doc.Tags), normalize(doc.Body), doc.ID).Error
+}
+
+func (d *postgresDialect) remove(tx *gorm.DB, rowIDs []uint) error {
+	return nil
+}
+
+func (d *postgresDialect) match(db *gorm.DB, terms []term) *gorm.DB {
+	return db.Table("search_documents d").
+		Where("d.tsv @@ to_tsquery('simple', ?)", tsQueryExpr(terms))
+}
+
+func (d *postgresDialect) rank(query *gorm.DB, terms []term, offset, limit int) ([]row, error) {
+	var rows []row
+	err := query.Select(rankColumns+", ts_rank_cd(d.tsv, to_tsquery('simple', ?), 32) AS score", tsQueryExpr(terms)).
+		Order("score DESC, d.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,160 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxQueryTerms 单次查询最多使用的检索词数
+const maxQueryTerms = 10
+
+// term 检索词。多个词元按短语匹配，prefix 表示末尾词元按前缀匹配（用于边输入边搜索）
+type term struct {
+	tokens []string
+	prefix bool
+}
+
+// isCJK 判断是否为中日韩字符，这类字符之间没有空格分词，按单字建索引
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Tokenize 将文本切分为归一化词元：转小写，连续的字母数字组成一个词元，
+// 中日韩字符逐字成为词元，其余字符视为分隔符
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// normalize 返回以空格连接的词元，作为 FTS5 和 tsvector 的索引内容
+func normalize(text string) string {
+	return strings.Join(Tokenize(text), " ")
+}
+
+// parseQuery 解析查询文本：按空白切分，双引号包裹的内容作为一个短语；
+// 一段内切出多个词元（如中文词、带连字符的词）时同样按短语匹配
+func parseQuery(text string) []term {
+	var terms []term
+	quoted := false
+	for i, part := range strings.Split(text, `"`) {
+		quoted = i%2 == 1
+		pieces := []string{part}
+		if !quoted {
+			pieces = strings.Fields(part)
+		}
+		for _, piece := range pieces {
+			tokens := Tokenize(piece)
+			if len(tokens) == 0 {
+				continue
+			}
+			terms = append(terms, term{tokens: tokens})
+			if len(terms) == maxQueryTerms {
+				return terms
+			}
+		}
+	}
+
+	// 未闭合引号之外的最后一个英文词按前缀匹配
+	if len(terms) > 0 && !quoted && !strings.HasSuffix(strings.TrimSpace(text), `"`) {
+		last := &terms[len(terms)-1]
+		lastToken := []rune(last.tokens[len(last.tokens)-1])
+		last.prefix = !isCJK(lastToken[0])
+	}
+	return terms
+}
+
+// text 返回检索词的原文形式：中日韩字符之间不加空格
+func (t term) text() string {
+	var b strings.Builder
+	prevCJK := false
+	for i, token := range t.tokens {
+		cjk := isCJK([]rune(token)[0])
+		if i > 0 && !(cjk && prevCJK) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(token)
+		prevCJK = cjk
+	}
+	return b.String()
+}
+
+// needlesOf 返回用于子串匹配和高亮的片段：英文按词，连续的中日韩字符合并为一段
+func needlesOf(terms []term) [][]rune {
+	var needles [][]rune
+	seen := make(map[string]bool)
+	for _, t := range terms {
+		for _, part := range strings.Fields(t.text()) {
+			if !seen[part] {
+				seen[part] = true
+				needles = append(needles, []rune(part))
+			}
+		}
+	}
+	return needles
+}
+
+// ftsMatchExpr 构造 SQLite FTS5 的 MATCH 表达式，各检索词之间为 AND
+func ftsMatchExpr(terms []term) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		phrase := `"` + strings.ReplaceAll(strings.Join(t.tokens, " "), `"`, `""`) + `"`
+		if t.prefix {
+			phrase += "*"
+		}
+		parts = append(parts, phrase)
+	}
+	return strings.Join(parts, " ")
+}
+
+// tsQueryExpr 构造 PostgreSQL to_tsquery 表达式，短语使用 <-> 相邻运算符
+func tsQueryExpr(terms []term) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		tokens := make([]string, len(t.tokens))
+		for i, token := range t.tokens {
+			tokens[i] = "'" + strings.ReplaceAll(token, "'", "''") + "'"
+		}
+		if t.prefix {
+			tokens[len(tokens)-1] += ":*"
+		}
+		phrase := strings.Join(tokens, " <-> ")
+		if len(tokens) > 1 {
+			phrase = "(" + phrase + ")"
+		}
+		parts = append(parts, phrase)
+	}
+	return strings.Join(parts, " & ")
+}
+
+// booleanModeExpr 构造 MySQL BOOLEAN MODE 表达式，每个检索词都必须出现
+func booleanModeExpr(terms []term) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		if t.prefix && len(t.tokens) == 1 {
+			parts = append(parts, "+"+t.tokens[0]+"*")
+			continue
+		}
+		parts = append(parts, `+"`+strings.ReplaceAll(t.text(), `"`, "")+`"`)
+	}
+	return strings.Join(parts, " ")
+}
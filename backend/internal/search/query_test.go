@@ -0,0 +1,87 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Review PR-42, today!", []string{"review", "pr", "42", "today"}},
+		{"准备季度汇报", []string{"准", "备", "季", "度", "汇", "报"}},
+		{"Go语言 入门", []string{"go", "语", "言", "入", "门"}},
+		{"  ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, Tokenize(tt.text))
+		})
+	}
+}
+
+func TestQueryExpressions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		fts     string
+		tsQuery string
+		boolean string
+	}{
+		{
+			name:    "last word is prefix",
+			query:   "weekly rep",
+			fts:     `"weekly" "rep"*`,
+			tsQuery: `'weekly' & 'rep':*`,
+			boolean: `+"weekly" +rep*`,
+		},
+		{
+			name:    "quoted phrase",
+			query:   `"release notes" draft`,
+			fts:     `"release notes" "draft"*`,
+			tsQuery: `('release' <-> 'notes') & 'draft':*`,
+			boolean: `+"release notes" +draft*`,
+		},
+		{
+			name:    "cjk word is phrase without prefix",
+			query:   "季度汇报",
+			fts:     `"季 度 汇 报"`,
+			tsQuery: `('季' <-> '度' <-> '汇' <-> '报')`,
+			boolean: `+"季度汇报"`,
+		},
+		{
+			name:    "closed quote disables prefix",
+			query:   `"budget"`,
+			fts:     `"budget"`,
+			tsQuery: `'budget'`,
+			boolean: `+"budget"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terms := parseQuery(tt.query)
+			assert.Equal(t, tt.fts, ftsMatchExpr(terms))
+			assert.Equal(t, tt.tsQuery, tsQueryExpr(terms))
+			assert.Equal(t, tt.boolean, booleanModeExpr(terms))
+		})
+	}
+
+	assert.Empty(t, parseQuery(`"" !!`))
+}
+
+func TestHighlight(t *testing.T) {
+	needles := needlesOf(parseQuery("report 汇报"))
+
+	assert.Equal(t, "Weekly <mark>Report</mark> &amp; <mark>汇报</mark>",
+		highlight("Weekly Report & 汇报", needles, 0))
+	assert.Equal(t, "no match", highlight("no match", needles, 0))
+
+	long := "aaaaaaaaaa bbbbbbbbbb cccccccccc report dddddddddd"
+	assert.Equal(t, "…b cccccccccc <mark>report</mark> dddddddddd", highlight(long, needles, 30))
+	assert.Equal(t, "aaaaaaaaaa bbbbbbbbbb…", highlight(long+" eeee", [][]rune{[]rune("zzz")}, 21))
+}
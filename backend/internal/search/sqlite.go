@@ -0,0 +1,96 @@
+package search
+
+import (
+	"strings"
+
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// sqliteFTSTable FTS5 虚拟表，rowid 与 search_documents.id 一致，内容为归一化后的词元
+const sqliteFTSTable = "search_documents_fts"
+
+// sqliteDialect 基于 SQLite FTS5 的实现，使用 bm25 排序（标题、标签权重高于正文）。
+// mattn/go-sqlite3 需以 sqlite_fts5 构建标签编译才包含 FTS5
+type sqliteDialect struct{}
+
+func (d *sqliteDialect) name() string {
+	return "sqlite-fts5"
+}
+
+func (d *sqliteDialect) setup(db *gorm.DB) (dialect, error) {
+	err := db.Exec("CREATE VIRTUAL TABLE IF NOT EXISTS " + sqliteFTSTable +
+		" USING fts5(title, body, tags, tokenize = 'unicode61 remove_diacritics 2')").Error
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			return &likeDialect{}, nil
+		}
+		return nil, err
+	}
+
+	// 曾以 LIKE 降级模式运行时 FTS 表不会同步，数量不一致时从 search_documents 重建
+	var docs, indexed int64
+	if err := db.Model(&models.SearchDocument{}).Count(&docs).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Table(sqliteFTSTable).Count(&indexed).Error; err != nil {
+		return nil, err
+	}
+	if docs != indexed {
+		if err := d.rebuild(db); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// rebuild 按 search_documents 重建 FTS 表
+func (d *sqliteDialect) rebuild(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM " + sqliteFTSTable).Error; err != nil {
+			return err
+		}
+		var docs []*models.SearchDocument
+		return tx.FindInBatches(&docs, 200, func(batch *gorm.DB, _ int) error {
+			for _, doc := range docs {
+				if err := d.insert(batch, doc); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	})
+}
+
+func (d *sqliteDialect) insert(tx *gorm.DB, doc *models.SearchDocument) error {
+	return tx.Exec("INSERT INTO "+sqliteFTSTable+" (rowid, title, body, tags) VALUES (?, ?, ?, ?)",
+		doc.ID, normalize(doc.Title), normalize(doc.Body), normalize(doc.Tags)).Error
+}
+
+func (d *sqliteDialect) write(tx *gorm.DB, doc *models.SearchDocument) error {
+	if err := tx.Exec("DELETE FROM "+sqliteFTSTable+" WHERE rowid = ?", doc.ID).Error; err != nil {
+		return err
+	}
+	return d.insert(tx, doc)
+}
+
+func (d *sqliteDialect) remove(tx *gorm.DB, rowIDs []uint) error {
+	return tx.Exec("DELETE FROM "+sqliteFTSTable+" WHERE rowid IN ?", rowIDs).Error
+}
+
+func (d *sqliteDialect) match(db *gorm.DB, terms []term) *gorm.DB {
+	return db.Table(sqliteFTSTable).
+		Joins("JOIN search_documents d ON d.id = "+sqliteFTSTable+".rowid").
+		Where(sqliteFTSTable+" MATCH ?", ftsMatchExpr(terms))
+}
+
+func (d *sqliteDialect) rank(query *gorm.DB, terms []term, offset, limit int) ([]row, error) {
+	var rows []row
+	err := query.Select(rankColumns + ", -bm25(" + sqliteFTSTable + ", 10.0, 1.0, 4.0) AS score").
+		Order("score DESC, d.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
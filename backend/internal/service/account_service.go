@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/auth"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/mailer"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMailLanguage  = "zh-CN"
+	accountTokenInterval = time.Minute // 同一用户同一用途两封邮件之间的最小间隔
+	mailSendTimeout      = 30 * time.Second
+)
+
+var (
+	ErrInvalidAccountToken  = errors.New("link is invalid or has already been used")
+	ErrAccountTokenExpired  = errors.New("link has expired, please request a new one")
+	ErrAccountTokenThrottle = errors.New("email was sent recently, please try again later")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+)
+
+//go:embed mail/*.tmpl
+var mailTemplateFS embed.FS
+
+// AccountOptions 重置密码和验证邮箱的配置
+type AccountOptions struct {
+	AppName     string        // 显示在邮件标题和正文中
+	FrontendURL string        // 邮件中的链接指向前端页面，例如 https://example.com
+	ResetTTL    time.Duration // 重置密码链接的有效期
+	VerifyTTL   time.Duration // 验证邮箱链接的有效期
+}
+
+// ForgotPasswordRequest 申请重置密码
+type ForgotPasswordRequest struct {
+	Email  string     `json:"email" binding:"required,email"`
+	Client ClientInfo `json:"-"`
+}
+
+// ResetPasswordRequest 用邮件中的令牌设置新密码
+type ResetPasswordRequest struct {
+	Token    string     `json:"token" binding:"required"`
+	Password string     `json:"password" binding:"required,min=6,max=100"`
+	Client   ClientInfo `json:"-"`
+}
+
+// VerifyEmailRequest 用邮件中的令牌验证邮箱
+type VerifyEmailRequest struct {
+	Token  string     `json:"token" binding:"required"`
+	Client ClientInfo `json:"-"`
+}
+
+// accountMailData 邮件模板数据
+type accountMailData struct {
+	AppName          string
+	Username         string
+	Email            string
+	Link             string
+	ExpiresInMinutes int
+}
+
+// AccountService 通过邮件找回密码和验证邮箱。
+// 邮件中的链接带有签名令牌，令牌过期或使用一次后失效；邮件按用户的界面语言渲染
+type AccountService struct {
+	db        *gorm.DB
+	tokens    *TokenService
+	mailer    mailer.Mailer
+	signer    *auth.TokenSigner
+	templates *mailer.Templates
+	options   AccountOptions
+	logger    logger.LoggerInterface
+	now       func() time.Time
+}
+
+// NewAccountService 创建账号服务
+func NewAccountService(db *gorm.DB, tokens *TokenService, m mailer.Mailer, signer *auth.TokenSigner, options AccountOptions, logger logger.LoggerInterface) *AccountService {
+	templates, err := mailer.ParseTemplates(mailTemplateFS, "mail/*.tmpl", defaultMailLanguage)
+	if err != nil {
+		// 模板随程序一起编译，解析失败属于编程错误
+		panic(fmt.Sprintf("failed to parse mail templates: %v", err))
+	}
+	return &AccountService{
+		db:        db,
+		tokens:    tokens,
+		mailer:    m,
+		signer:    signer,
+		templates: templates,
+		options:   options,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// ForgotPassword 向邮箱对应的用户发送重置密码邮件。
+// 无论邮箱是否存在都返回成功，避免通过该接口探测已注册的邮箱
+func (s *AccountService) ForgotPassword(req ForgotPasswordRequest) error {
+	var user models.User
+	if err := s.db.Where("email = ?", strings.TrimSpace(req.Email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.WithFields(map[string]any{"ip": req.Client.IP}).Info("Password reset requested for unknown email")
+			return nil
+		}
+		return fmt.Errorf("database error: %v", err)
+	}
+
+	err := s.send(&user, models.AccountTokenPasswordReset, "password_reset", "/reset-password", s.options.ResetTTL)
+	if errors.Is(err, ErrAccountTokenThrottle) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	recordAuditEvent(s.db, s.logger, AuditEvent{
+		UserID:   user.ID,
+		Username: user.Username,
+		Action:   "申请重置密码",
+		Client:   req.Client,
+	})
+	return nil
+}
+
+// ResetPassword 校验令牌后设置新密码，并退出该用户所有设备上的登录
+func (s *AccountService) ResetPassword(req ResetPasswordRequest) error {
+	user, token, err := s.consume(models.AccountTokenPasswordReset, req.Token, func(tx *gorm.DB, user *models.User, _ *models.AccountToken) error {
+		hashed, err := auth.HashPassword(req.Password)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %v", err)
+		}
+		if err := tx.Model(user).Update("password", hashed).Error; err != nil {
+			return fmt.Errorf("failed to update password: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 旧密码可能已泄露，已登录的设备都需要用新密码重新登录
+	if err := s.tokens.LogoutAll(user.ID); err != nil {
+		s.logger.WithFields(map[string]any{"user_id": user.ID, "error": err}).Error("Failed to revoke sessions after password reset")
+	}
+
+	s.logger.WithFields(map[string]any{"user_id": user.ID}).Info("Password reset via email")
+	recordAuditEvent(s.db, s.logger, AuditEvent{
+		UserID:   user.ID,
+		Username: user.Username,
+		Action:   "重置密码",
+		Details:  map[string]interface{}{"token_id": token.ID},
+		Client:   req.Client,
+	})
+	return nil
+}
+
+// SendVerification 向用户当前的邮箱发送验证邮件
+func (s *AccountService) SendVerification(userID uint) error {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("database error: %v", err)
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	return s.send(&user, models.AccountTokenEmailVerification, "email_verification", "/verify-email", s.options.VerifyTTL)
+}
+
+// VerifyEmail 校验令牌后把用户邮箱标记为已验证。令牌签发后修改过邮箱则令牌失效
+func (s *AccountService) VerifyEmail(req VerifyEmailRequest) error {
+	user, _, err := s.consume(models.AccountTokenEmailVerification, req.Token, func(tx *gorm.DB, user *models.User, token *models.AccountToken) error {
+		if !strings.EqualFold(token.Email, user.Email) {
+			return ErrInvalidAccountToken
+		}
+		if err := tx.Model(user).Update("email_verified_at", s.now()).Error; err != nil {
+			return fmt.Errorf("failed to verify email: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithFields(map[string]any{"user_id": user.ID}).Info("Email verified")
+	recordAuditEvent(s.db, s.logger, AuditEvent{
+		UserID:   user.ID,
+		Username: user.Username,
+		Action:   "验证邮箱",
+		Details:  map[string]interface{}{"email": user.Email},
+		Client:   req.Client,
+	})
+	return nil
+}
+
+// send 签发令牌、保存使用记录并异步发送邮件。同一用户同一用途的旧令牌随即失效
+func (s *AccountService) send(user *models.User, purpose, template, path string, ttl time.Duration) error {
+	now := s.now()
+	var recent int64
+	if err := s.db.Model(&models.AccountToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", user.ID, purpose, now.Add(-accountTokenInterval)).
+		Count(&recent).Error; err != nil {
+		return fmt.Errorf("failed to check recent tokens: %v", err)
+	}
+	if recent > 0 {
+		return ErrAccountTokenThrottle
+	}
+
+	token, claims, err := s.signer.Sign(purpose, user.ID, now.Add(ttl))
+	if err != nil {
+		return err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.invalidate(tx, user.ID, purpose, now); err != nil {
+			return err
+		}
+		return tx.Create(&models.AccountToken{
+			UserID:    user.ID,
+			Purpose:   purpose,
+			Nonce:     claims.Nonce,
+			Email:     user.Email,
+			ExpiresAt: claims.ExpiresAt,
+			CreatedAt: now,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save account token: %v", err)
+	}
+
+	msg, err := s.templates.Render(template, s.language(user.ID), accountMailData{
+		AppName:          s.options.AppName,
+		Username:         user.Username,
+		Email:            user.Email,
+		Link:             strings.TrimRight(s.options.FrontendURL, "/") + path + "?token=" + url.QueryEscape(token),
+		ExpiresInMinutes: int(ttl.Minutes()),
+	})
+	if err != nil {
+		return err
+	}
+	msg.To = []string{user.Email}
+
+	// SMTP 服务器可能很慢，不阻塞请求；发送失败时用户可以重新申请
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			s.logger.WithFields(map[string]any{"user_id": user.ID, "purpose": purpose, "error": err}).Error("Failed to send email")
+		}
+	}()
+	return nil
+}
+
+// consume 校验令牌并在事务中把它标记为已使用，然后执行 apply。
+// 标记和 apply 在同一事务中，apply 失败时令牌仍然可用
+func (s *AccountService) consume(purpose, rawToken string, apply func(tx *gorm.DB, user *models.User, token *models.AccountToken) error) (*models.User, *models.AccountToken, error) {
+	now := s.now()
+	claims, err := s.signer.Verify(purpose, rawToken, now)
+	if err != nil {
+		if errors.Is(err, auth.ErrSignedTokenExpired) {
+			return nil, nil, ErrAccountTokenExpired
+		}
+		return nil, nil, ErrInvalidAccountToken
+	}
+
+	var (
+		user  models.User
+		token models.AccountToken
+	)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("nonce = ? AND purpose = ? AND user_id = ?", claims.Nonce, purpose, claims.Subject).
+			First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidAccountToken
+			}
+			return fmt.Errorf("failed to get account token: %v", err)
+		}
+		// 条件更新保证并发提交同一令牌时只有一个请求成功
+		result := tx.Model(&models.AccountToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return fmt.Errorf("failed to use account token: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidAccountToken
+		}
+
+		if err := tx.First(&user, claims.Subject).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidAccountToken
+			}
+			return fmt.Errorf("database error: %v", err)
+		}
+		if err := apply(tx, &user, &token); err != nil {
+			return err
+		}
+		return s.invalidate(tx, user.ID, purpose, now)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &user, &token, nil
+}
+
+// invalidate 使用户指定用途的未使用令牌全部失效
+func (s *AccountService) invalidate(tx *gorm.DB, userID uint, purpose string, now time.Time) error {
+	if err := tx.Model(&models.AccountToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now).Error; err != nil {
+		return fmt.Errorf("failed to invalidate account tokens: %v", err)
+	}
+	return nil
+}
+
+// language 邮件语言取用户的界面语言设置，没有设置时使用中文
+func (s *AccountService) language(userID uint) string {
+	var settings models.UserSettings
+	if err := s.db.Select("language").Where("user_id = ?", userID).First(&settings).Error; err != nil || settings.Language == "" {
+		return defaultMailLanguage
+	}
+	return settings.Language
+}
+
+// CleanupExpired 删除过期的令牌记录，过期令牌的签名校验已经无法通过
+func (s *AccountService) CleanupExpired(now time.Time) (int64, error) {
+	result := s.db.Where("expires_at < ?", now).Delete(&models.AccountToken{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired account tokens: %v", result.Error)
+	}
+	return result.RowsAffected, nil
+}
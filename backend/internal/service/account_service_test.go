@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+
+	"gin-web-framework/pkg/mailer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountMailTemplates(t *testing.T) {
+	templates, err := mailer.ParseTemplates(mailTemplateFS, "mail/*.tmpl", defaultMailLanguage)
+	require.NoError(t, err)
+
+	data := accountMailData{
+		AppName:          "Todo",
+		Username:         "alice",
+		Email:            "alice@example.com",
+		Link:             "https://example.com/reset-password?token=a.b&c",
+		ExpiresInMinutes: 30,
+	}
+
+	tests := []struct {
+		name    string
+		lang    string
+		subject string
+	}{
+		{name: "password_reset", lang: "zh-CN", subject: "【Todo】重置密码"},
+		{name: "password_reset", lang: "en-US", subject: "[Todo] Reset your password"},
+		{name: "email_verification", lang: "zh-CN", subject: "【Todo】验证你的邮箱"},
+		{name: "email_verification", lang: "en-US", subject: "[Todo] Verify your email address"},
+		{name: "email_verification", lang: "fr-FR", subject: "【Todo】验证你的邮箱"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.lang, func(t *testing.T) {
+			msg, err := templates.Render(tt.name, tt.lang, data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.subject, msg.Subject)
+			assert.Contains(t, msg.Text, data.Link)
+			assert.Contains(t, msg.Text, "30")
+			assert.Contains(t, msg.HTML, `href="https://example.com/reset-password?token=a.b&amp;c"`)
+		})
+	}
+}
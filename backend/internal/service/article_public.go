@@ -0,0 +1,325 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/markdown"
+	"gin-web-framework/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// maxArticleSlugLength slug 的最大字数，留出去重后缀的位置
+	maxArticleSlugLength = 180
+	// articleFeedSize 订阅源中的文章数
+	articleFeedSize = 20
+	// slugBackfillBatch 补全旧文章 slug 时每批处理的文章数
+	slugBackfillBatch = 200
+)
+
+var (
+	ErrArticleSlugTaken   = errors.New("article slug is already in use")
+	ErrInvalidArticleSlug = errors.New("invalid article slug")
+)
+
+// PublicAuthor 公开接口中的作者信息，不包含邮箱等隐私字段
+type PublicAuthor struct {
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+}
+
+// PublicArticle 公开接口中的文章
+type PublicArticle struct {
+	ID          uint         `json:"id"`
+	Slug        string       `json:"slug"`
+	Title       string       `json:"title"`
+	Summary     string       `json:"summary"`
+	Content     string       `json:"content,omitempty"`
+	CoverImage  string       `json:"cover_image"`
+	Tags        []string     `json:"tags"`
+	ViewCount   int          `json:"view_count"`
+	LikeCount   int          `json:"like_count"`
+	PublishedAt time.Time    `json:"published_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	Author      PublicAuthor `json:"author"`
+
+	// 渲染结果，只在文章详情中返回
+	ContentHTML string             `json:"content_html,omitempty"`
+	TOC         []markdown.Heading `json:"toc,omitempty"`
+	ReadingTime int                `json:"reading_time,omitempty"`
+}
+
+// PublicArticleFilter 公开文章查询条件，Author 为用户名，Tag 为标签名
+type PublicArticleFilter struct {
+	Author string `form:"-"`
+	Tag    string `form:"tag"`
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
+
+// PaginatedPublicArticles 公开文章分页结果，列表不含正文
+type PaginatedPublicArticles struct {
+	Articles   []*PublicArticle `json:"articles"`
+	Total      int64            `json:"total"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+	TotalPages int              `json:"total_pages"`
+}
+
+// ArticleFeedState 订阅源的版本信息，用于生成 ETag 和 Last-Modified，文章未变化时不必加载正文
+type ArticleFeedState struct {
+	Count        int64
+	LatestID     uint
+	LastModified time.Time
+}
+
+// ArticleFeed 订阅源内容
+type ArticleFeed struct {
+	Author   *PublicAuthor
+	Articles []*PublicArticle
+}
+
+// GetPublicArticles 获取已发布的文章，按发布时间倒序
+func (s *ArticleService) GetPublicArticles(filter PublicArticleFilter) (*PaginatedPublicArticles, error) {
+	query, err := s.publicArticlesQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count articles: %v", err)
+	}
+	pagination := utils.NewPaginationInfo(filter.Page, filter.Limit, total)
+
+	var articles []*models.Article
+	if err := query.Omit("content").
+		Preload("User").
+		Order("COALESCE(published_at, created_at) DESC, id DESC").
+		Offset(pagination.Offset).
+		Limit(pagination.Limit).
+		Find(&articles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get articles: %v", err)
+	}
+
+	return &PaginatedPublicArticles{
+		Articles:   toPublicArticles(articles),
+		Total:      total,
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: pagination.TotalPages,
+	}, nil
+}
+
+// GetPublicArticleBySlug 按 slug 获取已发布的文章
+func (s *ArticleService) GetPublicArticleBySlug(slug string) (*PublicArticle, error) {
+	var article models.Article
+	if err := s.db.Where("slug = ? AND status = ?", slug, ArticleStatusPublished).
+		Preload("User").
+		First(&article).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, fmt.Errorf("failed to get article: %v", err)
+	}
+	return toPublicArticle(&article), nil
+}
+
+// GetArticleFeedState 获取订阅源的版本信息，只查询聚合值
+func (s *ArticleService) GetArticleFeedState(filter PublicArticleFilter) (*ArticleFeedState, error) {
+	query, err := s.publicArticlesQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var row struct {
+		Count    int64
+		LatestID uint
+	}
+	if err := query.Select("COUNT(*) AS count, COALESCE(MAX(id), 0) AS latest_id").Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to get feed state: %v", err)
+	}
+	state := &ArticleFeedState{Count: row.Count, LatestID: row.LatestID}
+	if row.Count == 0 {
+		return state, nil
+	}
+
+	// 聚合函数返回的时间在各数据库驱动中类型不一致，单独取最近更新的一条
+	if query, err = s.publicArticlesQuery(filter); err != nil {
+		return nil, err
+	}
+	var updated []time.Time
+	if err := query.Order("updated_at DESC").Limit(1).Pluck("updated_at", &updated).Error; err != nil {
+		return nil, fmt.Errorf("failed to get feed state: %v", err)
+	}
+	if len(updated) > 0 {
+		state.LastModified = updated[0]
+	}
+	return state, nil
+}
+
+// GetArticleFeed 获取订阅源中的最新文章（含正文）
+func (s *ArticleService) GetArticleFeed(filter PublicArticleFilter) (*ArticleFeed, error) {
+	query, err := s.publicArticlesQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var articles []*models.Article
+	if err := query.Preload("User").
+		Order("COALESCE(published_at, created_at) DESC, id DESC").
+		Limit(articleFeedSize).
+		Find(&articles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get feed articles: %v", err)
+	}
+
+	feed := &ArticleFeed{Articles: toPublicArticles(articles)}
+	if filter.Author != "" {
+		author, err := s.findAuthor(filter.Author)
+		if err != nil {
+			return nil, err
+		}
+		feed.Author = &PublicAuthor{Username: author.Username, Nickname: author.Nickname}
+	}
+	return feed, nil
+}
+
+// EnsureArticleSlugs 为启用 slug 之前创建的文章生成 slug，可重复执行
+func (s *ArticleService) EnsureArticleSlugs() error {
+	for {
+		var articles []*models.Article
+		if err := s.db.Select("id", "title").
+			Where("slug IS NULL").
+			Order("id").
+			Limit(slugBackfillBatch).
+			Find(&articles).Error; err != nil {
+			return fmt.Errorf("failed to get articles without slug: %v", err)
+		}
+		if len(articles) == 0 {
+			return nil
+		}
+		for _, article := range articles {
+			if err := s.assignArticleSlug(s.db, article, ""); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *ArticleService) publicArticlesQuery(filter PublicArticleFilter) (*gorm.DB, error) {
+	query := s.db.Model(&models.Article{}).Where("status = ? AND slug IS NOT NULL", ArticleStatusPublished)
+	if filter.Author != "" {
+		author, err := s.findAuthor(filter.Author)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("created_by = ?", author.ID)
+	}
+	if filter.Tag != "" {
+		query = query.Scopes(tagScope(SearchDocArticle, filter.Tag))
+	}
+	return query, nil
+}
+
+func (s *ArticleService) findAuthor(username string) (*models.User, error) {
+	var user models.User
+	if err := s.db.Select("id", "username", "nickname").Where("username = ?", username).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get author: %v", err)
+	}
+	return &user, nil
+}
+
+// assignArticleSlug 设置文章 slug。requested 非空时使用指定的 slug，已被其他文章占用时返回
+// ErrArticleSlugTaken；为空时根据标题生成，重复时追加文章ID
+func (s *ArticleService) assignArticleSlug(tx *gorm.DB, article *models.Article, requested string) error {
+	explicit := requested != ""
+	slug := utils.Slugify(requested, maxArticleSlugLength)
+	if !explicit {
+		slug = utils.Slugify(article.Title, maxArticleSlugLength)
+	}
+	if explicit && slug == "" {
+		return fmt.Errorf("%w: slug must contain letters or digits", ErrInvalidArticleSlug)
+	}
+	if !explicit && isNumericSlug(slug) {
+		// 纯数字的 slug 容易与文章ID混淆
+		slug = "article-" + slug
+	}
+	if slug == "" {
+		slug = "article"
+	}
+	if article.Slug != nil && *article.Slug == slug {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Unscoped().Model(&models.Article{}).
+		Where("slug = ? AND id <> ?", slug, article.ID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check article slug: %v", err)
+	}
+	if count > 0 {
+		if explicit {
+			return ErrArticleSlugTaken
+		}
+		slug = slug + "-" + strconv.FormatUint(uint64(article.ID), 10)
+	}
+
+	if err := tx.Model(&models.Article{}).Where("id = ?", article.ID).UpdateColumn("slug", slug).Error; err != nil {
+		return fmt.Errorf("failed to save article slug: %v", err)
+	}
+	article.Slug = &slug
+	return nil
+}
+
+func isNumericSlug(slug string) bool {
+	if slug == "" {
+		return false
+	}
+	_, err := strconv.ParseUint(slug, 10, 64)
+	return err == nil
+}
+
+func toPublicArticles(articles []*models.Article) []*PublicArticle {
+	result := make([]*PublicArticle, len(articles))
+	for i, article := range articles {
+		result[i] = toPublicArticle(article)
+	}
+	return result
+}
+
+func toPublicArticle(article *models.Article) *PublicArticle {
+	public := &PublicArticle{
+		ID:          article.ID,
+		Title:       article.Title,
+		Summary:     article.Summary,
+		Content:     article.Content,
+		CoverImage:  article.CoverImage,
+		Tags:        parseSearchTags(article.Tags),
+		ViewCount:   article.ViewCount,
+		LikeCount:   article.LikeCount,
+		PublishedAt: article.CreatedAt,
+		UpdatedAt:   article.UpdatedAt,
+		Author: PublicAuthor{
+			Username: article.User.Username,
+			Nickname: article.User.Nickname,
+		},
+	}
+	if article.Slug != nil {
+		public.Slug = *article.Slug
+	}
+	if article.PublishedAt != nil {
+		public.PublishedAt = *article.PublishedAt
+	}
+	if public.Tags == nil {
+		public.Tags = []string{}
+	}
+	return public
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArticleService_PublicArticlesOnlyIncludePublished(t *testing.T) {
+	db := openTestDB(t, &models.User{}, &models.Article{})
+	require.NoError(t, db.Create(&models.User{Username: "alice", Email: "alice@example.com", Password: "x"}).Error)
+	svc := NewArticleService(db, nil, logger.NewLogger(logger.DefaultLoggerConfig()))
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+	newArticle := func(slug, status string, publishAt, publishedAt *time.Time) *models.Article {
+		article := &models.Article{
+			Title:       slug,
+			Slug:        &slug,
+			Content:     "content",
+			Status:      status,
+			PublishAt:   publishAt,
+			PublishedAt: publishedAt,
+			CreatedBy:   1,
+		}
+		require.NoError(t, db.Create(article).Error)
+		return article
+	}
+	published := newArticle("published", ArticleStatusPublished, nil, &now)
+	newArticle("draft", ArticleStatusDraft, nil, nil)
+	newArticle("scheduled", ArticleStatusScheduled, &later, nil)
+	newArticle("archived", ArticleStatusArchived, nil, &now)
+
+	for _, filter := range []PublicArticleFilter{{}, {Author: "alice"}} {
+		list, err := svc.GetPublicArticles(filter)
+		require.NoError(t, err)
+		require.Len(t, list.Articles, 1)
+		assert.EqualValues(t, 1, list.Total)
+		assert.Equal(t, "published", list.Articles[0].Slug)
+
+		feed, err := svc.GetArticleFeed(filter)
+		require.NoError(t, err)
+		require.Len(t, feed.Articles, 1)
+		assert.Equal(t, "published", feed.Articles[0].Slug)
+
+		// 草稿和定时文章的修改不影响订阅源的版本
+		state, err := svc.GetArticleFeedState(filter)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, state.Count)
+		assert.Equal(t, published.ID, state.LatestID)
+		assert.WithinDuration(t, published.UpdatedAt, state.LastModified, time.Second)
+	}
+
+	for _, slug := range []string{"draft", "scheduled", "archived"} {
+		_, err := svc.GetPublicArticleBySlug(slug)
+		assert.ErrorIs(t, err, ErrArticleNotFound, slug)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/markdown"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// articleRenderVersion 渲染规则变化时递增，使旧的缓存失效
+	articleRenderVersion = 1
+	// articleRenderCacheTTL 渲染结果的缓存时间（秒）
+	articleRenderCacheTTL = 7 * 24 * 3600
+)
+
+// RenderedArticle 文章及其渲染结果，原始 Markdown 仍在 content 字段中
+type RenderedArticle struct {
+	*models.Article
+	ContentHTML string             `json:"content_html"`
+	TOC         []markdown.Heading `json:"toc"`
+	WordCount   int                `json:"word_count"`
+	ReadingTime int                `json:"reading_time"`
+}
+
+// PreviewRequest Markdown 预览请求
+type PreviewRequest struct {
+	Content string `json:"content" binding:"max=200000"`
+}
+
+// ArticleRenderService 文章 Markdown 渲染服务。正文只会随修订版本变化，
+// 因此渲染结果以文章ID和最新版本号为键缓存，编辑后自然换用新键
+type ArticleRenderService struct {
+	db     *gorm.DB
+	cache  CacheServiceInterface
+	logger logger.LoggerInterface
+}
+
+// NewArticleRenderService 创建文章渲染服务，cache 为 nil 时不缓存
+func NewArticleRenderService(db *gorm.DB, cache CacheServiceInterface, logger logger.LoggerInterface) *ArticleRenderService {
+	return &ArticleRenderService{
+		db:     db,
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// RenderArticle 渲染文章正文
+func (s *ArticleRenderService) RenderArticle(article *models.Article) (*RenderedArticle, error) {
+	doc, err := s.Render(article.ID, article.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderedArticle{
+		Article:     article,
+		ContentHTML: doc.HTML,
+		TOC:         doc.TOC,
+		WordCount:   doc.WordCount,
+		ReadingTime: doc.ReadingTime,
+	}, nil
+}
+
+// Render 渲染指定文章的正文，优先使用当前修订版本的缓存
+func (s *ArticleRenderService) Render(articleID uint, content string) (*markdown.Document, error) {
+	var revision int
+	if err := s.db.Model(&models.ArticleRevision{}).
+		Where("article_id = ?", articleID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&revision).Error; err != nil {
+		return nil, fmt.Errorf("failed to get article revision: %v", err)
+	}
+
+	key := fmt.Sprintf("article:render:v%d:%d:%d", articleRenderVersion, articleID, revision)
+	ctx := context.Background()
+	if s.cache != nil {
+		var cached markdown.Document
+		if err := s.cache.GetObject(ctx, key, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	doc := markdown.Render(content)
+	if s.cache != nil {
+		if err := s.cache.Set(ctx, key, doc, articleRenderCacheTTL); err != nil {
+			s.logger.Debugf("Failed to cache rendered article %d: %v", articleID, err)
+		}
+	}
+	return doc, nil
+}
+
+// Preview 渲染编辑中的内容，不读写缓存
+func (s *ArticleRenderService) Preview(req PreviewRequest) *markdown.Document {
+	return markdown.Render(req.Content)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newArticleRevisionTestService(t *testing.T) (*ArticleService, *gorm.DB) {
+	db := openTestDB(t, &models.User{}, &models.Article{}, &models.ArticleRevision{})
+	require.NoError(t, db.Create(&models.User{Username: "alice", Email: "alice@example.com", Password: "x"}).Error)
+	return NewArticleService(db, nil, logger.NewLogger(logger.DefaultLoggerConfig())), db
+}
+
+func loadRevisions(t *testing.T, db *gorm.DB, articleID uint) []models.ArticleRevision {
+	var revisions []models.ArticleRevision
+	require.NoError(t, db.Where("article_id = ?", articleID).Order("number").Find(&revisions).Error)
+	return revisions
+}
+
+func TestArticleService_RestoreRevisionAppendsHistory(t *testing.T) {
+	svc, db := newArticleRevisionTestService(t)
+	article, err := svc.CreateArticle(CreateArticleRequest{Title: "v1", Content: "one\ntwo"}, 1)
+	require.NoError(t, err)
+	_, err = svc.UpdateArticle(article.ID, 1, UpdateArticleRequest{Title: "v2", Content: "one\nthree"})
+	require.NoError(t, err)
+	// 只修改状态不生成版本
+	_, err = svc.UpdateArticle(article.ID, 1, UpdateArticleRequest{Status: ArticleStatusPublished})
+	require.NoError(t, err)
+	before := loadRevisions(t, db, article.ID)
+	require.Len(t, before, 2)
+
+	restored, err := svc.RestoreArticleRevision(article.ID, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", restored.Title)
+	assert.Equal(t, "one\ntwo", restored.Content)
+
+	// 恢复生成新版本，原有版本保持不变
+	after := loadRevisions(t, db, article.ID)
+	require.Len(t, after, 3)
+	assert.Equal(t, before, after[:2])
+	assert.Equal(t, 3, after[2].Number)
+	assert.Equal(t, "v1", after[2].Title)
+	require.NotNil(t, after[2].RestoredFrom)
+	assert.Equal(t, 1, *after[2].RestoredFrom)
+
+	result, err := svc.DiffArticleRevisions(article.ID, 1, 0, 0, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.From.Number)
+	assert.Equal(t, 3, result.To.Number)
+	assert.Equal(t, RevisionDiffLine, result.Mode)
+	assert.Empty(t, result.From.Content)
+
+	_, err = svc.DiffArticleRevisions(article.ID, 1, 3, 3, "")
+	assert.ErrorIs(t, err, ErrInvalidRevisionDiff)
+	_, err = svc.RestoreArticleRevision(article.ID, 1, 9)
+	assert.ErrorIs(t, err, ErrArticleRevisionNotFound)
+	_, err = svc.RestoreArticleRevision(article.ID, 2, 1)
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+}
+
+func TestArticleService_BaselineRevisionForExistingArticle(t *testing.T) {
+	svc, db := newArticleRevisionTestService(t)
+	// 启用修订历史之前创建的文章没有版本记录
+	legacy := &models.Article{Title: "legacy", Content: "old", CreatedBy: 1}
+	require.NoError(t, db.Create(legacy).Error)
+
+	_, err := svc.UpdateArticle(legacy.ID, 1, UpdateArticleRequest{Content: "new"})
+	require.NoError(t, err)
+	_, err = svc.UpdateArticle(legacy.ID, 1, UpdateArticleRequest{Content: "newer"})
+	require.NoError(t, err)
+
+	revisions := loadRevisions(t, db, legacy.ID)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, "old", revisions[0].Content)
+	assert.Equal(t, "new", revisions[1].Content)
+	assert.Equal(t, "newer", revisions[2].Content)
+
+	// 恢复失败时不留下基线版本
+	other := &models.Article{Title: "other", Content: "old", CreatedBy: 1}
+	require.NoError(t, db.Create(other).Error)
+	_, err = svc.RestoreArticleRevision(other.ID, 1, 1)
+	assert.ErrorIs(t, err, ErrArticleRevisionNotFound)
+	assert.Empty(t, loadRevisions(t, db, other.ID))
+}
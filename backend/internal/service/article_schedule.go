@@ -0,0 +1,206 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 文章状态
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusScheduled = "scheduled"
+	ArticleStatusPublished = "published"
+	ArticleStatusArchived  = "archived"
+)
+
+// scheduledArticleBatch 每次调度最多处理的文章数，剩余的在下一次调度处理
+const scheduledArticleBatch = 100
+
+var ErrInvalidArticleSchedule = errors.New("invalid article schedule")
+
+// ScheduleArticleRequest 定时发布请求。publish_at 为空时只设置已发布文章的下线时间；
+// publish_at 不晚于当前时间时立即发布
+type ScheduleArticleRequest struct {
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+// ScheduleArticle 设置文章的定时发布和到期下线时间
+func (s *ArticleService) ScheduleArticle(id, userID uint, req ScheduleArticleRequest) (*models.Article, error) {
+	article, err := s.getOwnedArticle(s.db, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.PublishAt == nil && req.UnpublishAt == nil {
+		return nil, fmt.Errorf("%w: publish_at or unpublish_at is required", ErrInvalidArticleSchedule)
+	}
+	if req.PublishAt == nil && article.Status != ArticleStatusPublished && article.Status != ArticleStatusScheduled {
+		return nil, fmt.Errorf("%w: publish_at is required for unpublished articles", ErrInvalidArticleSchedule)
+	}
+	if req.UnpublishAt != nil {
+		start := now
+		if req.PublishAt != nil && req.PublishAt.After(now) {
+			start = *req.PublishAt
+		} else if req.PublishAt == nil && article.PublishAt != nil {
+			start = *article.PublishAt
+		}
+		if !req.UnpublishAt.After(start) {
+			return nil, fmt.Errorf("%w: unpublish_at must be after the publish time", ErrInvalidArticleSchedule)
+		}
+	}
+
+	updates := map[string]interface{}{}
+	if req.UnpublishAt != nil {
+		updates["unpublish_at"] = *req.UnpublishAt
+	}
+	publishNow := false
+	if req.PublishAt != nil {
+		if req.PublishAt.After(now) {
+			updates["status"] = ArticleStatusScheduled
+			updates["publish_at"] = *req.PublishAt
+		} else {
+			publishNow = article.Status != ArticleStatusPublished
+			updates["status"] = ArticleStatusPublished
+			updates["publish_at"] = nil
+			if article.PublishedAt == nil {
+				updates["published_at"] = now
+			}
+		}
+	}
+
+	if err := s.db.Model(article).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to schedule article: %v", err)
+	}
+	if publishNow {
+		s.notifyArticlePublished(article)
+	}
+
+	return s.getOwnedArticle(s.db, id, userID)
+}
+
+// CancelArticleSchedule 取消定时：未发布的定时文章退回草稿，并清除下线时间
+func (s *ArticleService) CancelArticleSchedule(id, userID uint) (*models.Article, error) {
+	article, err := s.getOwnedArticle(s.db, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"publish_at":   nil,
+		"unpublish_at": nil,
+	}
+	if article.Status == ArticleStatusScheduled {
+		updates["status"] = ArticleStatusDraft
+	}
+	if err := s.db.Model(article).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel article schedule: %v", err)
+	}
+
+	return s.getOwnedArticle(s.db, id, userID)
+}
+
+// ProcessScheduledArticles 发布到期的定时文章并归档到达下线时间的文章，返回处理的数量。
+// 多个实例同时运行时，每篇文章通过带状态条件的更新只会被一个实例处理，发布通知也只发送一次
+func (s *ArticleService) ProcessScheduledArticles(now time.Time) (published, archived int, err error) {
+	var due []*models.Article
+	if err := s.db.Where("status = ? AND publish_at <= ?", ArticleStatusScheduled, now).
+		Order("publish_at ASC").
+		Limit(scheduledArticleBatch).
+		Find(&due).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to get scheduled articles: %v", err)
+	}
+
+	for _, article := range due {
+		updates := map[string]interface{}{
+			"status":     ArticleStatusPublished,
+			"publish_at": nil,
+		}
+		if article.PublishedAt == nil {
+			updates["published_at"] = now
+		}
+		result := s.db.Model(&models.Article{}).
+			Where("id = ? AND status = ? AND publish_at <= ?", article.ID, ArticleStatusScheduled, now).
+			Updates(updates)
+		if result.Error != nil {
+			s.logger.Errorf("Failed to publish scheduled article %d: %v", article.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		published++
+		s.notifyArticlePublished(article)
+	}
+
+	var expired []uint
+	if err := s.db.Model(&models.Article{}).
+		Where("status = ? AND unpublish_at <= ?", ArticleStatusPublished, now).
+		Limit(scheduledArticleBatch).
+		Pluck("id", &expired).Error; err != nil {
+		return published, 0, fmt.Errorf("failed to get expired articles: %v", err)
+	}
+	for _, id := range expired {
+		result := s.db.Model(&models.Article{}).
+			Where("id = ? AND status = ? AND unpublish_at <= ?", id, ArticleStatusPublished, now).
+			Updates(map[string]interface{}{
+				"status":       ArticleStatusArchived,
+				"unpublish_at": nil,
+			})
+		if result.Error != nil {
+			s.logger.Errorf("Failed to archive expired article %d: %v", id, result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			archived++
+		}
+	}
+
+	return published, archived, nil
+}
+
+func (s *ArticleService) notifyArticlePublished(article *models.Article) {
+	if err := NewNotificationManager(s.db, s.logger).CreateArticlePublishedNotification(article); err != nil {
+		s.logger.Errorf("Failed to create article published notification: %v", err)
+	}
+}
+
+// validateArticleStatus 定时状态只能通过 ScheduleArticle 设置
+func validateArticleStatus(status string, publishAt *time.Time) error {
+	if status == ArticleStatusScheduled && publishAt == nil {
+		return fmt.Errorf("%w: use the schedule endpoint to schedule an article", ErrInvalidArticleSchedule)
+	}
+	return nil
+}
+
+// applyArticleStatus 切换状态时维护发布相关的时间字段
+func applyArticleStatus(article *models.Article, status string, now time.Time) {
+	if status == "" || status == article.Status {
+		return
+	}
+	article.Status = status
+	if status != ArticleStatusScheduled {
+		article.PublishAt = nil
+	}
+	if status == ArticleStatusPublished && article.PublishedAt == nil {
+		article.PublishedAt = &now
+	}
+}
+
+// publishArticleUpdates 立即发布时的更新字段
+func publishArticleUpdates(db *gorm.DB, id, userID uint) *gorm.DB {
+	now := time.Now()
+	return db.Model(&models.Article{}).
+		Where("id = ? AND created_by = ?", id, userID).
+		Updates(map[string]interface{}{
+			"status":       ArticleStatusPublished,
+			"publish_at":   nil,
+			"published_at": gorm.Expr("COALESCE(published_at, ?)", now),
+		})
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newArticleScheduleTestService(t *testing.T) (*ArticleService, *gorm.DB) {
+	db := openTestDB(t, &models.User{}, &models.Article{}, &models.Notification{})
+	require.NoError(t, db.Create(&models.User{Username: "alice", Email: "alice@example.com", Password: "x"}).Error)
+	return NewArticleService(db, nil, logger.NewLogger(logger.DefaultLoggerConfig())), db
+}
+
+func publishedNotifications(t *testing.T, db *gorm.DB) int64 {
+	var count int64
+	require.NoError(t, db.Model(&models.Notification{}).Where("type = ?", "article_published").Count(&count).Error)
+	return count
+}
+
+func TestArticleService_ProcessScheduledArticles(t *testing.T) {
+	svc, db := newArticleScheduleTestService(t)
+	now := time.Now().Truncate(time.Second)
+	earlier, later := now.Add(-time.Minute), now.Add(time.Hour)
+
+	// 停机期间错过的发布时间在下一次调度时补发
+	due := &models.Article{Title: "due", Status: ArticleStatusScheduled, PublishAt: &earlier, UnpublishAt: &later, CreatedBy: 1}
+	overdue := now.Add(-24 * time.Hour)
+	missed := &models.Article{Title: "missed", Status: ArticleStatusScheduled, PublishAt: &overdue, CreatedBy: 1}
+	future := &models.Article{Title: "future", Status: ArticleStatusScheduled, PublishAt: &later, CreatedBy: 1}
+	expired := &models.Article{Title: "expired", Status: ArticleStatusPublished, PublishedAt: &overdue, UnpublishAt: &earlier, CreatedBy: 1}
+	for _, article := range []*models.Article{due, missed, future, expired} {
+		require.NoError(t, db.Create(article).Error)
+	}
+
+	published, archived, err := svc.ProcessScheduledArticles(now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, 1, archived)
+	assert.EqualValues(t, 2, publishedNotifications(t, db))
+
+	reload := func(id uint) models.Article {
+		var article models.Article
+		require.NoError(t, db.First(&article, id).Error)
+		return article
+	}
+	for _, id := range []uint{due.ID, missed.ID} {
+		article := reload(id)
+		assert.Equal(t, ArticleStatusPublished, article.Status)
+		assert.Nil(t, article.PublishAt)
+		require.NotNil(t, article.PublishedAt)
+		assert.True(t, article.PublishedAt.Equal(now))
+	}
+	assert.NotNil(t, reload(due.ID).UnpublishAt)
+	assert.Equal(t, ArticleStatusScheduled, reload(future.ID).Status)
+	archivedArticle := reload(expired.ID)
+	assert.Equal(t, ArticleStatusArchived, archivedArticle.Status)
+	assert.Nil(t, archivedArticle.UnpublishAt)
+	assert.True(t, archivedArticle.PublishedAt.Equal(overdue))
+
+	// 到达下线时间后归档
+	published, archived, err = svc.ProcessScheduledArticles(later)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, 1, archived)
+	assert.Equal(t, ArticleStatusArchived, reload(due.ID).Status)
+}
+
+func TestArticleService_ProcessScheduledArticlesPublishesOnce(t *testing.T) {
+	svc, db := newArticleScheduleTestService(t)
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+	article := &models.Article{Title: "due", Status: ArticleStatusScheduled, PublishAt: &earlier, CreatedBy: 1}
+	require.NoError(t, db.Create(article).Error)
+
+	// 模拟另一个实例在本实例查询到文章之后、更新之前抢先发布
+	raced := false
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:concurrent_publish", func(tx *gorm.DB) {
+		if raced || tx.Statement.Table != "articles" {
+			return
+		}
+		raced = true
+		require.NoError(t, tx.Session(&gorm.Session{NewDB: true}).Model(&models.Article{}).
+			Where("id = ?", article.ID).
+			Updates(map[string]interface{}{"status": ArticleStatusPublished, "publish_at": nil}).Error)
+	}))
+
+	published, _, err := svc.ProcessScheduledArticles(now)
+	require.NoError(t, err)
+	require.True(t, raced)
+	assert.Zero(t, published)
+	assert.Zero(t, publishedNotifications(t, db))
+}
+
+func TestArticleService_ScheduleInPastPublishesImmediately(t *testing.T) {
+	svc, db := newArticleScheduleTestService(t)
+	article := &models.Article{Title: "draft", Status: ArticleStatusDraft, CreatedBy: 1}
+	require.NoError(t, db.Create(article).Error)
+
+	past := time.Now().Add(-time.Hour)
+	scheduled, err := svc.ScheduleArticle(article.ID, 1, ScheduleArticleRequest{PublishAt: &past})
+	require.NoError(t, err)
+	assert.Equal(t, ArticleStatusPublished, scheduled.Status)
+	assert.Nil(t, scheduled.PublishAt)
+	assert.NotNil(t, scheduled.PublishedAt)
+	assert.EqualValues(t, 1, publishedNotifications(t, db))
+
+	// 调度任务不会再次发布
+	published, _, err := svc.ProcessScheduledArticles(time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	assert.EqualValues(t, 1, publishedNotifications(t, db))
+}
@@ -1,23 +1,26 @@
 package service
 
 import (
-	"encoding/json"
 	"fmt"
 	"gin-web-framework/internal/models"
+	"gin-web-framework/internal/search"
 	"gin-web-framework/pkg/logger"
 	"gin-web-framework/pkg/utils"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type ArticleService struct {
 	db     *gorm.DB
+	images *ImageService // 导入导出文章时读写正文引用的图片
 	logger logger.LoggerInterface
 }
 
-func NewArticleService(db *gorm.DB, logger logger.LoggerInterface) *ArticleService {
+func NewArticleService(db *gorm.DB, images *ImageService, logger logger.LoggerInterface) *ArticleService {
 	return &ArticleService{
 		db:     db,
+		images: images,
 		logger: logger,
 	}
 }
@@ -30,6 +33,7 @@ type CreateArticleRequest struct {
 	CoverImage string   `json:"cover_image"`
 	Status     string   `json:"status"`
 	Tags       []string `json:"tags"`
+	Slug       string   `json:"slug" binding:"max=200"` // 为空时根据标题生成
 }
 
 // UpdateArticleRequest 更新文章请求
@@ -40,6 +44,7 @@ type UpdateArticleRequest struct {
 	CoverImage string   `json:"cover_image"`
 	Status     string   `json:"status"`
 	Tags       []string `json:"tags"`
+	Slug       string   `json:"slug" binding:"max=200"`
 }
 
 type ArticleFilter struct {
@@ -63,12 +68,8 @@ type PaginatedArticles struct {
 
 // CreateArticle 创建文章
 func (s *ArticleService) CreateArticle(req CreateArticleRequest, userID uint) (*models.Article, error) {
-	// 将标签转换为JSON字符串
-	tagsJSON := ""
-	if req.Tags != nil {
-		if tags, err := json.Marshal(req.Tags); err == nil {
-			tagsJSON = string(tags)
-		}
+	if err := validateArticleStatus(req.Status, nil); err != nil {
+		return nil, err
 	}
 
 	article := &models.Article{
@@ -77,13 +78,36 @@ func (s *ArticleService) CreateArticle(req CreateArticleRequest, userID uint) (*
 		Summary:    req.Summary,
 		CoverImage: req.CoverImage,
 		Status:     req.Status,
-		Tags:       tagsJSON,
 		CreatedBy:  userID,
 	}
+	if article.Status == ArticleStatusPublished {
+		now := time.Now()
+		article.PublishedAt = &now
+	}
 
-	if err := s.db.Create(article).Error; err != nil {
-		return nil, fmt.Errorf("failed to create article: %v", err)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(article).Error; err != nil {
+			return fmt.Errorf("failed to create article: %v", err)
+		}
+		if err := s.assignArticleSlug(tx, article, req.Slug); err != nil {
+			return err
+		}
+		if req.Tags != nil {
+			tags, err := setContentTags(tx, SearchDocArticle, article.ID, req.Tags)
+			if err != nil {
+				return err
+			}
+			article.Tags = tags
+			if err := tx.Model(article).UpdateColumn("tags", tags).Error; err != nil {
+				return fmt.Errorf("failed to save article tags: %v", err)
+			}
+		}
+		return s.recordRevision(tx, article, userID, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
+	syncSearchIndex(s.db, SearchDocArticle, article.ID)
 
 	return article, nil
 }
@@ -105,14 +129,18 @@ func (s *ArticleService) GetUserArticles(userID uint, filter *ArticleFilter) (*P
 		s.logger.Debugf("Applying published filter: %v", *filter.IsPublished)
 		query = query.Where("status = ?", "published")
 	}
+	if filter.Tags != "" {
+		s.logger.Debugf("Applying tags filter: %s", filter.Tags)
+		query = query.Scopes(tagScope(SearchDocArticle, filter.Tags))
+	}
 	if filter.Search != "" {
 		s.logger.Debugf("Applying search filter: %s", filter.Search)
+		// 启用全文索引时按相关度排序并返回高亮摘要
+		if idx, ok := search.FromDB(s.db); ok {
+			return s.searchArticlesRanked(idx, query, *filter)
+		}
 		query = query.Where("title LIKE ? OR content LIKE ?", "%"+filter.Search+"%", "%"+filter.Search+"%")
 	}
-	if filter.Tags != "" {
-		s.logger.Debugf("Applying tags filter: %s", filter.Tags)
-		query = query.Where("tags LIKE ?", "%\""+filter.Tags+"\"%")
-	}
 
 	// 获取总数
 	if err := query.Model(&models.Article{}).Count(&total).Error; err != nil {
@@ -196,6 +224,9 @@ func (s *ArticleService) GetArticles(userID uint, filter ArticleFilter) (*Pagina
 		query = query.Where("status = ?", filter.Status)
 	}
 	if filter.Search != "" {
+		if idx, ok := search.FromDB(s.db); ok {
+			return s.searchArticlesRanked(idx, query, filter)
+		}
 		query = query.Where("title LIKE ? OR content LIKE ?", "%"+filter.Search+"%", "%"+filter.Search+"%")
 	}
 
@@ -230,9 +261,9 @@ func (s *ArticleService) GetArticles(userID uint, filter ArticleFilter) (*Pagina
 	}, nil
 }
 
-// PublishArticle 发布文章
+// PublishArticle 立即发布文章，会清除尚未执行的定时发布
 func (s *ArticleService) PublishArticle(id uint, userID uint) error {
-	return s.db.Model(&models.Article{}).Where("id = ? AND created_by = ?", id, userID).Update("status", "published").Error
+	return publishArticleUpdates(s.db, id, userID).Error
 }
 
 // ArchiveArticle 归档文章
@@ -242,12 +273,31 @@ func (s *ArticleService) ArchiveArticle(id uint, userID uint) error {
 
 // RestoreArticle 恢复文章
 func (s *ArticleService) RestoreArticle(id uint, userID uint) error {
-	return s.db.Model(&models.Article{}).Where("id = ? AND created_by = ?", id, userID).Update("status", "draft").Error
+	return s.db.Model(&models.Article{}).Where("id = ? AND created_by = ?", id, userID).
+		Updates(map[string]interface{}{"status": ArticleStatusDraft, "publish_at": nil}).Error
 }
 
 // UpdateContent 更新文章内容
 func (s *ArticleService) UpdateContent(id uint, userID uint, content string) error {
-	return s.db.Model(&models.Article{}).Where("id = ? AND created_by = ?", id, userID).Update("content", content).Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		article, err := s.getOwnedArticle(tx, id, userID)
+		if err != nil {
+			return err
+		}
+		if err := s.ensureBaselineRevision(tx, article); err != nil {
+			return err
+		}
+		if err := tx.Model(article).Update("content", content).Error; err != nil {
+			return err
+		}
+		article.Content = content
+		return s.recordRevision(tx, article, userID, nil)
+	})
+	if err != nil {
+		return err
+	}
+	syncSearchIndex(s.db, SearchDocArticle, id)
+	return nil
 }
 
 // IncrementViewCount 增加浏览次数
@@ -320,14 +370,47 @@ func (s *ArticleService) UnlikeArticle(id uint, userID uint) error {
 	return tx.Commit().Error
 }
 
-// UpdateArticle 更新文章
+// UpdateArticle 更新文章，标题、摘要或正文有变化时生成新的修订版本
 func (s *ArticleService) UpdateArticle(id uint, userID uint, req UpdateArticleRequest) (*models.Article, error) {
-	var article models.Article
-	if err := s.db.Where("id = ? AND created_by = ?", id, userID).First(&article).Error; err != nil {
-		return nil, fmt.Errorf("article not found: %v", err)
+	var article *models.Article
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if article, err = s.getOwnedArticle(tx, id, userID); err != nil {
+			return err
+		}
+		if err := validateArticleStatus(req.Status, article.PublishAt); err != nil {
+			return err
+		}
+		if err := s.ensureBaselineRevision(tx, article); err != nil {
+			return err
+		}
+		applyArticleUpdate(article, req)
+		if req.Slug != "" {
+			if err := s.assignArticleSlug(tx, article, req.Slug); err != nil {
+				return err
+			}
+		}
+		if req.Tags != nil {
+			if article.Tags, err = setContentTags(tx, SearchDocArticle, article.ID, req.Tags); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Save(article).Error; err != nil {
+			return fmt.Errorf("failed to update article: %v", err)
+		}
+		return s.recordRevision(tx, article, userID, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
+	syncSearchIndex(s.db, SearchDocArticle, article.ID)
+
+	return article, nil
+}
 
-	// 更新字段
+// applyArticleUpdate 应用更新请求中的非空字段
+func applyArticleUpdate(article *models.Article, req UpdateArticleRequest) {
 	if req.Title != "" {
 		article.Title = req.Title
 	}
@@ -340,20 +423,7 @@ func (s *ArticleService) UpdateArticle(id uint, userID uint, req UpdateArticleRe
 	if req.CoverImage != "" {
 		article.CoverImage = req.CoverImage
 	}
-	if req.Status != "" {
-		article.Status = req.Status
-	}
-	if req.Tags != nil {
-		if tags, err := json.Marshal(req.Tags); err == nil {
-			article.Tags = string(tags)
-		}
-	}
-
-	if err := s.db.Save(&article).Error; err != nil {
-		return nil, fmt.Errorf("failed to update article: %v", err)
-	}
-
-	return &article, nil
+	applyArticleStatus(article, req.Status, time.Now())
 }
 
 // DeleteArticle 删除文章
@@ -361,6 +431,7 @@ func (s *ArticleService) DeleteArticle(articleID, userID uint) error {
 	if err := s.db.Where("id = ? AND created_by = ?", articleID, userID).Delete(&models.Article{}).Error; err != nil {
 		return fmt.Errorf("failed to delete article: %v", err)
 	}
+	syncSearchIndex(s.db, SearchDocArticle, articleID)
 
 	return nil
 }
@@ -0,0 +1,452 @@
+package service
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/frontmatter"
+	"gin-web-framework/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxArticleArchiveSize 导入压缩包的大小上限
+	MaxArticleArchiveSize = 50 << 20
+	maxArchiveEntries     = 1000
+	maxArchiveArticleSize = 2 << 20
+	maxArchiveImageSize   = 5 << 20
+
+	// archiveImageDir 压缩包中图片所在的目录
+	archiveImageDir = "images"
+)
+
+var ErrInvalidArchive = errors.New("invalid article archive")
+
+var (
+	// uploadedImageRe 匹配正文中本站上传的图片地址
+	uploadedImageRe = regexp.MustCompile(`/uploads/images/([A-Za-z0-9][A-Za-z0-9._-]*)`)
+	// archiveImageRefRe 匹配正文中指向压缩包内 images 目录的相对地址
+	archiveImageRefRe = regexp.MustCompile(`(^|[\s(\[<"'=])((?:\.{1,2}/)*images/[A-Za-z0-9][A-Za-z0-9._-]*)`)
+)
+
+// articleFrontMatter 导入导出使用的 front matter 字段，兼容 Hugo/Jekyll 的 date、draft
+type articleFrontMatter struct {
+	Title       string      `yaml:"title"`
+	Slug        string      `yaml:"slug,omitempty"`
+	Summary     string      `yaml:"summary,omitempty"`
+	Cover       string      `yaml:"cover,omitempty"`
+	Tags        interface{} `yaml:"tags,omitempty"` // 列表或逗号分隔的字符串
+	Status      string      `yaml:"status,omitempty"`
+	Draft       *bool       `yaml:"draft,omitempty"`
+	Date        *time.Time  `yaml:"date,omitempty"` // 首次发布时间，未发布的文章为创建时间
+	Updated     *time.Time  `yaml:"updated,omitempty"`
+	PublishAt   *time.Time  `yaml:"publish_at,omitempty"`
+	UnpublishAt *time.Time  `yaml:"unpublish_at,omitempty"`
+}
+
+// ArticleImportResult 导入结果
+type ArticleImportResult struct {
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"` // 按 slug 匹配到已有文章并更新
+	Skipped  int               `json:"skipped"`
+	Images   int               `json:"images"`
+	Articles []*models.Article `json:"articles"`
+	Errors   []string          `json:"errors,omitempty"`
+}
+
+// ArticleExportFilter 导出条件
+type ArticleExportFilter struct {
+	Status string `form:"status"`
+}
+
+// ImportArticles 从 zip 压缩包导入 Markdown 文章。front matter 中的 slug 与自己的文章相同时更新该文章，
+// 否则新建；正文和封面中指向压缩包内 images 目录的图片会保存到上传目录并替换为站内地址。
+// 单篇文章出错时跳过并记录原因
+func (s *ArticleService) ImportArticles(userID uint, r io.ReaderAt, size int64) (*ArticleImportResult, error) {
+	if size > MaxArticleArchiveSize {
+		return nil, fmt.Errorf("%w: archive exceeds %d MB", ErrInvalidArchive, MaxArticleArchiveSize>>20)
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if len(zr.File) > maxArchiveEntries {
+		return nil, fmt.Errorf("%w: too many files (max %d)", ErrInvalidArchive, maxArchiveEntries)
+	}
+
+	var docs []*zip.File
+	images := &archiveImages{
+		service: s.images,
+		files:   make(map[string]*zip.File),
+		saved:   make(map[string]string),
+	}
+	for _, f := range zr.File {
+		name, ok := cleanArchivePath(f.Name)
+		if !ok || f.FileInfo().IsDir() {
+			continue
+		}
+		switch ext := strings.ToLower(path.Ext(name)); {
+		case ext == ".md" || ext == ".markdown":
+			docs = append(docs, f)
+		case utils.IsAllowedImageType(name):
+			images.files[name] = f
+		}
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("%w: no markdown files found", ErrInvalidArchive)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+
+	result := &ArticleImportResult{Articles: []*models.Article{}}
+	for _, f := range docs {
+		name, _ := cleanArchivePath(f.Name)
+		article, created, err := s.importArticle(userID, name, f, images)
+		if err != nil {
+			result.Skipped++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		result.Articles = append(result.Articles, article)
+		syncSearchIndex(s.db, SearchDocArticle, article.ID)
+	}
+	result.Images = len(images.saved)
+	return result, nil
+}
+
+func (s *ArticleService) importArticle(userID uint, name string, f *zip.File, images *archiveImages) (*models.Article, bool, error) {
+	data, err := readArchiveFile(f, maxArchiveArticleSize)
+	if err != nil {
+		return nil, false, err
+	}
+	var meta articleFrontMatter
+	body, err := frontmatter.Parse(data, &meta)
+	if err != nil {
+		return nil, false, err
+	}
+
+	title := strings.TrimSpace(meta.Title)
+	if title == "" {
+		title = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	status, err := meta.status()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := validateArticleStatus(status, meta.PublishAt); err != nil {
+		return nil, false, err
+	}
+
+	dir := path.Dir(name)
+	content, err := images.rewrite(dir, string(body))
+	if err != nil {
+		return nil, false, err
+	}
+	cover := strings.TrimSpace(meta.Cover)
+	if cover != "" {
+		if cover, err = images.rewrite(dir, cover); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var article models.Article
+	created := true
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if slug := utils.Slugify(meta.Slug, maxArticleSlugLength); slug != "" {
+			err := tx.Where("slug = ?", slug).First(&article).Error
+			switch {
+			case err == nil && article.CreatedBy != userID:
+				return ErrArticleSlugTaken
+			case err == nil:
+				created = false
+				if err := s.ensureBaselineRevision(tx, &article); err != nil {
+					return err
+				}
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				return fmt.Errorf("failed to find article: %v", err)
+			}
+		}
+
+		article.Title = title
+		article.Content = content
+		article.Summary = meta.Summary
+		article.CoverImage = cover
+		article.CreatedBy = userID
+		article.Status = status
+		article.PublishAt = nil
+		if status == ArticleStatusScheduled {
+			article.PublishAt = meta.PublishAt
+		}
+		article.UnpublishAt = meta.UnpublishAt
+		if meta.Date != nil {
+			if created {
+				article.CreatedAt = *meta.Date
+			}
+			if status != ArticleStatusDraft {
+				article.PublishedAt = meta.Date
+			}
+		}
+		if status == ArticleStatusPublished && article.PublishedAt == nil {
+			now := time.Now()
+			article.PublishedAt = &now
+		}
+
+		if created {
+			if err := tx.Create(&article).Error; err != nil {
+				return fmt.Errorf("failed to create article: %v", err)
+			}
+		} else if err := tx.Save(&article).Error; err != nil {
+			return fmt.Errorf("failed to update article: %v", err)
+		}
+		if created || meta.Slug != "" {
+			if err := s.assignArticleSlug(tx, &article, meta.Slug); err != nil {
+				return err
+			}
+		}
+
+		columns := map[string]interface{}{}
+		if meta.Tags != nil {
+			tags, err := setContentTags(tx, SearchDocArticle, article.ID, parseTagInput(meta.Tags))
+			if err != nil {
+				return err
+			}
+			article.Tags = tags
+			columns["tags"] = tags
+		}
+		if meta.Updated != nil {
+			article.UpdatedAt = *meta.Updated
+			columns["updated_at"] = *meta.Updated
+		}
+		if len(columns) > 0 {
+			if err := tx.Model(&article).UpdateColumns(columns).Error; err != nil {
+				return fmt.Errorf("failed to save article: %v", err)
+			}
+		}
+		return s.recordRevision(tx, &article, userID, nil)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &article, created, nil
+}
+
+// ExportArticles 将用户的文章导出为 zip 压缩包：每篇文章一个带 front matter 的 Markdown 文件，
+// 正文和封面引用的上传图片放在 images 目录并改为相对地址，可直接用 ImportArticles 导入
+func (s *ArticleService) ExportArticles(userID uint, filter ArticleExportFilter, w io.Writer) error {
+	query := s.db.Where("created_by = ?", userID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var articles []*models.Article
+	if err := query.Order("id").Find(&articles).Error; err != nil {
+		return fmt.Errorf("failed to get articles: %v", err)
+	}
+
+	zw := zip.NewWriter(w)
+	images := make(map[string]bool)
+	for _, article := range articles {
+		content := exportImageRefs(article.Content, images)
+		doc, err := frontmatter.Marshal(exportFrontMatter(article, exportImageRefs(article.CoverImage, images)), []byte(content))
+		if err != nil {
+			return fmt.Errorf("failed to encode article %d: %v", article.ID, err)
+		}
+		if err := writeArchiveFile(zw, exportFileName(article), article.UpdatedAt, doc); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(images))
+	for name := range images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := s.readImage(name)
+		if err != nil {
+			// 图片已被删除时保留正文中的引用，不中断导出
+			s.logger.Warnf("Skip missing article image %s: %v", name, err)
+			continue
+		}
+		if err := writeArchiveFile(zw, archiveImageDir+"/"+name, time.Time{}, data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (s *ArticleService) readImage(name string) ([]byte, error) {
+	rc, _, err := s.images.OpenImage(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (m *articleFrontMatter) status() (string, error) {
+	status := strings.ToLower(strings.TrimSpace(m.Status))
+	switch status {
+	case "":
+		if m.Draft != nil && !*m.Draft {
+			return ArticleStatusPublished, nil
+		}
+		return ArticleStatusDraft, nil
+	case ArticleStatusDraft, ArticleStatusScheduled, ArticleStatusPublished, ArticleStatusArchived:
+		return status, nil
+	}
+	return "", fmt.Errorf("unknown status %q", m.Status)
+}
+
+func exportFrontMatter(article *models.Article, cover string) *articleFrontMatter {
+	meta := &articleFrontMatter{
+		Title:       article.Title,
+		Summary:     article.Summary,
+		Cover:       cover,
+		Status:      article.Status,
+		PublishAt:   article.PublishAt,
+		UnpublishAt: article.UnpublishAt,
+	}
+	if article.Slug != nil {
+		meta.Slug = *article.Slug
+	}
+	if tags := parseSearchTags(article.Tags); len(tags) > 0 {
+		meta.Tags = tags
+	}
+	date := article.CreatedAt
+	if article.PublishedAt != nil {
+		date = *article.PublishedAt
+	}
+	updated := article.UpdatedAt
+	meta.Date = &date
+	meta.Updated = &updated
+	return meta
+}
+
+func exportFileName(article *models.Article) string {
+	if article.Slug != nil && *article.Slug != "" {
+		return *article.Slug + ".md"
+	}
+	return fmt.Sprintf("article-%d.md", article.ID)
+}
+
+// exportImageRefs 把本站上传图片的地址替换为压缩包内的相对地址，并记录需要打包的图片
+func exportImageRefs(text string, images map[string]bool) string {
+	return uploadedImageRe.ReplaceAllStringFunc(text, func(match string) string {
+		name := uploadedImageRe.FindStringSubmatch(match)[1]
+		images[name] = true
+		return archiveImageDir + "/" + name
+	})
+}
+
+// archiveImages 压缩包中的图片，同一张图片只保存一次
+type archiveImages struct {
+	service *ImageService
+	files   map[string]*zip.File
+	saved   map[string]string // 压缩包内路径 -> 站内地址
+}
+
+// rewrite 保存 text 中引用的压缩包图片并替换为站内地址，dir 为引用所在文件的目录。
+// 压缩包中不存在的图片保持原样
+func (a *archiveImages) rewrite(dir, text string) (string, error) {
+	var firstErr error
+	result := archiveImageRefRe.ReplaceAllStringFunc(text, func(match string) string {
+		m := archiveImageRefRe.FindStringSubmatch(match)
+		name := path.Join(dir, m[2])
+		if _, ok := a.files[name]; !ok {
+			return match
+		}
+		url, err := a.save(name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return match
+		}
+		return m[1] + url
+	})
+	return result, firstErr
+}
+
+// save 经图片服务校验、去除元数据后保存，内容相同的图片（包括之前导出的图片）复用已有文件
+func (a *archiveImages) save(name string) (string, error) {
+	if url, ok := a.saved[name]; ok {
+		return url, nil
+	}
+	data, err := readArchiveFile(a.files[name], maxArchiveImageSize)
+	if err != nil {
+		return "", err
+	}
+	image, err := a.service.SaveImage(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+	a.saved[name] = image.URL
+	return image.URL, nil
+}
+
+// cleanArchivePath 规范化压缩包内的路径，拒绝绝对路径、跳出根目录的路径和隐藏文件
+func cleanArchivePath(name string) (string, bool) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") {
+		return "", false
+	}
+	name = path.Clean(name)
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+func readArchiveFile(f *zip.File, limit int64) ([]byte, error) {
+	if f.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("file exceeds %d MB", limit>>20)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", f.Name, err)
+	}
+	defer rc.Close()
+
+	// 压缩包头中的大小可能被伪造，读取时再次限制
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", f.Name, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("file exceeds %d MB", limit>>20)
+	}
+	return data, nil
+}
+
+func writeArchiveFile(zw *zip.Writer, name string, modified time.Time, data []byte) error {
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	if !modified.IsZero() {
+		header.Modified = modified
+	}
+	fw, err := zw.CreateHeader(header)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,486 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/storage"
+	"gin-web-framework/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// attachmentKeyPrefix 附件在存储中的目录，完整 key 为 attachments/<用户ID>/<随机串>/<文件名>
+	attachmentKeyPrefix = "attachments/"
+	// attachmentOrphanGrace 未关联的附件保留时长，超过后视为孤立文件清理
+	attachmentOrphanGrace = 24 * time.Hour
+	// attachmentCleanupBatch 每轮清理的最大附件数
+	attachmentCleanupBatch = 200
+	// attachmentDefaultURLTTL 未配置时下载地址的有效期
+	attachmentDefaultURLTTL = 15 * time.Minute
+)
+
+var (
+	ErrAttachmentNotFound       = errors.New("attachment not found")
+	ErrAttachmentForbidden      = errors.New("not allowed to modify this attachment")
+	ErrAttachmentTypeNotAllowed = errors.New("file type is not allowed")
+	ErrAttachmentTooLarge       = errors.New("file is too large")
+	ErrAttachmentQuotaExceeded  = errors.New("storage quota exceeded")
+	ErrAttachmentEmpty          = errors.New("file is empty")
+	ErrInvalidAttachmentTarget  = errors.New("invalid attachment target")
+)
+
+// attachmentExtensionTypes 内容嗅探只能识别为纯文本或 zip 的常见文档类型
+var attachmentExtensionTypes = map[string]string{
+	".txt":      "text/plain",
+	".md":       "text/markdown",
+	".markdown": "text/markdown",
+	".csv":      "text/csv",
+	".docx":     "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx":     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx":     "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// AttachmentOptions 附件的大小、配额和类型限制
+type AttachmentOptions struct {
+	MaxSize      int64         // 单个文件的最大字节数
+	UserQuota    int64         // 每个用户附件的总字节数，0 表示不限制
+	AllowedTypes []string      // 允许的 MIME 类型，支持 image/* 形式的通配，为空时不限制
+	URLTTL       time.Duration // 下载地址的有效期
+}
+
+// AttachmentTarget 附件关联的任务或文章
+type AttachmentTarget struct {
+	Type string `json:"target_type" form:"target_type"`
+	ID   uint   `json:"target_id" form:"target_id"`
+}
+
+// UpdateAttachmentRequest 修改附件关联的内容，target_type 为空时取消关联
+type UpdateAttachmentRequest struct {
+	TargetType string `json:"target_type" binding:"omitempty,oneof=todo article"`
+	TargetID   uint   `json:"target_id"`
+}
+
+// AttachmentUsage 用户的附件存储用量
+type AttachmentUsage struct {
+	Used  int64 `json:"used"`
+	Quota int64 `json:"quota"`
+	Count int64 `json:"count"`
+}
+
+// AttachmentList 用户上传的附件及存储用量
+type AttachmentList struct {
+	Attachments []*models.Attachment `json:"attachments"`
+	Usage       AttachmentUsage      `json:"usage"`
+}
+
+// AttachmentService 任务和文章的附件服务：类型校验、用户配额、权限和孤立文件清理
+type AttachmentService struct {
+	db      *gorm.DB
+	store   storage.Storage
+	options AttachmentOptions
+	logger  logger.LoggerInterface
+}
+
+// NewAttachmentService 创建附件服务
+func NewAttachmentService(db *gorm.DB, store storage.Storage, options AttachmentOptions, logger logger.LoggerInterface) *AttachmentService {
+	if options.URLTTL <= 0 {
+		options.URLTTL = attachmentDefaultURLTTL
+	}
+	return &AttachmentService{
+		db:      db,
+		store:   store,
+		options: options,
+		logger:  logger,
+	}
+}
+
+// Upload 保存上传的附件，target 为空时先不关联，之后可通过 UpdateAttachment 关联到任务或文章。
+// 文件先写入存储，再在锁定用户记录的事务中检查配额并创建记录，失败时删除已写入的文件
+func (s *AttachmentService) Upload(userID uint, filename string, r io.Reader, target *AttachmentTarget) (*models.Attachment, error) {
+	if target != nil {
+		if err := s.checkTarget(s.db, *target, userID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.options.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	if len(data) == 0 {
+		return nil, ErrAttachmentEmpty
+	}
+	if int64(len(data)) > s.options.MaxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	name := utils.SanitizeFilename(path.Base(strings.ReplaceAll(filename, "\\", "/")))
+	mimeType := detectAttachmentType(name, data)
+	if !s.typeAllowed(mimeType) {
+		return nil, fmt.Errorf("%w: %s", ErrAttachmentTypeNotAllowed, mimeType)
+	}
+
+	key, err := attachmentKey(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(data)
+	attachment := &models.Attachment{
+		UserID:     userID,
+		Filename:   name,
+		StorageKey: key,
+		MimeType:   mimeType,
+		Size:       int64(len(data)),
+		Checksum:   hex.EncodeToString(checksum[:]),
+	}
+	if target != nil {
+		attachment.TargetType, attachment.TargetID = target.Type, &target.ID
+	}
+
+	ctx := context.Background()
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), attachment.Size, mimeType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %v", err)
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.User{}, userID).Error; err != nil {
+			return fmt.Errorf("failed to lock user: %v", err)
+		}
+		if s.options.UserQuota > 0 {
+			used, err := s.usedBytes(tx, userID)
+			if err != nil {
+				return err
+			}
+			if used+attachment.Size > s.options.UserQuota {
+				return ErrAttachmentQuotaExceeded
+			}
+		}
+		if err := tx.Create(attachment).Error; err != nil {
+			return fmt.Errorf("failed to create attachment: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if delErr := s.store.Delete(ctx, key); delErr != nil {
+			s.logger.Warnf("Failed to remove attachment file %s: %v", key, delErr)
+		}
+		return nil, err
+	}
+
+	s.logger.Infof("Attachment uploaded: id=%d user=%d size=%d type=%s", attachment.ID, userID, attachment.Size, mimeType)
+	return s.withURL(attachment)
+}
+
+// ListByTarget 获取任务或文章的附件，要求当前用户可以查看该内容
+func (s *AttachmentService) ListByTarget(target AttachmentTarget, userID uint) ([]*models.Attachment, error) {
+	if err := s.checkTarget(s.db, target, userID, false); err != nil {
+		return nil, err
+	}
+	var attachments []*models.Attachment
+	if err := s.db.Where("target_type = ? AND target_id = ?", target.Type, target.ID).
+		Order("created_at ASC, id ASC").
+		Find(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %v", err)
+	}
+	return s.withURLs(attachments)
+}
+
+// ListByUser 获取用户上传的所有附件及存储用量
+func (s *AttachmentService) ListByUser(userID uint) (*AttachmentList, error) {
+	var attachments []*models.Attachment
+	if err := s.db.Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %v", err)
+	}
+	if _, err := s.withURLs(attachments); err != nil {
+		return nil, err
+	}
+
+	usage := AttachmentUsage{Quota: s.options.UserQuota, Count: int64(len(attachments))}
+	for _, attachment := range attachments {
+		usage.Used += attachment.Size
+	}
+	return &AttachmentList{Attachments: attachments, Usage: usage}, nil
+}
+
+// GetAttachment 获取附件及其下载地址。上传者和可以查看所关联内容的用户可以访问
+func (s *AttachmentService) GetAttachment(id, userID uint) (*models.Attachment, error) {
+	attachment, err := s.getAttachment(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.UserID != userID {
+		allowed, err := s.canAccessTarget(attachment, userID, false)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrAttachmentNotFound
+		}
+	}
+	return s.withURL(attachment)
+}
+
+// UpdateAttachment 把附件关联到另一个任务或文章，或取消关联。只有上传者可以操作，
+// 且需要对新的内容有编辑权限
+func (s *AttachmentService) UpdateAttachment(id, userID uint, req UpdateAttachmentRequest) (*models.Attachment, error) {
+	attachment, err := s.getAttachment(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.UserID != userID {
+		return nil, ErrAttachmentForbidden
+	}
+
+	updates := map[string]interface{}{"target_type": "", "target_id": nil}
+	attachment.TargetType, attachment.TargetID = "", nil
+	if req.TargetType != "" {
+		target := AttachmentTarget{Type: req.TargetType, ID: req.TargetID}
+		if err := s.checkTarget(s.db, target, userID, true); err != nil {
+			return nil, err
+		}
+		updates["target_type"], updates["target_id"] = target.Type, target.ID
+		attachment.TargetType, attachment.TargetID = target.Type, &target.ID
+	}
+	if err := s.db.Model(attachment).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update attachment: %v", err)
+	}
+	return s.withURL(attachment)
+}
+
+// DeleteAttachment 删除附件及其文件。上传者和对所关联内容有编辑权限的用户可以删除
+func (s *AttachmentService) DeleteAttachment(id, userID uint) error {
+	attachment, err := s.getAttachment(s.db, id)
+	if err != nil {
+		return err
+	}
+	if attachment.UserID != userID {
+		if visible, err := s.canAccessTarget(attachment, userID, false); err != nil {
+			return err
+		} else if !visible {
+			return ErrAttachmentNotFound
+		}
+		if editable, err := s.canAccessTarget(attachment, userID, true); err != nil {
+			return err
+		} else if !editable {
+			return ErrAttachmentForbidden
+		}
+	}
+	return s.remove(attachment)
+}
+
+// CollectOrphans 清理孤立的附件：超过保留时长仍未关联的，以及所关联的任务或文章已被删除的。
+// 返回清理的附件数
+func (s *AttachmentService) CollectOrphans(now time.Time) (int, error) {
+	var orphans []*models.Attachment
+	err := s.db.Scopes(orphanedAttachments(now)).
+		Order("id ASC").
+		Limit(attachmentCleanupBatch).
+		Find(&orphans).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find orphaned attachments: %v", err)
+	}
+
+	removed := 0
+	for _, attachment := range orphans {
+		claimed, err := s.claimOrphan(attachment.ID, now)
+		if err != nil {
+			s.logger.Warnf("Failed to remove orphaned attachment %d: %v", attachment.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		removed++
+		if err := s.store.Delete(context.Background(), attachment.StorageKey); err != nil {
+			s.logger.Warnf("Failed to delete file %s of orphaned attachment %d: %v", attachment.StorageKey, attachment.ID, err)
+		}
+	}
+	return removed, nil
+}
+
+// claimOrphan 重新检查孤立条件并删除附件记录，查询之后附件被关联到其它内容时不会删除。
+// 返回 true 时由调用方删除存储中的文件
+func (s *AttachmentService) claimOrphan(id uint, now time.Time) (bool, error) {
+	result := s.db.Scopes(orphanedAttachments(now)).Where("id = ?", id).Delete(&models.Attachment{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete attachment: %v", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// orphanedAttachments 孤立的附件：超过保留时长仍未关联，或所关联的任务、文章已被删除
+func orphanedAttachments(now time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(target_type = '' AND created_at < ?) OR "+
+			"(target_type = ? AND NOT EXISTS (SELECT 1 FROM todos WHERE todos.id = attachments.target_id AND todos.deleted_at IS NULL)) OR "+
+			"(target_type = ? AND NOT EXISTS (SELECT 1 FROM articles WHERE articles.id = attachments.target_id AND articles.deleted_at IS NULL))",
+			now.Add(-attachmentOrphanGrace), models.AttachmentTargetTodo, models.AttachmentTargetArticle)
+	}
+}
+
+// remove 先删除文件再删除记录，文件删除失败时保留记录以便下次重试
+func (s *AttachmentService) remove(attachment *models.Attachment) error {
+	if err := s.store.Delete(context.Background(), attachment.StorageKey); err != nil {
+		return fmt.Errorf("failed to delete attachment file: %v", err)
+	}
+	if err := s.db.Delete(attachment).Error; err != nil {
+		return fmt.Errorf("failed to delete attachment: %v", err)
+	}
+	return nil
+}
+
+// checkTarget 检查关联的内容是否存在以及用户是否有查看（edit 为 false）或编辑权限。
+// 任务按清单成员权限判断；文章已发布时所有人可见，只有作者可以编辑
+func (s *AttachmentService) checkTarget(db *gorm.DB, target AttachmentTarget, userID uint, edit bool) error {
+	if target.ID == 0 {
+		return ErrInvalidAttachmentTarget
+	}
+	var count int64
+	switch target.Type {
+	case models.AttachmentTargetTodo:
+		scope := visibleTodos(userID)
+		if edit {
+			scope = editableTodos(userID)
+		}
+		if err := db.Model(&models.Todo{}).Where("todos.id = ?", target.ID).Scopes(scope).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check todo: %v", err)
+		}
+		if count == 0 {
+			return ErrTodoNotFound
+		}
+	case models.AttachmentTargetArticle:
+		query := db.Model(&models.Article{}).Where("id = ?", target.ID)
+		if edit {
+			query = query.Where("created_by = ?", userID)
+		} else {
+			query = query.Where("created_by = ? OR status = ?", userID, ArticleStatusPublished)
+		}
+		if err := query.Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check article: %v", err)
+		}
+		if count == 0 {
+			return ErrArticleNotFound
+		}
+	default:
+		return ErrInvalidAttachmentTarget
+	}
+	return nil
+}
+
+// canAccessTarget 非上传者是否可以查看或编辑附件所关联的内容，未关联的附件只有上传者可以访问
+func (s *AttachmentService) canAccessTarget(attachment *models.Attachment, userID uint, edit bool) (bool, error) {
+	if attachment.TargetType == "" {
+		return false, nil
+	}
+	err := s.checkTarget(s.db, attachmentTarget(attachment), userID, edit)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrTodoNotFound), errors.Is(err, ErrArticleNotFound), errors.Is(err, ErrInvalidAttachmentTarget):
+		return false, nil
+	}
+	return false, err
+}
+
+func (s *AttachmentService) getAttachment(db *gorm.DB, id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := db.First(&attachment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("failed to get attachment: %v", err)
+	}
+	return &attachment, nil
+}
+
+func (s *AttachmentService) usedBytes(db *gorm.DB, userID uint) (int64, error) {
+	var used int64
+	if err := db.Model(&models.Attachment{}).Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size), 0)").Scan(&used).Error; err != nil {
+		return 0, fmt.Errorf("failed to get storage usage: %v", err)
+	}
+	return used, nil
+}
+
+// typeAllowed 检查 MIME 类型是否在允许列表中
+func (s *AttachmentService) typeAllowed(mimeType string) bool {
+	if len(s.options.AllowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range s.options.AllowedTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mimeType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mimeType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AttachmentService) withURL(attachment *models.Attachment) (*models.Attachment, error) {
+	signed, err := s.store.SignedURL(context.Background(), attachment.StorageKey, s.options.URLTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign attachment url: %v", err)
+	}
+	attachment.URL = signed
+	return attachment, nil
+}
+
+func (s *AttachmentService) withURLs(attachments []*models.Attachment) ([]*models.Attachment, error) {
+	for _, attachment := range attachments {
+		if _, err := s.withURL(attachment); err != nil {
+			return nil, err
+		}
+	}
+	return attachments, nil
+}
+
+func attachmentTarget(attachment *models.Attachment) AttachmentTarget {
+	target := AttachmentTarget{Type: attachment.TargetType}
+	if attachment.TargetID != nil {
+		target.ID = *attachment.TargetID
+	}
+	return target
+}
+
+// attachmentKey 生成存储 key，随机目录保证同名文件不冲突，下载时仍使用原始文件名
+func attachmentKey(userID uint, filename string) (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate attachment key: %v", err)
+	}
+	return storage.CleanKey(fmt.Sprintf("%s%d/%s/%s", attachmentKeyPrefix, userID, hex.EncodeToString(buf), filename))
+}
+
+// detectAttachmentType 根据文件内容判断 MIME 类型，不信任客户端声明的类型。
+// 内容只能识别为通用类型时（纯文本、zip 容器）才按扩展名细化为 Markdown、CSV、Office 文档等
+func detectAttachmentType(filename string, data []byte) string {
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	byExt := attachmentExtensionTypes[strings.ToLower(path.Ext(filename))]
+
+	switch {
+	case sniffed == "text/plain" && strings.HasPrefix(byExt, "text/"):
+		return byExt
+	case sniffed == "application/zip" && strings.HasPrefix(byExt, "application/vnd.openxmlformats-officedocument."):
+		return byExt
+	}
+	return sniffed
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectAttachmentType(t *testing.T) {
+	var docx bytes.Buffer
+	zw := zip.NewWriter(&docx)
+	_, err := zw.Create("word/document.xml")
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		want     string
+	}{
+		{name: "PDF", filename: "report.pdf", data: []byte("%PDF-1.7\n"), want: "application/pdf"},
+		{name: "PNG ignores extension", filename: "photo.txt", data: []byte("\x89PNG\r\n\x1a\n0000"), want: "image/png"},
+		{name: "Plain text", filename: "notes.txt", data: []byte("hello"), want: "text/plain"},
+		{name: "Markdown", filename: "README.MD", data: []byte("# Title\n"), want: "text/markdown"},
+		{name: "CSV", filename: "data.csv", data: []byte("a,b\n1,2\n"), want: "text/csv"},
+		{name: "Office document", filename: "spec.docx", data: docx.Bytes(), want: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{name: "Zip named as text", filename: "archive.txt", data: docx.Bytes(), want: "application/zip"},
+		{name: "HTML named as text", filename: "page.md", data: []byte("<html><script>alert(1)</script>"), want: "text/html"},
+		{name: "Executable named as PDF", filename: "invoice.pdf", data: []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00"), want: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectAttachmentType(tt.filename, tt.data))
+		})
+	}
+}
+
+func TestAttachmentService_TypeAllowed(t *testing.T) {
+	s := &AttachmentService{options: AttachmentOptions{AllowedTypes: []string{"image/*", " application/pdf ", "TEXT/PLAIN"}}}
+
+	assert.True(t, s.typeAllowed("image/png"))
+	assert.True(t, s.typeAllowed("image/webp"))
+	assert.True(t, s.typeAllowed("application/pdf"))
+	assert.True(t, s.typeAllowed("text/plain"))
+	assert.False(t, s.typeAllowed("text/html"))
+	assert.False(t, s.typeAllowed("imagex/png"))
+	assert.False(t, s.typeAllowed("application/octet-stream"))
+
+	unrestricted := &AttachmentService{}
+	assert.True(t, unrestricted.typeAllowed("application/octet-stream"))
+}
+
+func TestAttachmentService_CollectOrphans(t *testing.T) {
+	db := openTestDB(t, &models.Todo{}, &models.Article{}, &models.Attachment{})
+	store := storage.NewLocal(t.TempDir(), nil)
+	svc := NewAttachmentService(db, store, AttachmentOptions{}, logger.NewLogger(logger.DefaultLoggerConfig()))
+
+	ctx := context.Background()
+	now := time.Now()
+	todo := &models.Todo{Title: "todo", PriorityID: 1, CreatedBy: 1}
+	require.NoError(t, db.Create(todo).Error)
+
+	newAttachment := func(key, targetType string, targetID *uint, createdAt time.Time) *models.Attachment {
+		require.NoError(t, store.Put(ctx, key, bytes.NewReader([]byte("data")), 4, "text/plain"))
+		attachment := &models.Attachment{
+			UserID: 1, TargetType: targetType, TargetID: targetID, Filename: "a.txt",
+			StorageKey: key, MimeType: "text/plain", Size: 4, Checksum: "x", CreatedAt: createdAt,
+		}
+		require.NoError(t, db.Create(attachment).Error)
+		return attachment
+	}
+	deletedTodo := uint(999)
+	stale := newAttachment("attachments/1/a/stale.txt", "", nil, now.Add(-2*attachmentOrphanGrace))
+	recent := newAttachment("attachments/1/b/recent.txt", "", nil, now)
+	attached := newAttachment("attachments/1/c/attached.txt", models.AttachmentTargetTodo, &todo.ID, now.Add(-2*attachmentOrphanGrace))
+	dangling := newAttachment("attachments/1/d/dangling.txt", models.AttachmentTargetTodo, &deletedTodo, now)
+
+	removed, err := svc.CollectOrphans(now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	var remaining []uint
+	require.NoError(t, db.Model(&models.Attachment{}).Order("id").Pluck("id", &remaining).Error)
+	assert.Equal(t, []uint{recent.ID, attached.ID}, remaining)
+	for _, a := range []*models.Attachment{stale, dangling} {
+		_, _, err := store.Get(ctx, a.StorageKey)
+		assert.ErrorIs(t, err, storage.ErrNotFound, a.StorageKey)
+	}
+	body, _, err := store.Get(ctx, attached.StorageKey)
+	require.NoError(t, err)
+	body.Close()
+
+	// 查询之后被关联的附件不会被删除
+	late := newAttachment("attachments/1/e/late.txt", "", nil, now.Add(-2*attachmentOrphanGrace))
+	require.NoError(t, db.Model(late).Updates(map[string]interface{}{
+		"target_type": models.AttachmentTargetTodo, "target_id": todo.ID,
+	}).Error)
+	claimed, err := svc.claimOrphan(late.ID, now)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	require.NoError(t, db.First(&models.Attachment{}, late.ID).Error)
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
+	"net/http"
 	"time"
 
 	"gin-web-framework/internal/model"
@@ -325,3 +327,43 @@ func (s *AuditService) CreateAuditLog(auditLog *model.AuditLog) error {
 	}
 	return nil
 }
+
+// AuditEvent 业务代码主动记录的安全相关操作，例如启用两步验证。
+// 与审计中间件按请求记录的日志写入同一张表
+type AuditEvent struct {
+	UserID     uint
+	Username   string
+	Action     string
+	Details    map[string]interface{} // 以 JSON 写入请求体字段
+	Client     ClientInfo
+	StatusCode int // 为 0 时记为 200
+}
+
+// recordAuditEvent 写入审计事件，失败时只记录日志，不影响业务操作
+func recordAuditEvent(db *gorm.DB, log logger.LoggerInterface, event AuditEvent) {
+	details := ""
+	if event.Details != nil {
+		if data, err := json.Marshal(event.Details); err == nil {
+			details = string(data)
+		}
+	}
+	status := event.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	auditLog := &model.AuditLog{
+		UserID:      event.UserID,
+		Username:    event.Username,
+		Action:      event.Action,
+		Resource:    "auth",
+		IPAddress:   event.Client.IP,
+		UserAgent:   truncateRunes(event.Client.UserAgent, 500),
+		RequestBody: details,
+		StatusCode:  status,
+		Timestamp:   time.Now(),
+	}
+	if err := db.Create(auditLog).Error; err != nil {
+		log.Errorf("Failed to record audit event %s: %v", event.Action, err)
+	}
+}
@@ -0,0 +1,653 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gin-web-framework/internal/models"
+	pkgdb "gin-web-framework/pkg/database"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BoardService 看板服务
+type BoardService struct {
+	db          *gorm.DB
+	logger      logger.LoggerInterface
+	todoService *TodoService
+	tx          *pkgdb.TransactionService
+}
+
+// NewBoardService 创建看板服务
+func NewBoardService(db *gorm.DB, logger logger.LoggerInterface) *BoardService {
+	return &BoardService{
+		db:          db,
+		logger:      logger,
+		todoService: NewTodoService(db, logger),
+		tx:          pkgdb.NewTransactionService(db, logger),
+	}
+}
+
+// CreateBoardRequest 创建看板请求，未指定列时按任务状态生成默认列
+type CreateBoardRequest struct {
+	Name        string                     `json:"name" binding:"required,max=100"`
+	Description string                     `json:"description" binding:"max=500"`
+	ListID      *uint                      `json:"list_id"`
+	Columns     []CreateBoardColumnRequest `json:"columns" binding:"dive"`
+}
+
+// UpdateBoardRequest 更新看板请求
+type UpdateBoardRequest struct {
+	Name        string `json:"name" binding:"max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+// CreateBoardColumnRequest 创建看板列请求
+type CreateBoardColumnRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Status   string `json:"status" binding:"omitempty,oneof=pending in_progress completed cancelled"`
+	WipLimit int    `json:"wip_limit" binding:"min=0"`
+	Color    string `json:"color"`
+	AfterID  *uint  `json:"after_id"` // 插入到该列之后，0表示最前，为空表示末尾
+}
+
+// UpdateBoardColumnRequest 更新看板列请求
+type UpdateBoardColumnRequest struct {
+	Name     string  `json:"name" binding:"max=100"`
+	Status   *string `json:"status"` // 空字符串表示改为自定义泳道
+	WipLimit *int    `json:"wip_limit" binding:"omitempty,min=0"`
+	Color    string  `json:"color"`
+	AfterID  *uint   `json:"after_id"` // 调整顺序：移动到该列之后，0表示最前
+}
+
+// MoveBoardTodoRequest 拖拽移动任务请求，after_id/before_id 为目标列中相邻的任务
+type MoveBoardTodoRequest struct {
+	TodoID   uint  `json:"todo_id" binding:"required"`
+	ColumnID uint  `json:"column_id" binding:"required"`
+	AfterID  *uint `json:"after_id"`
+	BeforeID *uint `json:"before_id"`
+}
+
+var (
+	ErrBoardNotFound        = errors.New("board not found")
+	ErrBoardColumnNotFound  = errors.New("board column not found")
+	ErrBoardWipLimit        = errors.New("column WIP limit reached")
+	ErrInvalidBoardPosition = errors.New("invalid board position")
+)
+
+// defaultBoardColumns 默认看板列
+var defaultBoardColumns = []CreateBoardColumnRequest{
+	{Name: "待处理", Status: "pending"},
+	{Name: "进行中", Status: "in_progress"},
+	{Name: "已完成", Status: "completed"},
+}
+
+// CreateBoard 创建看板，指定清单时需要有清单编辑权限
+func (s *BoardService) CreateBoard(userID uint, req CreateBoardRequest) (*models.Board, error) {
+	if req.ListID != nil && *req.ListID != 0 {
+		role, err := getTodoListRole(s.db, *req.ListID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if role == "" {
+			return nil, ErrTodoListNotFound
+		}
+		if !canEditTodoList(role) {
+			return nil, ErrTodoListDenied
+		}
+	} else {
+		req.ListID = nil
+	}
+
+	columns := req.Columns
+	if len(columns) == 0 {
+		columns = defaultBoardColumns
+	}
+
+	board := &models.Board{
+		Name:        req.Name,
+		Description: req.Description,
+		ListID:      req.ListID,
+		CreatedBy:   userID,
+	}
+	positions := utils.RankSequence(len(columns))
+	for i, column := range columns {
+		board.Columns = append(board.Columns, models.BoardColumn{
+			Name:     column.Name,
+			Status:   column.Status,
+			Position: positions[i],
+			WipLimit: column.WipLimit,
+			Color:    column.Color,
+		})
+	}
+
+	if err := s.db.Create(board).Error; err != nil {
+		return nil, fmt.Errorf("failed to create board: %v", err)
+	}
+	return board, nil
+}
+
+// GetBoards 获取用户可访问的看板：个人看板及所在清单的看板
+func (s *BoardService) GetBoards(userID uint) ([]*models.Board, error) {
+	memberLists := s.db.Model(&models.TodoListMember{}).Select("list_id").Where("user_id = ?", userID)
+
+	var boards []*models.Board
+	if err := s.db.Where("(list_id IS NULL AND created_by = ?) OR list_id IN (?)", userID, memberLists).
+		Preload("Columns", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, id ASC")
+		}).
+		Order("created_at ASC").
+		Find(&boards).Error; err != nil {
+		return nil, fmt.Errorf("failed to get boards: %v", err)
+	}
+	return boards, nil
+}
+
+// GetBoard 获取看板及各列中的任务
+func (s *BoardService) GetBoard(boardID, userID uint) (*models.Board, error) {
+	board, err := s.getBoard(boardID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := s.loadBoardLayout(s.db, board)
+	if err != nil {
+		return nil, err
+	}
+	for i := range layout.columns {
+		layout.columns[i].Todos = layout.todos[layout.columns[i].ID]
+	}
+	board.Columns = layout.columns
+	return board, nil
+}
+
+// UpdateBoard 更新看板信息
+func (s *BoardService) UpdateBoard(boardID, userID uint, req UpdateBoardRequest) (*models.Board, error) {
+	board, err := s.getBoard(boardID, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		board.Name = req.Name
+	}
+	if req.Description != "" {
+		board.Description = req.Description
+	}
+
+	if err := s.db.Save(board).Error; err != nil {
+		return nil, fmt.Errorf("failed to update board: %v", err)
+	}
+	return board, nil
+}
+
+// DeleteBoard 删除看板，任务保留但清除其在看板中的位置
+func (s *BoardService) DeleteBoard(boardID, userID uint) error {
+	board, err := s.getBoard(boardID, userID, true)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		columnIDs := tx.Model(&models.BoardColumn{}).Select("id").Where("board_id = ?", board.ID)
+		if err := clearBoardPlacement(tx.Where("board_column_id IN (?)", columnIDs)); err != nil {
+			return err
+		}
+		if err := tx.Where("board_id = ?", board.ID).Delete(&models.BoardColumn{}).Error; err != nil {
+			return fmt.Errorf("failed to delete board columns: %v", err)
+		}
+		if err := tx.Delete(board).Error; err != nil {
+			return fmt.Errorf("failed to delete board: %v", err)
+		}
+		return nil
+	})
+}
+
+// CreateColumn 添加看板列
+func (s *BoardService) CreateColumn(boardID, userID uint, req CreateBoardColumnRequest) (*models.BoardColumn, error) {
+	board, err := s.getBoard(boardID, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	column := &models.BoardColumn{
+		BoardID:  board.ID,
+		Name:     req.Name,
+		Status:   req.Status,
+		WipLimit: req.WipLimit,
+		Color:    req.Color,
+	}
+
+	err = s.tx.ExecuteInTransaction(context.Background(), func(tx *gorm.DB) error {
+		if err := lockBoard(tx, board.ID); err != nil {
+			return err
+		}
+		position, err := placeBoardColumn(tx, board.ID, 0, req.AfterID)
+		if err != nil {
+			return err
+		}
+		column.Position = position
+		return tx.Create(column).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return column, nil
+}
+
+// UpdateColumn 更新看板列，可同时调整列顺序
+func (s *BoardService) UpdateColumn(boardID, columnID, userID uint, req UpdateBoardColumnRequest) (*models.BoardColumn, error) {
+	board, err := s.getBoard(boardID, userID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var column models.BoardColumn
+	err = s.tx.ExecuteInTransaction(context.Background(), func(tx *gorm.DB) error {
+		if err := lockBoard(tx, board.ID); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND board_id = ?", columnID, board.ID).First(&column).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBoardColumnNotFound
+			}
+			return err
+		}
+
+		if req.Name != "" {
+			column.Name = req.Name
+		}
+		if req.Status != nil {
+			if !validBoardColumnStatus(*req.Status) {
+				return errors.New("invalid column status")
+			}
+			column.Status = *req.Status
+		}
+		if req.WipLimit != nil {
+			column.WipLimit = *req.WipLimit
+		}
+		if req.Color != "" {
+			column.Color = req.Color
+		}
+		if req.AfterID != nil {
+			position, err := placeBoardColumn(tx, board.ID, column.ID, req.AfterID)
+			if err != nil {
+				return err
+			}
+			column.Position = position
+		}
+
+		return tx.Save(&column).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &column, nil
+}
+
+// DeleteColumn 删除看板列，列中的任务回到按状态自动归列
+func (s *BoardService) DeleteColumn(boardID, columnID, userID uint) error {
+	board, err := s.getBoard(boardID, userID, true)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND board_id = ?", columnID, board.ID).Delete(&models.BoardColumn{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete board column: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrBoardColumnNotFound
+		}
+		return clearBoardPlacement(tx.Where("board_column_id = ?", columnID))
+	})
+}
+
+// MoveTodo 拖拽移动任务：在同一事务中修改所在列、列内位置以及映射的状态。
+// 目标列行被加锁，同一列的并发移动会串行执行，避免生成重复或错乱的排序键
+func (s *BoardService) MoveTodo(boardID, userID uint, req MoveBoardTodoRequest) (*models.Todo, error) {
+	board, err := s.getBoard(boardID, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.AfterID != nil && req.BeforeID != nil {
+		return nil, ErrInvalidBoardPosition
+	}
+
+	todo, err := s.todoService.getEditableTodo(req.TodoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	justCompleted := false
+	err = s.tx.ExecuteInTransaction(context.Background(), func(tx *gorm.DB) error {
+		var column models.BoardColumn
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND board_id = ?", req.ColumnID, board.ID).
+			First(&column).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBoardColumnNotFound
+			}
+			return err
+		}
+
+		// 事务内重新读取，保证基于最新的列内容计算位置
+		if err := tx.Scopes(boardTodos(board)).First(todo, todo.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("todo is not on this board")
+			}
+			return err
+		}
+
+		layout, err := s.loadBoardLayout(tx, board)
+		if err != nil {
+			return err
+		}
+
+		siblings := make([]*models.Todo, 0, len(layout.todos[column.ID]))
+		alreadyInColumn := false
+		for _, sibling := range layout.todos[column.ID] {
+			if sibling.ID == todo.ID {
+				alreadyInColumn = true
+				continue
+			}
+			siblings = append(siblings, sibling)
+		}
+		if !alreadyInColumn && column.WipLimit > 0 && len(siblings) >= column.WipLimit {
+			return ErrBoardWipLimit
+		}
+
+		index, err := boardInsertIndex(siblings, req.AfterID, req.BeforeID)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"board_column_id": column.ID}
+		if column.Status != "" && column.Status != todo.Status {
+			justCompleted = column.Status == "completed"
+			if justCompleted {
+				if err := ensureNotBlocked(tx, todo.ID); err != nil {
+					return err
+				}
+				now := time.Now()
+				updates["completed_at"] = now
+				todo.CompletedAt = &now
+			}
+			updates["status"] = column.Status
+			todo.Status = column.Status
+		}
+
+		position, ok := boardRankAt(siblings, layout.placed, index)
+		if ok {
+			updates["board_position"] = position
+		} else {
+			// 相邻位置缺少排序键或键过长时，重新均匀分配整列
+			ordered := make([]*models.Todo, 0, len(siblings)+1)
+			ordered = append(ordered, siblings[:index]...)
+			ordered = append(ordered, todo)
+			ordered = append(ordered, siblings[index:]...)
+			ranks := utils.RankSequence(len(ordered))
+			for i, item := range ordered {
+				if item.ID == todo.ID {
+					updates["board_position"] = ranks[i]
+					continue
+				}
+				if err := tx.Model(&models.Todo{}).Where("id = ?", item.ID).
+					Updates(map[string]interface{}{"board_column_id": column.ID, "board_position": ranks[i]}).Error; err != nil {
+					return fmt.Errorf("failed to rebalance column: %v", err)
+				}
+			}
+		}
+
+		if err := tx.Model(&models.Todo{}).Where("id = ?", todo.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to move todo: %v", err)
+		}
+		if justCompleted {
+			_, err := s.todoService.spawnNextOccurrence(tx, todo)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if justCompleted {
+		notificationManager := NewNotificationManager(s.db, s.logger)
+		if err := notificationManager.CreateTaskCompletedNotification(todo); err != nil {
+			s.logger.Errorf("Failed to create completion notification: %v", err)
+		}
+	}
+
+	return s.todoService.GetTodoByID(todo.ID, userID)
+}
+
+// getBoard 获取看板并校验权限，requireEdit 为 true 时需要编辑权限
+func (s *BoardService) getBoard(boardID, userID uint, requireEdit bool) (*models.Board, error) {
+	var board models.Board
+	if err := s.db.First(&board, boardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBoardNotFound
+		}
+		return nil, fmt.Errorf("failed to get board: %v", err)
+	}
+
+	if board.ListID == nil {
+		if board.CreatedBy != userID {
+			return nil, ErrBoardNotFound
+		}
+		return &board, nil
+	}
+
+	role, err := getTodoListRole(s.db, *board.ListID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrBoardNotFound
+	}
+	if requireEdit && !canEditTodoList(role) {
+		return nil, ErrTodoListDenied
+	}
+	return &board, nil
+}
+
+// boardLayout 看板列及各列中的任务
+type boardLayout struct {
+	columns []models.BoardColumn
+	todos   map[uint][]*models.Todo
+	placed  map[uint]bool // 已通过拖拽放入该列、排序键有效的任务
+}
+
+// loadBoardLayout 将看板范围内的任务分配到各列：
+// 已放入本看板某列且状态与列映射一致的任务留在该列，其余任务按状态归入第一个匹配的列
+func (s *BoardService) loadBoardLayout(db *gorm.DB, board *models.Board) (*boardLayout, error) {
+	layout := &boardLayout{
+		todos:  make(map[uint][]*models.Todo),
+		placed: make(map[uint]bool),
+	}
+	if err := db.Where("board_id = ?", board.ID).Order("position ASC, id ASC").Find(&layout.columns).Error; err != nil {
+		return nil, fmt.Errorf("failed to get board columns: %v", err)
+	}
+
+	var todos []*models.Todo
+	if err := db.Scopes(boardTodos(board)).
+		Preload("Priority").
+		Preload("Category").
+		Preload("Assignee").
+		Find(&todos).Error; err != nil {
+		return nil, fmt.Errorf("failed to get board todos: %v", err)
+	}
+
+	columnsByID := make(map[uint]models.BoardColumn, len(layout.columns))
+	for _, column := range layout.columns {
+		columnsByID[column.ID] = column
+	}
+
+	for _, todo := range todos {
+		if todo.BoardColumnID != nil && todo.BoardPosition != "" {
+			if column, ok := columnsByID[*todo.BoardColumnID]; ok && (column.Status == "" || column.Status == todo.Status) {
+				layout.todos[column.ID] = append(layout.todos[column.ID], todo)
+				layout.placed[todo.ID] = true
+				continue
+			}
+		}
+		for _, column := range layout.columns {
+			if column.Status != "" && column.Status == todo.Status {
+				layout.todos[column.ID] = append(layout.todos[column.ID], todo)
+				break
+			}
+		}
+	}
+
+	// 列内排序：有排序键的任务在前，未放置的任务按创建顺序排在末尾
+	for _, items := range layout.todos {
+		sort.SliceStable(items, func(i, j int) bool {
+			placedI, placedJ := layout.placed[items[i].ID], layout.placed[items[j].ID]
+			if placedI != placedJ {
+				return placedI
+			}
+			if placedI && items[i].BoardPosition != items[j].BoardPosition {
+				return items[i].BoardPosition < items[j].BoardPosition
+			}
+			return items[i].ID < items[j].ID
+		})
+	}
+
+	return layout, nil
+}
+
+// boardTodos 看板范围内的任务：清单看板为清单中的任务，个人看板为用户未放入清单的任务
+func boardTodos(board *models.Board) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if board.ListID != nil {
+			return db.Where("todos.list_id = ?", *board.ListID)
+		}
+		return db.Where("todos.created_by = ? AND todos.list_id IS NULL", board.CreatedBy)
+	}
+}
+
+// validBoardColumnStatus 列可映射的任务状态，空字符串表示不映射
+func validBoardColumnStatus(status string) bool {
+	switch status {
+	case "", "pending", "in_progress", "completed", "cancelled":
+		return true
+	}
+	return false
+}
+
+// boardInsertIndex 根据相邻任务计算插入下标，均未指定时追加到末尾
+func boardInsertIndex(siblings []*models.Todo, afterID, beforeID *uint) (int, error) {
+	if afterID == nil && beforeID == nil {
+		return len(siblings), nil
+	}
+	for i, sibling := range siblings {
+		if afterID != nil && sibling.ID == *afterID {
+			return i + 1, nil
+		}
+		if beforeID != nil && sibling.ID == *beforeID {
+			return i, nil
+		}
+	}
+	return 0, ErrInvalidBoardPosition
+}
+
+// boardRankAt 计算插入到 index 处的排序键，前一个任务没有有效排序键或结果过长时返回 false
+func boardRankAt(siblings []*models.Todo, placed map[uint]bool, index int) (string, bool) {
+	prev, next := "", ""
+	if index > 0 {
+		if !placed[siblings[index-1].ID] {
+			return "", false
+		}
+		prev = siblings[index-1].BoardPosition
+	}
+	// 后一个任务未放置时排在所有已放置任务之后，视为无上界
+	if index < len(siblings) && placed[siblings[index].ID] {
+		next = siblings[index].BoardPosition
+	}
+
+	rank, err := utils.RankBetween(prev, next)
+	if err != nil || len(rank) > utils.MaxRankLength {
+		return "", false
+	}
+	return rank, true
+}
+
+// lockBoard 锁定看板行，串行化同一看板的列顺序调整
+func lockBoard(tx *gorm.DB, boardID uint) error {
+	var board models.Board
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&board, boardID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBoardNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// placeBoardColumn 计算列插入到 afterID 之后的排序键，afterID 为0表示最前，为空表示末尾
+func placeBoardColumn(tx *gorm.DB, boardID, columnID uint, afterID *uint) (string, error) {
+	var columns []models.BoardColumn
+	if err := tx.Where("board_id = ? AND id != ?", boardID, columnID).
+		Order("position ASC, id ASC").
+		Find(&columns).Error; err != nil {
+		return "", fmt.Errorf("failed to get board columns: %v", err)
+	}
+
+	index := len(columns)
+	if afterID != nil {
+		index = -1
+		if *afterID == 0 {
+			index = 0
+		}
+		for i, column := range columns {
+			if column.ID == *afterID {
+				index = i + 1
+				break
+			}
+		}
+		if index < 0 {
+			return "", ErrBoardColumnNotFound
+		}
+	}
+
+	prev, next := "", ""
+	if index > 0 {
+		prev = columns[index-1].Position
+	}
+	if index < len(columns) {
+		next = columns[index].Position
+	}
+	if rank, err := utils.RankBetween(prev, next); err == nil && len(rank) <= utils.MaxRankLength {
+		return rank, nil
+	}
+
+	// 排序键冲突或过长时重新分配其余列，并返回插入位置的键
+	ranks := utils.RankSequence(len(columns) + 1)
+	for i, column := range columns {
+		rank := ranks[i]
+		if i >= index {
+			rank = ranks[i+1]
+		}
+		if err := tx.Model(&models.BoardColumn{}).Where("id = ?", column.ID).Update("position", rank).Error; err != nil {
+			return "", fmt.Errorf("failed to rebalance board columns: %v", err)
+		}
+	}
+	return ranks[index], nil
+}
+
+// clearBoardPlacement 清除任务在看板中的位置
+func clearBoardPlacement(query *gorm.DB) error {
+	if err := query.Model(&models.Todo{}).
+		Updates(map[string]interface{}{"board_column_id": nil, "board_position": ""}).Error; err != nil {
+		return fmt.Errorf("failed to clear board placement: %v", err)
+	}
+	return nil
+}
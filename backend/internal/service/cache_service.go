@@ -349,6 +349,25 @@ func (cs *CacheService) Increment(ctx context.Context, key string) (int64, error
 	return result.Result()
 }
 
+// IncrementWithExpiration 计数加一，键新建时设置过期时间（秒）。
+// Lua脚本保证原子性，不会留下没有过期时间的计数
+func (cs *CacheService) IncrementWithExpiration(ctx context.Context, key string, expiration int) (int64, error) {
+	if cs.redisClient == nil {
+		return 0, fmt.Errorf("Redis client is not available")
+	}
+
+	script := `
+		local count = redis.call("incr", KEYS[1])
+		if count == 1 or redis.call("ttl", KEYS[1]) == -1 then
+			redis.call("expire", KEYS[1], ARGV[1])
+		end
+		return count
+	`
+
+	result := cs.getNativeClient().Eval(ctx, script, []string{key}, expiration)
+	return result.Int64()
+}
+
 func (cs *CacheService) IncrementBy(ctx context.Context, key string, value int64) (int64, error) {
 	if cs.redisClient == nil {
 		return 0, fmt.Errorf("Redis client is not available")
@@ -438,6 +457,11 @@ func (cs *CacheService) Unlock(ctx context.Context, key string) error {
 }
 
 // 健康检查
+// Available 是否配置了 Redis，未配置时所有缓存操作都会返回错误
+func (cs *CacheService) Available() bool {
+	return cs.redisClient != nil
+}
+
 func (cs *CacheService) Ping(ctx context.Context) error {
 	if cs.redisClient == nil {
 		return fmt.Errorf("Redis client is not available")
@@ -0,0 +1,516 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/ical"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// CalendarService 日历订阅与导入服务
+type CalendarService struct {
+	db          *gorm.DB
+	logger      logger.LoggerInterface
+	todoService *TodoService
+}
+
+// NewCalendarService 创建日历服务
+func NewCalendarService(db *gorm.DB, logger logger.LoggerInterface) *CalendarService {
+	return &CalendarService{
+		db:          db,
+		logger:      logger,
+		todoService: NewTodoService(db, logger),
+	}
+}
+
+const (
+	calendarProdID = "-//gin-web-framework//Todo Calendar//ZH"
+	calendarUIDTag = "gin-web-framework"
+
+	// feedCompletedRetention 订阅源中保留最近完成的任务的时长
+	feedCompletedRetention = 30 * 24 * time.Hour
+	// defaultEventDuration 只有截止时间且没有预估工时的任务在日程中的默认时长
+	defaultEventDuration = 30 * time.Minute
+	// maxImportComponents 单次导入的最大条目数
+	maxImportComponents = 500
+)
+
+// 订阅源输出的组件类型
+const (
+	CalendarFeedTodo  = "todo"
+	CalendarFeedEvent = "event"
+	CalendarFeedBoth  = "both"
+)
+
+var ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+
+// CalendarImportOptions 导入选项
+type CalendarImportOptions struct {
+	ListID *uint `form:"list_id" json:"list_id"`
+}
+
+// CalendarImportResult 导入结果
+type CalendarImportResult struct {
+	Created int            `json:"created"`
+	Skipped int            `json:"skipped"` // 已导入过或缺少标题的条目
+	Todos   []*models.Todo `json:"todos"`
+	Errors  []string       `json:"errors,omitempty"`
+}
+
+// GetFeedToken 获取用户的订阅令牌，不存在时自动创建
+func (s *CalendarService) GetFeedToken(userID uint) (*models.CalendarFeedToken, error) {
+	var token models.CalendarFeedToken
+	err := s.db.Where("user_id = ?", userID).First(&token).Error
+	if err == nil {
+		return &token, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get calendar feed token: %v", err)
+	}
+	return s.RegenerateFeedToken(userID)
+}
+
+// RegenerateFeedToken 重新生成订阅令牌，旧的订阅地址立即失效
+func (s *CalendarService) RegenerateFeedToken(userID uint) (*models.CalendarFeedToken, error) {
+	value, err := utils.GenerateRandomToken(24)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate calendar feed token: %v", err)
+	}
+
+	var token models.CalendarFeedToken
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).First(&token).Error
+		switch {
+		case err == nil:
+			token.Token = value
+			token.LastAccessedAt = nil
+			return tx.Save(&token).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			token = models.CalendarFeedToken{UserID: userID, Token: value}
+			return tx.Create(&token).Error
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save calendar feed token: %v", err)
+	}
+
+	return &token, nil
+}
+
+// RevokeFeedToken 关闭日历订阅
+func (s *CalendarService) RevokeFeedToken(userID uint) error {
+	if err := s.db.Where("user_id = ?", userID).Delete(&models.CalendarFeedToken{}).Error; err != nil {
+		return fmt.Errorf("failed to revoke calendar feed token: %v", err)
+	}
+	return nil
+}
+
+// RenderFeed 根据订阅令牌生成ICS订阅源。
+// 包含用户可见、设置了开始或截止时间的任务，已完成的任务只保留最近30天
+func (s *CalendarService) RenderFeed(tokenValue, feedType string) (*ical.Component, error) {
+	if tokenValue == "" {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	var token models.CalendarFeedToken
+	if err := s.db.Where("token = ?", tokenValue).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarFeedNotFound
+		}
+		return nil, fmt.Errorf("failed to get calendar feed token: %v", err)
+	}
+
+	now := time.Now()
+	s.db.Model(&token).UpdateColumn("last_accessed_at", now)
+
+	var todos []*models.Todo
+	if err := s.db.Scopes(visibleTodos(token.UserID)).
+		Where("todos.start_date IS NOT NULL OR todos.due_date IS NOT NULL").
+		Where("todos.status != ? OR todos.completed_at >= ?", "completed", now.Add(-feedCompletedRetention)).
+		Preload("Priority").
+		Preload("Category").
+		Order("todos.due_date ASC, todos.id ASC").
+		Find(&todos).Error; err != nil {
+		return nil, fmt.Errorf("failed to get calendar todos: %v", err)
+	}
+
+	cal := ical.NewCalendar(calendarProdID)
+	cal.Set("METHOD", "PUBLISH")
+	cal.SetText("X-WR-CALNAME", "我的待办")
+	cal.Set("REFRESH-INTERVAL", "PT1H", "VALUE", "DURATION")
+	cal.Set("X-PUBLISHED-TTL", "PT1H")
+
+	for _, todo := range todos {
+		if feedType != CalendarFeedEvent {
+			cal.AddComponent(todoComponent(todo, now))
+		}
+		if feedType != CalendarFeedTodo {
+			if event := eventComponent(todo, now); event != nil {
+				cal.AddComponent(event)
+			}
+		}
+	}
+
+	return cal, nil
+}
+
+// ImportICS 从ICS文件导入任务：VTODO 和 VEVENT 均会创建为任务。
+// 已导入过的UID会被跳过，优先级按 iCalendar PRIORITY 映射，分类按名称匹配用户已有分类
+func (s *CalendarService) ImportICS(userID uint, r io.Reader, opts CalendarImportOptions) (*CalendarImportResult, error) {
+	cal, err := ical.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	components := append(cal.Children(ical.ComponentTodo), cal.Children(ical.ComponentEvent)...)
+	if len(components) > maxImportComponents {
+		return nil, fmt.Errorf("too many calendar items: %d (max %d)", len(components), maxImportComponents)
+	}
+
+	loc := loadUserLocation(s.db, userID)
+	priorities, defaultPriority, err := s.loadPriorityLevels()
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.loadCategoryNames(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CalendarImportResult{Todos: []*models.Todo{}}
+	seen := make(map[string]bool)
+	for _, component := range components {
+		title := strings.TrimSpace(component.Text("SUMMARY"))
+		uid := strings.TrimSpace(component.Text("UID"))
+		// 本系统订阅源导出的条目已存在，不重复导入
+		if title == "" || (uid != "" && seen[uid]) || strings.HasSuffix(uid, "@"+calendarUIDTag) {
+			result.Skipped++
+			continue
+		}
+		if uid != "" {
+			seen[uid] = true
+			var existing int64
+			s.db.Model(&models.Todo{}).Where("created_by = ? AND ical_uid = ?", userID, uid).Count(&existing)
+			if existing > 0 {
+				result.Skipped++
+				continue
+			}
+		}
+
+		req := CreateTodoRequest{
+			Title:       truncateRunes(title, 255),
+			Description: component.Text("DESCRIPTION"),
+			PriorityID:  defaultPriority,
+			ListID:      opts.ListID,
+		}
+		if level := icalPriorityToLevel(component.Get("PRIORITY")); level > 0 {
+			if id, ok := priorities[level]; ok {
+				req.PriorityID = id
+			}
+		}
+		for _, prop := range component.GetAll("CATEGORIES") {
+			for _, name := range ical.SplitText(prop.Value) {
+				if id, ok := categories[strings.ToLower(strings.TrimSpace(name))]; ok && req.CategoryID == nil {
+					categoryID := id
+					req.CategoryID = &categoryID
+				}
+			}
+		}
+		req.StartDate, req.DueDate = componentSchedule(component, loc)
+
+		todo, err := s.todoService.CreateTodo(req, userID)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", title, err))
+			continue
+		}
+
+		updates := map[string]interface{}{}
+		if uid != "" {
+			updates["ical_uid"] = uid
+			todo.ICalUID = uid
+		}
+		if status := icalStatusToTodo(component.Get("STATUS")); status != "" && status != todo.Status {
+			updates["status"] = status
+			todo.Status = status
+			if status == "completed" {
+				completedAt := time.Now()
+				if prop := component.Get("COMPLETED"); prop != nil {
+					if t, _, err := ical.ParseDateTime(prop, loc); err == nil {
+						completedAt = t
+					}
+				}
+				updates["completed_at"] = completedAt
+				todo.CompletedAt = &completedAt
+			}
+		}
+		if len(updates) > 0 {
+			if err := s.db.Model(todo).Updates(updates).Error; err != nil {
+				s.logger.Errorf("Failed to update imported todo %d: %v", todo.ID, err)
+			}
+		}
+
+		result.Created++
+		result.Todos = append(result.Todos, todo)
+	}
+
+	return result, nil
+}
+
+// loadPriorityLevels 获取优先级等级到ID的映射，以及默认优先级（“中”，不存在时取最低等级）
+func (s *CalendarService) loadPriorityLevels() (map[int]uint, uint, error) {
+	var priorities []models.TodoPriority
+	if err := s.db.Order("level ASC").Find(&priorities).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get priorities: %v", err)
+	}
+	if len(priorities) == 0 {
+		return nil, 0, ErrInvalidPriority
+	}
+
+	levels := make(map[int]uint, len(priorities))
+	for _, priority := range priorities {
+		levels[priority.Level] = priority.ID
+	}
+	defaultPriority, ok := levels[2]
+	if !ok {
+		defaultPriority = priorities[0].ID
+	}
+	return levels, defaultPriority, nil
+}
+
+// loadCategoryNames 获取用户分类名称（小写）到ID的映射
+func (s *CalendarService) loadCategoryNames(userID uint) (map[string]uint, error) {
+	var categories []models.Category
+	if err := s.db.Where("created_by = ?", userID).Find(&categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to get categories: %v", err)
+	}
+
+	names := make(map[string]uint, len(categories))
+	for _, category := range categories {
+		names[strings.ToLower(category.Name)] = category.ID
+	}
+	return names, nil
+}
+
+// todoComponent 将任务转换为 VTODO
+func todoComponent(todo *models.Todo, now time.Time) *ical.Component {
+	component := &ical.Component{Name: ical.ComponentTodo}
+	component.SetText("UID", todoUID(todo, ""))
+	component.SetDateTime("DTSTAMP", now)
+	component.SetDateTime("CREATED", todo.CreatedAt)
+	component.SetDateTime("LAST-MODIFIED", todo.UpdatedAt)
+	component.SetText("SUMMARY", todo.Title)
+	if todo.Description != "" {
+		component.SetText("DESCRIPTION", todo.Description)
+	}
+	if todo.StartDate != nil {
+		component.SetDateTime("DTSTART", *todo.StartDate)
+	}
+	if todo.DueDate != nil {
+		component.SetDateTime("DUE", *todo.DueDate)
+	}
+	if priority := levelToICalPriority(todo.Priority.Level); priority > 0 {
+		component.Set("PRIORITY", strconv.Itoa(priority))
+	}
+	if todo.Category != nil {
+		component.Set("CATEGORIES", ical.EscapeText(todo.Category.Name))
+	}
+
+	switch todo.Status {
+	case "in_progress":
+		component.Set("STATUS", "IN-PROCESS")
+	case "completed":
+		component.Set("STATUS", "COMPLETED")
+		component.Set("PERCENT-COMPLETE", "100")
+		if todo.CompletedAt != nil {
+			component.SetDateTime("COMPLETED", *todo.CompletedAt)
+		}
+	case "cancelled":
+		component.Set("STATUS", "CANCELLED")
+	default:
+		component.Set("STATUS", "NEEDS-ACTION")
+	}
+	return component
+}
+
+// eventComponent 将任务转换为 VEVENT，供不支持 VTODO 的日历客户端显示。
+// 日程在截止时间结束，有开始时间时从开始时间起，否则按预估工时向前推算
+func eventComponent(todo *models.Todo, now time.Time) *ical.Component {
+	var start, end time.Time
+	switch {
+	case todo.DueDate != nil:
+		end = *todo.DueDate
+		if todo.StartDate != nil && todo.StartDate.Before(end) {
+			start = *todo.StartDate
+		} else {
+			duration := defaultEventDuration
+			if todo.EstimatedHours > 0 {
+				duration = time.Duration(todo.EstimatedHours * float64(time.Hour))
+			}
+			start = end.Add(-duration)
+		}
+	case todo.StartDate != nil:
+		start = *todo.StartDate
+		end = start.Add(defaultEventDuration)
+	default:
+		return nil
+	}
+
+	component := &ical.Component{Name: ical.ComponentEvent}
+	component.SetText("UID", todoUID(todo, "event"))
+	component.SetDateTime("DTSTAMP", now)
+	component.SetDateTime("LAST-MODIFIED", todo.UpdatedAt)
+	component.SetText("SUMMARY", todo.Title)
+	if todo.Description != "" {
+		component.SetText("DESCRIPTION", todo.Description)
+	}
+	component.SetDateTime("DTSTART", start)
+	component.SetDateTime("DTEND", end)
+	if todo.Category != nil {
+		component.Set("CATEGORIES", ical.EscapeText(todo.Category.Name))
+	}
+	if todo.Status == "cancelled" {
+		component.Set("STATUS", "CANCELLED")
+	} else {
+		component.Set("STATUS", "CONFIRMED")
+	}
+	component.Set("TRANSP", "TRANSPARENT")
+	return component
+}
+
+// todoUID 生成订阅源中的UID，导入的任务沿用原始UID以便客户端去重
+func todoUID(todo *models.Todo, suffix string) string {
+	if todo.ICalUID != "" && suffix == "" {
+		return todo.ICalUID
+	}
+	uid := fmt.Sprintf("todo-%d", todo.ID)
+	if suffix != "" {
+		uid += "-" + suffix
+	}
+	return uid + "@" + calendarUIDTag
+}
+
+// componentSchedule 从组件中读取开始和截止时间。纯日期的截止时间视为当天结束
+func componentSchedule(component *ical.Component, loc *time.Location) (start, due *time.Time) {
+	if prop := component.Get("DTSTART"); prop != nil {
+		if t, _, err := ical.ParseDateTime(prop, loc); err == nil {
+			start = &t
+		}
+	}
+
+	dueProp := component.Get("DUE")
+	if component.Name == ical.ComponentEvent {
+		dueProp = component.Get("DTEND")
+	}
+	if dueProp != nil {
+		if t, allDay, err := ical.ParseDateTime(dueProp, loc); err == nil {
+			if allDay && component.Name == ical.ComponentTodo {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			due = &t
+		}
+	} else if component.Name == ical.ComponentEvent && start != nil {
+		// 没有结束时间的日程以开始时间作为截止时间
+		t := *start
+		due = &t
+	}
+
+	// 只有开始时间的待办保留开始时间，开始晚于截止时丢弃开始时间
+	if start != nil && due != nil && start.After(*due) {
+		start = nil
+	}
+	return start, due
+}
+
+// icalPriorityToLevel iCalendar 优先级（1最高，9最低，0未定义）转为优先级等级（5最高，1最低）
+func icalPriorityToLevel(prop *ical.Property) int {
+	if prop == nil {
+		return 0
+	}
+	priority, err := strconv.Atoi(strings.TrimSpace(prop.Value))
+	if err != nil {
+		return 0
+	}
+
+	switch {
+	case priority == 1:
+		return 5
+	case priority == 2:
+		return 4
+	case priority == 3 || priority == 4:
+		return 3
+	case priority == 5:
+		return 2
+	case priority >= 6 && priority <= 9:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// levelToICalPriority 优先级等级转为 iCalendar 优先级，与 icalPriorityToLevel 互逆
+func levelToICalPriority(level int) int {
+	switch level {
+	case 5:
+		return 1
+	case 4:
+		return 2
+	case 3:
+		return 3
+	case 2:
+		return 5
+	case 1:
+		return 9
+	default:
+		return 0
+	}
+}
+
+// icalStatusToTodo iCalendar 状态转为任务状态
+func icalStatusToTodo(prop *ical.Property) string {
+	if prop == nil {
+		return ""
+	}
+	switch strings.ToUpper(strings.TrimSpace(prop.Value)) {
+	case "IN-PROCESS":
+		return "in_progress"
+	case "COMPLETED":
+		return "completed"
+	case "CANCELLED":
+		return "cancelled"
+	case "NEEDS-ACTION":
+		return "pending"
+	default:
+		return ""
+	}
+}
+
+// loadUserLocation 获取用户设置的时区，未设置或无效时使用服务器本地时区
+func loadUserLocation(db *gorm.DB, userID uint) *time.Location {
+	var settings models.UserSettings
+	if err := db.Where("user_id = ?", userID).First(&settings).Error; err == nil && settings.Timezone != "" {
+		if loc, err := time.LoadLocation(settings.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
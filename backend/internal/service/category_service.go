@@ -92,6 +92,7 @@ func (s *OptimizedCategoryService) CreateCategory(ctx context.Context, category
 		if err := tx.Create(category).Error; err != nil {
 			return errors.NewDatabaseError("创建分类失败", err)
 		}
+		syncSearchIndex(tx, SearchDocCategory, category.ID)
 
 		s.logger.WithFields(map[string]any{
 			"category_id":   category.ID,
@@ -255,6 +256,7 @@ func (s *OptimizedCategoryService) UpdateCategory(ctx context.Context, id, userI
 		if err := tx.WithContext(ctx).Model(&existing).Updates(updates).Error; err != nil {
 			return errors.NewDatabaseError("更新分类失败", err)
 		}
+		syncSearchIndex(tx, SearchDocCategory, id)
 
 		s.logger.WithFields(map[string]any{
 			"category_id": id,
@@ -300,6 +302,7 @@ func (s *OptimizedCategoryService) DeleteCategory(ctx context.Context, id, userI
 		if err := tx.WithContext(ctx).Delete(&category).Error; err != nil {
 			return errors.NewDatabaseError("删除分类失败", err)
 		}
+		syncSearchIndex(tx, SearchDocCategory, id)
 
 		s.logger.WithFields(map[string]any{
 			"category_id":   id,
@@ -0,0 +1,569 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// 评论状态
+const (
+	CommentStatusApproved = "approved"
+	CommentStatusPending  = "pending"
+	CommentStatusHidden   = "hidden"
+)
+
+const (
+	// maxCommentDepth 回复的最大嵌套层级，回复更深层的评论时挂到被回复评论的同级
+	maxCommentDepth = 4
+	// commentEditWindow 发表后允许作者修改的时长
+	commentEditWindow = 15 * time.Minute
+	// commentSnippetLength 通知中引用评论内容的最大字数
+	commentSnippetLength = 80
+)
+
+var (
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrCommentForbidden  = errors.New("not allowed to modify this comment")
+	ErrCommentEditWindow = errors.New("comment can no longer be edited")
+	ErrInvalidComment    = errors.New("invalid comment")
+)
+
+var (
+	commentMentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@])@([A-Za-z0-9_]{3,50})`)
+	commentLinkPattern    = regexp.MustCompile(`(?i)(https?://|www\.)`)
+)
+
+// CommentService 文章评论服务
+type CommentService struct {
+	db                  *gorm.DB
+	logger              logger.LoggerInterface
+	notificationService *NotificationService
+}
+
+// NewCommentService 创建文章评论服务
+func NewCommentService(db *gorm.DB, logger logger.LoggerInterface) *CommentService {
+	return &CommentService{
+		db:                  db,
+		logger:              logger,
+		notificationService: NewNotificationService(db, logger),
+	}
+}
+
+// CommentViewer 当前操作评论的用户
+type CommentViewer struct {
+	UserID  uint
+	IsAdmin bool
+}
+
+// CreateCommentRequest 发表评论请求，parent_id 为空时为顶层评论
+type CreateCommentRequest struct {
+	Content  string `json:"content" binding:"required,max=5000"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// UpdateCommentRequest 修改评论请求
+type UpdateCommentRequest struct {
+	Content string `json:"content" binding:"required,max=5000"`
+}
+
+// ModerateCommentRequest 审核评论请求
+type ModerateCommentRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// CommentFilter 评论列表过滤条件，分页以顶层评论为单位
+type CommentFilter struct {
+	Status string `form:"status"` // 仅审核队列使用，默认 pending
+	Page   int    `form:"page"`
+	Limit  int    `form:"limit"`
+}
+
+// PaginatedComments 评论分页结果
+type PaginatedComments struct {
+	Comments   []*models.ArticleComment `json:"comments"`
+	Total      int64                    `json:"total"`
+	Page       int                      `json:"page"`
+	Limit      int                      `json:"limit"`
+	TotalPages int                      `json:"total_pages"`
+}
+
+// GetComments 获取文章评论树。顶层评论按时间正序分页，每个讨论串的回复一次性加载；
+// 已删除或已隐藏的评论仍有可见回复时保留为占位，待审核的评论只有作者和管理员可见
+func (s *CommentService) GetComments(articleID uint, viewer CommentViewer, filter CommentFilter) (*PaginatedComments, error) {
+	if _, err := s.getVisibleArticle(articleID, viewer); err != nil {
+		return nil, err
+	}
+
+	visibleSQL, visibleArgs := commentVisibility(viewer, "")
+	replySQL, replyArgs := commentVisibility(viewer, "r.")
+	query := s.db.Unscoped().Model(&models.ArticleComment{}).
+		Where("article_id = ? AND parent_id IS NULL", articleID).
+		Where("(deleted_at IS NULL AND "+visibleSQL+") OR EXISTS (SELECT 1 FROM article_comments r "+
+			"WHERE r.root_id = article_comments.id AND r.deleted_at IS NULL AND "+replySQL+")",
+			append(visibleArgs, replyArgs...)...)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count comments: %v", err)
+	}
+	pagination := utils.NewPaginationInfo(filter.Page, filter.Limit, total)
+
+	var roots []*models.ArticleComment
+	if err := query.Preload("User").
+		Order("created_at ASC, id ASC").
+		Offset(pagination.Offset).
+		Limit(pagination.Limit).
+		Find(&roots).Error; err != nil {
+		return nil, fmt.Errorf("failed to get comments: %v", err)
+	}
+
+	if len(roots) > 0 {
+		rootIDs := make([]uint, len(roots))
+		for i, root := range roots {
+			rootIDs[i] = root.ID
+		}
+		var replies []*models.ArticleComment
+		if err := s.db.Unscoped().
+			Where("root_id IN ?", rootIDs).
+			Preload("User").
+			Order("created_at ASC, id ASC").
+			Find(&replies).Error; err != nil {
+			return nil, fmt.Errorf("failed to get comment replies: %v", err)
+		}
+		buildCommentTree(roots, replies)
+	}
+
+	return &PaginatedComments{
+		Comments:   pruneComments(roots, viewer),
+		Total:      total,
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: pagination.TotalPages,
+	}, nil
+}
+
+// CreateComment 发表评论或回复。包含链接的评论需要审核，文章作者和管理员除外
+func (s *CommentService) CreateComment(articleID uint, viewer CommentViewer, req CreateCommentRequest) (*models.ArticleComment, error) {
+	article, err := s.getVisibleArticle(articleID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: content is required", ErrInvalidComment)
+	}
+
+	comment := &models.ArticleComment{
+		ArticleID: articleID,
+		UserID:    viewer.UserID,
+		Content:   content,
+		Status:    CommentStatusApproved,
+	}
+	var parent *models.ArticleComment
+	if req.ParentID != nil {
+		if parent, err = s.getComment(s.db, *req.ParentID); err != nil {
+			return nil, err
+		}
+		if parent.ArticleID != articleID || !commentVisibleTo(parent, viewer) {
+			return nil, ErrCommentNotFound
+		}
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+		if comment.Depth > maxCommentDepth {
+			comment.ParentID = parent.ParentID
+			comment.Depth = parent.Depth
+		}
+		comment.RootID = parent.RootID
+		if comment.RootID == nil {
+			comment.RootID = &parent.ID
+		}
+	}
+	if s.needsModeration(article, viewer, content) {
+		comment.Status = CommentStatusPending
+	}
+
+	if err := s.db.Create(comment).Error; err != nil {
+		return nil, fmt.Errorf("failed to create comment: %v", err)
+	}
+	if comment.Status == CommentStatusApproved {
+		s.notifyComment(article, comment, parent)
+	}
+
+	return s.getComment(s.db.Preload("User"), comment.ID)
+}
+
+// UpdateComment 修改评论，只有作者能在发表后的编辑时限内修改。
+// 修改后新增链接的评论重新进入审核，新增的 @ 提及会收到通知
+func (s *CommentService) UpdateComment(articleID, commentID uint, viewer CommentViewer, req UpdateCommentRequest) (*models.ArticleComment, error) {
+	article, err := s.getVisibleArticle(articleID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := s.getComment(s.db, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.ArticleID != articleID || !commentVisibleTo(comment, viewer) {
+		return nil, ErrCommentNotFound
+	}
+	if comment.UserID != viewer.UserID || comment.Status == CommentStatusHidden {
+		return nil, ErrCommentForbidden
+	}
+	now := time.Now()
+	if now.Sub(comment.CreatedAt) > commentEditWindow {
+		return nil, ErrCommentEditWindow
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, fmt.Errorf("%w: content is required", ErrInvalidComment)
+	}
+	if content == comment.Content {
+		return s.getComment(s.db.Preload("User"), comment.ID)
+	}
+
+	previous := comment.Content
+	updates := map[string]interface{}{
+		"content":   content,
+		"edited_at": now,
+	}
+	approved := comment.Status == CommentStatusApproved
+	if approved && s.needsModeration(article, viewer, content) {
+		updates["status"] = CommentStatusPending
+		approved = false
+	}
+	if err := s.db.Model(comment).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update comment: %v", err)
+	}
+	comment.Content = content
+	if approved {
+		s.notifyMentions(article, comment, commentMentions(content, previous), map[uint]bool{comment.UserID: true})
+	}
+
+	return s.getComment(s.db.Preload("User"), comment.ID)
+}
+
+// DeleteComment 删除评论（软删除），评论作者、文章作者和管理员可以删除。
+// 评论的回复保留，评论本身在列表中显示为占位
+func (s *CommentService) DeleteComment(articleID, commentID uint, viewer CommentViewer) error {
+	article, err := s.getVisibleArticle(articleID, viewer)
+	if err != nil {
+		return err
+	}
+	comment, err := s.getComment(s.db, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.ArticleID != articleID || !commentVisibleTo(comment, viewer) {
+		return ErrCommentNotFound
+	}
+	if comment.UserID != viewer.UserID && article.CreatedBy != viewer.UserID && !viewer.IsAdmin {
+		return ErrCommentForbidden
+	}
+
+	if err := s.db.Delete(comment).Error; err != nil {
+		return fmt.Errorf("failed to delete comment: %v", err)
+	}
+	return nil
+}
+
+// GetModerationQueue 获取审核队列，默认列出待审核评论，按时间正序
+func (s *CommentService) GetModerationQueue(filter CommentFilter) (*PaginatedComments, error) {
+	status := filter.Status
+	if status == "" {
+		status = CommentStatusPending
+	}
+	if status != CommentStatusPending && status != CommentStatusHidden && status != CommentStatusApproved {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidComment, status)
+	}
+
+	query := s.db.Model(&models.ArticleComment{}).Where("status = ?", status)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count comments: %v", err)
+	}
+	pagination := utils.NewPaginationInfo(filter.Page, filter.Limit, total)
+
+	var comments []*models.ArticleComment
+	if err := query.Preload("User").
+		Preload("Article", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "title", "created_by")
+		}).
+		Order("created_at ASC, id ASC").
+		Offset(pagination.Offset).
+		Limit(pagination.Limit).
+		Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get moderation queue: %v", err)
+	}
+
+	return &PaginatedComments{
+		Comments:   comments,
+		Total:      total,
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: pagination.TotalPages,
+	}, nil
+}
+
+// ApproveComment 通过评论。待审核的评论通过后才发送回复和提及通知
+func (s *CommentService) ApproveComment(commentID, moderatorID uint, req ModerateCommentRequest) (*models.ArticleComment, error) {
+	comment, changed, err := s.moderate(commentID, moderatorID, CommentStatusApproved, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	if changed && comment.Status == CommentStatusPending {
+		var article models.Article
+		if err := s.db.First(&article, comment.ArticleID).Error; err == nil {
+			var parent *models.ArticleComment
+			if comment.ParentID != nil {
+				parent, _ = s.getComment(s.db.Unscoped(), *comment.ParentID)
+			}
+			s.notifyComment(&article, comment, parent)
+		}
+	}
+	return s.getComment(s.db.Preload("User"), commentID)
+}
+
+// HideComment 隐藏评论，隐藏后只有管理员可见
+func (s *CommentService) HideComment(commentID, moderatorID uint, req ModerateCommentRequest) (*models.ArticleComment, error) {
+	if _, _, err := s.moderate(commentID, moderatorID, CommentStatusHidden, req.Reason); err != nil {
+		return nil, err
+	}
+	return s.getComment(s.db.Preload("User"), commentID)
+}
+
+// moderate 修改评论状态，返回修改前的评论。状态未变化或被并发修改时 changed 为 false
+func (s *CommentService) moderate(commentID, moderatorID uint, status, reason string) (*models.ArticleComment, bool, error) {
+	comment, err := s.getComment(s.db, commentID)
+	if err != nil {
+		return nil, false, err
+	}
+	if comment.Status == status {
+		return comment, false, nil
+	}
+
+	result := s.db.Model(&models.ArticleComment{}).
+		Where("id = ? AND status = ?", comment.ID, comment.Status).
+		Updates(map[string]interface{}{
+			"status":            status,
+			"moderated_by":      moderatorID,
+			"moderated_at":      time.Now(),
+			"moderation_reason": strings.TrimSpace(reason),
+		})
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("failed to moderate comment: %v", result.Error)
+	}
+	return comment, result.RowsAffected > 0, nil
+}
+
+// getVisibleArticle 已发布的文章所有人可见，未发布的文章只有作者和管理员可见
+func (s *CommentService) getVisibleArticle(articleID uint, viewer CommentViewer) (*models.Article, error) {
+	var article models.Article
+	if err := s.db.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, fmt.Errorf("failed to get article: %v", err)
+	}
+	if article.Status != ArticleStatusPublished && article.CreatedBy != viewer.UserID && !viewer.IsAdmin {
+		return nil, ErrArticleNotFound
+	}
+	return &article, nil
+}
+
+func (s *CommentService) getComment(db *gorm.DB, id uint) (*models.ArticleComment, error) {
+	var comment models.ArticleComment
+	if err := db.First(&comment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("failed to get comment: %v", err)
+	}
+	return &comment, nil
+}
+
+func (s *CommentService) needsModeration(article *models.Article, viewer CommentViewer, content string) bool {
+	if viewer.IsAdmin || viewer.UserID == article.CreatedBy {
+		return false
+	}
+	return commentLinkPattern.MatchString(content)
+}
+
+// notifyComment 通知被回复的评论作者、文章作者和被提及的用户，每人最多一条，不通知评论者本人
+func (s *CommentService) notifyComment(article *models.Article, comment, parent *models.ArticleComment) {
+	notified := map[uint]bool{comment.UserID: true}
+	actor := s.actorName(comment.UserID)
+	data := map[string]interface{}{
+		"article_id":    article.ID,
+		"article_title": article.Title,
+		"comment_id":    comment.ID,
+	}
+
+	if parent != nil && parent.DeletedAt.Time.IsZero() && !notified[parent.UserID] {
+		notified[parent.UserID] = true
+		s.notify(CreateNotificationRequest{
+			UserID:  parent.UserID,
+			Type:    "comment_reply",
+			Title:   "评论有新回复",
+			Message: fmt.Sprintf("%s 回复了你在「%s」下的评论：%s", actor, article.Title, commentSnippet(comment.Content)),
+			Data:    data,
+		})
+	}
+	if !notified[article.CreatedBy] {
+		notified[article.CreatedBy] = true
+		s.notify(CreateNotificationRequest{
+			UserID:  article.CreatedBy,
+			Type:    "article_comment",
+			Title:   "文章有新评论",
+			Message: fmt.Sprintf("%s 评论了你的文章「%s」：%s", actor, article.Title, commentSnippet(comment.Content)),
+			Data:    data,
+		})
+	}
+	s.notifyMentions(article, comment, commentMentions(comment.Content, ""), notified)
+}
+
+// notifyMentions 通知评论中提及的用户，跳过 notified 中已通知过的用户
+func (s *CommentService) notifyMentions(article *models.Article, comment *models.ArticleComment, usernames []string, notified map[uint]bool) {
+	if len(usernames) == 0 {
+		return
+	}
+	var users []models.User
+	if err := s.db.Select("id", "username").Where("username IN ?", usernames).Find(&users).Error; err != nil {
+		s.logger.Errorf("Failed to resolve comment mentions: %v", err)
+		return
+	}
+
+	actor := s.actorName(comment.UserID)
+	for _, user := range users {
+		if notified[user.ID] {
+			continue
+		}
+		notified[user.ID] = true
+		// 未发布的文章只有作者能看到，不通知其他人
+		if article.Status != ArticleStatusPublished && user.ID != article.CreatedBy {
+			continue
+		}
+		s.notify(CreateNotificationRequest{
+			UserID:  user.ID,
+			Type:    "comment_mention",
+			Title:   "有人在评论中提到了你",
+			Message: fmt.Sprintf("%s 在「%s」的评论中提到了你：%s", actor, article.Title, commentSnippet(comment.Content)),
+			Data: map[string]interface{}{
+				"article_id":    article.ID,
+				"article_title": article.Title,
+				"comment_id":    comment.ID,
+			},
+		})
+	}
+}
+
+func (s *CommentService) notify(req CreateNotificationRequest) {
+	if _, err := s.notificationService.CreateNotification(req); err != nil {
+		s.logger.Errorf("Failed to create %s notification: %v", req.Type, err)
+	}
+}
+
+func (s *CommentService) actorName(userID uint) string {
+	var user models.User
+	if err := s.db.Select("id", "username", "nickname").First(&user, userID).Error; err != nil {
+		return "有人"
+	}
+	if user.Nickname != "" {
+		return user.Nickname
+	}
+	return user.Username
+}
+
+// commentVisibility 评论对当前用户可见的条件：管理员可见全部，其他用户可见已通过的评论和自己待审核的评论
+func commentVisibility(viewer CommentViewer, prefix string) (string, []interface{}) {
+	if viewer.IsAdmin {
+		return "1 = 1", nil
+	}
+	return "(" + prefix + "status = ? OR (" + prefix + "status = ? AND " + prefix + "user_id = ?))",
+		[]interface{}{CommentStatusApproved, CommentStatusPending, viewer.UserID}
+}
+
+func commentVisibleTo(comment *models.ArticleComment, viewer CommentViewer) bool {
+	if comment.DeletedAt.Valid {
+		return false
+	}
+	if viewer.IsAdmin || comment.Status == CommentStatusApproved {
+		return true
+	}
+	return comment.Status == CommentStatusPending && comment.UserID == viewer.UserID
+}
+
+// buildCommentTree 按 ParentID 把回复挂到对应评论下，replies 需按时间正序
+func buildCommentTree(roots, replies []*models.ArticleComment) {
+	nodes := make(map[uint]*models.ArticleComment, len(roots)+len(replies))
+	for _, comment := range roots {
+		nodes[comment.ID] = comment
+	}
+	for _, comment := range replies {
+		nodes[comment.ID] = comment
+	}
+	for _, comment := range replies {
+		if comment.ParentID == nil {
+			continue
+		}
+		if parent, ok := nodes[*comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, comment)
+		}
+	}
+}
+
+// pruneComments 去掉当前用户不可见的评论；不可见但仍有可见回复的评论保留为不含内容的占位
+func pruneComments(comments []*models.ArticleComment, viewer CommentViewer) []*models.ArticleComment {
+	result := make([]*models.ArticleComment, 0, len(comments))
+	for _, comment := range comments {
+		comment.Replies = pruneComments(comment.Replies, viewer)
+		if commentVisibleTo(comment, viewer) {
+			result = append(result, comment)
+			continue
+		}
+		if len(comment.Replies) == 0 {
+			continue
+		}
+		comment.IsDeleted = true
+		comment.Content = ""
+		comment.UserID = 0
+		comment.User = nil
+		comment.ModerationReason = ""
+		result = append(result, comment)
+	}
+	return result
+}
+
+// commentMentions 返回 content 中提及、但 previous 中没有提及的用户名
+func commentMentions(content, previous string) []string {
+	existing := map[string]bool{}
+	for _, match := range commentMentionPattern.FindAllStringSubmatch(previous, -1) {
+		existing[strings.ToLower(match[1])] = true
+	}
+	var usernames []string
+	for _, match := range commentMentionPattern.FindAllStringSubmatch(content, -1) {
+		key := strings.ToLower(match[1])
+		if existing[key] {
+			continue
+		}
+		existing[key] = true
+		usernames = append(usernames, match[1])
+	}
+	return usernames
+}
+
+func commentSnippet(content string) string {
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+	if len(runes) <= commentSnippetLength {
+		return string(runes)
+	}
+	return string(runes[:commentSnippetLength]) + "…"
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var (
+	commentAlice = CommentViewer{UserID: 1} // 文章作者
+	commentBob   = CommentViewer{UserID: 2}
+	commentCarol = CommentViewer{UserID: 3}
+	commentAdmin = CommentViewer{UserID: 4, IsAdmin: true}
+)
+
+func newCommentTestService(t *testing.T) (*CommentService, *gorm.DB, *models.Article) {
+	db := openTestDB(t, &models.User{}, &models.Article{}, &models.ArticleComment{}, &models.Notification{})
+	for _, name := range []string{"alice", "bob", "carol", "admin"} {
+		require.NoError(t, db.Create(&models.User{Username: name, Email: name + "@example.com", Password: "x"}).Error)
+	}
+	article := &models.Article{Title: "hello", Status: ArticleStatusPublished, CreatedBy: commentAlice.UserID}
+	require.NoError(t, db.Create(article).Error)
+	return NewCommentService(db, logger.NewLogger(logger.DefaultLoggerConfig())), db, article
+}
+
+func TestCommentService_UnpublishedArticleHiddenFromOthers(t *testing.T) {
+	svc, db, _ := newCommentTestService(t)
+	draft := &models.Article{Title: "draft", Status: ArticleStatusDraft, CreatedBy: commentAlice.UserID}
+	require.NoError(t, db.Create(draft).Error)
+
+	comment, err := svc.CreateComment(draft.ID, commentAlice, CreateCommentRequest{Content: "note to self"})
+	require.NoError(t, err)
+
+	_, err = svc.GetComments(draft.ID, commentBob, CommentFilter{})
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+	_, err = svc.CreateComment(draft.ID, commentBob, CreateCommentRequest{Content: "hi", ParentID: &comment.ID})
+	assert.ErrorIs(t, err, ErrArticleNotFound)
+	assert.ErrorIs(t, svc.DeleteComment(draft.ID, comment.ID, commentBob), ErrArticleNotFound)
+
+	for _, viewer := range []CommentViewer{commentAlice, commentAdmin} {
+		list, err := svc.GetComments(draft.ID, viewer, CommentFilter{})
+		require.NoError(t, err)
+		assert.Len(t, list.Comments, 1)
+	}
+}
+
+func TestCommentService_EditWindow(t *testing.T) {
+	svc, db, article := newCommentTestService(t)
+	comment, err := svc.CreateComment(article.ID, commentBob, CreateCommentRequest{Content: "first"})
+	require.NoError(t, err)
+
+	_, err = svc.UpdateComment(article.ID, comment.ID, commentCarol, UpdateCommentRequest{Content: "hijack"})
+	assert.ErrorIs(t, err, ErrCommentForbidden)
+
+	updated, err := svc.UpdateComment(article.ID, comment.ID, commentBob, UpdateCommentRequest{Content: "second"})
+	require.NoError(t, err)
+	assert.Equal(t, "second", updated.Content)
+	assert.NotNil(t, updated.EditedAt)
+
+	// 超过编辑时限后不能再修改
+	require.NoError(t, db.Model(&models.ArticleComment{}).Where("id = ?", comment.ID).
+		UpdateColumn("created_at", time.Now().Add(-commentEditWindow-time.Minute)).Error)
+	_, err = svc.UpdateComment(article.ID, comment.ID, commentBob, UpdateCommentRequest{Content: "third"})
+	assert.ErrorIs(t, err, ErrCommentEditWindow)
+}
+
+func TestCommentService_RepliesFlattenPastMaxDepth(t *testing.T) {
+	svc, _, article := newCommentTestService(t)
+	root, err := svc.CreateComment(article.ID, commentBob, CreateCommentRequest{Content: "root"})
+	require.NoError(t, err)
+
+	parent := root
+	for depth := 1; depth <= maxCommentDepth; depth++ {
+		parent, err = svc.CreateComment(article.ID, commentCarol, CreateCommentRequest{Content: "reply", ParentID: &parent.ID})
+		require.NoError(t, err)
+		assert.Equal(t, depth, parent.Depth)
+		assert.Equal(t, root.ID, *parent.RootID)
+	}
+
+	// 回复最深一层的评论时挂到被回复评论的同级
+	deepest := parent
+	reply, err := svc.CreateComment(article.ID, commentBob, CreateCommentRequest{Content: "too deep", ParentID: &deepest.ID})
+	require.NoError(t, err)
+	assert.Equal(t, maxCommentDepth, reply.Depth)
+	assert.Equal(t, *deepest.ParentID, *reply.ParentID)
+	assert.Equal(t, root.ID, *reply.RootID)
+}
+
+func TestCommentService_DeleteKeepsReplies(t *testing.T) {
+	svc, _, article := newCommentTestService(t)
+	root, err := svc.CreateComment(article.ID, commentBob, CreateCommentRequest{Content: "root"})
+	require.NoError(t, err)
+	reply, err := svc.CreateComment(article.ID, commentCarol, CreateCommentRequest{Content: "reply", ParentID: &root.ID})
+	require.NoError(t, err)
+	lonely, err := svc.CreateComment(article.ID, commentBob, CreateCommentRequest{Content: "lonely"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.DeleteComment(article.ID, root.ID, commentCarol), ErrCommentForbidden)
+	require.NoError(t, svc.DeleteComment(article.ID, root.ID, commentBob))
+	require.NoError(t, svc.DeleteComment(article.ID, lonely.ID, commentAlice))
+
+	// 有回复的评论保留为不含内容的占位，没有回复的评论直接消失
+	list, err := svc.GetComments(article.ID, commentCarol, CommentFilter{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, list.Total)
+	require.Len(t, list.Comments, 1)
+	placeholder := list.Comments[0]
+	assert.Equal(t, root.ID, placeholder.ID)
+	assert.True(t, placeholder.IsDeleted)
+	assert.Empty(t, placeholder.Content)
+	assert.Zero(t, placeholder.UserID)
+	require.Len(t, placeholder.Replies, 1)
+	assert.Equal(t, reply.ID, placeholder.Replies[0].ID)
+	assert.Equal(t, "reply", placeholder.Replies[0].Content)
+}
+
+func TestCommentService_NotifiesAuthors(t *testing.T) {
+	svc, db, article := newCommentTestService(t)
+	notifications := func(userID uint) []string {
+		var types []string
+		require.NoError(t, db.Model(&models.Notification{}).Where("user_id = ?", userID).Order("id").Pluck("type", &types).Error)
+		return types
+	}
+
+	root, err := svc.CreateComment(article.ID, commentBob, CreateCommentRequest{Content: "nice post"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"article_comment"}, notifications(commentAlice.UserID))
+
+	// 每人最多收到一条通知，评论者本人不收到通知
+	_, err = svc.CreateComment(article.ID, commentCarol, CreateCommentRequest{
+		Content:  "@bob @alice @carol agreed",
+		ParentID: &root.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"comment_reply"}, notifications(commentBob.UserID))
+	assert.Equal(t, []string{"article_comment", "article_comment"}, notifications(commentAlice.UserID))
+	assert.Empty(t, notifications(commentCarol.UserID))
+
+	// 需要审核的评论在通过前不发送通知
+	_, err = svc.CreateComment(article.ID, commentCarol, CreateCommentRequest{Content: "see https://example.com", ParentID: &root.ID})
+	require.NoError(t, err)
+	assert.Len(t, notifications(commentBob.UserID), 1)
+}
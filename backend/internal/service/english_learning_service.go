@@ -156,7 +156,7 @@ func (s *EnglishLearningService) GetSongs(filter *SongFilter) (*PaginatedSongs,
 			"%"+filter.Search+"%", "%"+filter.Search+"%", "%"+filter.Search+"%")
 	}
 	if filter.Tags != "" {
-		query = query.Where("tags LIKE ?", "%\""+filter.Tags+"\"%")
+		query = query.Scopes(tagScope(SearchDocSong, filter.Tags))
 	}
 
 	// 获取总数
@@ -255,6 +255,17 @@ func (s *EnglishLearningService) CreateSong(song *models.Song) error {
 		if err := tx.Create(song).Error; err != nil {
 			return fmt.Errorf("failed to create song: %v", err)
 		}
+		if song.Tags != "" {
+			tags, err := setContentTags(tx, SearchDocSong, song.ID, parseTagInput(song.Tags))
+			if err != nil {
+				return err
+			}
+			song.Tags = tags
+			if err := tx.Model(song).UpdateColumn("tags", tags).Error; err != nil {
+				return fmt.Errorf("failed to save song tags: %v", err)
+			}
+		}
+		syncSearchIndex(tx, SearchDocSong, song.ID)
 
 		return nil
 	})
@@ -305,10 +316,20 @@ func (s *EnglishLearningService) UpdateSong(id uint, updates map[string]interfac
 			delete(updates, "category_name")
 		}
 
+		// 标签同时写入标签表
+		if rawTags, hasTags := updates["tags"]; hasTags {
+			tags, err := setContentTags(tx, SearchDocSong, id, parseTagInput(rawTags))
+			if err != nil {
+				return err
+			}
+			updates["tags"] = tags
+		}
+
 		// 更新歌曲
 		if err := tx.Model(&models.Song{}).Where("id = ?", id).Updates(updates).Error; err != nil {
 			return fmt.Errorf("failed to update song: %v", err)
 		}
+		syncSearchIndex(tx, SearchDocSong, id)
 
 		return nil
 	})
@@ -325,11 +346,15 @@ func (s *EnglishLearningService) DeleteSong(id uint) error {
 		if err := tx.Exec("DELETE FROM song_vocabularies WHERE song_id = ?", id).Error; err != nil {
 			return err
 		}
+		if err := deleteContentTags(tx, SearchDocSong, id); err != nil {
+			return err
+		}
 
 		// 删除歌曲
 		if err := tx.Delete(&models.Song{}, id).Error; err != nil {
 			return err
 		}
+		syncSearchIndex(tx, SearchDocSong, id)
 
 		return nil
 	})
@@ -1,7 +1,6 @@
 package service
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -46,7 +45,7 @@ func (s *EnglishVideoService) GetVideoSeries(req *api.VideoSeriesListRequest) (*
 
 	// 标签过滤
 	if req.Tag != "" {
-		query = query.Where("JSON_CONTAINS(tags, ?)", fmt.Sprintf(`"%s"`, req.Tag))
+		query = query.Scopes(tagScope(SearchDocVideoSeries, req.Tag))
 	}
 
 	// 获取总数
@@ -556,7 +555,7 @@ func (s *EnglishVideoService) SearchVideoSeries(req *api.SearchVideoSeriesReques
 		query = query.Where("age_range = ?", req.AgeRange)
 	}
 	if req.Tag != "" {
-		query = query.Where("JSON_CONTAINS(tags, ?)", fmt.Sprintf(`"%s"`, req.Tag))
+		query = query.Scopes(tagScope(SearchDocVideoSeries, req.Tag))
 	}
 
 	if err := query.Order("view_count DESC, created_at DESC").
@@ -619,8 +618,6 @@ func (s *EnglishVideoService) GetRecommendedSeries(userID uint, limit int) ([]mo
 
 // CreateVideoSeries 创建视频系列
 func (s *EnglishVideoService) CreateVideoSeries(req *api.CreateVideoSeriesRequest, userID uint) (*models.VideoSeries, error) {
-	tagsJSON, _ := json.Marshal(req.Tags)
-
 	series := models.VideoSeries{
 		Title:       req.Title,
 		TitleCN:     req.TitleCN,
@@ -628,15 +625,27 @@ func (s *EnglishVideoService) CreateVideoSeries(req *api.CreateVideoSeriesReques
 		CoverImage:  req.CoverImage,
 		Difficulty:  req.Difficulty,
 		AgeRange:    req.AgeRange,
-		Tags:        string(tagsJSON),
+		Tags:        "[]",
 		IsPublished: req.IsPublished,
 		Sort:        req.Sort,
 		CreatedBy:   userID,
 	}
 
-	if err := s.db.Create(&series).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&series).Error; err != nil {
+			return err
+		}
+		tags, err := setContentTags(tx, SearchDocVideoSeries, series.ID, req.Tags)
+		if err != nil {
+			return err
+		}
+		series.Tags = tags
+		return tx.Model(&series).UpdateColumn("tags", tags).Error
+	})
+	if err != nil {
 		return nil, err
 	}
+	syncSearchIndex(s.db, SearchDocVideoSeries, series.ID)
 
 	return &series, nil
 }
@@ -658,7 +667,6 @@ func (s *EnglishVideoService) UpdateVideoSeries(seriesID uint, req *api.UpdateVi
 		"cover_image":  req.CoverImage,
 		"difficulty":   req.Difficulty,
 		"age_range":    req.AgeRange,
-		"tags":         req.Tags,
 		"is_published": req.IsPublished,
 	}
 
@@ -666,7 +674,19 @@ func (s *EnglishVideoService) UpdateVideoSeries(seriesID uint, req *api.UpdateVi
 		updates["sort"] = req.Sort
 	}
 
-	return s.db.Model(&series).Updates(updates).Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tags, err := setContentTags(tx, SearchDocVideoSeries, seriesID, req.Tags)
+		if err != nil {
+			return err
+		}
+		updates["tags"] = tags
+		return tx.Model(&series).Updates(updates).Error
+	})
+	if err != nil {
+		return err
+	}
+	syncSearchIndex(s.db, SearchDocVideoSeries, seriesID)
+	return nil
 }
 
 // DeleteVideoSeries 删除视频系列
@@ -681,8 +701,18 @@ func (s *EnglishVideoService) DeleteVideoSeries(seriesID uint) error {
 	// 删除相关的点赞记录
 	s.db.Where("series_id = ?", seriesID).Delete(&models.VideoSeriesLike{})
 
-	// 删除系列
-	return s.db.Delete(&models.VideoSeries{}, seriesID).Error
+	// 删除系列及其标签关联
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteContentTags(tx, SearchDocVideoSeries, seriesID); err != nil {
+			return err
+		}
+		return tx.Delete(&models.VideoSeries{}, seriesID).Error
+	})
+	if err != nil {
+		return err
+	}
+	syncSearchIndex(s.db, SearchDocVideoSeries, seriesID)
+	return nil
 }
 
 // CreateEpisode 创建剧集
@@ -715,6 +745,7 @@ func (s *EnglishVideoService) CreateEpisode(seriesID uint, req *api.CreateEpisod
 	if err := s.db.Create(&episode).Error; err != nil {
 		return nil, err
 	}
+	syncSearchIndex(s.db, SearchDocVideoEpisode, episode.ID)
 
 	return &episode, nil
 }
@@ -746,7 +777,11 @@ func (s *EnglishVideoService) UpdateEpisode(episodeID uint, req *api.UpdateEpiso
 		updates["sort"] = req.Sort
 	}
 
-	return s.db.Model(&episode).Updates(updates).Error
+	if err := s.db.Model(&episode).Updates(updates).Error; err != nil {
+		return err
+	}
+	syncSearchIndex(s.db, SearchDocVideoEpisode, episodeID)
+	return nil
 }
 
 // DeleteEpisode 删除剧集
@@ -755,7 +790,11 @@ func (s *EnglishVideoService) DeleteEpisode(episodeID uint) error {
 	s.db.Where("episode_id = ?", episodeID).Delete(&models.VideoUserProgress{})
 
 	// 删除剧集
-	return s.db.Delete(&models.VideoEpisode{}, episodeID).Error
+	if err := s.db.Delete(&models.VideoEpisode{}, episodeID).Error; err != nil {
+		return err
+	}
+	syncSearchIndex(s.db, SearchDocVideoEpisode, episodeID)
+	return nil
 }
 
 // BatchImportEpisodes 批量导入剧集
@@ -794,6 +833,7 @@ func (s *EnglishVideoService) BatchImportEpisodes(seriesID uint, req *api.BatchI
 			errors = append(errors, fmt.Sprintf("Episode %d: %s", i+1, err.Error()))
 		} else {
 			successCount++
+			syncSearchIndex(s.db, SearchDocVideoEpisode, episode.ID)
 		}
 	}
 
@@ -825,6 +865,7 @@ func (s *EnglishVideoService) CreateUncategorizedEpisode(req *api.CreateEpisodeR
 	if err := s.db.Create(&episode).Error; err != nil {
 		return nil, err
 	}
+	syncSearchIndex(s.db, SearchDocVideoEpisode, episode.ID)
 
 	return &episode, nil
 }
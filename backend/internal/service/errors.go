@@ -6,6 +6,7 @@ import "errors"
 var (
 	ErrInvalidStatisticsType = errors.New("无效的统计类型")
 	ErrInvalidDaysParameter  = errors.New("无效的天数参数")
+	ErrInvalidWeeksParameter = errors.New("无效的周数参数")
 	ErrUserNotFound          = errors.New("用户不存在")
 	ErrInvalidCredentials    = errors.New("无效的凭据")
 	ErrEmailAlreadyExists    = errors.New("邮箱已存在")
@@ -28,4 +29,9 @@ var (
 	ErrInvalidTimeRange      = errors.New("无效的时间范围")
 	ErrWeakPassword          = errors.New("密码强度不够")
 	ErrInvalidUsername       = errors.New("无效的用户名")
+	ErrTodoBlocked           = errors.New("任务存在未完成的前置任务")
+	ErrDependencyCycle       = errors.New("任务依赖不能形成循环")
+	ErrNoRunningTimer        = errors.New("没有正在进行的计时")
+	ErrInvalidSearchType     = errors.New("无效的搜索类型")
+	ErrSearchUnavailable     = errors.New("全文检索不可用")
 )
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+
+	"gin-web-framework/pkg/imaging"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/storage"
+)
+
+const (
+	// imageKeyPrefix 图片在存储中的目录，对外地址为 /uploads/images/<文件名>
+	imageKeyPrefix = "images/"
+	imageURLPrefix = "/uploads/images/"
+	// imageHashLength 文件名中使用的内容哈希长度（十六进制字符数）
+	imageHashLength = 32
+)
+
+var (
+	ErrInvalidImage  = errors.New("文件不是有效的图片")
+	ErrImageTooLarge = errors.New("图片尺寸过大")
+)
+
+// imageVariantSpec 缩放版本的名称和最长边
+type imageVariantSpec struct {
+	name    string
+	maxSize int
+}
+
+var imageVariantSpecs = []imageVariantSpec{
+	{name: "thumbnail", maxSize: 320},
+	{name: "medium", maxSize: 1280},
+}
+
+// ImageVariant 图片的一个尺寸版本
+type ImageVariant struct {
+	URL       string `json:"url"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// UploadedImage 处理后的上传图片
+type UploadedImage struct {
+	URL         string                  `json:"url"`
+	Filename    string                  `json:"filename"`
+	Hash        string                  `json:"hash"`
+	ContentType string                  `json:"type"`
+	Width       int                     `json:"width"`
+	Height      int                     `json:"height"`
+	SizeBytes   int64                   `json:"size_bytes"`
+	Duplicate   bool                    `json:"duplicate"` // 相同内容的图片已上传过
+	Variants    map[string]ImageVariant `json:"variants"`
+}
+
+// ImageService 图片上传处理：校验、去除元数据、按内容哈希去重并生成缩略图
+type ImageService struct {
+	store  storage.Storage
+	logger logger.LoggerInterface
+}
+
+// NewImageService 创建图片服务
+func NewImageService(store storage.Storage, logger logger.LoggerInterface) *ImageService {
+	return &ImageService{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// SaveImage 解码校验上传的图片，去除 EXIF/GPS 等元数据后保存，并生成 thumbnail、medium 两个版本。
+// 文件名取去除元数据后内容的哈希，相同图片只保存一份；原图小于某个版本的尺寸时该版本直接使用原图
+func (s *ImageService) SaveImage(data []byte) (*UploadedImage, error) {
+	img, err := imaging.Decode(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return nil, fmt.Errorf("%w，最大 %d 像素", ErrImageTooLarge, imaging.MaxPixels)
+		}
+		return nil, ErrInvalidImage
+	}
+	cleaned, format, err := imaging.Clean(data, img)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	sum := sha256.Sum256(cleaned)
+	hash := hex.EncodeToString(sum[:])
+	base := hash[:imageHashLength]
+	filename := base + imaging.Extension(format)
+
+	duplicate, err := s.writeOnce(filename, imaging.ContentType(format), func() ([]byte, error) { return cleaned, nil })
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UploadedImage{
+		URL:         imageURLPrefix + filename,
+		Filename:    filename,
+		Hash:        hash,
+		ContentType: imaging.ContentType(format),
+		Width:       img.Width,
+		Height:      img.Height,
+		SizeBytes:   int64(len(cleaned)),
+		Duplicate:   duplicate,
+		Variants:    make(map[string]ImageVariant, len(imageVariantSpecs)),
+	}
+	for _, spec := range imageVariantSpecs {
+		variant, err := s.saveVariant(base, img.Image, spec, result)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants[spec.name] = variant
+	}
+	return result, nil
+}
+
+func (s *ImageService) saveVariant(base string, img image.Image, spec imageVariantSpec, original *UploadedImage) (ImageVariant, error) {
+	if original.Width <= spec.maxSize && original.Height <= spec.maxSize {
+		return ImageVariant{
+			URL:       original.URL,
+			Width:     original.Width,
+			Height:    original.Height,
+			SizeBytes: original.SizeBytes,
+		}, nil
+	}
+
+	resized := imaging.Fit(img, spec.maxSize, spec.maxSize)
+	format := imaging.VariantFormat(resized)
+	filename := base + "_" + spec.name + imaging.Extension(format)
+	var size int64
+	encode := func() ([]byte, error) {
+		data, err := imaging.Encode(resized, format)
+		size = int64(len(data))
+		return data, err
+	}
+	exists, err := s.writeOnce(filename, imaging.ContentType(format), encode)
+	if err != nil {
+		return ImageVariant{}, err
+	}
+	if exists {
+		if obj, err := s.store.Stat(context.Background(), imageKeyPrefix+filename); err == nil {
+			size = obj.Size
+		}
+	}
+	b := resized.Bounds()
+	return ImageVariant{
+		URL:       imageURLPrefix + filename,
+		Width:     b.Dx(),
+		Height:    b.Dy(),
+		SizeBytes: size,
+	}, nil
+}
+
+// OpenImage 读取已上传的图片，filename 为 /uploads/images/ 之后的文件名
+func (s *ImageService) OpenImage(filename string) (io.ReadCloser, *storage.Object, error) {
+	return s.store.Get(context.Background(), imageKeyPrefix+filename)
+}
+
+// writeOnce 对象不存在时写入，exists 表示已存在相同内容的对象（文件名即内容哈希）
+func (s *ImageService) writeOnce(filename, contentType string, content func() ([]byte, error)) (exists bool, err error) {
+	key := imageKeyPrefix + filename
+	ctx := context.Background()
+	if _, err := s.store.Stat(ctx, key); err == nil {
+		return true, nil
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return false, fmt.Errorf("failed to check %s: %v", key, err)
+	}
+
+	data, err := content()
+	if err != nil {
+		return false, fmt.Errorf("failed to encode %s: %v", filename, err)
+	}
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return false, err
+	}
+	return false, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"gin-web-framework/internal/api"
 	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/jwt"
 )
 
 // UserServiceInterface 用户服务接口
@@ -11,7 +12,10 @@ type UserServiceInterface interface {
 	// 用户认证
 	Register(req RegisterRequest) (*models.User, error)
 	Login(req LoginRequest) (*LoginResponse, error)
-	RefreshToken(token string) (*LoginResponse, error)
+	VerifyTwoFactorLogin(req TwoFactorLoginRequest) (*LoginResponse, error)
+	RefreshToken(req RefreshTokenRequest) (*LoginResponse, error)
+	Logout(claims *jwt.Claims) error
+	LogoutAll(userID uint) error
 	GetUserByID(id uint) (*models.User, error)
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByUsername(username string) (*models.User, error)
@@ -55,6 +59,29 @@ type TodoServiceInterface interface {
 
 	// 搜索
 	SearchTodos(userID uint, query string, filter TodoFilter) (*PaginatedTodos, error)
+
+	// 重复任务
+	GetRecurrence(id uint, userID uint) (*TodoRecurrenceDetail, error)
+	SetRecurrence(id uint, userID uint, req SetRecurrenceRequest) (*TodoRecurrenceDetail, error)
+	StopRecurrence(id uint, userID uint) error
+
+	// 子任务与依赖
+	CreateSubtask(parentID uint, userID uint, req CreateTodoRequest) (*models.Todo, error)
+	GetSubtasks(parentID uint, userID uint) (*TodoSubtasks, error)
+	GetDependencies(id uint, userID uint) (*TodoDependencies, error)
+	AddDependency(id uint, blockedByID uint, userID uint) error
+	RemoveDependency(id uint, blockedByID uint, userID uint) error
+
+	// 计时
+	StartTimer(id uint, userID uint, req StartTimerRequest) (*StartTimerResult, error)
+	StopTimer(userID uint) (*models.TodoTimeEntry, error)
+	GetRunningTimer(userID uint) (*models.TodoTimeEntry, error)
+	AddTimeEntry(id uint, userID uint, req ManualTimeEntryRequest) (*models.TodoTimeEntry, error)
+	GetTimeEntries(id uint, userID uint) (*TodoTimeEntries, error)
+	DeleteTimeEntry(id uint, entryID uint, userID uint) error
+
+	// 快速添加
+	QuickAdd(userID uint, req QuickAddRequest) (*QuickAddPreview, error)
 }
 
 // ArticleServiceInterface 文章服务接口
@@ -125,6 +152,7 @@ type StatisticsServiceInterface interface {
 	GetTodosByStatus(userID uint) (map[string]int64, error)
 	GetTodosByPriority(userID uint) (map[string]int64, error)
 	GetTodosByCategory(userID uint) (map[string]int64, error)
+	GetEstimateReport(userID uint, weeks int) (*EstimateReport, error)
 
 	// 文章统计
 	GetArticleStatistics(userID uint) (*ArticleStatistics, error)
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"gin-web-framework/pkg/logger"
+)
+
+// attemptStore 登录失败计数和锁定标记的存储。多实例部署时使用 Redis 共享状态，
+// 未配置 Redis 或 Redis 不可用时退回到进程内存
+type attemptStore interface {
+	// incr 计数加一并返回新值，计数从第一次增加起 window 后过期
+	incr(ctx context.Context, key string, window time.Duration) (int64, error)
+	// block 设置一个 d 后过期的标记，用于锁定和延迟
+	block(ctx context.Context, key string, d time.Duration) error
+	// blockedFor 返回标记的剩余时间，没有标记时返回 0
+	blockedFor(ctx context.Context, key string) (time.Duration, error)
+	reset(ctx context.Context, keys ...string) error
+}
+
+// cacheAttemptStore 基于 CacheService 的 Redis 存储
+type cacheAttemptStore struct {
+	cache *CacheService
+}
+
+func (s *cacheAttemptStore) incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return s.cache.IncrementWithExpiration(ctx, key, ceilSeconds(window))
+}
+
+func (s *cacheAttemptStore) block(ctx context.Context, key string, d time.Duration) error {
+	return s.cache.Set(ctx, key, 1, ceilSeconds(d))
+}
+
+func (s *cacheAttemptStore) blockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.cache.GetTTL(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	// 键不存在时 TTL 为负数
+	if ttl <= 0 {
+		return 0, nil
+	}
+	return time.Duration(ttl) * time.Second, nil
+}
+
+func (s *cacheAttemptStore) reset(ctx context.Context, keys ...string) error {
+	return s.cache.MDelete(ctx, keys)
+}
+
+// memoryAttemptStore 进程内存储，只在单实例部署时准确
+type memoryAttemptStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryAttempt
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryAttempt struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func newMemoryAttemptStore() *memoryAttemptStore {
+	return &memoryAttemptStore{
+		entries: make(map[string]*memoryAttempt),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryAttemptStore) incr(_ context.Context, key string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		entry = &memoryAttempt{expiresAt: now.Add(window)}
+		s.entries[key] = entry
+	}
+	entry.count++
+	return entry.count, nil
+}
+
+func (s *memoryAttemptStore) block(_ context.Context, key string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &memoryAttempt{count: 1, expiresAt: s.now().Add(d)}
+	return nil
+}
+
+func (s *memoryAttemptStore) blockedFor(_ context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return 0, nil
+	}
+	remaining := entry.expiresAt.Sub(s.now())
+	if remaining <= 0 {
+		delete(s.entries, key)
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+func (s *memoryAttemptStore) reset(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// sweep 每分钟最多一次清理过期的记录，避免大量不同 IP 的请求撑大内存，调用方需持有锁
+func (s *memoryAttemptStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// fallbackAttemptStore 优先使用 Redis，出错时记录日志并改用内存存储，
+// 保证 Redis 故障时登录保护仍然有效（各实例分别计数）
+type fallbackAttemptStore struct {
+	primary  attemptStore // 未配置 Redis 时为 nil
+	fallback attemptStore
+	logger   logger.LoggerInterface
+}
+
+func (s *fallbackAttemptStore) incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if s.primary != nil {
+		count, err := s.primary.incr(ctx, key, window)
+		if err == nil {
+			return count, nil
+		}
+		s.warn(err)
+	}
+	return s.fallback.incr(ctx, key, window)
+}
+
+func (s *fallbackAttemptStore) block(ctx context.Context, key string, d time.Duration) error {
+	if s.primary != nil {
+		err := s.primary.block(ctx, key, d)
+		if err == nil {
+			return nil
+		}
+		s.warn(err)
+	}
+	return s.fallback.block(ctx, key, d)
+}
+
+func (s *fallbackAttemptStore) blockedFor(ctx context.Context, key string) (time.Duration, error) {
+	if s.primary != nil {
+		remaining, err := s.primary.blockedFor(ctx, key)
+		if err == nil {
+			// Redis 恢复前写入内存的锁定仍然有效
+			if fallback, _ := s.fallback.blockedFor(ctx, key); fallback > remaining {
+				return fallback, nil
+			}
+			return remaining, nil
+		}
+		s.warn(err)
+	}
+	return s.fallback.blockedFor(ctx, key)
+}
+
+func (s *fallbackAttemptStore) reset(ctx context.Context, keys ...string) error {
+	if s.primary != nil {
+		if err := s.primary.reset(ctx, keys...); err != nil {
+			s.warn(err)
+		}
+	}
+	return s.fallback.reset(ctx, keys...)
+}
+
+func (s *fallbackAttemptStore) warn(err error) {
+	s.logger.WithFields(map[string]any{"error": err}).Warn("Login attempt store unavailable, falling back to memory")
+}
+
+// ceilSeconds 把时长向上取整为秒，Redis 过期时间以秒为单位
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	loginAttemptWindow  = time.Hour        // 失败次数的统计周期
+	loginFreeAttempts   = 2                // 连续失败这么多次以内不延迟
+	loginMaxDelay       = 30 * time.Second // 逐步延迟的上限
+	loginMaxLockout     = 24 * time.Hour   // 锁定时长翻倍的上限
+	loginLockoutHistory = 24 * time.Hour   // 这段时间内再次锁定时时长翻倍
+	loginGuardKeyPrefix = "login_guard:"
+)
+
+// ErrLoginLocked 登录失败次数过多，需要等待后重试
+var ErrLoginLocked = errors.New("too many failed login attempts, please try again later")
+
+// LoginLockedError 登录被暂时拒绝，RetryAfter 后可以重试
+type LoginLockedError struct {
+	RetryAfter time.Duration
+	Locked     bool // true 为达到次数上限后的锁定，false 为连续失败后的逐步延迟
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%s (retry after %ds)", ErrLoginLocked.Error(), e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds 向上取整的等待秒数，用于 Retry-After 响应头
+func (e *LoginLockedError) RetryAfterSeconds() int {
+	return ceilSeconds(e.RetryAfter)
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// LoginGuardOptions 登录保护配置
+type LoginGuardOptions struct {
+	MaxAttempts   int           // 同一账号锁定前允许的连续失败次数
+	IPMaxAttempts int           // 同一 IP 锁定前允许的失败次数，不区分账号
+	Lockout       time.Duration // 首次锁定的时长
+}
+
+// LoginGuard 登录防暴力破解。按账号和按 IP 分别统计失败次数：
+// 账号连续失败几次后每次失败都要等待逐步变长的时间，达到上限后锁定一段时间，短期内再次锁定时时长翻倍；
+// 同一 IP 的失败次数达到上限后该 IP 被锁定，防止用同一密码尝试大量账号。
+// 账号按用户名计数，不存在的用户名同样会被锁定，避免通过响应区分用户名是否存在
+type LoginGuard struct {
+	db                  *gorm.DB
+	store               attemptStore
+	notificationService *NotificationService
+	options             LoginGuardOptions
+	logger              logger.LoggerInterface
+}
+
+// NewLoginGuard 创建登录保护，cache 未配置 Redis 时只使用进程内存计数
+func NewLoginGuard(db *gorm.DB, cache *CacheService, options LoginGuardOptions, logger logger.LoggerInterface) *LoginGuard {
+	store := &fallbackAttemptStore{fallback: newMemoryAttemptStore(), logger: logger}
+	if cache != nil && cache.Available() {
+		store.primary = &cacheAttemptStore{cache: cache}
+	}
+	return &LoginGuard{
+		db:                  db,
+		store:               store,
+		notificationService: NewNotificationService(db, logger),
+		options:             options,
+		logger:              logger,
+	}
+}
+
+// Check 校验密码前调用，账号或 IP 被锁定、或仍在延迟期内时返回 *LoginLockedError
+func (g *LoginGuard) Check(username string, client ClientInfo) error {
+	ctx := context.Background()
+	account := normalizeLoginName(username)
+
+	type blockCheck struct {
+		key    string
+		locked bool
+	}
+	checks := []blockCheck{
+		{key: g.key("lock", "account", account), locked: true},
+		{key: g.key("delay", "account", account)},
+	}
+	if client.IP != "" {
+		checks = append(checks, blockCheck{key: g.key("lock", "ip", client.IP), locked: true})
+	}
+	for _, check := range checks {
+		remaining, err := g.store.blockedFor(ctx, check.key)
+		if err != nil {
+			return err
+		}
+		if remaining > 0 {
+			return &LoginLockedError{RetryAfter: remaining, Locked: check.locked}
+		}
+	}
+	return nil
+}
+
+// RecordFailure 记录一次密码错误。user 为空表示用户名不存在。
+// 这次失败导致锁定时返回 *LoginLockedError，否则返回 nil
+func (g *LoginGuard) RecordFailure(user *models.User, username string, client ClientInfo) error {
+	ctx := context.Background()
+	account := normalizeLoginName(username)
+
+	var locked *LoginLockedError
+	if client.IP != "" {
+		count, err := g.store.incr(ctx, g.key("fail", "ip", client.IP), loginAttemptWindow)
+		if err != nil {
+			return err
+		}
+		if count >= int64(g.options.IPMaxAttempts) {
+			if err := g.lockIP(ctx, client, count); err != nil {
+				return err
+			}
+			locked = &LoginLockedError{RetryAfter: g.options.Lockout, Locked: true}
+		}
+	}
+
+	count, err := g.store.incr(ctx, g.key("fail", "account", account), loginAttemptWindow)
+	if err != nil {
+		return err
+	}
+	switch {
+	case count >= int64(g.options.MaxAttempts):
+		duration, err := g.lockAccount(ctx, user, account, client, count)
+		if err != nil {
+			return err
+		}
+		if locked == nil || duration > locked.RetryAfter {
+			locked = &LoginLockedError{RetryAfter: duration, Locked: true}
+		}
+	case count > loginFreeAttempts:
+		delay := loginMaxDelay
+		if shift := count - loginFreeAttempts - 1; shift < 5 {
+			delay = min(time.Second<<shift, loginMaxDelay)
+		}
+		if err := g.store.block(ctx, g.key("delay", "account", account), delay); err != nil {
+			return err
+		}
+	}
+
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+// RecordSuccess 登录成功后清除账号的失败计数。IP 的失败计数不清除，
+// 否则攻击者可以穿插登录自己的账号来绕过 IP 限制
+func (g *LoginGuard) RecordSuccess(username string) {
+	account := normalizeLoginName(username)
+	if err := g.store.reset(context.Background(),
+		g.key("fail", "account", account), g.key("delay", "account", account)); err != nil {
+		g.logger.WithFields(map[string]any{"username": username, "error": err}).Warn("Failed to reset login attempts")
+	}
+}
+
+// Unlock 管理员解除账号锁定，同时清除失败计数和锁定历史
+func (g *LoginGuard) Unlock(adminID, userID uint, client ClientInfo) error {
+	var admin, user models.User
+	if err := g.db.First(&admin, adminID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+	if err := g.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+
+	account := normalizeLoginName(user.Username)
+	if err := g.store.reset(context.Background(),
+		g.key("fail", "account", account), g.key("delay", "account", account),
+		g.key("lock", "account", account), g.key("lockouts", "account", account)); err != nil {
+		return err
+	}
+
+	recordAuditEvent(g.db, g.logger, AuditEvent{
+		UserID:   admin.ID,
+		Username: admin.Username,
+		Action:   "解除账号锁定",
+		Client:   client,
+		Details: map[string]interface{}{
+			"target_user_id":  user.ID,
+			"target_username": user.Username,
+		},
+	})
+	g.logger.WithFields(map[string]any{"admin_id": adminID, "user_id": userID}).Info("Account unlocked by admin")
+	return nil
+}
+
+// lockAccount 锁定账号，短期内多次锁定时时长翻倍，返回锁定时长
+func (g *LoginGuard) lockAccount(ctx context.Context, user *models.User, account string, client ClientInfo, failures int64) (time.Duration, error) {
+	lockouts, err := g.store.incr(ctx, g.key("lockouts", "account", account), loginLockoutHistory)
+	if err != nil {
+		return 0, err
+	}
+	duration := loginMaxLockout
+	if shift := lockouts - 1; shift < 16 {
+		duration = min(g.options.Lockout<<shift, loginMaxLockout)
+	}
+	if err := g.store.block(ctx, g.key("lock", "account", account), duration); err != nil {
+		return 0, err
+	}
+	if err := g.store.reset(ctx, g.key("fail", "account", account), g.key("delay", "account", account)); err != nil {
+		return 0, err
+	}
+
+	g.logger.WithFields(map[string]any{"username": account, "ip": client.IP, "lockouts": lockouts, "duration": duration.String()}).
+		Warn("Account locked after too many failed login attempts")
+	if user == nil {
+		return duration, nil
+	}
+
+	until := time.Now().Add(duration)
+	recordAuditEvent(g.db, g.logger, AuditEvent{
+		UserID:   user.ID,
+		Username: user.Username,
+		Action:   "账号锁定",
+		Client:   client,
+		Details: map[string]interface{}{
+			"failed_attempts": failures,
+			"locked_until":    until.Format(time.RFC3339),
+		},
+		StatusCode: http.StatusTooManyRequests,
+	})
+	g.notifyLocked(user, client, until)
+	return duration, nil
+}
+
+// lockIP 锁定 IP，该 IP 在锁定期内不能登录任何账号
+func (g *LoginGuard) lockIP(ctx context.Context, client ClientInfo, failures int64) error {
+	if err := g.store.block(ctx, g.key("lock", "ip", client.IP), g.options.Lockout); err != nil {
+		return err
+	}
+	if err := g.store.reset(ctx, g.key("fail", "ip", client.IP)); err != nil {
+		return err
+	}
+
+	g.logger.WithFields(map[string]any{"ip": client.IP, "failures": failures}).Warn("IP locked after too many failed login attempts")
+	recordAuditEvent(g.db, g.logger, AuditEvent{
+		Action: "IP锁定",
+		Client: client,
+		Details: map[string]interface{}{
+			"failed_attempts": failures,
+			"locked_until":    time.Now().Add(g.options.Lockout).Format(time.RFC3339),
+		},
+		StatusCode: http.StatusTooManyRequests,
+	})
+	return nil
+}
+
+func (g *LoginGuard) notifyLocked(user *models.User, client ClientInfo, until time.Time) {
+	_, err := g.notificationService.CreateNotification(CreateNotificationRequest{
+		UserID: user.ID,
+		Type:   "account_locked",
+		Title:  "账号已被临时锁定",
+		Message: fmt.Sprintf("由于多次输入错误的密码，你的账号已被锁定至 %s（最近一次尝试来自 %s）。如果不是你本人操作，请在解锁后尽快修改密码。",
+			until.Format("2006-01-02 15:04"), sessionLocation(client.IP)),
+		Data: map[string]interface{}{
+			"ip_address":   client.IP,
+			"locked_until": until,
+		},
+	})
+	if err != nil {
+		g.logger.Errorf("Failed to create account_locked notification: %v", err)
+	}
+}
+
+// key 生成存储键，例如 login_guard:lock:account:alice
+func (g *LoginGuard) key(kind, scope, subject string) string {
+	return loginGuardKeyPrefix + kind + ":" + scope + ":" + subject
+}
+
+// normalizeLoginName 同一用户名的大小写变体按同一账号计数
+func normalizeLoginName(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gin-web-framework/internal/model"
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/auth"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryAttemptStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemoryAttemptStore()
+	store.now = func() time.Time { return now }
+
+	count, _ := store.incr(ctx, "fail", time.Minute)
+	assert.EqualValues(t, 1, count)
+	count, _ = store.incr(ctx, "fail", time.Minute)
+	assert.EqualValues(t, 2, count)
+
+	// 计数从第一次失败起过期，之后重新计数
+	now = now.Add(time.Minute)
+	count, _ = store.incr(ctx, "fail", time.Minute)
+	assert.EqualValues(t, 1, count)
+
+	require.NoError(t, store.block(ctx, "lock", 10*time.Second))
+	remaining, _ := store.blockedFor(ctx, "lock")
+	assert.Equal(t, 10*time.Second, remaining)
+	now = now.Add(10 * time.Second)
+	remaining, _ = store.blockedFor(ctx, "lock")
+	assert.Zero(t, remaining)
+
+	require.NoError(t, store.reset(ctx, "fail"))
+	count, _ = store.incr(ctx, "fail", time.Minute)
+	assert.EqualValues(t, 1, count)
+}
+
+// failingAttemptStore 模拟 Redis 不可用
+type failingAttemptStore struct{}
+
+var errStoreDown = errors.New("store down")
+
+func (failingAttemptStore) incr(context.Context, string, time.Duration) (int64, error) {
+	return 0, errStoreDown
+}
+func (failingAttemptStore) block(context.Context, string, time.Duration) error { return errStoreDown }
+func (failingAttemptStore) blockedFor(context.Context, string) (time.Duration, error) {
+	return 0, errStoreDown
+}
+func (failingAttemptStore) reset(context.Context, ...string) error { return errStoreDown }
+
+func TestLoginGuard_DelaysThenLocksAccount(t *testing.T) {
+	store := &fallbackAttemptStore{
+		primary:  failingAttemptStore{},
+		fallback: newMemoryAttemptStore(),
+		logger:   logger.NewLogger(logger.DefaultLoggerConfig()),
+	}
+	guard := &LoginGuard{
+		store:   store,
+		options: LoginGuardOptions{MaxAttempts: 5, IPMaxAttempts: 20, Lockout: 15 * time.Minute},
+		logger:  store.logger,
+	}
+
+	// 前两次失败不延迟
+	for i := 0; i < loginFreeAttempts; i++ {
+		require.NoError(t, guard.RecordFailure(nil, "Alice", ClientInfo{}))
+		require.NoError(t, guard.Check("alice", ClientInfo{}))
+	}
+
+	// 之后每次失败的延迟翻倍
+	for i, want := range []time.Duration{time.Second, 2 * time.Second} {
+		require.NoError(t, guard.RecordFailure(nil, "alice", ClientInfo{}), "attempt %d", i)
+		var locked *LoginLockedError
+		require.ErrorAs(t, guard.Check(" ALICE ", ClientInfo{}), &locked)
+		assert.False(t, locked.Locked)
+		assert.InDelta(t, want.Seconds(), locked.RetryAfter.Seconds(), 0.1)
+	}
+
+	// 达到次数上限后锁定
+	var locked *LoginLockedError
+	require.ErrorAs(t, guard.RecordFailure(nil, "alice", ClientInfo{}), &locked)
+	assert.True(t, locked.Locked)
+	assert.Equal(t, 15*time.Minute, locked.RetryAfter)
+	err := guard.Check("alice", ClientInfo{})
+	require.ErrorIs(t, err, ErrLoginLocked)
+	require.ErrorAs(t, err, &locked)
+	assert.True(t, locked.Locked)
+	assert.Equal(t, 900, locked.RetryAfterSeconds())
+
+	// 其他账号不受影响
+	assert.NoError(t, guard.Check("bob", ClientInfo{}))
+}
+
+func TestUserService_TwoFactorFailuresCountTowardsLockout(t *testing.T) {
+	db := openTestDB(t, &models.User{}, &models.UserTwoFactor{}, &models.RecoveryCode{}, &models.LoginChallenge{},
+		&models.Notification{}, &model.AuditLog{})
+	password, err := auth.HashPassword("secret1")
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.User{Username: "alice", Email: "alice@example.com", Password: password}).Error)
+
+	log := logger.NewLogger(logger.DefaultLoggerConfig())
+	twoFactor := NewTwoFactorService(db, "Todo", log)
+	setup, err := twoFactor.Setup(1)
+	require.NoError(t, err)
+	code, err := auth.TOTPCode(setup.Secret, auth.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	_, err = twoFactor.Enable(1, TwoFactorCodeRequest{Code: code})
+	require.NoError(t, err)
+	// 每次验证失败都会逐个比对恢复码的 bcrypt 哈希，删除后测试更快
+	require.NoError(t, db.Where("user_id = ?", 1).Delete(&models.RecoveryCode{}).Error)
+
+	guard := NewLoginGuard(db, nil, LoginGuardOptions{MaxAttempts: 4, IPMaxAttempts: 20, Lockout: time.Minute}, log)
+	users := NewUserService(db, nil, nil, twoFactor, nil, guard, log)
+	client := ClientInfo{IP: "192.0.2.1"}
+
+	_, err = users.Login(LoginRequest{Username: "alice", Password: "wrong", Client: client})
+	require.Error(t, err)
+
+	// 密码正确但尚未通过两步验证，不清除失败计数
+	first, err := users.Login(LoginRequest{Username: "alice", Password: "secret1", Client: client})
+	require.NoError(t, err)
+	require.True(t, first.TwoFactorRequired)
+	second, err := users.Login(LoginRequest{Username: "alice", Password: "secret1", Client: client})
+	require.NoError(t, err)
+
+	// 验证码错误同样计入失败次数：第 2、3 次失败，之后进入延迟
+	_, err = users.VerifyTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: first.ChallengeToken, Code: "000000", Client: client})
+	require.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	_, err = users.VerifyTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: first.ChallengeToken, Code: "000000", Client: client})
+	require.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	var locked *LoginLockedError
+	_, err = users.VerifyTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: second.ChallengeToken, Code: "000000", Client: client})
+	require.ErrorAs(t, err, &locked)
+	assert.False(t, locked.Locked)
+
+	// 第 4 次失败后锁定，其它挑战令牌也不能继续尝试
+	require.NoError(t, guard.store.reset(context.Background(), guard.key("delay", "account", "alice")))
+	_, err = users.VerifyTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: second.ChallengeToken, Code: "000000", Client: client})
+	require.ErrorAs(t, err, &locked)
+	assert.True(t, locked.Locked)
+	_, err = users.VerifyTwoFactorLogin(TwoFactorLoginRequest{ChallengeToken: second.ChallengeToken, Code: code, Client: client})
+	require.ErrorIs(t, err, ErrLoginLocked)
+	_, err = users.Login(LoginRequest{Username: "alice", Password: "secret1", Client: client})
+	require.ErrorIs(t, err, ErrLoginLocked)
+}
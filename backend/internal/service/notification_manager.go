@@ -36,17 +36,19 @@ func (nm *NotificationManager) CheckTaskDueNotifications() error {
 	}
 
 	for _, task := range dueSoonTasks {
+		userID := responsibleUserID(&task)
+
 		// 检查是否已经发送过通知
 		var existingNotification models.Notification
 		if err := nm.db.Where("user_id = ? AND type = ? AND data LIKE ?",
-			task.CreatedBy, "due_soon", "%"+task.Title+"%").First(&existingNotification).Error; err == nil {
+			userID, "due_soon", "%"+task.Title+"%").First(&existingNotification).Error; err == nil {
 			// 已经发送过通知，跳过
 			continue
 		}
 
 		// 创建即将到期通知
 		req := &CreateNotificationRequest{
-			UserID: task.CreatedBy,
+			UserID: userID,
 			Type:   "due_soon",
 			Title:  "任务即将到期提醒",
 			Message: fmt.Sprintf("任务「%s」将在 %s 到期，请及时处理",
@@ -71,17 +73,19 @@ func (nm *NotificationManager) CheckTaskDueNotifications() error {
 	}
 
 	for _, task := range overdueTasks {
+		userID := responsibleUserID(&task)
+
 		// 检查是否已经发送过逾期通知
 		var existingNotification models.Notification
 		if err := nm.db.Where("user_id = ? AND type = ? AND data LIKE ?",
-			task.CreatedBy, "overdue", "%"+task.Title+"%").First(&existingNotification).Error; err == nil {
+			userID, "overdue", "%"+task.Title+"%").First(&existingNotification).Error; err == nil {
 			// 已经发送过通知，跳过
 			continue
 		}
 
 		// 创建逾期通知
 		req := &CreateNotificationRequest{
-			UserID: task.CreatedBy,
+			UserID: userID,
 			Type:   "overdue",
 			Title:  "任务已逾期",
 			Message: fmt.Sprintf("任务「%s」已逾期 %d 天，请尽快完成",
@@ -103,16 +107,59 @@ func (nm *NotificationManager) CheckTaskDueNotifications() error {
 }
 
 // CreateTaskCompletedNotification 创建任务完成通知
+// 通知创建者、负责人以及共享清单中有编辑权限的成员
 func (nm *NotificationManager) CreateTaskCompletedNotification(task *models.Todo) error {
+	recipients, err := nm.taskRecipients(task)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range recipients {
+		req := &CreateNotificationRequest{
+			UserID:  userID,
+			Type:    "completed",
+			Title:   "任务已完成",
+			Message: fmt.Sprintf("恭喜！任务「%s」已完成", task.Title),
+			Data: map[string]interface{}{
+				"task_id":      task.ID,
+				"task_title":   task.Title,
+				"completed_at": task.CompletedAt.Format(time.RFC3339),
+			},
+		}
+
+		if _, err := nm.notificationService.CreateNotification(*req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateTaskAssignedNotification 创建任务指派通知
+func (nm *NotificationManager) CreateTaskAssignedNotification(task *models.Todo, assignerID uint) error {
+	if task.AssigneeID == nil {
+		return nil
+	}
+
+	var assigner models.User
+	if err := nm.db.Select("id", "username", "nickname").First(&assigner, assignerID).Error; err != nil {
+		return fmt.Errorf("failed to get assigner: %v", err)
+	}
+	name := assigner.Nickname
+	if name == "" {
+		name = assigner.Username
+	}
+
 	req := &CreateNotificationRequest{
-		UserID:  task.CreatedBy,
-		Type:    "completed",
-		Title:   "任务已完成",
-		Message: fmt.Sprintf("恭喜！任务「%s」已完成", task.Title),
+		UserID:  *task.AssigneeID,
+		Type:    "task_assigned",
+		Title:   "新的任务指派",
+		Message: fmt.Sprintf("%s 将任务「%s」指派给了你", name, task.Title),
 		Data: map[string]interface{}{
-			"task_id":      task.ID,
-			"task_title":   task.Title,
-			"completed_at": task.CompletedAt.Format(time.RFC3339),
+			"task_id":     task.ID,
+			"task_title":  task.Title,
+			"list_id":     task.ListID,
+			"assigner_id": assignerID,
 		},
 	}
 
@@ -120,6 +167,44 @@ func (nm *NotificationManager) CreateTaskCompletedNotification(task *models.Todo
 	return err
 }
 
+// taskRecipients 任务相关的通知对象（去重）
+func (nm *NotificationManager) taskRecipients(task *models.Todo) ([]uint, error) {
+	seen := map[uint]bool{}
+	var recipients []uint
+	add := func(userID uint) {
+		if userID != 0 && !seen[userID] {
+			seen[userID] = true
+			recipients = append(recipients, userID)
+		}
+	}
+
+	add(task.CreatedBy)
+	if task.AssigneeID != nil {
+		add(*task.AssigneeID)
+	}
+	if task.ListID != nil {
+		var memberIDs []uint
+		if err := nm.db.Model(&models.TodoListMember{}).
+			Where("list_id = ? AND role IN ?", *task.ListID, []string{models.TodoListRoleOwner, models.TodoListRoleEditor}).
+			Pluck("user_id", &memberIDs).Error; err != nil {
+			return nil, fmt.Errorf("failed to get list members: %v", err)
+		}
+		for _, userID := range memberIDs {
+			add(userID)
+		}
+	}
+
+	return recipients, nil
+}
+
+// responsibleUserID 任务的负责人，未指派时为创建者
+func responsibleUserID(task *models.Todo) uint {
+	if task.AssigneeID != nil {
+		return *task.AssigneeID
+	}
+	return task.CreatedBy
+}
+
 // CreateArticlePublishedNotification 创建文章发布通知
 func (nm *NotificationManager) CreateArticlePublishedNotification(article *models.Article) error {
 	req := &CreateNotificationRequest{
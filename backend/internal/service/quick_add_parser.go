@@ -0,0 +1,538 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QuickAddParseResult 快速添加文本的解析结果
+type QuickAddParseResult struct {
+	Title          string     `json:"title"`
+	DueDate        *time.Time `json:"due_date"`
+	HasTime        bool       `json:"has_time"`       // 是否解析到具体时间，否则截止时间为当天结束
+	PriorityLevel  int        `json:"priority_level"` // 0 表示未指定
+	Tags           []string   `json:"tags"`
+	EstimatedHours float64    `json:"estimated_hours"`
+}
+
+// quickClock 时分
+type quickClock struct {
+	hour, minute int
+}
+
+// quickDateRule 日期规则：返回日期（当天零点）、该日期隐含的默认时间；exact 为 true 时返回值即为精确时间
+type quickDateRule struct {
+	pattern *regexp.Regexp
+	resolve func(match []string, now, today time.Time) (date time.Time, clock *quickClock, exact bool, ok bool)
+}
+
+// quickTimeRule 时间规则
+type quickTimeRule struct {
+	pattern *regexp.Regexp
+	resolve func(match []string) (quickClock, bool)
+}
+
+var (
+	quickEstimatePattern = regexp.MustCompile(`(?i)[~～](\d+(?:\.\d+)?)\s*(hours|hour|hrs|hr|h|小时|minutes|minute|mins|min|m|分钟|days|day|d|天)?(?:(\d+)\s*(minutes|minute|mins|min|m|分钟))?`)
+	quickPriorityPattern = regexp.MustCompile(`[!！]([\p{L}\p{N}]+)`)
+	quickTagPattern      = regexp.MustCompile(`(?:^|\s)[#＃]([^\s#＃!！~～]+)`)
+	quickSpacePattern    = regexp.MustCompile(`\s+`)
+	quickDanglingPattern = regexp.MustCompile(`(?i)\s+(on|at|by|due|before|in)$`)
+)
+
+// quickPriorityLevels 优先级关键字到 TodoPriority.Level 的映射
+var quickPriorityLevels = map[string]int{
+	"low": 1, "低": 1,
+	"medium": 2, "med": 2, "normal": 2, "中": 2,
+	"high": 3, "高": 3,
+	"urgent": 4, "紧急": 4,
+	"immediate": 5, "asap": 5, "critical": 5, "立即": 5,
+	"1": 1, "2": 2, "3": 3, "4": 4, "5": 5,
+}
+
+var quickWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+	"一": time.Monday, "二": time.Tuesday, "三": time.Wednesday, "四": time.Thursday,
+	"五": time.Friday, "六": time.Saturday, "日": time.Sunday, "天": time.Sunday,
+}
+
+var quickMonths = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// 日期规则按顺序匹配，只使用第一个命中的规则
+var quickDateRules = []quickDateRule{
+	{
+		// in 2 hours / 30 minutes later
+		pattern: regexp.MustCompile(`(?i)\bin\s+(\d+)\s*(hours|hour|hrs|hr|minutes|minute|mins|min)\b`),
+		resolve: func(m []string, now, _ time.Time) (time.Time, *quickClock, bool, bool) {
+			n, _ := strconv.Atoi(m[1])
+			unit := time.Hour
+			if strings.HasPrefix(strings.ToLower(m[2]), "m") {
+				unit = time.Minute
+			}
+			return now.Add(time.Duration(n) * unit), nil, true, true
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(\d+)\s*(小时|分钟)后`),
+		resolve: func(m []string, now, _ time.Time) (time.Time, *quickClock, bool, bool) {
+			n, _ := strconv.Atoi(m[1])
+			unit := time.Hour
+			if m[2] == "分钟" {
+				unit = time.Minute
+			}
+			return now.Add(time.Duration(n) * unit), nil, true, true
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)\b(?:(?:on|by|due)\s+)?(day after tomorrow|today|tonight|tomorrow|tmr)\b`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			switch strings.ToLower(m[1]) {
+			case "today":
+				return today, nil, false, true
+			case "tonight":
+				return today, &quickClock{hour: 20}, false, true
+			case "day after tomorrow":
+				return today.AddDate(0, 0, 2), nil, false, true
+			default:
+				return today.AddDate(0, 0, 1), nil, false, true
+			}
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`大后天|后天|明天|明早|明晚|今天|今晚`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			switch m[0] {
+			case "今天":
+				return today, nil, false, true
+			case "今晚":
+				return today, &quickClock{hour: 20}, false, true
+			case "明天":
+				return today.AddDate(0, 0, 1), nil, false, true
+			case "明早":
+				return today.AddDate(0, 0, 1), &quickClock{hour: 9}, false, true
+			case "明晚":
+				return today.AddDate(0, 0, 1), &quickClock{hour: 20}, false, true
+			case "后天":
+				return today.AddDate(0, 0, 2), nil, false, true
+			default:
+				return today.AddDate(0, 0, 3), nil, false, true
+			}
+		},
+	},
+	{
+		// in 3 days / in 2 weeks / in 1 month
+		pattern: regexp.MustCompile(`(?i)\bin\s+(\d+)\s*(days|day|weeks|week|months|month)\b`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			n, _ := strconv.Atoi(m[1])
+			switch unit := strings.ToLower(m[2]); {
+			case strings.HasPrefix(unit, "day"):
+				return today.AddDate(0, 0, n), nil, false, true
+			case strings.HasPrefix(unit, "week"):
+				return today.AddDate(0, 0, 7*n), nil, false, true
+			default:
+				return today.AddDate(0, n, 0), nil, false, true
+			}
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(\d+)\s*(天|周|个月)后`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			n, _ := strconv.Atoi(m[1])
+			switch m[2] {
+			case "天":
+				return today.AddDate(0, 0, n), nil, false, true
+			case "周":
+				return today.AddDate(0, 0, 7*n), nil, false, true
+			default:
+				return today.AddDate(0, n, 0), nil, false, true
+			}
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)\b(?:(?:on|by|due)\s+)?(next|this)?\s*(monday|mon|tuesday|tues|tue|wednesday|wed|thursday|thurs|thur|thu|friday|fri|saturday|sat|sunday|sun)\b`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			weekday := quickWeekdays[strings.ToLower(m[2])]
+			return resolveWeekday(today, weekday, strings.ToLower(m[1])), nil, false, true
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(下下|下|这|本)?(?:周|星期|礼拜)([一二三四五六日天])`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			weekday := quickWeekdays[m[2]]
+			switch m[1] {
+			case "下下":
+				return resolveWeekday(today, weekday, "next").AddDate(0, 0, 7), nil, false, true
+			case "下":
+				return resolveWeekday(today, weekday, "next"), nil, false, true
+			case "这", "本":
+				return resolveWeekday(today, weekday, "this"), nil, false, true
+			default:
+				return resolveWeekday(today, weekday, ""), nil, false, true
+			}
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)\bnext\s+(week|month)\b|下周|下个月`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			if strings.EqualFold(m[1], "month") || m[0] == "下个月" {
+				return time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, today.Location()), nil, false, true
+			}
+			return resolveWeekday(today, time.Monday, "next"), nil, false, true
+		},
+	},
+	{
+		// 2024-05-01
+		pattern: regexp.MustCompile(`\b(\d{4})-(\d{1,2})-(\d{1,2})\b`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			year, _ := strconv.Atoi(m[1])
+			month, _ := strconv.Atoi(m[2])
+			day, _ := strconv.Atoi(m[3])
+			return quickDate(year, month, day, today.Location())
+		},
+	},
+	{
+		// 5月1日 / 5月1号
+		pattern: regexp.MustCompile(`(\d{1,2})月(\d{1,2})[日号]`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			month, _ := strconv.Atoi(m[1])
+			day, _ := strconv.Atoi(m[2])
+			return upcomingMonthDay(today, month, day)
+		},
+	},
+	{
+		// may 5 / May 5th
+		pattern: regexp.MustCompile(`(?i)\b(?:(?:on|by|due)\s+)?(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			day, _ := strconv.Atoi(m[2])
+			return upcomingMonthDay(today, int(quickMonths[strings.ToLower(m[1])]), day)
+		},
+	},
+	{
+		// 5/1（月/日）
+		pattern: regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})\b`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			month, _ := strconv.Atoi(m[1])
+			day, _ := strconv.Atoi(m[2])
+			return upcomingMonthDay(today, month, day)
+		},
+	},
+	{
+		// 15号 / 15日：本月，已过则为下月
+		pattern: regexp.MustCompile(`(\d{1,2})[日号]`),
+		resolve: func(m []string, _, today time.Time) (time.Time, *quickClock, bool, bool) {
+			day, _ := strconv.Atoi(m[1])
+			date, clock, exact, ok := quickDate(today.Year(), int(today.Month()), day, today.Location())
+			if ok && date.Before(today) {
+				date = date.AddDate(0, 1, 0)
+			}
+			return date, clock, exact, ok
+		},
+	},
+}
+
+// 时间规则按顺序匹配，只使用第一个命中的规则
+var quickTimeRules = []quickTimeRule{
+	{
+		// 3pm / 3:30 pm / at 11am
+		pattern: regexp.MustCompile(`(?i)\b(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b`),
+		resolve: func(m []string) (quickClock, bool) {
+			hour, _ := strconv.Atoi(m[1])
+			minute, _ := strconv.Atoi(m[2])
+			if hour < 1 || hour > 12 {
+				return quickClock{}, false
+			}
+			hour %= 12
+			if strings.EqualFold(m[3], "pm") {
+				hour += 12
+			}
+			return quickClock{hour: hour, minute: minute}, minute < 60
+		},
+	},
+	{
+		// 15:00 / at 9:30
+		pattern: regexp.MustCompile(`(?i)\b(?:at\s+)?(\d{1,2}):(\d{2})\b`),
+		resolve: func(m []string) (quickClock, bool) {
+			hour, _ := strconv.Atoi(m[1])
+			minute, _ := strconv.Atoi(m[2])
+			return quickClock{hour: hour, minute: minute}, hour < 24 && minute < 60
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)\b(?:at\s+)?(noon|midnight)\b`),
+		resolve: func(m []string) (quickClock, bool) {
+			if strings.EqualFold(m[1], "noon") {
+				return quickClock{hour: 12}, true
+			}
+			return quickClock{hour: 23, minute: 59}, true
+		},
+	},
+	{
+		// 下午3点 / 晚上8点半 / 10点15分
+		pattern: regexp.MustCompile(`(凌晨|早上|上午|中午|下午|傍晚|晚上)?(\d{1,2}|[零一二两三四五六七八九十]{1,3})[点點时](?:(\d{1,2})分?|(半))?`),
+		resolve: func(m []string) (quickClock, bool) {
+			hour, ok := parseChineseNumber(m[2])
+			if !ok {
+				return quickClock{}, false
+			}
+			minute := 0
+			if m[3] != "" {
+				minute, _ = strconv.Atoi(m[3])
+			} else if m[4] != "" {
+				minute = 30
+			}
+			switch m[1] {
+			case "下午", "傍晚", "晚上":
+				if hour < 12 {
+					hour += 12
+				}
+			case "中午":
+				if hour < 11 {
+					hour += 12
+				}
+			}
+			return quickClock{hour: hour, minute: minute}, hour < 24 && minute < 60
+		},
+	},
+	{
+		// at 9（24小时制）
+		pattern: regexp.MustCompile(`(?i)\bat\s+(\d{1,2})\b`),
+		resolve: func(m []string) (quickClock, bool) {
+			hour, _ := strconv.Atoi(m[1])
+			return quickClock{hour: hour}, hour < 24
+		},
+	},
+}
+
+// ParseQuickAdd 解析快速添加文本，now 应为用户时区下的当前时间。
+// 支持的标记：#分类、!优先级（low/medium/high/urgent/immediate、1-5、低/中/高/紧急/立即）、
+// ~预估工时（2h、30m、1h30m、1.5小时），以及中英文的日期和时间表达
+func ParseQuickAdd(text string, now time.Time) *QuickAddParseResult {
+	result := &QuickAddParseResult{Tags: []string{}}
+
+	if m := quickEstimatePattern.FindStringSubmatchIndex(text); m != nil {
+		if hours, ok := parseQuickEstimate(text, m); ok {
+			result.EstimatedHours = hours
+			text = cutMatch(text, m)
+		}
+	}
+
+	for _, m := range quickPriorityPattern.FindAllStringSubmatchIndex(text, -1) {
+		if level, ok := quickPriorityLevels[strings.ToLower(text[m[2]:m[3]])]; ok {
+			result.PriorityLevel = level
+			text = cutMatch(text, m)
+			break
+		}
+	}
+
+	for {
+		m := quickTagPattern.FindStringSubmatchIndex(text)
+		if m == nil {
+			break
+		}
+		result.Tags = append(result.Tags, text[m[2]:m[3]])
+		text = cutMatch(text, m)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var date time.Time
+	var dateClock *quickClock
+	dateFound, exact := false, false
+	for _, rule := range quickDateRules {
+		m := rule.pattern.FindStringSubmatchIndex(text)
+		if m == nil {
+			continue
+		}
+		d, clock, isExact, ok := rule.resolve(submatches(text, m), now, today)
+		if !ok {
+			continue
+		}
+		date, dateClock, exact, dateFound = d, clock, isExact, true
+		text = cutMatch(text, m)
+		break
+	}
+
+	var clock *quickClock
+	if !exact {
+		for _, rule := range quickTimeRules {
+			m := rule.pattern.FindStringSubmatchIndex(text)
+			if m == nil {
+				continue
+			}
+			c, ok := rule.resolve(submatches(text, m))
+			if !ok {
+				continue
+			}
+			clock = &c
+			text = cutMatch(text, m)
+			break
+		}
+	}
+
+	switch {
+	case exact:
+		due := date.Truncate(time.Minute)
+		result.DueDate, result.HasTime = &due, true
+	case dateFound || clock != nil:
+		if clock == nil {
+			clock = dateClock
+		}
+		if !dateFound {
+			date = today
+		}
+		var due time.Time
+		if clock != nil {
+			due = time.Date(date.Year(), date.Month(), date.Day(), clock.hour, clock.minute, 0, 0, date.Location())
+			// 只指定时间且已经过去时顺延到明天
+			if !dateFound && due.Before(now) {
+				due = due.AddDate(0, 0, 1)
+			}
+			result.HasTime = true
+		} else {
+			due = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+		}
+		result.DueDate = &due
+	}
+
+	text = strings.TrimSpace(quickSpacePattern.ReplaceAllString(text, " "))
+	text = strings.TrimSpace(quickDanglingPattern.ReplaceAllString(text, ""))
+	result.Title = text
+	return result
+}
+
+// parseQuickEstimate 解析预估工时，未指定单位时按小时计算
+func parseQuickEstimate(text string, m []int) (float64, bool) {
+	value, err := strconv.ParseFloat(text[m[2]:m[3]], 64)
+	if err != nil {
+		return 0, false
+	}
+
+	unit := ""
+	if m[4] >= 0 {
+		unit = strings.ToLower(text[m[4]:m[5]])
+	}
+	hours := value
+	switch {
+	case unit == "分钟" || strings.HasPrefix(unit, "m"):
+		hours = value / 60
+	case unit == "天" || strings.HasPrefix(unit, "d"):
+		hours = value * 8 // 按每天8个工时计算
+	}
+
+	// 1h30m 形式的附加分钟
+	if m[6] >= 0 {
+		minutes, err := strconv.ParseFloat(text[m[6]:m[7]], 64)
+		if err != nil {
+			return 0, false
+		}
+		hours += minutes / 60
+	}
+
+	if hours <= 0 {
+		return 0, false
+	}
+	return float64(int(hours*100+0.5)) / 100, true
+}
+
+// resolveWeekday 计算星期对应的日期：
+// mode 为空表示今天或之后最近的一天，this 表示本周（周一开始）的那天，next 表示下周的那天
+func resolveWeekday(today time.Time, weekday time.Weekday, mode string) time.Time {
+	switch mode {
+	case "this", "next":
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		offset := (int(weekday) + 6) % 7
+		if mode == "next" {
+			offset += 7
+		}
+		return monday.AddDate(0, 0, offset)
+	default:
+		return today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+	}
+}
+
+// upcomingMonthDay 今年的某月某日，已过则为明年
+func upcomingMonthDay(today time.Time, month, day int) (time.Time, *quickClock, bool, bool) {
+	date, clock, exact, ok := quickDate(today.Year(), month, day, today.Location())
+	if ok && date.Before(today) {
+		date, clock, exact, ok = quickDate(today.Year()+1, month, day, today.Location())
+	}
+	return date, clock, exact, ok
+}
+
+// quickDate 构造日期并校验是否合法（例如拒绝 2月30日）
+func quickDate(year, month, day int, loc *time.Location) (time.Time, *quickClock, bool, bool) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, nil, false, false
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+	if date.Day() != day {
+		return time.Time{}, nil, false, false
+	}
+	return date, nil, false, true
+}
+
+// parseChineseNumber 解析阿拉伯数字或 0-99 的中文数字
+func parseChineseNumber(s string) (int, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, true
+	}
+
+	digits := map[rune]int{'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	runes := []rune(s)
+	for i, r := range runes {
+		if r != '十' {
+			continue
+		}
+		tens := 1
+		if i > 0 {
+			d, ok := digits[runes[i-1]]
+			if !ok || i > 1 {
+				return 0, false
+			}
+			tens = d
+		}
+		ones := 0
+		if i+1 < len(runes) {
+			d, ok := digits[runes[i+1]]
+			if !ok || i+2 < len(runes) {
+				return 0, false
+			}
+			ones = d
+		}
+		return tens*10 + ones, true
+	}
+
+	if len(runes) == 1 {
+		d, ok := digits[runes[0]]
+		return d, ok
+	}
+	return 0, false
+}
+
+// submatches 根据索引取出各分组文本，未匹配的分组为空字符串
+func submatches(text string, m []int) []string {
+	groups := make([]string, len(m)/2)
+	for i := range groups {
+		if m[2*i] >= 0 {
+			groups[i] = text[m[2*i]:m[2*i+1]]
+		}
+	}
+	return groups
+}
+
+// cutMatch 从文本中移除匹配的部分
+func cutMatch(text string, m []int) string {
+	return text[:m[0]] + " " + text[m[1]:]
+}
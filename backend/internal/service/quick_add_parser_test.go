@@ -0,0 +1,197 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	// 2024-03-13 是周三
+	now := time.Date(2024, 3, 13, 10, 0, 0, 0, loc)
+	at := func(month time.Month, day, hour, minute int) *time.Time {
+		t := time.Date(2024, month, day, hour, minute, 0, 0, loc)
+		return &t
+	}
+	endOf := func(month time.Month, day int) *time.Time {
+		t := time.Date(2024, month, day, 23, 59, 59, 0, loc)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		title    string
+		due      *time.Time
+		hasTime  bool
+		priority int
+		tags     []string
+		estimate float64
+	}{
+		{
+			name:     "English date time tag and priority",
+			text:     "Review PR tomorrow 3pm #work !high",
+			title:    "Review PR",
+			due:      at(3, 14, 15, 0),
+			hasTime:  true,
+			priority: 3,
+			tags:     []string{"work"},
+		},
+		{
+			name:     "Estimate with hours and minutes",
+			text:     "Write report ~1h30m !2",
+			title:    "Write report",
+			priority: 2,
+			estimate: 1.5,
+		},
+		{
+			name:     "Estimate in minutes",
+			text:     "Call mom ~45m",
+			title:    "Call mom",
+			estimate: 0.75,
+		},
+		{
+			name:  "Date only is end of day",
+			text:  "Pay rent by friday",
+			title: "Pay rent",
+			due:   endOf(3, 15),
+		},
+		{
+			name:  "Weekday today counts",
+			text:  "Standup wednesday",
+			title: "Standup",
+			due:   endOf(3, 13),
+		},
+		{
+			name:    "Next weekday is in the following week",
+			text:    "Plan sprint next monday at 9:30",
+			title:   "Plan sprint",
+			due:     at(3, 18, 9, 30),
+			hasTime: true,
+		},
+		{
+			name:    "Past time only rolls to tomorrow",
+			text:    "Gym 7am",
+			title:   "Gym",
+			due:     at(3, 14, 7, 0),
+			hasTime: true,
+		},
+		{
+			name:    "Future time only stays today",
+			text:    "Lunch at noon",
+			title:   "Lunch",
+			due:     at(3, 13, 12, 0),
+			hasTime: true,
+		},
+		{
+			name:    "Tonight implies evening",
+			text:    "Read book tonight",
+			title:   "Read book",
+			due:     at(3, 13, 20, 0),
+			hasTime: true,
+		},
+		{
+			name:    "Relative hours",
+			text:    "Check deploy in 2 hours",
+			title:   "Check deploy",
+			due:     at(3, 13, 12, 0),
+			hasTime: true,
+		},
+		{
+			name:  "ISO date",
+			text:  "Renew passport 2024-04-01",
+			title: "Renew passport",
+			due:   endOf(4, 1),
+		},
+		{
+			name:  "Month name",
+			text:  "Birthday gift May 5th",
+			title: "Birthday gift",
+			due:   endOf(5, 5),
+		},
+		{
+			name:     "Chinese date time and priority",
+			text:     "明天下午3点开会 #工作 !紧急",
+			title:    "开会",
+			due:      at(3, 14, 15, 0),
+			hasTime:  true,
+			priority: 4,
+			tags:     []string{"工作"},
+		},
+		{
+			name:    "Chinese weekday with half hour",
+			text:    "下周五晚上八点半 聚餐",
+			title:   "聚餐",
+			due:     at(3, 22, 20, 30),
+			hasTime: true,
+		},
+		{
+			name:     "Chinese day after tomorrow and estimate",
+			text:     "后天 提交报销 ~2小时",
+			title:    "提交报销",
+			due:      endOf(3, 15),
+			estimate: 2,
+		},
+		{
+			name:  "Chinese month day",
+			text:  "交房租 4月1号",
+			title: "交房租",
+			due:   endOf(4, 1),
+		},
+		{
+			name:  "Plain text is untouched",
+			text:  "Buy milk!",
+			title: "Buy milk!",
+		},
+		{
+			name:  "Invalid date is kept in title",
+			text:  "Meet 2/30",
+			title: "Meet 2/30",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseQuickAdd(tt.text, now)
+			assert.Equal(t, tt.title, got.Title)
+			if tt.due == nil {
+				assert.Nil(t, got.DueDate)
+			} else if assert.NotNil(t, got.DueDate) {
+				assert.True(t, tt.due.Equal(*got.DueDate), "due = %v, want %v", got.DueDate, tt.due)
+			}
+			assert.Equal(t, tt.hasTime, got.HasTime)
+			assert.Equal(t, tt.priority, got.PriorityLevel)
+			if tt.tags == nil {
+				tt.tags = []string{}
+			}
+			assert.Equal(t, tt.tags, got.Tags)
+			assert.InDelta(t, tt.estimate, got.EstimatedHours, 0.001)
+		})
+	}
+}
+
+func TestParseChineseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"3", 3, true},
+		{"三", 3, true},
+		{"两", 2, true},
+		{"十", 10, true},
+		{"十二", 12, true},
+		{"二十", 20, true},
+		{"二十三", 23, true},
+		{"三四", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseChineseNumber(tt.in)
+		assert.Equal(t, tt.ok, ok, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 支持的重复频率
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// maxRecurrenceIterations 计算下一次实例时的最大迭代次数，防止不可满足的规则死循环
+const maxRecurrenceIterations = 1000
+
+var (
+	ErrInvalidRRule = errors.New("无效的重复规则")
+
+	weekdayCodes = map[string]time.Weekday{
+		"SU": time.Sunday,
+		"MO": time.Monday,
+		"TU": time.Tuesday,
+		"WE": time.Wednesday,
+		"TH": time.Thursday,
+		"FR": time.Friday,
+		"SA": time.Saturday,
+	}
+)
+
+// RecurrenceRule 解析后的重复规则，仅支持 RFC 5545 RRULE 的子集：
+// FREQ、INTERVAL、BYDAY（不带序号）、BYMONTHDAY、UNTIL、COUNT
+type RecurrenceRule struct {
+	Freq       string     `json:"freq"`
+	Interval   int        `json:"interval"`
+	ByDay      []string   `json:"by_day,omitempty"`
+	ByMonthDay []int      `json:"by_month_day,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+	Count      int        `json:"count,omitempty"`
+}
+
+// ParseRRule 解析RRULE字符串，允许带或不带 "RRULE:" 前缀
+func ParseRRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= 6 && strings.EqualFold(value[:6], "RRULE:") {
+		value = value[6:]
+	}
+	if value == "" {
+		return nil, ErrInvalidRRule
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRRule, part)
+		}
+		key, val := strings.ToUpper(strings.TrimSpace(kv[0])), strings.TrimSpace(kv[1])
+
+		switch key {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL=%s", ErrInvalidRRule, val)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(val), ",") {
+				if _, ok := weekdayCodes[day]; !ok {
+					return nil, fmt.Errorf("%w: BYDAY=%s", ErrInvalidRRule, day)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: BYMONTHDAY=%s", ErrInvalidRRule, day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := parseRRuleTime(val)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL=%s", ErrInvalidRRule, val)
+			}
+			rule.Until = &until
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT=%s", ErrInvalidRRule, val)
+			}
+			rule.Count = n
+		case "WKST":
+			// 固定以周一为一周开始，忽略
+		default:
+			return nil, fmt.Errorf("%w: 不支持的属性 %s", ErrInvalidRRule, key)
+		}
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// parseRRuleTime 解析UNTIL的日期或日期时间格式
+func parseRRuleTime(value string) (time.Time, error) {
+	layouts := []string{"20060102T150405Z", "20060102T150405", "20060102", time.RFC3339}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			// 仅日期时包含当天
+			if layout == "20060102" {
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidRRule
+}
+
+// Validate 校验规则
+func (r *RecurrenceRule) Validate() error {
+	switch r.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+	default:
+		return fmt.Errorf("%w: FREQ=%s", ErrInvalidRRule, r.Freq)
+	}
+	if r.Interval < 1 {
+		r.Interval = 1
+	}
+	if r.Until != nil && r.Count > 0 {
+		return fmt.Errorf("%w: UNTIL与COUNT不能同时使用", ErrInvalidRRule)
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != FreqMonthly {
+		return fmt.Errorf("%w: BYMONTHDAY仅支持MONTHLY", ErrInvalidRRule)
+	}
+	for _, day := range r.ByDay {
+		if _, ok := weekdayCodes[strings.ToUpper(day)]; !ok {
+			return fmt.Errorf("%w: BYDAY=%s", ErrInvalidRRule, day)
+		}
+	}
+	return nil
+}
+
+// String 生成RRULE字符串
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = strings.ToUpper(day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next 计算严格晚于after的下一次实例时间，保留after的时分秒；超出UNTIL时返回false。
+// COUNT由调用方根据已生成实例数判断
+func (r *RecurrenceRule) Next(after time.Time) (time.Time, bool) {
+	var next time.Time
+	switch r.Freq {
+	case FreqDaily:
+		next = r.nextDaily(after)
+	case FreqWeekly:
+		next = r.nextWeekly(after)
+	case FreqMonthly:
+		next = r.nextMonthly(after)
+	case FreqYearly:
+		next = r.nextYearly(after)
+	}
+
+	if next.IsZero() {
+		return time.Time{}, false
+	}
+	if r.Until != nil && next.After(*r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// matchesDay 判断星期是否满足BYDAY
+func (r *RecurrenceRule) matchesDay(day time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, code := range r.ByDay {
+		if weekdayCodes[strings.ToUpper(code)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) nextDaily(after time.Time) time.Time {
+	candidate := after
+	for i := 0; i < maxRecurrenceIterations; i++ {
+		candidate = candidate.AddDate(0, 0, r.Interval)
+		if r.matchesDay(candidate.Weekday()) {
+			return candidate
+		}
+	}
+	return time.Time{}
+}
+
+func (r *RecurrenceRule) nextWeekly(after time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return after.AddDate(0, 0, 7*r.Interval)
+	}
+
+	// 以周一为一周开始：先在当前周剩余天数中查找，再跳到下一个间隔周
+	offset := (int(after.Weekday()) + 6) % 7
+	weekStart := after.AddDate(0, 0, -offset)
+	for week := 0; week < maxRecurrenceIterations; week++ {
+		for d := 0; d < 7; d++ {
+			candidate := weekStart.AddDate(0, 0, d)
+			if candidate.After(after) && r.matchesDay(candidate.Weekday()) {
+				return candidate
+			}
+		}
+		weekStart = weekStart.AddDate(0, 0, 7*r.Interval)
+	}
+	return time.Time{}
+}
+
+func (r *RecurrenceRule) nextMonthly(after time.Time) time.Time {
+	days := r.ByMonthDay
+	if len(days) == 0 {
+		days = []int{after.Day()}
+	}
+
+	year, month, _ := after.Date()
+	for i := 0; i < maxRecurrenceIterations; i++ {
+		monthStart := time.Date(year, month, 1, after.Hour(), after.Minute(), after.Second(), 0, after.Location())
+		lastDay := monthStart.AddDate(0, 1, -1).Day()
+
+		var candidates []int
+		for _, day := range days {
+			if day < 0 {
+				day = lastDay + day + 1
+			}
+			// 按RFC 5545跳过不存在的日期（例如2月30日）
+			if day >= 1 && day <= lastDay {
+				candidates = append(candidates, day)
+			}
+		}
+		sort.Ints(candidates)
+
+		for _, day := range candidates {
+			candidate := monthStart.AddDate(0, 0, day-1)
+			if candidate.After(after) && r.matchesDay(candidate.Weekday()) {
+				return candidate
+			}
+		}
+
+		monthStart = monthStart.AddDate(0, r.Interval, 0)
+		year, month = monthStart.Year(), monthStart.Month()
+	}
+	return time.Time{}
+}
+
+func (r *RecurrenceRule) nextYearly(after time.Time) time.Time {
+	for i := 1; i < maxRecurrenceIterations; i++ {
+		year := after.Year() + i*r.Interval
+		candidate := time.Date(year, after.Month(), after.Day(), after.Hour(), after.Minute(), after.Second(), 0, after.Location())
+		// 跳过不存在的日期（例如非闰年的2月29日）
+		if candidate.Day() == after.Day() {
+			return candidate
+		}
+	}
+	return time.Time{}
+}
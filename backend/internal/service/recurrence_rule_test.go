@@ -0,0 +1,137 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Daily", input: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "With prefix and lowercase", input: "rrule:freq=weekly;interval=2;byday=mo,fr", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR"},
+		{name: "Monthly last day", input: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=6", want: "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=6"},
+		{name: "Until date", input: "FREQ=DAILY;UNTIL=20251231", want: "FREQ=DAILY;UNTIL=20251231T235959Z"},
+		{name: "Missing freq", input: "INTERVAL=2", wantErr: true},
+		{name: "Unsupported freq", input: "FREQ=HOURLY", wantErr: true},
+		{name: "Invalid weekday", input: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{name: "Until and count", input: "FREQ=DAILY;COUNT=3;UNTIL=20251231", wantErr: true},
+		{name: "Unsupported property", input: "FREQ=DAILY;BYSETPOS=1", wantErr: true},
+		{name: "Empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.input)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRRule)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, rule.String())
+		})
+	}
+}
+
+func TestRecurrenceRule_Next(t *testing.T) {
+	// 2025-01-15 是周三
+	base := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		rrule  string
+		after  time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "Daily",
+			rrule:  "FREQ=DAILY",
+			after:  base,
+			want:   time.Date(2025, 1, 16, 9, 30, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Every 3 days",
+			rrule:  "FREQ=DAILY;INTERVAL=3",
+			after:  base,
+			want:   time.Date(2025, 1, 18, 9, 30, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Weekly same weekday",
+			rrule:  "FREQ=WEEKLY",
+			after:  base,
+			want:   time.Date(2025, 1, 22, 9, 30, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Weekly by day later in same week",
+			rrule:  "FREQ=WEEKLY;BYDAY=MO,FR",
+			after:  base,
+			want:   time.Date(2025, 1, 17, 9, 30, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Biweekly by day jumps interval",
+			rrule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO",
+			after:  base,
+			want:   time.Date(2025, 1, 27, 9, 30, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Monthly same day",
+			rrule:  "FREQ=MONTHLY",
+			after:  base,
+			want:   time.Date(2025, 2, 15, 9, 30, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Monthly skips months without the day",
+			rrule:  "FREQ=MONTHLY",
+			after:  time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC),
+			want:   time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Monthly last day",
+			rrule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			after:  time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC),
+			want:   time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Yearly leap day",
+			rrule:  "FREQ=YEARLY",
+			after:  time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC),
+			want:   time.Date(2028, 2, 29, 8, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			name:   "Past until",
+			rrule:  "FREQ=DAILY;UNTIL=20250115T235959Z",
+			after:  base,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rrule)
+			require.NoError(t, err)
+
+			got, ok := rule.Next(tt.after)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// maxSavedFilters 每个用户可保存的过滤器上限
+const maxSavedFilters = 50
+
+var (
+	ErrSavedFilterNotFound = errors.New("saved filter not found")
+	ErrSavedFilterLimit    = errors.New("too many saved filters")
+)
+
+// SavedFilterService 保存的任务过滤器（智能清单）服务
+type SavedFilterService struct {
+	db          *gorm.DB
+	logger      logger.LoggerInterface
+	todoService *TodoService
+}
+
+// NewSavedFilterService 创建保存的过滤器服务
+func NewSavedFilterService(db *gorm.DB, logger logger.LoggerInterface) *SavedFilterService {
+	return &SavedFilterService{
+		db:          db,
+		logger:      logger,
+		todoService: NewTodoService(db, logger),
+	}
+}
+
+// SavedFilterRequest 创建保存的过滤器请求
+type SavedFilterRequest struct {
+	Name  string `json:"name" binding:"required,max=100"`
+	Query string `json:"query" binding:"required,max=1000"`
+	Icon  string `json:"icon" binding:"max=50"`
+	Color string `json:"color" binding:"max=20"`
+	Sort  int    `json:"sort"`
+}
+
+// UpdateSavedFilterRequest 更新保存的过滤器请求，未提供的字段不修改
+type UpdateSavedFilterRequest struct {
+	Name  *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Query *string `json:"query" binding:"omitempty,min=1,max=1000"`
+	Icon  *string `json:"icon" binding:"omitempty,max=50"`
+	Color *string `json:"color" binding:"omitempty,max=20"`
+	Sort  *int    `json:"sort"`
+}
+
+// GetSavedFilters 获取用户的过滤器，withCounts 为 true 时附带匹配的任务数
+func (s *SavedFilterService) GetSavedFilters(userID uint, withCounts bool) ([]*models.SavedFilter, error) {
+	var filters []*models.SavedFilter
+	if err := s.db.Where("user_id = ?", userID).Order("sort ASC, id ASC").Find(&filters).Error; err != nil {
+		return nil, fmt.Errorf("failed to get saved filters: %v", err)
+	}
+
+	if withCounts {
+		for _, filter := range filters {
+			count, err := s.countTodos(userID, filter.ID)
+			if err != nil {
+				// 单个过滤器失效（例如引用的分类已删除）不影响列表
+				s.logger.Warnf("Failed to count saved filter %d: %v", filter.ID, err)
+				continue
+			}
+			filter.Count = &count
+		}
+	}
+	return filters, nil
+}
+
+// GetSavedFilter 获取单个过滤器及匹配的任务数
+func (s *SavedFilterService) GetSavedFilter(id, userID uint) (*models.SavedFilter, error) {
+	filter, err := s.getOwnedFilter(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.countTodos(userID, filter.ID)
+	if err != nil {
+		return nil, err
+	}
+	filter.Count = &count
+	return filter, nil
+}
+
+// CreateSavedFilter 创建过滤器，表达式需能正确解析
+func (s *SavedFilterService) CreateSavedFilter(userID uint, req SavedFilterRequest) (*models.SavedFilter, error) {
+	if _, err := ParseTodoQuery(req.Query); err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := s.db.Model(&models.SavedFilter{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count saved filters: %v", err)
+	}
+	if total >= maxSavedFilters {
+		return nil, ErrSavedFilterLimit
+	}
+
+	filter := &models.SavedFilter{
+		UserID: userID,
+		Name:   req.Name,
+		Query:  req.Query,
+		Icon:   req.Icon,
+		Color:  req.Color,
+		Sort:   req.Sort,
+	}
+	if err := s.db.Create(filter).Error; err != nil {
+		return nil, fmt.Errorf("failed to create saved filter: %v", err)
+	}
+	return filter, nil
+}
+
+// UpdateSavedFilter 更新过滤器
+func (s *SavedFilterService) UpdateSavedFilter(id, userID uint, req UpdateSavedFilterRequest) (*models.SavedFilter, error) {
+	filter, err := s.getOwnedFilter(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Query != nil {
+		if _, err := ParseTodoQuery(*req.Query); err != nil {
+			return nil, err
+		}
+		filter.Query = *req.Query
+	}
+	if req.Name != nil {
+		filter.Name = *req.Name
+	}
+	if req.Icon != nil {
+		filter.Icon = *req.Icon
+	}
+	if req.Color != nil {
+		filter.Color = *req.Color
+	}
+	if req.Sort != nil {
+		filter.Sort = *req.Sort
+	}
+
+	if err := s.db.Save(filter).Error; err != nil {
+		return nil, fmt.Errorf("failed to update saved filter: %v", err)
+	}
+	return filter, nil
+}
+
+// DeleteSavedFilter 删除过滤器
+func (s *SavedFilterService) DeleteSavedFilter(id, userID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.SavedFilter{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete saved filter: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSavedFilterNotFound
+	}
+	return nil
+}
+
+func (s *SavedFilterService) getOwnedFilter(id, userID uint) (*models.SavedFilter, error) {
+	var filter models.SavedFilter
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&filter).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSavedFilterNotFound
+		}
+		return nil, fmt.Errorf("failed to get saved filter: %v", err)
+	}
+	return &filter, nil
+}
+
+// countTodos 与 GET /todos?view= 使用相同的查询路径统计任务数
+func (s *SavedFilterService) countTodos(userID, filterID uint) (int64, error) {
+	result, err := s.todoService.GetTodos(userID, TodoFilter{ViewID: &filterID, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	return result.Total, nil
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"gin-web-framework/pkg/logger"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,28 +12,53 @@ import (
 // Scheduler 定时任务调度器
 type Scheduler struct {
 	notificationManager *NotificationManager
+	articleService      *ArticleService
+	attachmentService   *AttachmentService
+	tokenService        *TokenService
+	sessionService      *SessionService
+	accountService      *AccountService
 	stopChan            chan bool
+	stopOnce            sync.Once
 	logger              logger.LoggerInterface
 	db                  *gorm.DB
 }
 
-func NewScheduler(db *gorm.DB, logger logger.LoggerInterface) *Scheduler {
+func NewScheduler(db *gorm.DB, articleService *ArticleService, attachmentService *AttachmentService, tokenService *TokenService, sessionService *SessionService, accountService *AccountService, logger logger.LoggerInterface) *Scheduler {
 	return &Scheduler{
 		notificationManager: NewNotificationManager(db, logger),
+		articleService:      articleService,
+		attachmentService:   attachmentService,
+		tokenService:        tokenService,
+		sessionService:      sessionService,
+		accountService:      accountService,
 		stopChan:            make(chan bool),
 		logger:              logger,
 		db:                  db,
 	}
 }
 
-// Start 启动定时任务
+// Start 启动通知检查定时任务
 func (s *Scheduler) Start() {
 	go s.runNotificationChecks()
 }
 
-// Stop 停止定时任务
+// StartBackgroundJobs 启动文章定时发布和定期清理任务，不包含通知检查
+func (s *Scheduler) StartBackgroundJobs() {
+	go s.runArticleSchedule()
+	go s.runCleanup()
+}
+
+// Stop 停止定时任务，可重复调用
 func (s *Scheduler) Stop() {
-	close(s.stopChan)
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+}
+
+// Shutdown 容器关闭时停止定时任务
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.Stop()
+	return nil
 }
 
 // runNotificationChecks 运行通知检查定时任务
@@ -48,3 +75,60 @@ func (s *Scheduler) runNotificationChecks() {
 		}
 	}
 }
+
+// runArticleSchedule 定时发布和下线文章，启动时先执行一次以补上停机期间到期的文章
+func (s *Scheduler) runArticleSchedule() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		published, archived, err := s.articleService.ProcessScheduledArticles(time.Now())
+		if err != nil {
+			s.logger.Errorf("Failed to process scheduled articles: %v", err)
+		} else if published > 0 || archived > 0 {
+			s.logger.Infof("Scheduled articles processed: %d published, %d archived", published, archived)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// runCleanup 启动时和之后每小时清理孤立的附件、过期的令牌记录和长期未活动的会话
+func (s *Scheduler) runCleanup() {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		removed, err := s.attachmentService.CollectOrphans(time.Now())
+		if err != nil {
+			s.logger.Errorf("Failed to clean up attachments: %v", err)
+		} else if removed > 0 {
+			s.logger.Infof("Orphaned attachments removed: %d", removed)
+		}
+		if removed, err := s.tokenService.CleanupExpired(time.Now()); err != nil {
+			s.logger.Errorf("Failed to clean up expired tokens: %v", err)
+		} else if removed > 0 {
+			s.logger.Infof("Expired token records removed: %d", removed)
+		}
+		if removed, err := s.sessionService.CleanupInactive(time.Now()); err != nil {
+			s.logger.Errorf("Failed to clean up inactive sessions: %v", err)
+		} else if removed > 0 {
+			s.logger.Infof("Inactive sessions removed: %d", removed)
+		}
+		if removed, err := s.accountService.CleanupExpired(time.Now()); err != nil {
+			s.logger.Errorf("Failed to clean up expired account tokens: %v", err)
+		} else if removed > 0 {
+			s.logger.Infof("Expired account tokens removed: %d", removed)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-s.stopChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/internal/search"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 全文索引中的文档类型
+const (
+	SearchDocTodo         = "todo"
+	SearchDocArticle      = "article"
+	SearchDocCategory     = "category"
+	SearchDocSong         = "song"
+	SearchDocVideoSeries  = "video_series"
+	SearchDocVideoEpisode = "video_episode"
+)
+
+// searchDocTypes 所有可检索的文档类型，按全局搜索的默认展示顺序排列
+var searchDocTypes = []string{
+	SearchDocTodo,
+	SearchDocArticle,
+	SearchDocCategory,
+	SearchDocSong,
+	SearchDocVideoSeries,
+	SearchDocVideoEpisode,
+}
+
+// searchSource 可检索内容的数据来源
+type searchSource struct {
+	model interface{}
+	// documents 加载指定ID的索引内容，已删除的记录不返回
+	documents func(db *gorm.DB, ids []uint) ([]search.Document, error)
+}
+
+var searchSources = map[string]searchSource{
+	SearchDocTodo: {
+		model: &models.Todo{},
+		documents: func(db *gorm.DB, ids []uint) ([]search.Document, error) {
+			var todos []*models.Todo
+			if err := db.Where("id IN ?", ids).Preload("Category").Find(&todos).Error; err != nil {
+				return nil, err
+			}
+			docs := make([]search.Document, 0, len(todos))
+			for _, todo := range todos {
+				doc := search.Document{Type: SearchDocTodo, ID: todo.ID, Title: todo.Title, Body: todo.Description}
+				// 分类名作为标签
+				if todo.Category != nil {
+					doc.Tags = []string{todo.Category.Name}
+				}
+				docs = append(docs, doc)
+			}
+			return docs, nil
+		},
+	},
+	SearchDocArticle: {
+		model: &models.Article{},
+		documents: func(db *gorm.DB, ids []uint) ([]search.Document, error) {
+			var articles []*models.Article
+			if err := db.Where("id IN ?", ids).Find(&articles).Error; err != nil {
+				return nil, err
+			}
+			docs := make([]search.Document, 0, len(articles))
+			for _, article := range articles {
+				docs = append(docs, search.Document{
+					Type:  SearchDocArticle,
+					ID:    article.ID,
+					Title: article.Title,
+					Body:  joinSearchText(article.Summary, article.Content),
+					Tags:  parseSearchTags(article.Tags),
+				})
+			}
+			return docs, nil
+		},
+	},
+	SearchDocCategory: {
+		model: &models.Category{},
+		documents: func(db *gorm.DB, ids []uint) ([]search.Document, error) {
+			var categories []*models.Category
+			if err := db.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+				return nil, err
+			}
+			docs := make([]search.Document, 0, len(categories))
+			for _, category := range categories {
+				docs = append(docs, search.Document{
+					Type:  SearchDocCategory,
+					ID:    category.ID,
+					Title: category.Name,
+					Body:  category.Description,
+				})
+			}
+			return docs, nil
+		},
+	},
+	SearchDocSong: {
+		model: &models.Song{},
+		documents: func(db *gorm.DB, ids []uint) ([]search.Document, error) {
+			var songs []*models.Song
+			if err := db.Where("id IN ?", ids).Find(&songs).Error; err != nil {
+				return nil, err
+			}
+			docs := make([]search.Document, 0, len(songs))
+			for _, song := range songs {
+				docs = append(docs, search.Document{
+					Type:  SearchDocSong,
+					ID:    song.ID,
+					Title: joinSearchText(song.Title, song.TitleCN),
+					Body:  joinSearchText(song.Description, song.Lyrics, song.LyricsCN),
+					Tags:  parseSearchTags(song.Tags),
+				})
+			}
+			return docs, nil
+		},
+	},
+	SearchDocVideoSeries: {
+		model: &models.VideoSeries{},
+		documents: func(db *gorm.DB, ids []uint) ([]search.Document, error) {
+			var series []*models.VideoSeries
+			if err := db.Where("id IN ?", ids).Find(&series).Error; err != nil {
+				return nil, err
+			}
+			docs := make([]search.Document, 0, len(series))
+			for _, item := range series {
+				docs = append(docs, search.Document{
+					Type:  SearchDocVideoSeries,
+					ID:    item.ID,
+					Title: joinSearchText(item.Title, item.TitleCN),
+					Body:  item.Description,
+					Tags:  parseSearchTags(item.Tags),
+				})
+			}
+			return docs, nil
+		},
+	},
+	SearchDocVideoEpisode: {
+		model: &models.VideoEpisode{},
+		documents: func(db *gorm.DB, ids []uint) ([]search.Document, error) {
+			var episodes []*models.VideoEpisode
+			if err := db.Where("id IN ?", ids).Find(&episodes).Error; err != nil {
+				return nil, err
+			}
+			docs := make([]search.Document, 0, len(episodes))
+			for _, episode := range episodes {
+				// 文字稿是剧集的主要检索内容
+				docs = append(docs, search.Document{
+					Type:  SearchDocVideoEpisode,
+					ID:    episode.ID,
+					Title: joinSearchText(episode.Title, episode.TitleCN),
+					Body:  joinSearchText(episode.Description, episode.Transcript),
+				})
+			}
+			return docs, nil
+		},
+	},
+}
+
+// syncSearchIndex 按当前数据刷新索引，已删除的记录从索引中移除。
+// 索引失败只记录日志，不影响业务写入，可通过重建索引修复
+func syncSearchIndex(db *gorm.DB, docType string, ids ...uint) {
+	idx, ok := search.FromDB(db)
+	if !ok || len(ids) == 0 {
+		return
+	}
+
+	docs, err := searchSources[docType].documents(db, ids)
+	if err != nil {
+		logger.Warnf("Failed to load %s documents for search index: %v", docType, err)
+		return
+	}
+
+	found := make(map[uint]bool, len(docs))
+	for _, doc := range docs {
+		found[doc.ID] = true
+	}
+	var removed []uint
+	for _, id := range ids {
+		if !found[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	if err := idx.Index(db, docs...); err != nil {
+		logger.Warnf("Failed to index %s documents: %v", docType, err)
+	}
+	if err := idx.Remove(db, docType, removed...); err != nil {
+		logger.Warnf("Failed to remove %s documents from search index: %v", docType, err)
+	}
+}
+
+// joinSearchText 拼接非空文本
+func joinSearchText(parts ...string) string {
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			texts = append(texts, part)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// parseSearchTags 解析JSON数组格式的标签，非JSON时按逗号分隔
+func parseSearchTags(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var tags []string
+	if err := json.Unmarshal([]byte(raw), &tags); err == nil {
+		return tags
+	}
+	return strings.Split(raw, ",")
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/internal/search"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// searchReindexBatch 重建索引时每批处理的记录数
+	searchReindexBatch = 200
+	// maxSearchLimit 全局搜索每页最大条数
+	maxSearchLimit = 50
+)
+
+// SearchService 全文检索服务：全局搜索与索引重建
+type SearchService struct {
+	db     *gorm.DB
+	logger logger.LoggerInterface
+}
+
+// NewSearchService 创建全文检索服务
+func NewSearchService(db *gorm.DB, logger logger.LoggerInterface) *SearchService {
+	return &SearchService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GlobalSearchRequest 全局搜索请求
+type GlobalSearchRequest struct {
+	Query string `form:"q" binding:"required,max=200"`
+	Types string `form:"types"` // 逗号分隔，为空时搜索全部类型
+	Page  int    `form:"page"`
+	Limit int    `form:"limit"`
+}
+
+// GlobalSearchItem 全局搜索结果项，Data 为对应类型的记录
+type GlobalSearchItem struct {
+	Type    string      `json:"type"`
+	ID      uint        `json:"id"`
+	Score   float64     `json:"score"`
+	Title   string      `json:"title"`
+	Snippet string      `json:"snippet"`
+	Data    interface{} `json:"data"`
+}
+
+// GlobalSearchResult 全局搜索结果，Facets 为各类型的命中数
+type GlobalSearchResult struct {
+	Query      string             `json:"query"`
+	Items      []GlobalSearchItem `json:"items"`
+	Facets     map[string]int64   `json:"facets"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalPages int                `json:"total_pages"`
+}
+
+// Search 跨类型全局搜索，结果按相关度混合排序。
+// 任务按清单权限可见，文章为自己的或已发布的，分类仅自己的，歌曲和视频为已发布的或自己创建的
+func (s *SearchService) Search(userID uint, req GlobalSearchRequest) (*GlobalSearchResult, error) {
+	idx, ok := search.FromDB(s.db)
+	if !ok {
+		return nil, ErrSearchUnavailable
+	}
+
+	var types []string
+	for _, t := range strings.Split(req.Types, ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		if _, ok := searchSources[t]; !ok {
+			return nil, ErrInvalidSearchType
+		}
+		types = append(types, t)
+	}
+
+	page, limit, offset := searchPage(req.Page, req.Limit)
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	scopes := make([]search.Scope, 0, len(searchDocTypes))
+	for _, docType := range searchDocTypes {
+		scopes = append(scopes, search.Scope{Type: docType, IDs: s.visibleIDs(docType, userID)})
+	}
+
+	result, err := idx.Search(s.db, search.Query{
+		Text:   req.Query,
+		Scopes: scopes,
+		Types:  types,
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %v", err)
+	}
+
+	records, err := s.loadSearchRecords(result.Hits)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]GlobalSearchItem, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		record, ok := records[hit.Type][hit.ID]
+		if !ok {
+			continue
+		}
+		items = append(items, GlobalSearchItem{
+			Type:    hit.Type,
+			ID:      hit.ID,
+			Score:   hit.Score,
+			Title:   hit.Title,
+			Snippet: hit.Snippet,
+			Data:    record,
+		})
+	}
+
+	return &GlobalSearchResult{
+		Query:      req.Query,
+		Items:      items,
+		Facets:     result.Facets,
+		Total:      result.Total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: int((result.Total + int64(limit) - 1) / int64(limit)),
+	}, nil
+}
+
+// visibleIDs 返回用户可见记录ID的子查询
+func (s *SearchService) visibleIDs(docType string, userID uint) *gorm.DB {
+	db := s.db.Session(&gorm.Session{NewDB: true})
+	switch docType {
+	case SearchDocTodo:
+		return db.Model(&models.Todo{}).Scopes(visibleTodos(userID)).Select("todos.id")
+	case SearchDocArticle:
+		return db.Model(&models.Article{}).Where("created_by = ? OR status = ?", userID, "published").Select("id")
+	case SearchDocCategory:
+		return db.Model(&models.Category{}).Where("created_by = ?", userID).Select("id")
+	case SearchDocSong:
+		return db.Model(&models.Song{}).Where("is_published = ? OR created_by = ?", true, userID).Select("id")
+	case SearchDocVideoSeries:
+		return db.Model(&models.VideoSeries{}).Where("is_published = ? OR created_by = ?", true, userID).Select("id")
+	case SearchDocVideoEpisode:
+		// 剧集本身及所属系列都发布后才对其他用户可见
+		return db.Model(&models.VideoEpisode{}).
+			Where("video_episodes.created_by = ? OR (video_episodes.is_published = ? AND NOT EXISTS "+
+				"(SELECT 1 FROM video_series vs WHERE vs.id = video_episodes.series_id AND vs.is_published = ?))",
+				userID, true, false).
+			Select("video_episodes.id")
+	}
+	return nil
+}
+
+// loadSearchRecords 按类型批量加载命中的记录，大文本字段（歌词、文字稿）不返回，由摘要展示
+func (s *SearchService) loadSearchRecords(hits []search.Hit) (map[string]map[uint]interface{}, error) {
+	idsByType := make(map[string][]uint)
+	for _, hit := range hits {
+		idsByType[hit.Type] = append(idsByType[hit.Type], hit.ID)
+	}
+
+	records := make(map[string]map[uint]interface{}, len(idsByType))
+	for docType, ids := range idsByType {
+		byID := make(map[uint]interface{}, len(ids))
+		var err error
+		switch docType {
+		case SearchDocTodo:
+			var todos []*models.Todo
+			err = s.db.Where("id IN ?", ids).Preload("Priority").Preload("Category").Find(&todos).Error
+			for _, todo := range todos {
+				byID[todo.ID] = todo
+			}
+		case SearchDocArticle:
+			var articles []*models.Article
+			err = s.db.Where("id IN ?", ids).Omit("content").Preload("User").Find(&articles).Error
+			for _, article := range articles {
+				byID[article.ID] = article
+			}
+		case SearchDocCategory:
+			var categories []*models.Category
+			err = s.db.Where("id IN ?", ids).Find(&categories).Error
+			for _, category := range categories {
+				byID[category.ID] = category
+			}
+		case SearchDocSong:
+			var songs []*models.Song
+			err = s.db.Where("id IN ?", ids).Omit("lyrics", "lyrics_cn").Preload("Category").Find(&songs).Error
+			for _, song := range songs {
+				byID[song.ID] = song
+			}
+		case SearchDocVideoSeries:
+			var series []*models.VideoSeries
+			err = s.db.Where("id IN ?", ids).Find(&series).Error
+			for _, item := range series {
+				byID[item.ID] = item
+			}
+		case SearchDocVideoEpisode:
+			var episodes []*models.VideoEpisode
+			err = s.db.Where("id IN ?", ids).Omit("subtitles", "transcript").Preload("Series").Find(&episodes).Error
+			for _, episode := range episodes {
+				byID[episode.ID] = episode
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s search results: %v", docType, err)
+		}
+		records[docType] = byID
+	}
+	return records, nil
+}
+
+// EnsureIndexed 某类文档索引为空时（首次启用或索引表被清空）从业务表重建
+func (s *SearchService) EnsureIndexed() error {
+	idx, ok := search.FromDB(s.db)
+	if !ok {
+		return nil
+	}
+
+	for _, docType := range searchDocTypes {
+		count, err := idx.Count(s.db, docType)
+		if err != nil {
+			return fmt.Errorf("failed to count search documents: %v", err)
+		}
+		if count == 0 {
+			if err := s.reindex(idx, docType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reindex 清空并重建全部索引
+func (s *SearchService) Reindex() error {
+	idx, ok := search.FromDB(s.db)
+	if !ok {
+		return ErrSearchUnavailable
+	}
+
+	for _, docType := range searchDocTypes {
+		if err := s.reindex(idx, docType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SearchService) reindex(idx search.Index, docType string) error {
+	if err := idx.Clear(s.db, docType); err != nil {
+		return fmt.Errorf("failed to clear %s search index: %v", docType, err)
+	}
+
+	source := searchSources[docType]
+	var ids []uint
+	if err := s.db.Model(source.model).Order("id").Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("failed to list %s for search index: %v", docType, err)
+	}
+
+	for start := 0; start < len(ids); start += searchReindexBatch {
+		end := start + searchReindexBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+		docs, err := source.documents(s.db, ids[start:end])
+		if err != nil {
+			return fmt.Errorf("failed to load %s for search index: %v", docType, err)
+		}
+		if err := idx.Index(s.db, docs...); err != nil {
+			return fmt.Errorf("failed to reindex %s: %v", docType, err)
+		}
+	}
+
+	s.logger.Infof("Rebuilt %s search index with %d documents", docType, len(ids))
+	return nil
+}
+
+// searchPage 计算全文检索的分页参数
+func searchPage(page, limit int) (int, int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	return page, limit, (page - 1) * limit
+}
+
+// searchTodosRanked 使用全文索引检索TODO，query 为已应用其他过滤条件的可见TODO查询
+func (s *TodoService) searchTodosRanked(idx search.Index, query *gorm.DB, filter TodoFilter) (*PaginatedTodos, error) {
+	page, limit, offset := searchPage(filter.Page, filter.Limit)
+	result, err := idx.Search(s.db, search.Query{
+		Text:   filter.Search,
+		Scopes: []search.Scope{{Type: SearchDocTodo, IDs: query.Model(&models.Todo{}).Select("todos.id")}},
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search todos: %v", err)
+	}
+
+	ids := make([]uint, len(result.Hits))
+	for i, hit := range result.Hits {
+		ids[i] = hit.ID
+	}
+	var found []*models.Todo
+	if len(ids) > 0 {
+		if err := s.db.Where("id IN ?", ids).
+			Preload("Priority").Preload("Category").Preload("Assignee").
+			Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to get todos: %v", err)
+		}
+	}
+
+	byID := make(map[uint]*models.Todo, len(found))
+	for _, todo := range found {
+		byID[todo.ID] = todo
+	}
+	todos := make([]*models.Todo, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		if todo, ok := byID[hit.ID]; ok {
+			todo.Search = &models.SearchHighlight{Score: hit.Score, Title: hit.Title, Snippet: hit.Snippet}
+			todos = append(todos, todo)
+		}
+	}
+
+	return &PaginatedTodos{
+		Todos:      todos,
+		Total:      result.Total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: int((result.Total + int64(limit) - 1) / int64(limit)),
+	}, nil
+}
+
+// searchArticlesRanked 使用全文索引检索文章，query 为已应用其他过滤条件的文章查询
+func (s *ArticleService) searchArticlesRanked(idx search.Index, query *gorm.DB, filter ArticleFilter) (*PaginatedArticles, error) {
+	page, limit, offset := searchPage(filter.Page, filter.Limit)
+	result, err := idx.Search(s.db, search.Query{
+		Text:   filter.Search,
+		Scopes: []search.Scope{{Type: SearchDocArticle, IDs: query.Model(&models.Article{}).Select("id")}},
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search articles: %v", err)
+	}
+
+	ids := make([]uint, len(result.Hits))
+	for i, hit := range result.Hits {
+		ids[i] = hit.ID
+	}
+	var found []*models.Article
+	if len(ids) > 0 {
+		if err := s.db.Where("id IN ?", ids).Preload("User").Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to get articles: %v", err)
+		}
+	}
+
+	byID := make(map[uint]*models.Article, len(found))
+	for _, article := range found {
+		byID[article.ID] = article
+	}
+	articles := make([]*models.Article, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		if article, ok := byID[hit.ID]; ok {
+			article.Search = &models.SearchHighlight{Score: hit.Score, Title: hit.Title, Snippet: hit.Snippet}
+			articles = append(articles, article)
+		}
+	}
+
+	return &PaginatedArticles{
+		Articles:   articles,
+		Total:      result.Total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: int((result.Total + int64(limit) - 1) / int64(limit)),
+	}, nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+	"gin-web-framework/pkg/useragent"
+
+	"gorm.io/gorm"
+)
+
+// sessionRetention 失效会话的保留时长，保留期内的设备仍视为已知设备，登录时不再提醒
+const sessionRetention = 90 * 24 * time.Hour
+
+// activeSessionCondition 会话所属的令牌族中还有可用的刷新令牌
+const activeSessionCondition = `EXISTS (SELECT 1 FROM refresh_tokens WHERE refresh_tokens.family_id = user_sessions.session_id
+	AND refresh_tokens.revoked_at IS NULL AND refresh_tokens.rotated_at IS NULL AND refresh_tokens.expires_at > ?)`
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ClientInfo 发起登录或刷新请求的客户端
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// SessionService 登录会话和设备管理：记录每次登录的设备，列出和移除仍有效的会话，
+// 在陌生设备登录时通知用户
+type SessionService struct {
+	db                  *gorm.DB
+	tokens              *TokenService
+	notificationService *NotificationService
+	logger              logger.LoggerInterface
+	now                 func() time.Time
+}
+
+// NewSessionService 创建会话服务
+func NewSessionService(db *gorm.DB, tokens *TokenService, logger logger.LoggerInterface) *SessionService {
+	return &SessionService{
+		db:                  db,
+		tokens:              tokens,
+		notificationService: NewNotificationService(db, logger),
+		logger:              logger,
+		now:                 time.Now,
+	}
+}
+
+// Start 记录新登录的会话，设备此前未出现过时通知用户，首次登录不通知
+func (s *SessionService) Start(userID uint, sessionID string, client ClientInfo) error {
+	session := newUserSession(userID, sessionID, client, s.now())
+
+	var known, total int64
+	if err := s.db.Model(&models.UserSession{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return fmt.Errorf("failed to count sessions: %v", err)
+	}
+	if err := s.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND device_name = ?", userID, session.DeviceName).
+		Count(&known).Error; err != nil {
+		return fmt.Errorf("failed to check known devices: %v", err)
+	}
+
+	if err := s.db.Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+
+	if total > 0 && known == 0 {
+		s.notifyNewDevice(session)
+	}
+	return nil
+}
+
+// Touch 刷新令牌时更新会话的最近活动时间和 IP，功能上线前登录的会话在这里补建记录
+func (s *SessionService) Touch(userID uint, sessionID string, client ClientInfo) error {
+	now := s.now()
+	result := s.db.Model(&models.UserSession{}).
+		Where("user_id = ? AND session_id = ?", userID, sessionID).
+		Updates(map[string]interface{}{
+			"ip_address":   client.IP,
+			"location":     sessionLocation(client.IP),
+			"last_seen_at": now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update session: %v", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	if err := s.db.Create(newUserSession(userID, sessionID, client, now)).Error; err != nil {
+		return fmt.Errorf("failed to create session: %v", err)
+	}
+	return nil
+}
+
+// List 列出用户仍有效的会话，currentSessionID 对应的会话标记为当前会话
+func (s *SessionService) List(userID uint, currentSessionID string) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	if err := s.db.Where("user_id = ?", userID).
+		Where(activeSessionCondition, s.now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %v", err)
+	}
+
+	for i := range sessions {
+		sessions[i].Current = currentSessionID != "" && sessions[i].SessionID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// Revoke 移除用户的某个会话，该设备上的令牌立即失效
+func (s *SessionService) Revoke(userID, id uint) error {
+	var session models.UserSession
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to get session: %v", err)
+	}
+
+	if err := s.tokens.RevokeSession(userID, session.SessionID); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(map[string]any{"user_id": userID, "session_id": session.SessionID}).Info("Session revoked")
+	return nil
+}
+
+// CleanupInactive 删除超过保留时长未活动且已失效的会话记录
+func (s *SessionService) CleanupInactive(now time.Time) (int64, error) {
+	result := s.db.Where("last_seen_at < ?", now.Add(-sessionRetention)).
+		Where("NOT "+activeSessionCondition, now).
+		Delete(&models.UserSession{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete inactive sessions: %v", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (s *SessionService) notifyNewDevice(session *models.UserSession) {
+	_, err := s.notificationService.CreateNotification(CreateNotificationRequest{
+		UserID: session.UserID,
+		Type:   "new_device_login",
+		Title:  "新设备登录提醒",
+		Message: fmt.Sprintf("你的账号于 %s 在新设备 %s 上登录（%s）。如果不是你本人操作，请移除该会话并尽快修改密码。",
+			session.LastSeenAt.Format("2006-01-02 15:04"), session.DeviceName, session.Location),
+		Data: map[string]interface{}{
+			"session_id":  session.ID,
+			"device_name": session.DeviceName,
+			"ip_address":  session.IPAddress,
+			"location":    session.Location,
+		},
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to create new_device_login notification: %v", err)
+	}
+}
+
+func newUserSession(userID uint, sessionID string, client ClientInfo, now time.Time) *models.UserSession {
+	return &models.UserSession{
+		UserID:     userID,
+		SessionID:  sessionID,
+		IPAddress:  client.IP,
+		UserAgent:  truncateRunes(client.UserAgent, 512),
+		DeviceName: useragent.Parse(client.UserAgent).String(),
+		Location:   sessionLocation(client.IP),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+}
+
+// sessionLocation 不依赖地理位置库的位置描述：本机、局域网，公网地址只显示所在网段
+func sessionLocation(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return "未知位置"
+	}
+	addr = addr.Unmap()
+
+	switch {
+	case addr.IsLoopback():
+		return "本机"
+	case addr.IsPrivate(), addr.IsLinkLocalUnicast():
+		return "局域网"
+	case addr.Is4():
+		octets := addr.As4()
+		return fmt.Sprintf("%d.%d.%d.*", octets[0], octets[1], octets[2])
+	default:
+		return netip.PrefixFrom(addr, 48).Masked().String()
+	}
+}
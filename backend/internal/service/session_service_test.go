@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionLocation(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "127.0.0.1", want: "本机"},
+		{ip: "::1", want: "本机"},
+		{ip: "192.168.1.20", want: "局域网"},
+		{ip: "10.0.0.8", want: "局域网"},
+		{ip: "fe80::1", want: "局域网"},
+		{ip: "203.0.113.45", want: "203.0.113.*"},
+		{ip: "::ffff:203.0.113.45", want: "203.0.113.*"},
+		{ip: "2001:db8:85a3:8d3:1319:8a2e:370:7348", want: "2001:db8:85a3::/48"},
+		{ip: "", want: "未知位置"},
+		{ip: "not-an-ip", want: "未知位置"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.want, sessionLocation(tt.ip))
+		})
+	}
+}
@@ -10,14 +10,16 @@ import (
 )
 
 type SettingsService struct {
-	db     *gorm.DB
-	logger logger.LoggerInterface
+	db       *gorm.DB
+	accounts *AccountService
+	logger   logger.LoggerInterface
 }
 
-func NewSettingsService(db *gorm.DB, logger logger.LoggerInterface) *SettingsService {
+func NewSettingsService(db *gorm.DB, accounts *AccountService, logger logger.LoggerInterface) *SettingsService {
 	return &SettingsService{
-		db:     db,
-		logger: logger,
+		db:       db,
+		accounts: accounts,
+		logger:   logger,
 	}
 }
 
@@ -61,6 +63,12 @@ func (s *SettingsService) UpdateProfile(userID uint, req *models.UpdateProfileRe
 		return errors.New("邮箱已存在")
 	}
 
+	var user models.User
+	if err := s.db.Select("email").First(&user, userID).Error; err != nil {
+		return err
+	}
+	emailChanged := req.Email != user.Email
+
 	// 更新用户信息
 	updates := models.User{
 		Username: req.Username,
@@ -68,7 +76,20 @@ func (s *SettingsService) UpdateProfile(userID uint, req *models.UpdateProfileRe
 		Nickname: req.Nickname,
 	}
 	
-	return s.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error
+	if err := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// 修改邮箱后需要重新验证
+	if emailChanged {
+		if err := s.db.Model(&models.User{}).Where("id = ?", userID).Update("email_verified_at", nil).Error; err != nil {
+			return err
+		}
+		if err := s.accounts.SendVerification(userID); err != nil {
+			s.logger.WithFields(map[string]any{"user_id": userID, "error": err}).Warn("Failed to send verification email")
+		}
+	}
+	return nil
 }
 
 func (s *SettingsService) ChangePassword(userID uint, req *models.ChangePasswordRequest) error {
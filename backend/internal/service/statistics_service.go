@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"gin-web-framework/internal/models"
 	"gin-web-framework/pkg/logger"
+	"math"
+	"sort"
 	"strconv"
 	"time"
 
@@ -276,6 +278,124 @@ func (s *StatisticsService) GetArticleTrends(userID uint, days int) ([]TrendData
 	return trends, nil
 }
 
+// EstimateAccuracy 预估工时与实际工时对比
+type EstimateAccuracy struct {
+	Key             string  `json:"key"` // 分类名称或周起始日期
+	CategoryID      *uint   `json:"categoryId,omitempty"`
+	TodoCount       int64   `json:"todoCount"`
+	OverrunCount    int64   `json:"overrunCount"` // 实际工时超出预估的任务数
+	EstimatedHours  float64 `json:"estimatedHours"`
+	ActualHours     float64 `json:"actualHours"`
+	VarianceHours   float64 `json:"varianceHours"`   // 实际 - 预估
+	VariancePercent float64 `json:"variancePercent"` // 偏差百分比，正数表示超出预估
+}
+
+// EstimateReport 预估偏差报表
+type EstimateReport struct {
+	Weeks      int                `json:"weeks"`
+	StartDate  string             `json:"startDate"`
+	Summary    EstimateAccuracy   `json:"summary"`
+	ByCategory []EstimateAccuracy `json:"byCategory"`
+	ByWeek     []EstimateAccuracy `json:"byWeek"`
+}
+
+// GetEstimateReport 统计最近若干周已完成任务的预估与实际工时，按分类和周汇总
+func (s *StatisticsService) GetEstimateReport(userID uint, weeks int) (*EstimateReport, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// 以周一作为一周的开始
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	startDate := weekStart.AddDate(0, 0, -7*(weeks-1))
+
+	var todos []models.Todo
+	if err := s.db.Preload("Category").
+		Where("(created_by = ? OR assignee_id = ?) AND status = ? AND completed_at >= ? AND estimated_hours > 0",
+			userID, userID, "completed", startDate).
+		Find(&todos).Error; err != nil {
+		return nil, fmt.Errorf("failed to get estimated todos: %v", err)
+	}
+
+	report := &EstimateReport{
+		Weeks:     weeks,
+		StartDate: startDate.Format("2006-01-02"),
+		Summary:   EstimateAccuracy{Key: "total"},
+	}
+
+	byWeek := make(map[string]*EstimateAccuracy, weeks)
+	for i := 0; i < weeks; i++ {
+		key := startDate.AddDate(0, 0, 7*i).Format("2006-01-02")
+		report.ByWeek = append(report.ByWeek, EstimateAccuracy{Key: key})
+	}
+	for i := range report.ByWeek {
+		byWeek[report.ByWeek[i].Key] = &report.ByWeek[i]
+	}
+
+	byCategory := make(map[string]*EstimateAccuracy)
+	var categoryKeys []string
+	for _, todo := range todos {
+		categoryKey := "uncategorized"
+		if todo.Category != nil {
+			categoryKey = todo.Category.Name
+		}
+		category, ok := byCategory[categoryKey]
+		if !ok {
+			category = &EstimateAccuracy{Key: categoryKey, CategoryID: todo.CategoryID}
+			byCategory[categoryKey] = category
+			categoryKeys = append(categoryKeys, categoryKey)
+		}
+
+		completedAt := todo.CompletedAt.In(now.Location())
+		completedDay := time.Date(completedAt.Year(), completedAt.Month(), completedAt.Day(), 0, 0, 0, 0, now.Location())
+		weekKey := completedDay.AddDate(0, 0, -((int(completedDay.Weekday()) + 6) % 7)).Format("2006-01-02")
+
+		for _, bucket := range []*EstimateAccuracy{&report.Summary, category, byWeek[weekKey]} {
+			if bucket == nil {
+				continue
+			}
+			bucket.TodoCount++
+			bucket.EstimatedHours += todo.EstimatedHours
+			bucket.ActualHours += todo.ActualHours
+			if todo.ActualHours > todo.EstimatedHours {
+				bucket.OverrunCount++
+			}
+		}
+	}
+
+	finishEstimateAccuracy(&report.Summary)
+	for i := range report.ByWeek {
+		finishEstimateAccuracy(&report.ByWeek[i])
+	}
+	for _, key := range categoryKeys {
+		finishEstimateAccuracy(byCategory[key])
+		report.ByCategory = append(report.ByCategory, *byCategory[key])
+	}
+	// 偏差最大的分类排在前面
+	sort.SliceStable(report.ByCategory, func(i, j int) bool {
+		return report.ByCategory[i].VarianceHours > report.ByCategory[j].VarianceHours
+	})
+
+	return report, nil
+}
+
+// finishEstimateAccuracy 计算偏差并保留两位小数
+func finishEstimateAccuracy(bucket *EstimateAccuracy) {
+	bucket.EstimatedHours = math.Round(bucket.EstimatedHours*100) / 100
+	bucket.ActualHours = math.Round(bucket.ActualHours*100) / 100
+	bucket.VarianceHours = math.Round((bucket.ActualHours-bucket.EstimatedHours)*100) / 100
+	if bucket.EstimatedHours > 0 {
+		bucket.VariancePercent = math.Round(bucket.VarianceHours/bucket.EstimatedHours*10000) / 100
+	}
+}
+
+// ValidateWeeks 验证周数参数
+func ValidateWeeks(weeksStr string) (int, error) {
+	weeks, err := strconv.Atoi(weeksStr)
+	if err != nil || weeks < 1 || weeks > 52 {
+		return 8, ErrInvalidWeeksParameter
+	}
+	return weeks, nil
+}
+
 // ValidateStatisticsType 验证统计类型
 func ValidateStatisticsType(statType string) (StatisticsType, error) {
 	switch StatisticsType(statType) {
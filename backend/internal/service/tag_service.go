@@ -0,0 +1,594 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxTagLength 标签名的最大字数
+	maxTagLength = 50
+	// maxTagsPerItem 每条内容最多的标签数
+	maxTagsPerItem = 20
+	// defaultTagLimit、maxTagLimit 标签云和自动补全返回的数量
+	defaultTagLimit = 50
+	maxTagLimit     = 200
+	// legacyTagBatch 迁移旧 JSON 标签时每批处理的记录数
+	legacyTagBatch = 200
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagConflict = errors.New("a tag with this name already exists, merge the tags instead")
+	ErrInvalidTag  = errors.New("invalid tag")
+)
+
+// tagTarget 可打标签的内容，join 为关联表，column 为关联表中指向内容的列
+type tagTarget struct {
+	table  string
+	join   string
+	column string
+	// visible 标签计数只统计当前用户可见的内容，c 为内容表别名
+	visible func(viewerID uint) (string, []interface{})
+}
+
+// tagTypes 可打标签的内容类型，与全文检索的文档类型一致
+var tagTypes = []string{SearchDocArticle, SearchDocSong, SearchDocVideoSeries}
+
+var tagTargets = map[string]tagTarget{
+	SearchDocArticle: {
+		table:  "articles",
+		join:   "article_tags",
+		column: "article_id",
+		visible: func(viewerID uint) (string, []interface{}) {
+			return "c.deleted_at IS NULL AND (c.status = ? OR c.created_by = ?)", []interface{}{ArticleStatusPublished, viewerID}
+		},
+	},
+	SearchDocSong: {
+		table:  "songs",
+		join:   "song_tags",
+		column: "song_id",
+		visible: func(uint) (string, []interface{}) {
+			return "c.is_published = ?", []interface{}{true}
+		},
+	},
+	SearchDocVideoSeries: {
+		table:  "video_series",
+		join:   "video_series_tags",
+		column: "series_id",
+		visible: func(uint) (string, []interface{}) {
+			return "c.is_published = ?", []interface{}{true}
+		},
+	},
+}
+
+// TagService 标签服务
+type TagService struct {
+	db     *gorm.DB
+	logger logger.LoggerInterface
+}
+
+// NewTagService 创建标签服务
+func NewTagService(db *gorm.DB, logger logger.LoggerInterface) *TagService {
+	return &TagService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// TagFilter 标签云和自动补全的查询条件，q 为名称前缀，type 限定内容类型
+type TagFilter struct {
+	Query string `form:"q"`
+	Type  string `form:"type"`
+	Limit int    `form:"limit"`
+}
+
+// TagUsage 标签及其使用次数，Counts 按内容类型统计
+type TagUsage struct {
+	models.Tag
+	Count  int64            `json:"count"`
+	Counts map[string]int64 `json:"counts"`
+}
+
+// RenameTagRequest 重命名标签请求
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+// MergeTagsRequest 合并标签请求，source_ids 中的标签合并到 target_id 后删除
+type MergeTagsRequest struct {
+	SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+	TargetID  uint   `json:"target_id" binding:"required"`
+}
+
+// GetTags 按使用次数倒序返回标签，只统计当前用户可见的内容，管理员统计全部内容
+func (s *TagService) GetTags(filter TagFilter, viewerID uint, isAdmin bool) ([]*TagUsage, error) {
+	types := tagTypes
+	if filter.Type != "" {
+		if _, ok := tagTargets[filter.Type]; !ok {
+			return nil, fmt.Errorf("%w: unknown content type %q", ErrInvalidTag, filter.Type)
+		}
+		types = []string{filter.Type}
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultTagLimit
+	}
+	if limit > maxTagLimit {
+		limit = maxTagLimit
+	}
+
+	var parts []string
+	var args []interface{}
+	for _, contentType := range types {
+		target := tagTargets[contentType]
+		part := fmt.Sprintf("SELECT jt.tag_id, '%s' AS content_type FROM %s jt JOIN %s c ON c.id = jt.%s",
+			contentType, target.join, target.table, target.column)
+		if !isAdmin {
+			condition, conditionArgs := target.visible(viewerID)
+			part += " WHERE " + condition
+			args = append(args, conditionArgs...)
+		} else if contentType == SearchDocArticle {
+			part += " WHERE c.deleted_at IS NULL"
+		}
+		parts = append(parts, part)
+	}
+
+	query := s.db.Table("("+strings.Join(parts, " UNION ALL ")+") u", args...).
+		Select("u.tag_id, u.content_type, COUNT(*) AS count").
+		Joins("JOIN tags t ON t.id = u.tag_id").
+		Group("u.tag_id, u.content_type")
+	if _, slug := normalizeTagName(filter.Query); slug != "" {
+		query = query.Where("t.slug LIKE ? ESCAPE '!'", escapeTagLike(slug)+"%")
+	}
+
+	var rows []struct {
+		TagID       uint
+		ContentType string
+		Count       int64
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count tags: %v", err)
+	}
+
+	usages := map[uint]*TagUsage{}
+	var ids []uint
+	for _, row := range rows {
+		usage, ok := usages[row.TagID]
+		if !ok {
+			usage = &TagUsage{Counts: map[string]int64{}}
+			usages[row.TagID] = usage
+			ids = append(ids, row.TagID)
+		}
+		usage.Counts[row.ContentType] = row.Count
+		usage.Count += row.Count
+	}
+	if len(ids) == 0 {
+		return []*TagUsage{}, nil
+	}
+
+	var tags []models.Tag
+	if err := s.db.Where("id IN ?", ids).Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tags: %v", err)
+	}
+	result := make([]*TagUsage, 0, len(tags))
+	for _, tag := range tags {
+		usage := usages[tag.ID]
+		usage.Tag = tag
+		result = append(result, usage)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Slug < result[j].Slug
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// RenameTag 重命名标签并同步所有内容上的标签。改名只改变大小写或空白时直接生效，
+// 与其他标签重名时返回 ErrTagConflict，应改用合并
+func (s *TagService) RenameTag(id uint, req RenameTagRequest) (*models.Tag, error) {
+	name, slug := normalizeTagName(req.Name)
+	if slug == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTag)
+	}
+
+	var tag models.Tag
+	affected := map[string][]uint{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&tag, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTagNotFound
+			}
+			return fmt.Errorf("failed to get tag: %v", err)
+		}
+		if slug != tag.Slug {
+			var count int64
+			if err := tx.Model(&models.Tag{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check tag name: %v", err)
+			}
+			if count > 0 {
+				return ErrTagConflict
+			}
+		}
+
+		if err := tx.Model(&tag).Updates(map[string]interface{}{"name": name, "slug": slug}).Error; err != nil {
+			return fmt.Errorf("failed to rename tag: %v", err)
+		}
+		for _, contentType := range tagTypes {
+			target := tagTargets[contentType]
+			var contentIDs []uint
+			if err := tx.Table(target.join).Where("tag_id = ?", tag.ID).Pluck(target.column, &contentIDs).Error; err != nil {
+				return fmt.Errorf("failed to get tagged content: %v", err)
+			}
+			if err := refreshContentTags(tx, contentType, contentIDs); err != nil {
+				return err
+			}
+			affected[contentType] = contentIDs
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reindex(affected)
+
+	return &tag, nil
+}
+
+// MergeTags 把源标签合并到目标标签：内容上的源标签替换为目标标签（已有目标标签的不重复添加），
+// 然后删除源标签
+func (s *TagService) MergeTags(req MergeTagsRequest) (*models.Tag, error) {
+	sourceIDs := make([]uint, 0, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		if id != req.TargetID {
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+	sourceIDs = uniqueIDs(sourceIDs)
+	if len(sourceIDs) == 0 {
+		return nil, fmt.Errorf("%w: source tags must differ from the target", ErrInvalidTag)
+	}
+
+	var target models.Tag
+	affected := map[string][]uint{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&target, req.TargetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTagNotFound
+			}
+			return fmt.Errorf("failed to get tag: %v", err)
+		}
+		var count int64
+		if err := tx.Model(&models.Tag{}).Where("id IN ?", sourceIDs).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to get tags: %v", err)
+		}
+		if int(count) != len(sourceIDs) {
+			return ErrTagNotFound
+		}
+
+		for _, contentType := range tagTypes {
+			contentIDs, err := mergeContentTags(tx, contentType, sourceIDs, target.ID)
+			if err != nil {
+				return err
+			}
+			if err := refreshContentTags(tx, contentType, contentIDs); err != nil {
+				return err
+			}
+			affected[contentType] = contentIDs
+		}
+		if err := tx.Delete(&models.Tag{}, sourceIDs).Error; err != nil {
+			return fmt.Errorf("failed to delete merged tags: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.reindex(affected)
+
+	return &target, nil
+}
+
+// MigrateLegacyTags 把还没有关联记录的 JSON 标签转换为标签表记录，可重复执行。
+// 转换后 tags 列改写为规范化的标签名
+func (s *TagService) MigrateLegacyTags() error {
+	for _, contentType := range tagTypes {
+		target := tagTargets[contentType]
+		lastID := uint(0)
+		migrated := 0
+		for {
+			var rows []struct {
+				ID   uint
+				Tags string
+			}
+			if err := s.db.Table(target.table+" c").
+				Select("c.id, c.tags").
+				Where("c.id > ?", lastID).
+				Where("c.tags IS NOT NULL AND c.tags NOT IN ?", []string{"", "[]", "null"}).
+				Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s jt WHERE jt.%s = c.id)", target.join, target.column)).
+				Order("c.id").
+				Limit(legacyTagBatch).
+				Scan(&rows).Error; err != nil {
+				return fmt.Errorf("failed to load %s tags: %v", contentType, err)
+			}
+			if len(rows) == 0 {
+				break
+			}
+
+			ids := make([]uint, 0, len(rows))
+			err := s.db.Transaction(func(tx *gorm.DB) error {
+				for _, row := range rows {
+					tagsJSON, err := setContentTags(tx, contentType, row.ID, parseSearchTags(row.Tags))
+					if err != nil {
+						return err
+					}
+					if err := tx.Table(target.table).Where("id = ?", row.ID).UpdateColumn("tags", tagsJSON).Error; err != nil {
+						return fmt.Errorf("failed to update %s tags: %v", contentType, err)
+					}
+					ids = append(ids, row.ID)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			syncSearchIndex(s.db, contentType, ids...)
+			migrated += len(rows)
+			lastID = rows[len(rows)-1].ID
+		}
+		if migrated > 0 {
+			s.logger.Infof("Migrated legacy tags for %d %s records", migrated, contentType)
+		}
+	}
+	return nil
+}
+
+func (s *TagService) reindex(affected map[string][]uint) {
+	for contentType, ids := range affected {
+		syncSearchIndex(s.db, contentType, ids...)
+	}
+}
+
+// setContentTags 设置内容的标签，不存在的标签自动创建，返回写入内容 tags 列的 JSON
+func setContentTags(tx *gorm.DB, contentType string, contentID uint, names []string) (string, error) {
+	target := tagTargets[contentType]
+	tags, err := ensureTags(tx, names)
+	if err != nil {
+		return "", err
+	}
+
+	if err := tx.Exec("DELETE FROM "+target.join+" WHERE "+target.column+" = ?", contentID).Error; err != nil {
+		return "", fmt.Errorf("failed to clear %s tags: %v", contentType, err)
+	}
+	if len(tags) == 0 {
+		return "[]", nil
+	}
+	rows := make([]map[string]interface{}, len(tags))
+	tagNames := make([]string, len(tags))
+	for i, tag := range tags {
+		rows[i] = map[string]interface{}{target.column: contentID, "tag_id": tag.ID, "position": i}
+		tagNames[i] = tag.Name
+	}
+	if err := tx.Table(target.join).Create(rows).Error; err != nil {
+		return "", fmt.Errorf("failed to save %s tags: %v", contentType, err)
+	}
+	return marshalTagNames(tagNames), nil
+}
+
+// deleteContentTags 删除内容的标签关联，用于物理删除内容时
+func deleteContentTags(tx *gorm.DB, contentType string, contentIDs ...uint) error {
+	target := tagTargets[contentType]
+	if err := tx.Exec("DELETE FROM "+target.join+" WHERE "+target.column+" IN ?", contentIDs).Error; err != nil {
+		return fmt.Errorf("failed to delete %s tags: %v", contentType, err)
+	}
+	return nil
+}
+
+// ensureTags 按名称查找或创建标签，名称按规范化后去重，保持输入顺序
+func ensureTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	var wanted []models.Tag
+	seen := map[string]bool{}
+	for _, raw := range names {
+		name, slug := normalizeTagName(raw)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		if len(wanted) == maxTagsPerItem {
+			return nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidTag, maxTagsPerItem)
+		}
+		seen[slug] = true
+		wanted = append(wanted, models.Tag{Name: name, Slug: slug})
+	}
+	if len(wanted) == 0 {
+		return nil, nil
+	}
+
+	// 并发创建同名标签时由唯一索引去重，之后统一按 slug 重新查询
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&wanted).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tags: %v", err)
+	}
+	slugs := make([]string, len(wanted))
+	for i, tag := range wanted {
+		slugs[i] = tag.Slug
+	}
+	var existing []models.Tag
+	if err := tx.Where("slug IN ?", slugs).Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tags: %v", err)
+	}
+	bySlug := make(map[string]models.Tag, len(existing))
+	for _, tag := range existing {
+		bySlug[tag.Slug] = tag
+	}
+	tags := make([]models.Tag, 0, len(slugs))
+	for _, slug := range slugs {
+		if tag, ok := bySlug[slug]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// mergeContentTags 把内容上的源标签替换为目标标签，返回受影响的内容ID
+func mergeContentTags(tx *gorm.DB, contentType string, sourceIDs []uint, targetID uint) ([]uint, error) {
+	target := tagTargets[contentType]
+	var links []struct {
+		ContentID uint
+		TagID     uint
+		Position  int
+	}
+	if err := tx.Table(target.join).
+		Select(target.column+" AS content_id, tag_id, position").
+		Where("tag_id IN ?", append([]uint{targetID}, sourceIDs...)).
+		Order("position").
+		Scan(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s tags: %v", contentType, err)
+	}
+
+	hasTarget := map[uint]bool{}
+	for _, link := range links {
+		if link.TagID == targetID {
+			hasTarget[link.ContentID] = true
+		}
+	}
+	var contentIDs []uint
+	var rows []map[string]interface{}
+	for _, link := range links {
+		if link.TagID == targetID || hasTarget[link.ContentID] {
+			if link.TagID != targetID {
+				contentIDs = append(contentIDs, link.ContentID)
+			}
+			continue
+		}
+		// 源标签按位置排序，取最靠前的位置
+		hasTarget[link.ContentID] = true
+		contentIDs = append(contentIDs, link.ContentID)
+		rows = append(rows, map[string]interface{}{target.column: link.ContentID, "tag_id": targetID, "position": link.Position})
+	}
+	if len(contentIDs) == 0 {
+		return nil, nil
+	}
+
+	if err := tx.Exec("DELETE FROM "+target.join+" WHERE tag_id IN ?", sourceIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete merged %s tags: %v", contentType, err)
+	}
+	if len(rows) > 0 {
+		if err := tx.Table(target.join).Create(rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to save merged %s tags: %v", contentType, err)
+		}
+	}
+	return uniqueIDs(contentIDs), nil
+}
+
+// refreshContentTags 按关联表重写内容 tags 列，保持旧接口返回的 JSON 标签与标签表一致
+func refreshContentTags(tx *gorm.DB, contentType string, contentIDs []uint) error {
+	if len(contentIDs) == 0 {
+		return nil
+	}
+	target := tagTargets[contentType]
+	var links []struct {
+		ContentID uint
+		Name      string
+	}
+	if err := tx.Table(target.join+" jt").
+		Select("jt."+target.column+" AS content_id, t.name").
+		Joins("JOIN tags t ON t.id = jt.tag_id").
+		Where("jt."+target.column+" IN ?", contentIDs).
+		Order("jt.position, t.id").
+		Scan(&links).Error; err != nil {
+		return fmt.Errorf("failed to get %s tags: %v", contentType, err)
+	}
+
+	names := map[uint][]string{}
+	for _, link := range links {
+		names[link.ContentID] = append(names[link.ContentID], link.Name)
+	}
+	for _, id := range contentIDs {
+		if err := tx.Table(target.table).Where("id = ?", id).UpdateColumn("tags", marshalTagNames(names[id])).Error; err != nil {
+			return fmt.Errorf("failed to update %s tags: %v", contentType, err)
+		}
+	}
+	return nil
+}
+
+// tagScope 只保留带有指定标签的内容
+func tagScope(contentType, name string) func(*gorm.DB) *gorm.DB {
+	target := tagTargets[contentType]
+	_, slug := normalizeTagName(name)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(fmt.Sprintf("%s.id IN (SELECT jt.%s FROM %s jt JOIN tags t ON t.id = jt.tag_id WHERE t.slug = ?)",
+			target.table, target.column, target.join), slug)
+	}
+}
+
+// parseTagInput 解析请求中的标签，支持字符串数组、JSON数组字符串和逗号分隔的字符串
+func parseTagInput(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	case []interface{}:
+		names := make([]string, 0, len(v))
+		for _, item := range v {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	case string:
+		return parseSearchTags(strings.TrimSpace(v))
+	default:
+		return nil
+	}
+}
+
+// normalizeTagName 返回标签的展示名和用于去重的 slug：去掉首尾空白和开头的 #，合并连续空白，
+// 超长截断，slug 为展示名的小写形式
+func normalizeTagName(raw string) (string, string) {
+	name := strings.TrimPrefix(strings.TrimSpace(raw), "#")
+	name = strings.Join(strings.Fields(name), " ")
+	if utf8.RuneCountInString(name) > maxTagLength {
+		name = strings.TrimSpace(string([]rune(name)[:maxTagLength]))
+	}
+	return name, strings.ToLower(name)
+}
+
+func marshalTagNames(names []string) string {
+	if len(names) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(names)
+	return string(data)
+}
+
+// escapeTagLike 转义 LIKE 通配符，配合 ESCAPE '!' 使用
+func escapeTagLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
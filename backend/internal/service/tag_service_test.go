@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTagTestService(t *testing.T) (*TagService, *gorm.DB) {
+	db := openTestDB(t, &models.User{}, &models.Article{}, &models.Song{}, &models.VideoSeries{},
+		&models.Tag{}, &models.ArticleTag{}, &models.SongTag{}, &models.VideoSeriesTag{})
+	return NewTagService(db, logger.NewLogger(logger.DefaultLoggerConfig())), db
+}
+
+// createTaggedArticle 创建文章并通过关联表打上标签
+func createTaggedArticle(t *testing.T, db *gorm.DB, title string, tags ...string) *models.Article {
+	article := &models.Article{Title: title, Status: ArticleStatusPublished, CreatedBy: 1}
+	require.NoError(t, db.Create(article).Error)
+	tagsJSON, err := setContentTags(db, SearchDocArticle, article.ID, tags)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(article).UpdateColumn("tags", tagsJSON).Error)
+	return article
+}
+
+func findTag(t *testing.T, db *gorm.DB, slug string) models.Tag {
+	var tag models.Tag
+	require.NoError(t, db.Where("slug = ?", slug).First(&tag).Error)
+	return tag
+}
+
+func articleTagsColumn(t *testing.T, db *gorm.DB, id uint) string {
+	var article models.Article
+	require.NoError(t, db.First(&article, id).Error)
+	return article.Tags
+}
+
+func TestTagService_MergeIntoTagAlreadyOnContent(t *testing.T) {
+	svc, db := newTagTestService(t)
+	both := createTaggedArticle(t, db, "both", "Golang", "Go", "web")
+	sourceOnly := createTaggedArticle(t, db, "source only", "web", "Golang")
+	source, target := findTag(t, db, "golang"), findTag(t, db, "go")
+
+	merged, err := svc.MergeTags(MergeTagsRequest{SourceIDs: []uint{source.ID}, TargetID: target.ID})
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, merged.ID)
+
+	// 已有目标标签的内容不重复添加关联，只删除源标签
+	var links []models.ArticleTag
+	require.NoError(t, db.Where("article_id = ?", both.ID).Order("position").Find(&links).Error)
+	require.Len(t, links, 2)
+	assert.Equal(t, target.ID, links[0].TagID)
+	var count int64
+	require.NoError(t, db.Model(&models.Tag{}).Where("id = ?", source.ID).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// 旧接口使用的 JSON 标签列随之更新，源标签的位置由目标标签接替
+	assert.JSONEq(t, `["Go","web"]`, articleTagsColumn(t, db, both.ID))
+	assert.JSONEq(t, `["web","Go"]`, articleTagsColumn(t, db, sourceOnly.ID))
+}
+
+func TestTagService_RenameTag(t *testing.T) {
+	svc, db := newTagTestService(t)
+	article := createTaggedArticle(t, db, "article", "golang", "Web")
+	tag, other := findTag(t, db, "golang"), findTag(t, db, "web")
+
+	_, err := svc.RenameTag(tag.ID, RenameTagRequest{Name: " WEB "})
+	assert.ErrorIs(t, err, ErrTagConflict)
+
+	// 只改变大小写时直接生效，并同步内容上的 JSON 标签
+	renamed, err := svc.RenameTag(other.ID, RenameTagRequest{Name: "web"})
+	require.NoError(t, err)
+	assert.Equal(t, "web", renamed.Name)
+	renamed, err = svc.RenameTag(tag.ID, RenameTagRequest{Name: "Go"})
+	require.NoError(t, err)
+	assert.Equal(t, "go", renamed.Slug)
+	assert.JSONEq(t, `["Go","web"]`, articleTagsColumn(t, db, article.ID))
+}
+
+func TestTagService_MigrateLegacyTagsIsIdempotent(t *testing.T) {
+	svc, db := newTagTestService(t)
+	song := &models.Song{Title: "song", Tags: `["Rock"," rock ","#Pop"]`}
+	require.NoError(t, db.Create(song).Error)
+	series := &models.VideoSeries{Title: "series", Tags: `["pop"]`}
+	require.NoError(t, db.Create(series).Error)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, svc.MigrateLegacyTags())
+
+		var tags, songLinks, seriesLinks int64
+		require.NoError(t, db.Model(&models.Tag{}).Count(&tags).Error)
+		require.NoError(t, db.Model(&models.SongTag{}).Where("song_id = ?", song.ID).Count(&songLinks).Error)
+		require.NoError(t, db.Model(&models.VideoSeriesTag{}).Where("series_id = ?", series.ID).Count(&seriesLinks).Error)
+		assert.EqualValues(t, 2, tags)
+		assert.EqualValues(t, 2, songLinks)
+		assert.EqualValues(t, 1, seriesLinks)
+
+		var migrated models.Song
+		require.NoError(t, db.First(&migrated, song.ID).Error)
+		assert.JSONEq(t, `["Rock","Pop"]`, migrated.Tags)
+	}
+}
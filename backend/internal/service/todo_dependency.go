@@ -0,0 +1,293 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// maxTodoTreeDepth 子任务树/依赖图遍历的最大层数
+const maxTodoTreeDepth = 100
+
+// AddDependencyRequest 添加依赖请求
+type AddDependencyRequest struct {
+	BlockedByID uint `json:"blocked_by_id" binding:"required"`
+}
+
+// SubtaskProgress 子任务完成进度
+type SubtaskProgress struct {
+	TodoID    uint    `json:"todo_id"`
+	Title     string  `json:"title"`
+	Total     int64   `json:"total"`
+	Completed int64   `json:"completed"`
+	Progress  float64 `json:"progress"` // 百分比 0-100
+}
+
+// TodoSubtasks 子任务列表及进度
+type TodoSubtasks struct {
+	Subtasks []*models.Todo  `json:"subtasks"`
+	Progress SubtaskProgress `json:"progress"`
+}
+
+// TodoDependencies TODO依赖关系
+type TodoDependencies struct {
+	BlockedBy   []*models.Todo `json:"blocked_by"`   // 阻塞当前任务的任务
+	Blocking    []*models.Todo `json:"blocking"`     // 被当前任务阻塞的任务
+	OpenBlocker int64          `json:"open_blocker"` // 未完成的前置任务数
+}
+
+// CreateSubtask 在父任务下创建子任务
+func (s *TodoService) CreateSubtask(parentID, userID uint, req CreateTodoRequest) (*models.Todo, error) {
+	req.ParentID = &parentID
+	return s.CreateTodo(req, userID)
+}
+
+// GetSubtasks 获取子任务及完成进度
+func (s *TodoService) GetSubtasks(parentID, userID uint) (*TodoSubtasks, error) {
+	parent, err := s.GetTodoByID(parentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 子任务可能属于用户看不到的其它清单，只返回并统计可见的子任务
+	var subtasks []*models.Todo
+	if err := s.db.Scopes(visibleTodos(userID)).
+		Where("todos.parent_id = ?", parent.ID).
+		Preload("Priority").
+		Preload("Category").
+		Order("todos.created_at ASC").
+		Find(&subtasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %v", err)
+	}
+
+	progress := SubtaskProgress{TodoID: parent.ID, Title: parent.Title, Total: int64(len(subtasks))}
+	for _, subtask := range subtasks {
+		if subtask.Status == "completed" {
+			progress.Completed++
+		}
+	}
+	if progress.Total > 0 {
+		progress.Progress = float64(progress.Completed) / float64(progress.Total) * 100
+	}
+
+	return &TodoSubtasks{Subtasks: subtasks, Progress: progress}, nil
+}
+
+// GetDependencies 获取TODO的依赖关系
+func (s *TodoService) GetDependencies(todoID, userID uint) (*TodoDependencies, error) {
+	todo, err := s.GetTodoByID(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 依赖的任务可能属于用户看不到的其它清单，只返回并统计可见的任务
+	deps := &TodoDependencies{}
+	if err := s.db.Scopes(visibleTodos(userID)).
+		Joins("JOIN todo_dependencies ON todo_dependencies.blocked_by_id = todos.id").
+		Where("todo_dependencies.todo_id = ?", todo.ID).
+		Preload("Priority").
+		Find(&deps.BlockedBy).Error; err != nil {
+		return nil, fmt.Errorf("failed to get blockers: %v", err)
+	}
+	if err := s.db.Scopes(visibleTodos(userID)).
+		Joins("JOIN todo_dependencies ON todo_dependencies.todo_id = todos.id").
+		Where("todo_dependencies.blocked_by_id = ?", todo.ID).
+		Preload("Priority").
+		Find(&deps.Blocking).Error; err != nil {
+		return nil, fmt.Errorf("failed to get blocked todos: %v", err)
+	}
+
+	for _, blocker := range deps.BlockedBy {
+		if !isTodoClosed(blocker.Status) {
+			deps.OpenBlocker++
+		}
+	}
+
+	return deps, nil
+}
+
+// AddDependency 添加依赖：todoID 被 blockedByID 阻塞，拒绝形成环
+func (s *TodoService) AddDependency(todoID, blockedByID, userID uint) error {
+	if todoID == blockedByID {
+		return ErrDependencyCycle
+	}
+
+	if _, err := s.getEditableTodo(todoID, userID); err != nil {
+		return err
+	}
+	if _, err := s.GetTodoByID(blockedByID, userID); err != nil {
+		return errors.New("blocking todo not found")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.TodoDependency{}).
+			Where("todo_id = ? AND blocked_by_id = ?", todoID, blockedByID).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check dependency: %v", err)
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		// 如果 blockedByID 已经（直接或间接）被 todoID 阻塞，新边会形成环
+		cyclic, err := dependencyReachable(tx, blockedByID, todoID)
+		if err != nil {
+			return err
+		}
+		if cyclic {
+			return ErrDependencyCycle
+		}
+
+		dependency := &models.TodoDependency{
+			TodoID:      todoID,
+			BlockedByID: blockedByID,
+			CreatedBy:   userID,
+		}
+		if err := tx.Create(dependency).Error; err != nil {
+			return fmt.Errorf("failed to create dependency: %v", err)
+		}
+		return nil
+	})
+}
+
+// RemoveDependency 移除依赖
+func (s *TodoService) RemoveDependency(todoID, blockedByID, userID uint) error {
+	if _, err := s.getEditableTodo(todoID, userID); err != nil {
+		return err
+	}
+
+	result := s.db.Where("todo_id = ? AND blocked_by_id = ?", todoID, blockedByID).Delete(&models.TodoDependency{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove dependency: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("dependency not found")
+	}
+
+	return nil
+}
+
+// dependencyReachable 沿“被阻塞”边从 from 出发，判断能否到达 target
+func dependencyReachable(db *gorm.DB, from, target uint) (bool, error) {
+	visited := map[uint]bool{from: true}
+	frontier := []uint{from}
+
+	for depth := 0; len(frontier) > 0 && depth < maxTodoTreeDepth; depth++ {
+		var next []uint
+		if err := db.Model(&models.TodoDependency{}).
+			Where("todo_id IN ?", frontier).
+			Pluck("blocked_by_id", &next).Error; err != nil {
+			return false, fmt.Errorf("failed to traverse dependencies: %v", err)
+		}
+
+		frontier = frontier[:0]
+		for _, id := range next {
+			if id == target {
+				return true, nil
+			}
+			if !visited[id] {
+				visited[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// countOpenBlockers 统计未完成的前置任务数
+func countOpenBlockers(db *gorm.DB, todoIDs []uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.Todo{}).
+		Joins("JOIN todo_dependencies ON todo_dependencies.blocked_by_id = todos.id").
+		Where("todo_dependencies.todo_id IN ? AND todos.status NOT IN ?", todoIDs, []string{"completed", "cancelled"}).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to check blockers: %v", err)
+	}
+	return count, nil
+}
+
+// ensureNotBlocked 完成任务前检查前置任务
+func ensureNotBlocked(db *gorm.DB, todoIDs ...uint) error {
+	count, err := countOpenBlockers(db, todoIDs)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrTodoBlocked
+	}
+	return nil
+}
+
+// collectSubtaskIDs 收集所有后代子任务ID
+func collectSubtaskIDs(db *gorm.DB, parentIDs []uint) ([]uint, error) {
+	var all []uint
+	frontier := parentIDs
+	for depth := 0; len(frontier) > 0 && depth < maxTodoTreeDepth; depth++ {
+		var children []uint
+		if err := db.Model(&models.Todo{}).Where("parent_id IN ?", frontier).Pluck("id", &children).Error; err != nil {
+			return nil, fmt.Errorf("failed to get subtasks: %v", err)
+		}
+		all = append(all, children...)
+		frontier = children
+	}
+	return all, nil
+}
+
+// cleanupDeletedTodos 删除TODO后级联删除子任务、停止计时、清理依赖并更新全文索引
+func cleanupDeletedTodos(tx *gorm.DB, ids []uint) error {
+	subtaskIDs, err := collectSubtaskIDs(tx, ids)
+	if err != nil {
+		return err
+	}
+	if len(subtaskIDs) > 0 {
+		if err := tx.Where("id IN ?", subtaskIDs).Delete(&models.Todo{}).Error; err != nil {
+			return fmt.Errorf("failed to delete subtasks: %v", err)
+		}
+	}
+
+	removed := append(append([]uint{}, ids...), subtaskIDs...)
+	if err := stopTimersForTodos(tx, removed); err != nil {
+		return err
+	}
+	if err := tx.Where("todo_id IN ? OR blocked_by_id IN ?", removed, removed).
+		Delete(&models.TodoDependency{}).Error; err != nil {
+		return fmt.Errorf("failed to delete dependencies: %v", err)
+	}
+	syncSearchIndex(tx, SearchDocTodo, removed...)
+	return nil
+}
+
+// getSubtaskProgress 统计用户所有父任务的子任务进度
+func (s *TodoService) getSubtaskProgress(userID uint) ([]SubtaskProgress, error) {
+	// 只统计用户可见的子任务
+	visibleChildren := s.db.Model(&models.Todo{}).Select("todos.id").Scopes(visibleTodos(userID))
+	var rows []SubtaskProgress
+	err := s.db.Table("todos AS parent").
+		Select("parent.id AS todo_id, parent.title AS title, COUNT(child.id) AS total, "+
+			"SUM(CASE WHEN child.status = 'completed' THEN 1 ELSE 0 END) AS completed").
+		Joins("JOIN todos AS child ON child.parent_id = parent.id AND child.deleted_at IS NULL AND child.id IN (?)", visibleChildren).
+		Where("parent.created_by = ? AND parent.deleted_at IS NULL", userID).
+		Group("parent.id, parent.title").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subtask progress: %v", err)
+	}
+
+	for i := range rows {
+		if rows[i].Total > 0 {
+			rows[i].Progress = float64(rows[i].Completed) / float64(rows[i].Total) * 100
+		}
+	}
+	return rows, nil
+}
+
+// isTodoClosed 判断任务是否已结束
+func isTodoClosed(status string) bool {
+	return status == "completed" || status == "cancelled"
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 任务过滤表达式由空格分隔的条件组成，条件之间为“且”：
+//
+//	status:pending,in_progress   状态，逗号分隔表示“或”
+//	priority:high / priority:>=3 优先级名称或等级，支持 > >= < <= 比较
+//	category:12                  分类及其所有子分类，none 表示未分类
+//	list:5 / assignee:me         共享清单、负责人，none 表示为空
+//	due:next7d                   截止时间：overdue today tomorrow none nextNd lastNd nextNw lastNw，
+//	                             或日期 2024-05-01，支持 due:<2024-05-01 等比较
+//	-status:completed            前缀 - 表示取反
+//
+// 其余词语（可用双引号包裹短语）作为全文检索关键字。
+
+// maxTodoQueryLength 过滤表达式最大长度
+const maxTodoQueryLength = 1000
+
+// ErrInvalidTodoQuery 过滤表达式无效
+var ErrInvalidTodoQuery = errors.New("invalid filter query")
+
+// TodoQueryTerm 过滤表达式中的一个条件
+type TodoQueryTerm struct {
+	Field  string   `json:"field"`
+	Op     string   `json:"op"`     // = > >= < <=
+	Values []string `json:"values"` // 多个值之间为“或”
+	Negate bool     `json:"negate"`
+}
+
+// TodoQuery 解析后的过滤表达式
+type TodoQuery struct {
+	Terms []TodoQueryTerm `json:"terms"`
+	Text  string          `json:"text"`
+}
+
+var (
+	todoQueryStatuses = map[string]bool{"pending": true, "in_progress": true, "completed": true, "cancelled": true}
+	todoQueryDueWords = map[string]bool{"overdue": true, "today": true, "tomorrow": true, "none": true}
+	todoQueryRelative = regexp.MustCompile(`^(next|last)(\d{1,3})([dw])$`)
+	todoQueryOps      = []string{">=", "<=", ">", "<"}
+)
+
+// todoQueryFields 支持的字段及取值校验
+var todoQueryFields = map[string]func(value string) bool{
+	"status": func(v string) bool { return todoQueryStatuses[v] },
+	"priority": func(v string) bool {
+		_, ok := quickPriorityLevels[v]
+		return ok
+	},
+	"category": isIDOrNone,
+	"list":     isIDOrNone,
+	"assignee": func(v string) bool { return v == "me" || isIDOrNone(v) },
+	"due": func(v string) bool {
+		if todoQueryDueWords[v] || todoQueryRelative.MatchString(v) {
+			return true
+		}
+		_, err := time.Parse("2006-01-02", v)
+		return err == nil
+	},
+}
+
+// ParseTodoQuery 解析过滤表达式
+func ParseTodoQuery(input string) (*TodoQuery, error) {
+	if len(input) > maxTodoQueryLength {
+		return nil, fmt.Errorf("%w: query too long", ErrInvalidTodoQuery)
+	}
+
+	query := &TodoQuery{Terms: []TodoQueryTerm{}}
+	var text []string
+	for _, token := range splitTodoQuery(input) {
+		term, ok, err := parseTodoQueryTerm(token)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			query.Terms = append(query.Terms, term)
+		} else {
+			text = append(text, token)
+		}
+	}
+	query.Text = strings.Join(text, " ")
+	return query, nil
+}
+
+// splitTodoQuery 按空白分词，双引号内的空白不切分，引号保留以便全文检索按短语匹配
+func splitTodoQuery(input string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '　'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// parseTodoQueryTerm 解析 field:value 形式的条件，字段未知时视为普通关键字
+func parseTodoQueryTerm(token string) (TodoQueryTerm, bool, error) {
+	var term TodoQueryTerm
+	key, value, found := strings.Cut(token, ":")
+	if !found {
+		return term, false, nil
+	}
+	if strings.HasPrefix(key, "-") {
+		term.Negate = true
+		key = key[1:]
+	}
+	key = strings.ToLower(key)
+	validate, known := todoQueryFields[key]
+	if !known {
+		return term, false, nil
+	}
+	term.Field = key
+
+	term.Op = "="
+	for _, op := range todoQueryOps {
+		if strings.HasPrefix(value, op) {
+			term.Op = op
+			value = value[len(op):]
+			break
+		}
+	}
+	if term.Op != "=" && key != "priority" && key != "due" {
+		return term, false, fmt.Errorf("%w: %s does not support %s", ErrInvalidTodoQuery, key, term.Op)
+	}
+
+	for _, v := range strings.Split(strings.ToLower(value), ",") {
+		if v == "" {
+			continue
+		}
+		if !validate(v) {
+			return term, false, fmt.Errorf("%w: invalid %s value %q", ErrInvalidTodoQuery, key, v)
+		}
+		term.Values = append(term.Values, v)
+	}
+	if len(term.Values) == 0 {
+		return term, false, fmt.Errorf("%w: missing %s value", ErrInvalidTodoQuery, key)
+	}
+	if term.Op != "=" {
+		if len(term.Values) > 1 {
+			return term, false, fmt.Errorf("%w: %s %s takes a single value", ErrInvalidTodoQuery, key, term.Op)
+		}
+		if key == "due" {
+			if _, err := time.Parse("2006-01-02", term.Values[0]); err != nil {
+				return term, false, fmt.Errorf("%w: due %s requires a date", ErrInvalidTodoQuery, term.Op)
+			}
+		}
+	}
+	return term, true, nil
+}
+
+func isIDOrNone(v string) bool {
+	if v == "none" {
+		return true
+	}
+	id, err := strconv.ParseUint(v, 10, 32)
+	return err == nil && id > 0
+}
+
+// todoQueryCondition 编译后的SQL条件，nullable 为取反时需要保留空值的列
+type todoQueryCondition struct {
+	sql      string
+	args     []interface{}
+	nullable string
+}
+
+// applyTodoQuery 将过滤条件（不含关键字）应用到任务查询，now 用于计算相对日期
+func applyTodoQuery(db, query *gorm.DB, q *TodoQuery, userID uint, now time.Time) (*gorm.DB, error) {
+	for _, term := range q.Terms {
+		var parts []string
+		var args []interface{}
+		nullable := ""
+		for _, value := range term.Values {
+			cond, err := compileTodoQueryValue(db, term, value, userID, now)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, "("+cond.sql+")")
+			args = append(args, cond.args...)
+			nullable = cond.nullable
+		}
+
+		sql := strings.Join(parts, " OR ")
+		if term.Negate {
+			if nullable != "" {
+				sql = nullable + " IS NULL OR NOT (" + sql + ")"
+			} else {
+				sql = "NOT (" + sql + ")"
+			}
+		}
+		query = query.Where(sql, args...)
+	}
+	return query, nil
+}
+
+func compileTodoQueryValue(db *gorm.DB, term TodoQueryTerm, value string, userID uint, now time.Time) (todoQueryCondition, error) {
+	switch term.Field {
+	case "status":
+		return todoQueryCondition{sql: "todos.status = ?", args: []interface{}{value}}, nil
+	case "priority":
+		levels := db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.TodoPriority{}).
+			Select("id").
+			Where("level "+term.Op+" ?", quickPriorityLevels[value])
+		return todoQueryCondition{sql: "todos.priority_id IN (?)", args: []interface{}{levels}}, nil
+	case "category":
+		if value == "none" {
+			return todoQueryCondition{sql: "todos.category_id IS NULL"}, nil
+		}
+		id, _ := strconv.ParseUint(value, 10, 32)
+		ids, err := categorySubtree(db, uint(id))
+		if err != nil {
+			return todoQueryCondition{}, err
+		}
+		return todoQueryCondition{sql: "todos.category_id IN ?", args: []interface{}{ids}, nullable: "todos.category_id"}, nil
+	case "list", "assignee":
+		column := "todos.list_id"
+		if term.Field == "assignee" {
+			column = "todos.assignee_id"
+		}
+		switch value {
+		case "none":
+			return todoQueryCondition{sql: column + " IS NULL"}, nil
+		case "me":
+			return todoQueryCondition{sql: column + " = ?", args: []interface{}{userID}, nullable: column}, nil
+		}
+		id, _ := strconv.ParseUint(value, 10, 32)
+		return todoQueryCondition{sql: column + " = ?", args: []interface{}{uint(id)}, nullable: column}, nil
+	case "due":
+		return compileDueCondition(term.Op, value, now), nil
+	}
+	return todoQueryCondition{}, fmt.Errorf("%w: unknown field %s", ErrInvalidTodoQuery, term.Field)
+}
+
+// compileDueCondition 截止时间条件，相对范围按自然日计算且包含今天
+func compileDueCondition(op, value string, now time.Time) todoQueryCondition {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	between := func(from, to time.Time) todoQueryCondition {
+		return todoQueryCondition{sql: "todos.due_date >= ? AND todos.due_date < ?", args: []interface{}{from, to}, nullable: "todos.due_date"}
+	}
+
+	switch value {
+	case "overdue":
+		return todoQueryCondition{
+			sql:      "todos.due_date < ? AND todos.status NOT IN ?",
+			args:     []interface{}{now, []string{"completed", "cancelled"}},
+			nullable: "todos.due_date",
+		}
+	case "none":
+		return todoQueryCondition{sql: "todos.due_date IS NULL"}
+	case "today":
+		return between(today, today.AddDate(0, 0, 1))
+	case "tomorrow":
+		return between(today.AddDate(0, 0, 1), today.AddDate(0, 0, 2))
+	}
+
+	if m := todoQueryRelative.FindStringSubmatch(value); m != nil {
+		days, _ := strconv.Atoi(m[2])
+		if m[3] == "w" {
+			days *= 7
+		}
+		if m[1] == "next" {
+			return between(today, today.AddDate(0, 0, days))
+		}
+		return between(today.AddDate(0, 0, 1-days), today.AddDate(0, 0, 1))
+	}
+
+	day, _ := time.ParseInLocation("2006-01-02", value, now.Location())
+	next := day.AddDate(0, 0, 1)
+	cond := todoQueryCondition{nullable: "todos.due_date"}
+	switch op {
+	case ">":
+		cond.sql, cond.args = "todos.due_date >= ?", []interface{}{next}
+	case ">=":
+		cond.sql, cond.args = "todos.due_date >= ?", []interface{}{day}
+	case "<":
+		cond.sql, cond.args = "todos.due_date < ?", []interface{}{day}
+	case "<=":
+		cond.sql, cond.args = "todos.due_date < ?", []interface{}{next}
+	default:
+		return between(day, next)
+	}
+	return cond
+}
+
+// categorySubtree 返回分类及其所有子孙分类的ID
+func categorySubtree(db *gorm.DB, rootID uint) ([]uint, error) {
+	ids := []uint{rootID}
+	seen := map[uint]bool{rootID: true}
+	frontier := []uint{rootID}
+	for len(frontier) > 0 {
+		var children []uint
+		if err := db.Model(&models.Category{}).Where("parent_id IN ?", frontier).Pluck("id", &children).Error; err != nil {
+			return nil, fmt.Errorf("failed to load sub categories: %v", err)
+		}
+		frontier = frontier[:0]
+		for _, id := range children {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+				frontier = append(frontier, id)
+			}
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTodoQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		terms []TodoQueryTerm
+		text  string
+	}{
+		{
+			name:  "Empty",
+			input: "  ",
+			terms: []TodoQueryTerm{},
+		},
+		{
+			name:  "Fields and text",
+			input: `status:pending,in_progress priority:>=high due:next7d 周报 "release notes"`,
+			terms: []TodoQueryTerm{
+				{Field: "status", Op: "=", Values: []string{"pending", "in_progress"}},
+				{Field: "priority", Op: ">=", Values: []string{"high"}},
+				{Field: "due", Op: "=", Values: []string{"next7d"}},
+			},
+			text: `周报 "release notes"`,
+		},
+		{
+			name:  "Negation and case",
+			input: "-Status:COMPLETED category:12 assignee:me list:none",
+			terms: []TodoQueryTerm{
+				{Field: "status", Op: "=", Values: []string{"completed"}, Negate: true},
+				{Field: "category", Op: "=", Values: []string{"12"}},
+				{Field: "assignee", Op: "=", Values: []string{"me"}},
+				{Field: "list", Op: "=", Values: []string{"none"}},
+			},
+		},
+		{
+			name:  "Unknown keys are text",
+			input: "meeting at 10:30 http://example.com",
+			terms: []TodoQueryTerm{},
+			text:  "meeting at 10:30 http://example.com",
+		},
+		{
+			name:  "Due date comparison",
+			input: "due:<2024-05-01",
+			terms: []TodoQueryTerm{{Field: "due", Op: "<", Values: []string{"2024-05-01"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseTodoQuery(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.terms, query.Terms)
+			assert.Equal(t, tt.text, query.Text)
+		})
+	}
+}
+
+func TestParseTodoQuery_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"status:done",
+		"priority:extreme",
+		"category:abc",
+		"due:someday",
+		"due:>today",
+		"status:>pending",
+		"priority:>=1,2",
+		"assignee:",
+	} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseTodoQuery(input)
+			assert.ErrorIs(t, err, ErrInvalidTodoQuery)
+		})
+	}
+}
+
+func TestCompileDueCondition(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	now := time.Date(2024, 3, 13, 10, 0, 0, 0, loc)
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 0, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		op    string
+		value string
+		sql   string
+		args  []interface{}
+	}{
+		{"=", "today", "todos.due_date >= ? AND todos.due_date < ?", []interface{}{day(13), day(14)}},
+		{"=", "tomorrow", "todos.due_date >= ? AND todos.due_date < ?", []interface{}{day(14), day(15)}},
+		{"=", "next7d", "todos.due_date >= ? AND todos.due_date < ?", []interface{}{day(13), day(20)}},
+		{"=", "next2w", "todos.due_date >= ? AND todos.due_date < ?", []interface{}{day(13), day(27)}},
+		{"=", "last3d", "todos.due_date >= ? AND todos.due_date < ?", []interface{}{day(11), day(14)}},
+		{"=", "2024-03-20", "todos.due_date >= ? AND todos.due_date < ?", []interface{}{day(20), day(21)}},
+		{">", "2024-03-20", "todos.due_date >= ?", []interface{}{day(21)}},
+		{"<=", "2024-03-20", "todos.due_date < ?", []interface{}{day(21)}},
+		{"=", "none", "todos.due_date IS NULL", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.op+tt.value, func(t *testing.T) {
+			cond := compileDueCondition(tt.op, tt.value, now)
+			assert.Equal(t, tt.sql, cond.sql)
+			assert.Equal(t, tt.args, cond.args)
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// TodoListService 共享清单服务
+type TodoListService struct {
+	db     *gorm.DB
+	logger logger.LoggerInterface
+}
+
+// NewTodoListService 创建共享清单服务
+func NewTodoListService(db *gorm.DB, logger logger.LoggerInterface) *TodoListService {
+	return &TodoListService{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateTodoListRequest 创建清单请求
+type CreateTodoListRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Color       string `json:"color"`
+}
+
+// UpdateTodoListRequest 更新清单请求
+type UpdateTodoListRequest struct {
+	Name        string `json:"name" binding:"max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Color       string `json:"color"`
+}
+
+// AddTodoListMemberRequest 添加成员请求，UserID 与 Username 二选一
+type AddTodoListMemberRequest struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// UpdateTodoListMemberRequest 修改成员角色请求
+type UpdateTodoListMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// TodoListSummary 清单概要（含当前用户角色）
+type TodoListSummary struct {
+	models.TodoList
+	Role        string `json:"role"`
+	TodoCount   int64  `json:"todo_count"`
+	MemberCount int64  `json:"member_count"`
+}
+
+var (
+	ErrTodoListNotFound = errors.New("todo list not found")
+	ErrTodoListDenied   = errors.New("permission denied for this todo list")
+	ErrTodoAssigneeOnly = errors.New("assignees can only update the status and progress of a todo")
+)
+
+// CreateList 创建清单，创建者成为所有者
+func (s *TodoListService) CreateList(userID uint, req CreateTodoListRequest) (*models.TodoList, error) {
+	list := &models.TodoList{
+		Name:        req.Name,
+		Description: req.Description,
+		Color:       req.Color,
+		OwnerID:     userID,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(list).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TodoListMember{
+			ListID: list.ID,
+			UserID: userID,
+			Role:   models.TodoListRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create todo list: %v", err)
+	}
+
+	return list, nil
+}
+
+// GetLists 获取用户参与的所有清单
+func (s *TodoListService) GetLists(userID uint) ([]*TodoListSummary, error) {
+	var members []models.TodoListMember
+	if err := s.db.Where("user_id = ?", userID).Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("failed to get todo list memberships: %v", err)
+	}
+	if len(members) == 0 {
+		return []*TodoListSummary{}, nil
+	}
+
+	roles := make(map[uint]string, len(members))
+	listIDs := make([]uint, 0, len(members))
+	for _, member := range members {
+		roles[member.ListID] = member.Role
+		listIDs = append(listIDs, member.ListID)
+	}
+
+	var lists []models.TodoList
+	if err := s.db.Where("id IN ?", listIDs).Preload("Owner").Order("created_at ASC").Find(&lists).Error; err != nil {
+		return nil, fmt.Errorf("failed to get todo lists: %v", err)
+	}
+
+	summaries := make([]*TodoListSummary, 0, len(lists))
+	for _, list := range lists {
+		summary := &TodoListSummary{TodoList: list, Role: roles[list.ID]}
+		s.db.Model(&models.Todo{}).Where("list_id = ?", list.ID).Count(&summary.TodoCount)
+		s.db.Model(&models.TodoListMember{}).Where("list_id = ?", list.ID).Count(&summary.MemberCount)
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// GetList 获取清单详情及成员，需要是清单成员
+func (s *TodoListService) GetList(listID, userID uint) (*TodoListSummary, error) {
+	role, err := getTodoListRole(s.db, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrTodoListNotFound
+	}
+
+	var list models.TodoList
+	if err := s.db.Preload("Owner").Preload("Members.User").First(&list, listID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTodoListNotFound
+		}
+		return nil, fmt.Errorf("failed to get todo list: %v", err)
+	}
+
+	summary := &TodoListSummary{TodoList: list, Role: role, MemberCount: int64(len(list.Members))}
+	s.db.Model(&models.Todo{}).Where("list_id = ?", list.ID).Count(&summary.TodoCount)
+	return summary, nil
+}
+
+// UpdateList 更新清单信息，仅所有者可操作
+func (s *TodoListService) UpdateList(listID, userID uint, req UpdateTodoListRequest) (*models.TodoList, error) {
+	list, err := s.getOwnedList(listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		list.Name = req.Name
+	}
+	if req.Description != "" {
+		list.Description = req.Description
+	}
+	if req.Color != "" {
+		list.Color = req.Color
+	}
+
+	if err := s.db.Save(list).Error; err != nil {
+		return nil, fmt.Errorf("failed to update todo list: %v", err)
+	}
+	return list, nil
+}
+
+// DeleteList 删除清单，清单中的任务回到各自创建者名下
+func (s *TodoListService) DeleteList(listID, userID uint) error {
+	list, err := s.getOwnedList(listID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Todo{}).Where("list_id = ?", list.ID).
+			Updates(map[string]interface{}{"list_id": nil, "assignee_id": nil}).Error; err != nil {
+			return fmt.Errorf("failed to detach todos: %v", err)
+		}
+		if err := tx.Where("list_id = ?", list.ID).Delete(&models.TodoListMember{}).Error; err != nil {
+			return fmt.Errorf("failed to delete members: %v", err)
+		}
+		if err := tx.Delete(list).Error; err != nil {
+			return fmt.Errorf("failed to delete todo list: %v", err)
+		}
+		return nil
+	})
+}
+
+// AddMember 添加成员，仅所有者可操作
+func (s *TodoListService) AddMember(listID, userID uint, req AddTodoListMemberRequest) (*models.TodoListMember, error) {
+	list, err := s.getOwnedList(listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	query := s.db
+	switch {
+	case req.UserID != 0:
+		query = query.Where("id = ?", req.UserID)
+	case req.Username != "":
+		query = query.Where("username = ?", req.Username)
+	default:
+		return nil, errors.New("user_id or username is required")
+	}
+	if err := query.First(&user).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var member models.TodoListMember
+	err = s.db.Where("list_id = ? AND user_id = ?", list.ID, user.ID).First(&member).Error
+	switch {
+	case err == nil:
+		if member.Role == models.TodoListRoleOwner {
+			return nil, errors.New("cannot change the owner's role")
+		}
+		member.Role = req.Role
+		if err := s.db.Save(&member).Error; err != nil {
+			return nil, fmt.Errorf("failed to update member: %v", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		member = models.TodoListMember{ListID: list.ID, UserID: user.ID, Role: req.Role}
+		if err := s.db.Create(&member).Error; err != nil {
+			return nil, fmt.Errorf("failed to add member: %v", err)
+		}
+
+		notificationManager := NewNotificationManager(s.db, s.logger)
+		if err := notificationManager.CreateSystemNotification(user.ID, "加入共享清单",
+			fmt.Sprintf("你已被加入清单「%s」", list.Name),
+			map[string]interface{}{"list_id": list.ID, "role": req.Role}); err != nil {
+			s.logger.Errorf("Failed to create list member notification: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get member: %v", err)
+	}
+
+	member.User = user
+	return &member, nil
+}
+
+// UpdateMemberRole 修改成员角色，仅所有者可操作
+func (s *TodoListService) UpdateMemberRole(listID, userID, memberUserID uint, req UpdateTodoListMemberRequest) error {
+	if _, err := s.getOwnedList(listID, userID); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.TodoListMember{}).
+		Where("list_id = ? AND user_id = ? AND role != ?", listID, memberUserID, models.TodoListRoleOwner).
+		Update("role", req.Role)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update member role: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("member not found")
+	}
+	return nil
+}
+
+// RemoveMember 移除成员：所有者可移除他人，成员可自行退出；所有者不能被移除
+func (s *TodoListService) RemoveMember(listID, userID, memberUserID uint) error {
+	if userID != memberUserID {
+		if _, err := s.getOwnedList(listID, userID); err != nil {
+			return err
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("list_id = ? AND user_id = ? AND role != ?", listID, memberUserID, models.TodoListRoleOwner).
+			Delete(&models.TodoListMember{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove member: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("member not found")
+		}
+
+		// 取消该成员在清单中被指派的他人任务，避免继续通过指派访问
+		return tx.Model(&models.Todo{}).
+			Where("list_id = ? AND assignee_id = ? AND created_by != ?", listID, memberUserID, memberUserID).
+			Update("assignee_id", nil).Error
+	})
+}
+
+// getOwnedList 获取用户作为所有者的清单
+func (s *TodoListService) getOwnedList(listID, userID uint) (*models.TodoList, error) {
+	role, err := getTodoListRole(s.db, listID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrTodoListNotFound
+	}
+	if role != models.TodoListRoleOwner {
+		return nil, ErrTodoListDenied
+	}
+
+	var list models.TodoList
+	if err := s.db.First(&list, listID).Error; err != nil {
+		return nil, ErrTodoListNotFound
+	}
+	return &list, nil
+}
+
+// getTodoListRole 获取用户在清单中的角色，非成员返回空字符串
+func getTodoListRole(db *gorm.DB, listID, userID uint) (string, error) {
+	var member models.TodoListMember
+	err := db.Joins("JOIN todo_lists ON todo_lists.id = todo_list_members.list_id AND todo_lists.deleted_at IS NULL").
+		Where("todo_list_members.list_id = ? AND todo_list_members.user_id = ?", listID, userID).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get list membership: %v", err)
+	}
+	return member.Role, nil
+}
+
+// canEditTodoList 判断角色是否可编辑清单中的任务
+func canEditTodoList(role string) bool {
+	return role == models.TodoListRoleOwner || role == models.TodoListRoleEditor
+}
+
+// visibleTodos 用户可见的任务：自己创建、指派给自己或所在清单中的任务
+func visibleTodos(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		memberLists := db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.TodoListMember{}).
+			Select("list_id").
+			Where("user_id = ?", userID)
+		return db.Where("todos.created_by = ? OR todos.assignee_id = ? OR todos.list_id IN (?)", userID, userID, memberLists)
+	}
+}
+
+// editableTodos 用户可编辑的任务：自己创建或所在清单中拥有编辑权限的任务，可以修改内容、指派和删除
+func editableTodos(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		editorLists := db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.TodoListMember{}).
+			Select("list_id").
+			Where("user_id = ? AND role IN ?", userID, []string{models.TodoListRoleOwner, models.TodoListRoleEditor})
+		return db.Where("todos.created_by = ? OR todos.list_id IN (?)", userID, editorLists)
+	}
+}
+
+// progressTodos 用户可更新进度的任务：可编辑的任务以及指派给自己的任务，负责人只能修改状态和工时
+func progressTodos(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		editorLists := db.Session(&gorm.Session{NewDB: true}).
+			Model(&models.TodoListMember{}).
+			Select("list_id").
+			Where("user_id = ? AND role IN ?", userID, []string{models.TodoListRoleOwner, models.TodoListRoleEditor})
+		return db.Where("todos.created_by = ? OR todos.assignee_id = ? OR todos.list_id IN (?)", userID, userID, editorLists)
+	}
+}
+
+// filterTodoIDs 从 ids 中筛选出满足权限范围 scope 的TODO ID
+func filterTodoIDs(db *gorm.DB, scope func(*gorm.DB) *gorm.DB, ids []uint) ([]uint, error) {
+	var allowed []uint
+	if err := db.Model(&models.Todo{}).Scopes(scope).
+		Where("todos.id IN ?", ids).Pluck("todos.id", &allowed).Error; err != nil {
+		return nil, fmt.Errorf("failed to get todos: %v", err)
+	}
+	return allowed, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"testing"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTodoTestService 创建使用内存 SQLite 的 TodoService，并准备两个用户
+func newTodoTestService(t *testing.T) (*TodoService, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(
+		&models.User{},
+		&models.TodoPriority{},
+		&models.TodoList{},
+		&models.TodoListMember{},
+		&models.Todo{},
+		&models.TodoDependency{},
+		&models.TodoTimeEntry{},
+	))
+	require.NoError(t, db.Create(&models.TodoPriority{Name: "中", Level: 2, Color: "#fff"}).Error)
+	require.NoError(t, db.Create(&models.User{Username: "alice", Email: "alice@example.com", Password: "x"}).Error)
+	require.NoError(t, db.Create(&models.User{Username: "bob", Email: "bob@example.com", Password: "x"}).Error)
+
+	return NewTodoService(db, logger.NewLogger(logger.DefaultLoggerConfig())), db
+}
+
+func TestTodoService_BatchOperationsIgnoreOtherUsersTodos(t *testing.T) {
+	const alice, bob uint = 1, 2
+
+	t.Run("BatchDelete", func(t *testing.T) {
+		svc, db := newTodoTestService(t)
+
+		parent, err := svc.CreateTodo(CreateTodoRequest{Title: "alice parent", PriorityID: 1}, alice)
+		require.NoError(t, err)
+		subtask, err := svc.CreateSubtask(parent.ID, alice, CreateTodoRequest{Title: "alice subtask", PriorityID: 1})
+		require.NoError(t, err)
+		other, err := svc.CreateTodo(CreateTodoRequest{Title: "alice other", PriorityID: 1}, alice)
+		require.NoError(t, err)
+		require.NoError(t, svc.AddDependency(other.ID, parent.ID, alice))
+		own, err := svc.CreateTodo(CreateTodoRequest{Title: "bob todo", PriorityID: 1}, bob)
+		require.NoError(t, err)
+
+		require.NoError(t, svc.BatchDelete([]uint{parent.ID, own.ID}, bob))
+
+		var remaining []uint
+		require.NoError(t, db.Model(&models.Todo{}).Order("id").Pluck("id", &remaining).Error)
+		assert.Equal(t, []uint{parent.ID, subtask.ID, other.ID}, remaining)
+		var deps int64
+		require.NoError(t, db.Model(&models.TodoDependency{}).Count(&deps).Error)
+		assert.EqualValues(t, 1, deps)
+
+		assert.Error(t, svc.BatchDelete([]uint{parent.ID}, bob))
+	})
+
+	t.Run("BatchUpdateStatus", func(t *testing.T) {
+		svc, db := newTodoTestService(t)
+
+		blocker, err := svc.CreateTodo(CreateTodoRequest{Title: "alice blocker", PriorityID: 1}, alice)
+		require.NoError(t, err)
+		blocked, err := svc.CreateTodo(CreateTodoRequest{Title: "alice blocked", PriorityID: 1}, alice)
+		require.NoError(t, err)
+		require.NoError(t, svc.AddDependency(blocked.ID, blocker.ID, alice))
+		own, err := svc.CreateTodo(CreateTodoRequest{Title: "bob todo", PriorityID: 1}, bob)
+		require.NoError(t, err)
+
+		// 他人被阻塞的TODO不影响批量完成自己的TODO
+		require.NoError(t, svc.BatchUpdateStatus([]uint{blocked.ID, own.ID}, bob, "completed"))
+
+		var todo models.Todo
+		require.NoError(t, db.First(&todo, own.ID).Error)
+		assert.Equal(t, "completed", todo.Status)
+		todo = models.Todo{}
+		require.NoError(t, db.First(&todo, blocked.ID).Error)
+		assert.NotEqual(t, "completed", todo.Status)
+
+		assert.ErrorIs(t, svc.BatchUpdateStatus([]uint{blocked.ID}, alice, "completed"), ErrTodoBlocked)
+	})
+}
+
+func TestTodoService_AssigneeCanOnlyUpdateProgress(t *testing.T) {
+	const alice, bob uint = 1, 2
+	svc, db := newTodoTestService(t)
+	lists := NewTodoListService(db, svc.logger)
+
+	list, err := lists.CreateList(alice, CreateTodoListRequest{Name: "team"})
+	require.NoError(t, err)
+	_, err = lists.AddMember(list.ID, alice, AddTodoListMemberRequest{UserID: bob, Role: models.TodoListRoleViewer})
+	require.NoError(t, err)
+	assignee := bob
+	todo, err := svc.CreateTodo(CreateTodoRequest{Title: "assigned", PriorityID: 1, ListID: &list.ID, AssigneeID: &assignee}, alice)
+	require.NoError(t, err)
+
+	// 负责人可以修改状态和工时
+	_, err = svc.UpdateTodo(todo.ID, bob, UpdateTodoRequest{Status: "in_progress", ActualHours: 2})
+	require.NoError(t, err)
+	require.NoError(t, svc.MarkCompleted(todo.ID, bob))
+	require.NoError(t, svc.BatchUpdateStatus([]uint{todo.ID}, bob, "pending"))
+
+	// 但不能修改内容、重新指派或删除
+	_, err = svc.UpdateTodo(todo.ID, bob, UpdateTodoRequest{Title: "renamed"})
+	assert.ErrorIs(t, err, ErrTodoAssigneeOnly)
+	unassign := uint(0)
+	_, err = svc.UpdateTodo(todo.ID, bob, UpdateTodoRequest{AssigneeID: &unassign})
+	assert.ErrorIs(t, err, ErrTodoAssigneeOnly)
+	assert.Error(t, svc.DeleteTodo(todo.ID, bob))
+	assert.Error(t, svc.BatchDelete([]uint{todo.ID}, bob))
+
+	var stored models.Todo
+	require.NoError(t, db.First(&stored, todo.ID).Error)
+	assert.Equal(t, "assigned", stored.Title)
+	assert.Equal(t, "pending", stored.Status)
+	require.NotNil(t, stored.AssigneeID)
+	assert.Equal(t, bob, *stored.AssigneeID)
+
+	// 清单所有者仍可完整编辑
+	_, err = svc.UpdateTodo(todo.ID, alice, UpdateTodoRequest{Title: "renamed"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteTodo(todo.ID, alice))
+}
+
+// newSharedParentFixture Alice 的共享清单中 Bob 为查看者、Carol 为编辑者；
+// Carol 在 Alice 的任务下创建了放在自己私有清单中的子任务和依赖任务
+func newSharedParentFixture(t *testing.T) (svc *TodoService, parent, secret *models.Todo) {
+	const alice, bob, carol uint = 1, 2, 3
+	svc, db := newTodoTestService(t)
+	require.NoError(t, db.Create(&models.User{Username: "carol", Email: "carol@example.com", Password: "x"}).Error)
+	lists := NewTodoListService(db, svc.logger)
+
+	shared, err := lists.CreateList(alice, CreateTodoListRequest{Name: "team"})
+	require.NoError(t, err)
+	_, err = lists.AddMember(shared.ID, alice, AddTodoListMemberRequest{UserID: bob, Role: models.TodoListRoleViewer})
+	require.NoError(t, err)
+	_, err = lists.AddMember(shared.ID, alice, AddTodoListMemberRequest{UserID: carol, Role: models.TodoListRoleEditor})
+	require.NoError(t, err)
+	private, err := lists.CreateList(carol, CreateTodoListRequest{Name: "carol only"})
+	require.NoError(t, err)
+
+	parent, err = svc.CreateTodo(CreateTodoRequest{Title: "shared parent", PriorityID: 1, ListID: &shared.ID}, alice)
+	require.NoError(t, err)
+	_, err = svc.CreateSubtask(parent.ID, alice, CreateTodoRequest{Title: "shared subtask", PriorityID: 1, ListID: &shared.ID})
+	require.NoError(t, err)
+	secret, err = svc.CreateSubtask(parent.ID, carol, CreateTodoRequest{Title: "secret", PriorityID: 1, ListID: &private.ID})
+	require.NoError(t, err)
+
+	_, err = svc.GetTodoByID(secret.ID, bob)
+	require.Error(t, err)
+	return svc, parent, secret
+}
+
+func TestTodoService_GetSubtasksHidesInvisibleSubtasks(t *testing.T) {
+	const alice, bob, carol uint = 1, 2, 3
+	svc, parent, secret := newSharedParentFixture(t)
+
+	result, err := svc.GetSubtasks(parent.ID, bob)
+	require.NoError(t, err)
+	require.Len(t, result.Subtasks, 1)
+	assert.Equal(t, "shared subtask", result.Subtasks[0].Title)
+	assert.EqualValues(t, 1, result.Progress.Total)
+
+	result, err = svc.GetSubtasks(parent.ID, carol)
+	require.NoError(t, err)
+	assert.Len(t, result.Subtasks, 2)
+
+	// 子任务进度统计同样不计入看不到的子任务
+	require.NoError(t, svc.MarkCompleted(secret.ID, carol))
+	progress, err := svc.getSubtaskProgress(alice)
+	require.NoError(t, err)
+	require.Len(t, progress, 1)
+	assert.EqualValues(t, 1, progress[0].Total)
+	assert.EqualValues(t, 0, progress[0].Completed)
+}
+
+func TestTodoService_GetDependenciesHidesInvisibleTodos(t *testing.T) {
+	const bob, carol uint = 2, 3
+	svc, parent, secret := newSharedParentFixture(t)
+
+	blocked, err := svc.CreateTodo(CreateTodoRequest{Title: "secret blocked", PriorityID: 1, ListID: secret.ListID}, carol)
+	require.NoError(t, err)
+	require.NoError(t, svc.AddDependency(parent.ID, secret.ID, carol))
+	require.NoError(t, svc.AddDependency(blocked.ID, parent.ID, carol))
+
+	deps, err := svc.GetDependencies(parent.ID, bob)
+	require.NoError(t, err)
+	assert.Empty(t, deps.BlockedBy)
+	assert.Empty(t, deps.Blocking)
+	assert.Zero(t, deps.OpenBlocker)
+
+	deps, err = svc.GetDependencies(parent.ID, carol)
+	require.NoError(t, err)
+	require.Len(t, deps.BlockedBy, 1)
+	assert.Equal(t, secret.ID, deps.BlockedBy[0].ID)
+	require.Len(t, deps.Blocking, 1)
+	assert.Equal(t, blocked.ID, deps.Blocking[0].ID)
+	assert.EqualValues(t, 1, deps.OpenBlocker)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+)
+
+// QuickAddRequest 快速添加请求
+type QuickAddRequest struct {
+	Text   string `json:"text" binding:"required,max=500"`
+	ListID *uint  `json:"list_id"`
+	Create bool   `json:"create"` // 为 true 时直接创建，否则只返回解析预览
+}
+
+// QuickAddPreview 快速添加解析预览，Todo 可以直接提交给创建接口
+type QuickAddPreview struct {
+	Input         string               `json:"input"`
+	Timezone      string               `json:"timezone"`
+	Parsed        *QuickAddParseResult `json:"parsed"`
+	Todo          CreateTodoRequest    `json:"todo"`
+	Priority      *models.TodoPriority `json:"priority,omitempty"`
+	Category      *models.Category     `json:"category,omitempty"`
+	UnmatchedTags []string             `json:"unmatched_tags"`
+	Created       *models.Todo         `json:"created,omitempty"`
+}
+
+// QuickAdd 解析自然语言文本生成任务预览，按用户时区解释日期时间；
+// req.Create 为 true 时同时创建任务
+func (s *TodoService) QuickAdd(userID uint, req QuickAddRequest) (*QuickAddPreview, error) {
+	loc := loadUserLocation(s.db, userID)
+	parsed := ParseQuickAdd(req.Text, time.Now().In(loc))
+	if parsed.Title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	preview := &QuickAddPreview{
+		Input:         req.Text,
+		Timezone:      loc.String(),
+		Parsed:        parsed,
+		UnmatchedTags: []string{},
+		Todo: CreateTodoRequest{
+			Title:          parsed.Title,
+			DueDate:        parsed.DueDate,
+			EstimatedHours: parsed.EstimatedHours,
+			ListID:         req.ListID,
+		},
+	}
+
+	// 未指定优先级时使用“中”
+	level := parsed.PriorityLevel
+	if level == 0 {
+		level = 2
+	}
+	var priority models.TodoPriority
+	if err := s.db.Where("level = ?", level).First(&priority).Error; err != nil {
+		if err := s.db.Order("level ASC").First(&priority).Error; err != nil {
+			return nil, ErrInvalidPriority
+		}
+	}
+	preview.Priority = &priority
+	preview.Todo.PriorityID = priority.ID
+
+	// 第一个与用户分类同名（不区分大小写）的标签作为分类
+	for _, tag := range parsed.Tags {
+		if preview.Category == nil {
+			var category models.Category
+			if err := s.db.Where("created_by = ? AND LOWER(name) = ?", userID, strings.ToLower(tag)).
+				First(&category).Error; err == nil {
+				preview.Category = &category
+				preview.Todo.CategoryID = &category.ID
+				continue
+			}
+		}
+		preview.UnmatchedTags = append(preview.UnmatchedTags, tag)
+	}
+
+	if req.Create {
+		todo, err := s.CreateTodo(preview.Todo, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create todo: %v", err)
+		}
+		preview.Created = todo
+	}
+
+	return preview, nil
+}
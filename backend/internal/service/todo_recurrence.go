@@ -0,0 +1,241 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SetRecurrenceRequest 设置重复规则请求，可直接提供RRULE或使用结构化字段
+type SetRecurrenceRequest struct {
+	RRule      string     `json:"rrule"`
+	Frequency  string     `json:"frequency"` // daily, weekly, monthly, yearly
+	Interval   int        `json:"interval"`
+	ByDay      []string   `json:"by_day"`       // MO, TU, ...
+	ByMonthDay []int      `json:"by_month_day"` // 1-31，负数表示倒数
+	Until      *time.Time `json:"until"`
+	Count      int        `json:"count"`
+}
+
+// ToRule 将请求转换为重复规则
+func (req SetRecurrenceRequest) ToRule() (*RecurrenceRule, error) {
+	if req.RRule != "" {
+		return ParseRRule(req.RRule)
+	}
+
+	rule := &RecurrenceRule{
+		Freq:       strings.ToUpper(req.Frequency),
+		Interval:   req.Interval,
+		ByDay:      req.ByDay,
+		ByMonthDay: req.ByMonthDay,
+		Until:      req.Until,
+		Count:      req.Count,
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// TodoRecurrenceDetail 重复系列详情
+type TodoRecurrenceDetail struct {
+	Recurrence     *models.TodoRecurrence `json:"recurrence"`
+	Rule           *RecurrenceRule        `json:"rule"`
+	NextOccurrence *time.Time             `json:"next_occurrence"`
+	Occurrences    []*models.Todo         `json:"occurrences"`
+}
+
+// GetRecurrence 获取TODO所属的重复系列
+func (s *TodoService) GetRecurrence(todoID, userID uint) (*TodoRecurrenceDetail, error) {
+	todo, err := s.GetTodoByID(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.RecurrenceID == nil {
+		return nil, errors.New("recurrence not found")
+	}
+
+	var recurrence models.TodoRecurrence
+	if err := s.db.First(&recurrence, *todo.RecurrenceID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("recurrence not found")
+		}
+		return nil, fmt.Errorf("failed to get recurrence: %v", err)
+	}
+
+	return s.buildRecurrenceDetail(todo, &recurrence)
+}
+
+// SetRecurrence 为TODO设置或修改重复规则，修改已停止的系列会重新启用
+func (s *TodoService) SetRecurrence(todoID, userID uint, req SetRecurrenceRequest) (*TodoRecurrenceDetail, error) {
+	rule, err := req.ToRule()
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.getEditableTodo(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var recurrence models.TodoRecurrence
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if todo.RecurrenceID != nil {
+			if err := tx.First(&recurrence, *todo.RecurrenceID).Error; err == nil {
+				recurrence.RRule = rule.String()
+				recurrence.IsActive = true
+				return tx.Save(&recurrence).Error
+			}
+		}
+
+		recurrence = models.TodoRecurrence{
+			RRule:           rule.String(),
+			OccurrenceCount: 1,
+			IsActive:        true,
+			CreatedBy:       userID,
+		}
+		if err := tx.Create(&recurrence).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Todo{}).Where("id = ?", todo.ID).Update("recurrence_id", recurrence.ID).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save recurrence: %v", err)
+	}
+
+	todo.RecurrenceID = &recurrence.ID
+	return s.buildRecurrenceDetail(todo, &recurrence)
+}
+
+// StopRecurrence 停止重复系列，已生成的实例保留
+func (s *TodoService) StopRecurrence(todoID, userID uint) error {
+	todo, err := s.getEditableTodo(todoID, userID)
+	if err != nil {
+		return err
+	}
+	if todo.RecurrenceID == nil {
+		return errors.New("recurrence not found")
+	}
+
+	if err := s.db.Model(&models.TodoRecurrence{}).
+		Where("id = ?", *todo.RecurrenceID).
+		Update("is_active", false).Error; err != nil {
+		return fmt.Errorf("failed to stop recurrence: %v", err)
+	}
+
+	return nil
+}
+
+// buildRecurrenceDetail 组装重复系列详情
+func (s *TodoService) buildRecurrenceDetail(todo *models.Todo, recurrence *models.TodoRecurrence) (*TodoRecurrenceDetail, error) {
+	rule, err := ParseRRule(recurrence.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &TodoRecurrenceDetail{
+		Recurrence: recurrence,
+		Rule:       rule,
+	}
+	if recurrence.IsActive && (rule.Count == 0 || recurrence.OccurrenceCount < rule.Count) {
+		if next, ok := rule.Next(recurrenceAnchor(todo)); ok {
+			detail.NextOccurrence = &next
+		}
+	}
+
+	if err := s.db.Where("recurrence_id = ?", recurrence.ID).
+		Preload("Priority").
+		Order("created_at ASC").
+		Find(&detail.Occurrences).Error; err != nil {
+		return nil, fmt.Errorf("failed to get occurrences: %v", err)
+	}
+
+	return detail, nil
+}
+
+// recurrenceAnchor 计算下一次实例的基准时间：截止时间 > 开始时间 > 完成时间
+func recurrenceAnchor(todo *models.Todo) time.Time {
+	switch {
+	case todo.DueDate != nil:
+		return *todo.DueDate
+	case todo.StartDate != nil:
+		return *todo.StartDate
+	case todo.CompletedAt != nil:
+		return *todo.CompletedAt
+	default:
+		return time.Now()
+	}
+}
+
+// spawnNextOccurrence 在已完成的重复TODO之后生成下一个实例，系列结束时自动停止
+func (s *TodoService) spawnNextOccurrence(tx *gorm.DB, todo *models.Todo) (*models.Todo, error) {
+	if todo.RecurrenceID == nil {
+		return nil, nil
+	}
+
+	var recurrence models.TodoRecurrence
+	if err := tx.Where("id = ? AND is_active = ?", *todo.RecurrenceID, true).First(&recurrence).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get recurrence: %v", err)
+	}
+
+	rule, err := ParseRRule(recurrence.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	anchor := recurrenceAnchor(todo)
+	next, ok := rule.Next(anchor)
+	// 逾期完成时跳过已错过的实例，直接生成下一个未来的实例
+	now := time.Now()
+	for i := 0; ok && next.Before(now) && i < maxRecurrenceIterations; i++ {
+		next, ok = rule.Next(next)
+	}
+
+	if !ok || (rule.Count > 0 && recurrence.OccurrenceCount >= rule.Count) {
+		if err := tx.Model(&recurrence).Update("is_active", false).Error; err != nil {
+			return nil, fmt.Errorf("failed to stop recurrence: %v", err)
+		}
+		return nil, nil
+	}
+
+	shift := next.Sub(anchor)
+	nextTodo := &models.Todo{
+		Title:          todo.Title,
+		Description:    todo.Description,
+		Status:         "pending",
+		PriorityID:     todo.PriorityID,
+		CategoryID:     todo.CategoryID,
+		EstimatedHours: todo.EstimatedHours,
+		RecurrenceID:   todo.RecurrenceID,
+		ParentID:       todo.ParentID,
+		ListID:         todo.ListID,
+		AssigneeID:     todo.AssigneeID,
+		CreatedBy:      todo.CreatedBy,
+	}
+	if todo.StartDate != nil {
+		startDate := todo.StartDate.Add(shift)
+		nextTodo.StartDate = &startDate
+	}
+	if todo.DueDate != nil || todo.StartDate == nil {
+		dueDate := next
+		nextTodo.DueDate = &dueDate
+	}
+
+	if err := tx.Create(nextTodo).Error; err != nil {
+		return nil, fmt.Errorf("failed to create next occurrence: %v", err)
+	}
+	if err := tx.Model(&recurrence).Update("occurrence_count", gorm.Expr("occurrence_count + 1")).Error; err != nil {
+		return nil, fmt.Errorf("failed to update recurrence: %v", err)
+	}
+	syncSearchIndex(tx, SearchDocTodo, nextTodo.ID)
+
+	return nextTodo, nil
+}
@@ -3,9 +3,11 @@ package service
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"gin-web-framework/internal/models"
+	"gin-web-framework/internal/search"
 	"gin-web-framework/pkg/logger"
 
 	"gorm.io/gorm"
@@ -32,6 +34,9 @@ type CreateTodoRequest struct {
 	StartDate      *time.Time `json:"start_date"`      // 开始时间
 	DueDate        *time.Time `json:"due_date"`        // 截止时间
 	EstimatedHours float64    `json:"estimated_hours"` // 预估工时
+	ParentID       *uint      `json:"parent_id"`       // 父任务，创建子任务时使用
+	ListID         *uint      `json:"list_id"`         // 所属共享清单
+	AssigneeID     *uint      `json:"assignee_id"`     // 负责人
 }
 
 // UpdateTodoRequest 更新TODO请求
@@ -45,6 +50,8 @@ type UpdateTodoRequest struct {
 	DueDate        *time.Time `json:"due_date"`        // 截止时间
 	EstimatedHours float64    `json:"estimated_hours"` // 预估工时
 	ActualHours    float64    `json:"actual_hours"`    // 实际工时
+	ListID         *uint      `json:"list_id"`         // 所属共享清单，0表示移出清单
+	AssigneeID     *uint      `json:"assignee_id"`     // 负责人，0表示取消指派
 }
 
 type TodoFilter struct {
@@ -53,7 +60,11 @@ type TodoFilter struct {
 	CategoryID *uint      `json:"category_id"`
 	DueDate    *time.Time `json:"due_date"`
 	Overdue    *bool      `json:"overdue"`
+	ListID     *uint      `json:"list_id"`
+	AssigneeID *uint      `json:"assignee_id"`
 	Search     string     `json:"search"`
+	Query      string     `json:"query"`   // 过滤表达式，见 ParseTodoQuery
+	ViewID     *uint      `json:"view_id"` // 保存的过滤器，与 Query 同时指定时两者都生效
 	Page       int        `json:"page"`
 	Limit      int        `json:"limit"`
 	SortBy     string     `json:"sort_by"`
@@ -71,6 +82,20 @@ type PaginatedTodos struct {
 // CreateTodo 创建TODO
 func (s *TodoService) CreateTodo(req CreateTodoRequest, userID uint) (*models.Todo, error) {
 
+	if req.ParentID != nil {
+		parent, err := s.getEditableTodo(*req.ParentID, userID)
+		if err != nil {
+			return nil, errors.New("parent todo not found")
+		}
+		// 子任务默认与父任务在同一清单
+		if req.ListID == nil {
+			req.ListID = parent.ListID
+		}
+	}
+	if err := s.validateTodoAssignment(req.ListID, req.AssigneeID, userID); err != nil {
+		return nil, err
+	}
+
 	todo := &models.Todo{
 		Title:          req.Title,
 		Description:    req.Description,
@@ -80,12 +105,23 @@ func (s *TodoService) CreateTodo(req CreateTodoRequest, userID uint) (*models.To
 		StartDate:      req.StartDate,
 		DueDate:        req.DueDate,
 		EstimatedHours: req.EstimatedHours,
+		ParentID:       req.ParentID,
+		ListID:         req.ListID,
+		AssigneeID:     req.AssigneeID,
 		CreatedBy:      userID,
 	}
 
 	if err := s.db.Create(todo).Error; err != nil {
 		return nil, fmt.Errorf("failed to create todo: %v", err)
 	}
+	syncSearchIndex(s.db, SearchDocTodo, todo.ID)
+
+	if todo.AssigneeID != nil && *todo.AssigneeID != userID {
+		notificationManager := NewNotificationManager(s.db, s.logger)
+		if err := notificationManager.CreateTaskAssignedNotification(todo, userID); err != nil {
+			s.logger.Errorf("Failed to create assignment notification: %v", err)
+		}
+	}
 
 	return todo, nil
 }
@@ -95,7 +131,7 @@ func (s *TodoService) GetTodoList(userID uint) ([]models.Todo, error) {
 
 	var todos []models.Todo
 	if err := s.db.Preload("Priority").Preload("Category").
-		Where("created_by = ?", userID).
+		Scopes(visibleTodos(userID)).
 		Order("created_at DESC").
 		Find(&todos).Error; err != nil {
 		return nil, fmt.Errorf("failed to get todos: %v", err)
@@ -108,9 +144,11 @@ func (s *TodoService) GetTodoList(userID uint) ([]models.Todo, error) {
 func (s *TodoService) GetTodoByID(id uint, userID uint) (*models.Todo, error) {
 
 	var todo models.Todo
-	err := s.db.Where("id = ? AND created_by = ?", id, userID).
+	err := s.db.Scopes(visibleTodos(userID)).
+		Where("todos.id = ?", id).
 		Preload("Priority").
 		Preload("Category").
+		Preload("Assignee").
 		First(&todo).Error
 
 	if err != nil {
@@ -123,12 +161,113 @@ func (s *TodoService) GetTodoByID(id uint, userID uint) (*models.Todo, error) {
 	return &todo, nil
 }
 
+// getEditableTodo 获取用户有编辑权限的TODO
+func (s *TodoService) getEditableTodo(id uint, userID uint) (*models.Todo, error) {
+
+	var todo models.Todo
+	if err := s.db.Scopes(editableTodos(userID)).Where("todos.id = ?", id).First(&todo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+
+	return &todo, nil
+}
+
+// getProgressTodo 获取用户可以更新状态和工时的TODO，包括指派给用户的TODO
+func (s *TodoService) getProgressTodo(id uint, userID uint) (*models.Todo, error) {
+
+	var todo models.Todo
+	if err := s.db.Scopes(progressTodos(userID)).Where("todos.id = ?", id).First(&todo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("todo not found")
+		}
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+
+	return &todo, nil
+}
+
+// canEditTodo 判断用户是否可以修改TODO的内容、指派或删除
+func (s *TodoService) canEditTodo(todoID, userID uint) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.Todo{}).Scopes(editableTodos(userID)).
+		Where("todos.id = ?", todoID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("database error: %v", err)
+	}
+	return count > 0, nil
+}
+
+// validateTodoAssignment 校验清单编辑权限以及负责人必须是清单成员
+func (s *TodoService) validateTodoAssignment(listID, assigneeID *uint, userID uint) error {
+	if listID != nil {
+		role, err := getTodoListRole(s.db, *listID, userID)
+		if err != nil {
+			return err
+		}
+		if !canEditTodoList(role) {
+			return ErrTodoListDenied
+		}
+	}
+
+	if assigneeID != nil && *assigneeID != userID {
+		if listID == nil {
+			return errors.New("todos outside a shared list can only be assigned to yourself")
+		}
+		role, err := getTodoListRole(s.db, *listID, *assigneeID)
+		if err != nil {
+			return err
+		}
+		if role == "" {
+			return errors.New("assignee is not a member of the todo list")
+		}
+	}
+
+	return nil
+}
+
 // UpdateTodo 更新TODO
 func (s *TodoService) UpdateTodo(todoID, userID uint, req UpdateTodoRequest) (*models.Todo, error) {
 
-	var todo models.Todo
-	if err := s.db.Where("id = ? AND created_by = ?", todoID, userID).First(&todo).Error; err != nil {
-		return nil, errors.New("todo not found")
+	existing, err := s.getProgressTodo(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// 仅是负责人时只能修改状态和实际工时
+	if req.Title != "" || req.Description != "" || req.PriorityID != 0 || req.CategoryID != nil ||
+		req.StartDate != nil || req.DueDate != nil || req.EstimatedHours > 0 ||
+		req.ListID != nil || req.AssigneeID != nil {
+		editable, err := s.canEditTodo(todoID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !editable {
+			return nil, ErrTodoAssigneeOnly
+		}
+	}
+	todo := *existing
+	previousAssignee := todo.AssigneeID
+
+	// 清单与负责人变更（传0表示移出清单/取消指派）
+	if req.ListID != nil {
+		if *req.ListID == 0 {
+			todo.ListID = nil
+		} else {
+			todo.ListID = req.ListID
+		}
+	}
+	if req.AssigneeID != nil {
+		if *req.AssigneeID == 0 {
+			todo.AssigneeID = nil
+		} else {
+			todo.AssigneeID = req.AssigneeID
+		}
+	}
+	if req.ListID != nil || req.AssigneeID != nil {
+		if err := s.validateTodoAssignment(todo.ListID, todo.AssigneeID, userID); err != nil {
+			return nil, err
+		}
 	}
 
 	// 更新字段
@@ -138,7 +277,14 @@ func (s *TodoService) UpdateTodo(todoID, userID uint, req UpdateTodoRequest) (*m
 	if req.Description != "" {
 		todo.Description = req.Description
 	}
+	justCompleted := false
 	if req.Status != "" {
+		justCompleted = req.Status == "completed" && todo.Status != "completed"
+		if justCompleted {
+			if err := ensureNotBlocked(s.db, todo.ID); err != nil {
+				return nil, err
+			}
+		}
 		todo.Status = req.Status
 		if req.Status == "completed" && todo.CompletedAt == nil {
 			now := time.Now()
@@ -167,50 +313,109 @@ func (s *TodoService) UpdateTodo(todoID, userID uint, req UpdateTodoRequest) (*m
 		todo.EstimatedHours = req.EstimatedHours
 	}
 	if req.ActualHours > 0 {
-		todo.ActualHours = req.ActualHours
+		// 已有计时记录时实际工时由记录汇总，不接受手动填写
+		tracked, err := hasTimeEntries(s.db, todo.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !tracked {
+			todo.ActualHours = req.ActualHours
+		}
 	}
 
-	if err := s.db.Save(&todo).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&todo).Error; err != nil {
+			return err
+		}
+		syncSearchIndex(tx, SearchDocTodo, todo.ID)
+		if justCompleted {
+			_, err := s.spawnNextOccurrence(tx, &todo)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update todo: %v", err)
 	}
 
+	// 指派给他人时通知新负责人
+	if todo.AssigneeID != nil && *todo.AssigneeID != userID &&
+		(previousAssignee == nil || *previousAssignee != *todo.AssigneeID) {
+		notificationManager := NewNotificationManager(s.db, s.logger)
+		if err := notificationManager.CreateTaskAssignedNotification(&todo, userID); err != nil {
+			s.logger.Errorf("Failed to create assignment notification: %v", err)
+		}
+	}
+
 	return &todo, nil
 }
 
 // DeleteTodo 删除TODO
 func (s *TodoService) DeleteTodo(todoID, userID uint) error {
 
-	result := s.db.Where("id = ? AND created_by = ?", todoID, userID).Delete(&models.Todo{})
-	if result.RowsAffected == 0 {
-		return errors.New("todo not found")
-	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Scopes(editableTodos(userID)).Where("todos.id = ?", todoID).Delete(&models.Todo{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete todo: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("todo not found")
+		}
 
-	return nil
+		return cleanupDeletedTodos(tx, []uint{todoID})
+	})
 }
 
 // BatchDelete 批量删除TODO（实现TodoServiceInterface接口）
 func (s *TodoService) BatchDelete(ids []uint, userID uint) error {
 
-	// 批量删除指定用户的TODO
-	result := s.db.Where("id IN ? AND created_by = ?", ids, userID).Delete(&models.Todo{})
-	if result.Error != nil {
-		return fmt.Errorf("failed to batch delete todos: %v", result.Error)
-	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// 只处理当前用户有权删除的TODO，子任务和依赖的清理也限定在这些ID内
+		allowed, err := filterTodoIDs(tx, editableTodos(userID), ids)
+		if err != nil {
+			return err
+		}
+		if len(allowed) == 0 {
+			return errors.New("no todos found to delete")
+		}
 
-	// 检查是否有记录被删除
-	if result.RowsAffected == 0 {
-		return errors.New("no todos found to delete")
-	}
+		if err := tx.Where("id IN ?", allowed).Delete(&models.Todo{}).Error; err != nil {
+			return fmt.Errorf("failed to batch delete todos: %v", err)
+		}
 
-	return nil
+		return cleanupDeletedTodos(tx, allowed)
+	})
 }
 
 // BatchUpdateStatus 批量更新TODO状态（实现TodoServiceInterface接口）
 func (s *TodoService) BatchUpdateStatus(ids []uint, userID uint, status string) error {
 
+	// 只处理当前用户有权修改状态的TODO，无权修改的ID不参与阻塞检查
+	ids, err := filterTodoIDs(s.db, progressTodos(userID), ids)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return errors.New("no todos found to update")
+	}
+
+	// 记录本次由未完成变为完成的重复TODO，更新后为其生成下一个实例
+	var recurring []models.Todo
+	if status == "completed" {
+		if err := ensureNotBlocked(s.db, ids...); err != nil {
+			return err
+		}
+		if err := s.db.Scopes(progressTodos(userID)).
+			Where("todos.id IN ? AND status != ? AND recurrence_id IS NOT NULL", ids, "completed").
+			Find(&recurring).Error; err != nil {
+			return fmt.Errorf("failed to get recurring todos: %v", err)
+		}
+	}
+
 	// 批量更新指定用户的TODO状态
 	result := s.db.Model(&models.Todo{}).
-		Where("id IN ? AND created_by = ?", ids, userID).
+		Scopes(progressTodos(userID)).
+		Where("todos.id IN ?", ids).
 		Update("status", status)
 
 	if result.Error != nil {
@@ -226,8 +431,17 @@ func (s *TodoService) BatchUpdateStatus(ids []uint, userID uint, status string)
 	if status == "completed" {
 		now := time.Now()
 		s.db.Model(&models.Todo{}).
-			Where("id IN ? AND created_by = ?", ids, userID).
+			Scopes(progressTodos(userID)).
+			Where("todos.id IN ?", ids).
 			Update("completed_at", now)
+
+		for i := range recurring {
+			recurring[i].Status = "completed"
+			recurring[i].CompletedAt = &now
+			if _, err := s.spawnNextOccurrence(s.db, &recurring[i]); err != nil {
+				s.logger.Errorf("Failed to spawn next occurrence for todo %d: %v", recurring[i].ID, err)
+			}
+		}
 	}
 
 	return nil
@@ -239,7 +453,8 @@ func (s *TodoService) GetOverdueTodos(userID uint) ([]*models.Todo, error) {
 	var todos []*models.Todo
 	now := time.Now()
 
-	err := s.db.Where("created_by = ? AND due_date < ? AND status != 'completed'", userID, now).
+	err := s.db.Scopes(visibleTodos(userID)).
+		Where("due_date < ? AND status != 'completed'", now).
 		Preload("Priority").
 		Preload("Category").
 		Order("due_date ASC").
@@ -301,35 +516,89 @@ func (s *TodoService) GetTodoStats(userID uint) (map[string]interface{}, error)
 		stats["completion_rate"] = 0.0
 	}
 
+	// 子任务进度汇总到父任务
+	subtaskProgress, err := s.getSubtaskProgress(userID)
+	if err != nil {
+		return nil, err
+	}
+	stats["subtask_progress"] = subtaskProgress
+
+	// 统计被未完成前置任务阻塞的任务
+	var blocked int64
+	if err := s.db.Model(&models.Todo{}).
+		Where("created_by = ? AND status NOT IN ?", userID, []string{"completed", "cancelled"}).
+		Where("EXISTS (SELECT 1 FROM todo_dependencies d JOIN todos b ON b.id = d.blocked_by_id "+
+			"WHERE d.todo_id = todos.id AND b.deleted_at IS NULL AND b.status NOT IN ?)", []string{"completed", "cancelled"}).
+		Count(&blocked).Error; err != nil {
+		return nil, fmt.Errorf("failed to count blocked todos: %v", err)
+	}
+	stats["blocked"] = blocked
+
 	return stats, nil
 }
 
 // GetTodos 获取TODO列表（实现TodoServiceInterface接口）
 func (s *TodoService) GetTodos(userID uint, filter TodoFilter) (*PaginatedTodos, error) {
 
-	query := s.db.Where("created_by = ?", userID)
+	query := s.db.Scopes(visibleTodos(userID))
+
+	// 保存的过滤器与过滤表达式
+	expressions := []string{filter.Query}
+	if filter.ViewID != nil {
+		var view models.SavedFilter
+		if err := s.db.Where("id = ? AND user_id = ?", *filter.ViewID, userID).First(&view).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrSavedFilterNotFound
+			}
+			return nil, fmt.Errorf("failed to get saved filter: %v", err)
+		}
+		expressions = append(expressions, view.Query)
+	}
+	now := time.Now()
+	for _, expr := range expressions {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		parsed, err := ParseTodoQuery(expr)
+		if err != nil {
+			return nil, err
+		}
+		if query, err = applyTodoQuery(s.db, query, parsed, userID, now); err != nil {
+			return nil, err
+		}
+		filter.Search = strings.TrimSpace(filter.Search + " " + parsed.Text)
+	}
 
 	// 应用过滤器
+	if filter.ListID != nil {
+		query = query.Where("todos.list_id = ?", *filter.ListID)
+	}
+	if filter.AssigneeID != nil {
+		query = query.Where("todos.assignee_id = ?", *filter.AssigneeID)
+	}
 	if filter.Status != "" {
-		query = query.Where("status = ?", filter.Status)
+		query = query.Where("todos.status = ?", filter.Status)
 	}
 	if filter.Priority != "" {
-		query = query.Joins("JOIN priorities ON todos.priority_id = priorities.id").
-			Where("priorities.name = ?", filter.Priority)
+		query = query.Joins("JOIN todo_priorities ON todos.priority_id = todo_priorities.id").
+			Where("todo_priorities.name = ?", filter.Priority)
 	}
 	if filter.CategoryID != nil {
-		query = query.Where("category_id = ?", *filter.CategoryID)
+		query = query.Where("todos.category_id = ?", *filter.CategoryID)
 	}
 	if filter.DueDate != nil {
-		query = query.Where("due_date <= ?", filter.DueDate)
+		query = query.Where("todos.due_date <= ?", filter.DueDate)
 	}
 	if filter.Overdue != nil && *filter.Overdue {
-		now := time.Now()
-		query = query.Where("due_date < ? AND status != ?", now, "completed")
+		query = query.Where("todos.due_date < ? AND todos.status != ?", now, "completed")
 	}
 	if filter.Search != "" {
-		searchTerm := "%" + filter.Search + "%"
-		query = query.Where("title LIKE ? OR description LIKE ?", searchTerm, searchTerm)
+		// 启用全文索引时按相关度排序并返回高亮摘要
+		if idx, ok := search.FromDB(s.db); ok {
+			return s.searchTodosRanked(idx, query, filter)
+		}
+		searchTerm := "%" + strings.ReplaceAll(filter.Search, `"`, "") + "%"
+		query = query.Where("todos.title LIKE ? OR todos.description LIKE ?", searchTerm, searchTerm)
 	}
 
 	// 获取总数
@@ -348,7 +617,7 @@ func (s *TodoService) GetTodos(userID uint, filter TodoFilter) (*PaginatedTodos,
 		}
 		query = query.Order(order)
 	} else {
-		query = query.Order("created_at DESC")
+		query = query.Order("todos.created_at DESC")
 	}
 
 	// 应用分页
@@ -363,7 +632,7 @@ func (s *TodoService) GetTodos(userID uint, filter TodoFilter) (*PaginatedTodos,
 
 	// 获取数据
 	var todos []*models.Todo
-	if err := query.Preload("Priority").Preload("Category").Find(&todos).Error; err != nil {
+	if err := query.Preload("Priority").Preload("Category").Preload("Assignee").Find(&todos).Error; err != nil {
 		return nil, fmt.Errorf("failed to get todos: %v", err)
 	}
 
@@ -382,7 +651,8 @@ func (s *TodoService) GetTodos(userID uint, filter TodoFilter) (*PaginatedTodos,
 func (s *TodoService) GetTodosByCategory(userID uint, categoryID uint) ([]*models.Todo, error) {
 
 	var todos []*models.Todo
-	err := s.db.Where("created_by = ? AND category_id = ?", userID, categoryID).
+	err := s.db.Scopes(visibleTodos(userID)).
+		Where("category_id = ?", categoryID).
 		Preload("Priority").
 		Preload("Category").
 		Order("created_at DESC").
@@ -399,8 +669,9 @@ func (s *TodoService) GetTodosByCategory(userID uint, categoryID uint) ([]*model
 func (s *TodoService) GetTodosByPriority(userID uint, priority string) ([]*models.Todo, error) {
 
 	var todos []*models.Todo
-	err := s.db.Joins("JOIN priorities ON todos.priority_id = priorities.id").
-		Where("todos.created_by = ? AND priorities.name = ?", userID, priority).
+	err := s.db.Joins("JOIN todo_priorities ON todos.priority_id = todo_priorities.id").
+		Scopes(visibleTodos(userID)).
+		Where("todo_priorities.name = ?", priority).
 		Preload("Priority").
 		Preload("Category").
 		Order("todos.created_at DESC").
@@ -414,22 +685,50 @@ func (s *TodoService) GetTodosByPriority(userID uint, priority string) ([]*model
 }
 
 // MarkCompleted 标记为已完成（实现TodoServiceInterface接口）
+// 对于重复TODO，完成当前实例后会生成下一个实例
 func (s *TodoService) MarkCompleted(id uint, userID uint) error {
 
+	todo, err := s.getProgressTodo(id, userID)
+	if err != nil {
+		return err
+	}
+	if todo.Status == "completed" {
+		return nil
+	}
+	if err := ensureNotBlocked(s.db, todo.ID); err != nil {
+		return err
+	}
+
 	now := time.Now()
-	result := s.db.Model(&models.Todo{}).
-		Where("id = ? AND created_by = ?", id, userID).
-		Updates(map[string]interface{}{
-			"status":       "completed",
-			"completed_at": now,
-		})
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 带状态条件更新，避免并发完成时重复生成下一个实例
+		result := tx.Model(&models.Todo{}).
+			Where("id = ? AND status != ?", todo.ID, "completed").
+			Updates(map[string]interface{}{
+				"status":       "completed",
+				"completed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
 
-	if result.Error != nil {
-		return fmt.Errorf("failed to mark todo as completed: %v", result.Error)
+		todo.Status = "completed"
+		todo.CompletedAt = &now
+		_, err := s.spawnNextOccurrence(tx, todo)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark todo as completed: %v", err)
 	}
 
-	if result.RowsAffected == 0 {
-		return errors.New("todo not found")
+	if todo.Status == "completed" {
+		notificationManager := NewNotificationManager(s.db, s.logger)
+		if err := notificationManager.CreateTaskCompletedNotification(todo); err != nil {
+			s.logger.Errorf("Failed to create completion notification: %v", err)
+		}
 	}
 
 	return nil
@@ -439,7 +738,8 @@ func (s *TodoService) MarkCompleted(id uint, userID uint) error {
 func (s *TodoService) MarkInProgress(id uint, userID uint) error {
 
 	result := s.db.Model(&models.Todo{}).
-		Where("id = ? AND created_by = ?", id, userID).
+		Scopes(progressTodos(userID)).
+		Where("todos.id = ?", id).
 		Update("status", "in_progress")
 
 	if result.Error != nil {
@@ -457,7 +757,8 @@ func (s *TodoService) MarkInProgress(id uint, userID uint) error {
 func (s *TodoService) MarkCancelled(id uint, userID uint) error {
 
 	result := s.db.Model(&models.Todo{}).
-		Where("id = ? AND created_by = ?", id, userID).
+		Scopes(progressTodos(userID)).
+		Where("todos.id = ?", id).
 		Update("status", "cancelled")
 
 	if result.Error != nil {
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gin-web-framework/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxTimeEntryDuration 单条计时记录的最长时长
+const maxTimeEntryDuration = 24 * time.Hour
+
+// StartTimerRequest 开始计时请求
+type StartTimerRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// ManualTimeEntryRequest 手动补录计时请求
+type ManualTimeEntryRequest struct {
+	StartedAt time.Time `json:"started_at" binding:"required"`
+	EndedAt   time.Time `json:"ended_at" binding:"required"`
+	Note      string    `json:"note" binding:"max=500"`
+}
+
+// StartTimerResult 开始计时结果，Stopped 为被自动停止的上一个计时
+type StartTimerResult struct {
+	Entry   *models.TodoTimeEntry `json:"entry"`
+	Stopped *models.TodoTimeEntry `json:"stopped,omitempty"`
+}
+
+// TodoTimeEntries TODO计时记录汇总
+type TodoTimeEntries struct {
+	Entries        []*models.TodoTimeEntry `json:"entries"`
+	TotalSeconds   int64                   `json:"total_seconds"`
+	EstimatedHours float64                 `json:"estimated_hours"`
+	ActualHours    float64                 `json:"actual_hours"`
+}
+
+// StartTimer 开始计时。每个用户同时只有一个计时，已有计时会先被停止
+func (s *TodoService) StartTimer(todoID, userID uint, req StartTimerRequest) (*StartTimerResult, error) {
+	todo, err := s.getProgressTodo(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StartTimerResult{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定用户行，串行化同一用户的并发开始计时
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		stopped, err := stopRunningTimer(tx, userID, now)
+		if err != nil && !errors.Is(err, ErrNoRunningTimer) {
+			return err
+		}
+		result.Stopped = stopped
+
+		entry := &models.TodoTimeEntry{
+			TodoID:    todo.ID,
+			UserID:    userID,
+			StartedAt: now,
+			Note:      req.Note,
+		}
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		result.Entry = entry
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start timer: %v", err)
+	}
+
+	return result, nil
+}
+
+// StopTimer 停止当前用户正在进行的计时
+func (s *TodoService) StopTimer(userID uint) (*models.TodoTimeEntry, error) {
+	var stopped *models.TodoTimeEntry
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		stopped, err = stopRunningTimer(tx, userID, time.Now())
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrNoRunningTimer) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to stop timer: %v", err)
+	}
+
+	return stopped, nil
+}
+
+// GetRunningTimer 获取当前用户正在进行的计时，Duration 为截至目前的时长
+func (s *TodoService) GetRunningTimer(userID uint) (*models.TodoTimeEntry, error) {
+	var entry models.TodoTimeEntry
+	if err := s.db.Where("user_id = ? AND ended_at IS NULL", userID).
+		Preload("Todo").
+		First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoRunningTimer
+		}
+		return nil, fmt.Errorf("failed to get running timer: %v", err)
+	}
+
+	entry.Duration = int64(time.Since(entry.StartedAt).Seconds())
+	return &entry, nil
+}
+
+// AddTimeEntry 手动补录计时
+func (s *TodoService) AddTimeEntry(todoID, userID uint, req ManualTimeEntryRequest) (*models.TodoTimeEntry, error) {
+	if !req.EndedAt.After(req.StartedAt) || req.EndedAt.After(time.Now()) ||
+		req.EndedAt.Sub(req.StartedAt) > maxTimeEntryDuration {
+		return nil, ErrInvalidTimeRange
+	}
+
+	todo, err := s.getProgressTodo(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	endedAt := req.EndedAt
+	entry := &models.TodoTimeEntry{
+		TodoID:    todo.ID,
+		UserID:    userID,
+		StartedAt: req.StartedAt,
+		EndedAt:   &endedAt,
+		Duration:  int64(endedAt.Sub(req.StartedAt).Seconds()),
+		Manual:    true,
+		Note:      req.Note,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return recomputeActualHours(tx, todo.ID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add time entry: %v", err)
+	}
+
+	return entry, nil
+}
+
+// GetTimeEntries 获取TODO的计时记录（包含所有协作者的记录）
+func (s *TodoService) GetTimeEntries(todoID, userID uint) (*TodoTimeEntries, error) {
+	todo, err := s.GetTodoByID(todoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TodoTimeEntries{
+		EstimatedHours: todo.EstimatedHours,
+		ActualHours:    todo.ActualHours,
+	}
+	if err := s.db.Where("todo_id = ?", todo.ID).
+		Order("started_at DESC").
+		Find(&result.Entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to get time entries: %v", err)
+	}
+
+	for _, entry := range result.Entries {
+		if entry.EndedAt == nil {
+			entry.Duration = int64(time.Since(entry.StartedAt).Seconds())
+		}
+		result.TotalSeconds += entry.Duration
+	}
+
+	return result, nil
+}
+
+// DeleteTimeEntry 删除自己的计时记录
+func (s *TodoService) DeleteTimeEntry(todoID, entryID, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND todo_id = ? AND user_id = ?", entryID, todoID, userID).
+			Delete(&models.TodoTimeEntry{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete time entry: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("time entry not found")
+		}
+		return recomputeActualHours(tx, todoID)
+	})
+}
+
+// stopRunningTimer 停止用户正在进行的计时并更新对应TODO的实际工时
+func stopRunningTimer(tx *gorm.DB, userID uint, now time.Time) (*models.TodoTimeEntry, error) {
+	var entry models.TodoTimeEntry
+	if err := tx.Where("user_id = ? AND ended_at IS NULL", userID).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoRunningTimer
+		}
+		return nil, err
+	}
+
+	if err := finishTimeEntry(tx, &entry, now); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// finishTimeEntry 结束计时记录，超过最长时长的部分不计入
+func finishTimeEntry(tx *gorm.DB, entry *models.TodoTimeEntry, now time.Time) error {
+	if limit := entry.StartedAt.Add(maxTimeEntryDuration); now.After(limit) {
+		now = limit
+	}
+	entry.EndedAt = &now
+	entry.Duration = int64(now.Sub(entry.StartedAt).Seconds())
+
+	if err := tx.Model(entry).Updates(map[string]interface{}{
+		"ended_at": entry.EndedAt,
+		"duration": entry.Duration,
+	}).Error; err != nil {
+		return err
+	}
+	return recomputeActualHours(tx, entry.TodoID)
+}
+
+// recomputeActualHours 按已结束的计时记录汇总TODO的实际工时
+func recomputeActualHours(tx *gorm.DB, todoID uint) error {
+	var seconds int64
+	if err := tx.Model(&models.TodoTimeEntry{}).
+		Where("todo_id = ? AND ended_at IS NOT NULL", todoID).
+		Select("COALESCE(SUM(duration), 0)").
+		Scan(&seconds).Error; err != nil {
+		return fmt.Errorf("failed to sum time entries: %v", err)
+	}
+
+	hours := math.Round(float64(seconds)/36) / 100
+	return tx.Model(&models.Todo{}).Where("id = ?", todoID).Update("actual_hours", hours).Error
+}
+
+// stopTimersForTodos 删除TODO时停止其上正在进行的计时
+func stopTimersForTodos(tx *gorm.DB, todoIDs []uint) error {
+	var entries []models.TodoTimeEntry
+	if err := tx.Where("todo_id IN ? AND ended_at IS NULL", todoIDs).Find(&entries).Error; err != nil {
+		return fmt.Errorf("failed to get running timers: %v", err)
+	}
+
+	now := time.Now()
+	for i := range entries {
+		if err := finishTimeEntry(tx, &entries[i], now); err != nil {
+			return fmt.Errorf("failed to stop timer: %v", err)
+		}
+	}
+	return nil
+}
+
+// hasTimeEntries 判断TODO是否已有计时记录
+func hasTimeEntries(db *gorm.DB, todoID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.TodoTimeEntry{}).Where("todo_id = ?", todoID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count time entries: %v", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/auth"
+	"gin-web-framework/pkg/jwt"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used, please log in again")
+)
+
+// TokenPair 登录或刷新后签发的令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // 访问令牌的有效秒数
+	SessionID    string
+}
+
+// TokenService 访问令牌和刷新令牌的签发、轮换与吊销。
+// 刷新令牌是服务端保存哈希的不透明随机值，每次刷新轮换；已轮换的令牌再次出现说明被盗用，
+// 此时吊销整个令牌族。吊销时把仍有效的访问令牌 jti 写入吊销列表，由认证中间件拒绝
+type TokenService struct {
+	db         *gorm.DB
+	refreshTTL time.Duration
+	logger     logger.LoggerInterface
+	now        func() time.Time
+}
+
+// NewTokenService 创建令牌服务
+func NewTokenService(db *gorm.DB, refreshTTL time.Duration, logger logger.LoggerInterface) *TokenService {
+	return &TokenService{
+		db:         db,
+		refreshTTL: refreshTTL,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// IssueTokens 登录成功后开启新会话并签发令牌
+func (s *TokenService) IssueTokens(user *models.User) (*TokenPair, error) {
+	familyID, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(s.db, user, familyID)
+}
+
+// Refresh 用刷新令牌换取新的访问令牌和刷新令牌，旧刷新令牌随即失效
+func (s *TokenService) Refresh(refreshToken string) (*TokenPair, *models.User, error) {
+	var (
+		pair   *TokenPair
+		user   models.User
+		reused *models.RefreshToken
+	)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", auth.HashToken(refreshToken)).
+			First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to get refresh token: %v", err)
+		}
+
+		now := s.now()
+		if current.RevokedAt != nil || !current.ExpiresAt.After(now) {
+			return ErrInvalidRefreshToken
+		}
+		if current.RotatedAt != nil {
+			// 已轮换过的令牌被再次使用：攻击者和合法用户至少有一方持有被盗的令牌，吊销整个会话
+			reused = &current
+			return s.revoke(tx, now, "family_id = ?", current.FamilyID)
+		}
+
+		if err := tx.First(&user, current.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("failed to get user: %v", err)
+		}
+		if err := tx.Model(&current).Update("rotated_at", now).Error; err != nil {
+			return fmt.Errorf("failed to rotate refresh token: %v", err)
+		}
+
+		var err error
+		pair, err = s.issue(tx, &user, current.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if reused != nil {
+		s.logger.WithFields(map[string]any{"user_id": reused.UserID, "session_id": reused.FamilyID}).
+			Warn("Refresh token reuse detected, session revoked")
+		return nil, nil, ErrRefreshTokenReused
+	}
+	return pair, &user, nil
+}
+
+// Logout 退出当前会话：吊销当前访问令牌和会话内的所有刷新令牌
+func (s *TokenService) Logout(claims *jwt.Claims) error {
+	now := s.now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if claims.ID != "" && claims.ExpiresAt != nil {
+			if err := s.denyAccessTokens(tx, []models.RevokedToken{{
+				TokenID:   claims.ID,
+				UserID:    claims.UserID,
+				ExpiresAt: claims.ExpiresAt.Time,
+			}}); err != nil {
+				return err
+			}
+		}
+		if claims.SessionID == "" {
+			return nil
+		}
+		return s.revoke(tx, now, "user_id = ? AND family_id = ?", claims.UserID, claims.SessionID)
+	})
+}
+
+// LogoutAll 退出所有设备：吊销用户的所有会话及其访问令牌
+func (s *TokenService) LogoutAll(userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.revoke(tx, s.now(), "user_id = ?", userID)
+	})
+}
+
+// LogoutOthers 退出除指定会话外的所有设备，keepSessionID 为空时吊销全部会话
+func (s *TokenService) LogoutOthers(userID uint, keepSessionID string) error {
+	if keepSessionID == "" {
+		return s.LogoutAll(userID)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.revoke(tx, s.now(), "user_id = ? AND family_id <> ?", userID, keepSessionID)
+	})
+}
+
+// RevokeSession 吊销用户的指定会话，该会话的设备需要重新登录
+func (s *TokenService) RevokeSession(userID uint, sessionID string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return s.revoke(tx, s.now(), "user_id = ? AND family_id = ?", userID, sessionID)
+	})
+}
+
+// IsRevoked 访问令牌是否在吊销列表中，实现 jwt.Denylist
+func (s *TokenService) IsRevoked(jti string) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.RevokedToken{}).Where("token_id = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CleanupExpired 删除已过期的刷新令牌和吊销记录，过期的令牌本身已无法使用
+func (s *TokenService) CleanupExpired(now time.Time) (int64, error) {
+	refresh := s.db.Where("expires_at < ?", now).Delete(&models.RefreshToken{})
+	if refresh.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired refresh tokens: %v", refresh.Error)
+	}
+	revoked := s.db.Where("expires_at < ?", now).Delete(&models.RevokedToken{})
+	if revoked.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired revoked tokens: %v", revoked.Error)
+	}
+	return refresh.RowsAffected + revoked.RowsAffected, nil
+}
+
+// issue 在会话内签发一对新令牌
+func (s *TokenService) issue(db *gorm.DB, user *models.User, familyID string) (*TokenPair, error) {
+	accessToken, claims, err := jwt.GenerateToken(user.ID, user.Username, user.Email, user.Role, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %v", err)
+	}
+	refreshToken, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.RefreshToken{
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       hash,
+		AccessTokenID:   claims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		ExpiresAt:       s.now().Add(s.refreshTTL),
+	}
+	if err := db.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %v", err)
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(claims.ExpiresAt.Sub(claims.IssuedAt.Time).Seconds()),
+		SessionID:    familyID,
+	}, nil
+}
+
+// revoke 吊销符合条件且尚未吊销的刷新令牌，并把对应的未过期访问令牌加入吊销列表
+func (s *TokenService) revoke(tx *gorm.DB, now time.Time, query string, args ...interface{}) error {
+	var tokens []models.RefreshToken
+	if err := tx.Where(query, args...).Where("revoked_at IS NULL").Find(&tokens).Error; err != nil {
+		return fmt.Errorf("failed to get refresh tokens: %v", err)
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(tokens))
+	var denied []models.RevokedToken
+	for _, token := range tokens {
+		ids = append(ids, token.ID)
+		if token.AccessTokenID != "" && token.AccessExpiresAt.After(now) {
+			denied = append(denied, models.RevokedToken{
+				TokenID:   token.AccessTokenID,
+				UserID:    token.UserID,
+				ExpiresAt: token.AccessExpiresAt,
+			})
+		}
+	}
+	if err := s.denyAccessTokens(tx, denied); err != nil {
+		return err
+	}
+	if err := tx.Model(&models.RefreshToken{}).Where("id IN ?", ids).Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+	return nil
+}
+
+func (s *TokenService) denyAccessTokens(tx *gorm.DB, tokens []models.RevokedToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tokens).Error; err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %v", err)
+	}
+	return nil
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
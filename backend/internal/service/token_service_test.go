@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gin-web-framework/config"
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/jwt"
+	"gin-web-framework/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenTestService(t *testing.T) (*TokenService, *models.User) {
+	require.NoError(t, config.Load())
+	db := openTestDB(t, &models.User{}, &models.RefreshToken{}, &models.RevokedToken{})
+	user := &models.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	require.NoError(t, db.Create(user).Error)
+	return NewTokenService(db, time.Hour, logger.NewLogger(logger.DefaultLoggerConfig())), user
+}
+
+func TestTokenService_RefreshRotatesToken(t *testing.T) {
+	tokens, user := newTokenTestService(t)
+	first, err := tokens.IssueTokens(user)
+	require.NoError(t, err)
+
+	second, refreshed, err := tokens.Refresh(first.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, refreshed.ID)
+	assert.Equal(t, first.SessionID, second.SessionID)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+	assert.NotEqual(t, first.AccessToken, second.AccessToken)
+
+	// 轮换后的新令牌可以继续刷新
+	third, _, err := tokens.Refresh(second.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, first.SessionID, third.SessionID)
+}
+
+func TestTokenService_ReusedRefreshTokenRevokesFamily(t *testing.T) {
+	tokens, user := newTokenTestService(t)
+	first, err := tokens.IssueTokens(user)
+	require.NoError(t, err)
+	other, err := tokens.IssueTokens(user)
+	require.NoError(t, err)
+	second, _, err := tokens.Refresh(first.RefreshToken)
+	require.NoError(t, err)
+
+	_, _, err = tokens.Refresh(first.RefreshToken)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	// 同一会话内最新的令牌和访问令牌都被吊销
+	_, _, err = tokens.Refresh(second.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	claims, err := jwt.ParseToken(second.AccessToken)
+	require.NoError(t, err)
+	revoked, err := tokens.IsRevoked(claims.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// 其它会话不受影响
+	_, _, err = tokens.Refresh(other.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestTokenService_ExpiredFamilyCannotRefresh(t *testing.T) {
+	tokens, user := newTokenTestService(t)
+	pair, err := tokens.IssueTokens(user)
+	require.NoError(t, err)
+
+	now := time.Now().Add(time.Hour + time.Minute)
+	tokens.now = func() time.Time { return now }
+	_, _, err = tokens.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	removed, err := tokens.CleanupExpired(now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, removed)
+}
+
+func TestTokenService_DenylistedAccessTokenRejected(t *testing.T) {
+	tokens, user := newTokenTestService(t)
+	jwt.SetDenylist(tokens)
+	t.Cleanup(func() { jwt.SetDenylist(nil) })
+
+	pair, err := tokens.IssueTokens(user)
+	require.NoError(t, err)
+	claims, err := jwt.ValidateToken(pair.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, tokens.Logout(claims))
+	_, err = jwt.ValidateToken(pair.AccessToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenRevoked)
+	_, _, err = tokens.Refresh(pair.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
@@ -0,0 +1,510 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/auth"
+	"gin-web-framework/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	recoveryCodeCount         = 10
+	loginChallengeTTL         = 5 * time.Minute
+	loginChallengeMaxAttempts = 5
+	totpSkew                  = 1 // 允许前后各一个时间步的时钟偏差
+)
+
+var (
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotSetUp       = errors.New("two-factor authentication has not been set up")
+	ErrInvalidTwoFactorCode    = errors.New("invalid verification code")
+	ErrInvalidLoginChallenge   = errors.New("login challenge is invalid or expired, please log in again")
+)
+
+// TwoFactorSetup 开始启用两步验证时返回的密钥，ProvisioningURI 即二维码内容
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorCodeRequest 提交身份验证器中的验证码
+type TwoFactorCodeRequest struct {
+	Code   string     `json:"code" binding:"required"`
+	Client ClientInfo `json:"-"`
+}
+
+// DisableTwoFactorRequest 关闭两步验证，需要密码和验证码（或恢复码）
+type DisableTwoFactorRequest struct {
+	Password string     `json:"password" binding:"required"`
+	Code     string     `json:"code" binding:"required"`
+	Client   ClientInfo `json:"-"`
+}
+
+// TwoFactorLoginRequest 登录第二步，Code 可以是验证码或恢复码
+type TwoFactorLoginRequest struct {
+	ChallengeToken string     `json:"challenge_token" binding:"required"`
+	Code           string     `json:"code" binding:"required"`
+	Client         ClientInfo `json:"-"`
+}
+
+// TwoFactorService TOTP 两步验证（RFC 6238）：启用、关闭、恢复码和登录第二步校验。
+// 所有状态变化和登录第二步的结果都记录到审计日志
+type TwoFactorService struct {
+	db     *gorm.DB
+	issuer string
+	logger logger.LoggerInterface
+	now    func() time.Time
+}
+
+// NewTwoFactorService 创建两步验证服务，issuer 显示在身份验证器应用中
+func NewTwoFactorService(db *gorm.DB, issuer string, logger logger.LoggerInterface) *TwoFactorService {
+	return &TwoFactorService{
+		db:     db,
+		issuer: issuer,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Status 获取用户的两步验证状态
+func (s *TwoFactorService) Status(userID uint) (*TwoFactorStatus, error) {
+	config, err := s.getConfig(userID)
+	if err != nil && !errors.Is(err, ErrTwoFactorNotSetUp) {
+		return nil, err
+	}
+	status := &TwoFactorStatus{}
+	if config == nil || config.EnabledAt == nil {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.EnabledAt = config.EnabledAt
+	if err := s.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&status.RecoveryCodesRemaining).Error; err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %v", err)
+	}
+	return status, nil
+}
+
+// IsEnabled 用户是否已启用两步验证
+func (s *TwoFactorService) IsEnabled(userID uint) (bool, error) {
+	var count int64
+	if err := s.db.Model(&models.UserTwoFactor{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL", userID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check two-factor status: %v", err)
+	}
+	return count > 0, nil
+}
+
+// Setup 生成新的密钥，用户用身份验证器扫码后调用 Enable 提交验证码才会真正启用
+func (s *TwoFactorService) Setup(userID uint) (*TwoFactorSetup, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.getConfig(userID)
+	if err != nil && !errors.Is(err, ErrTwoFactorNotSetUp) {
+		return nil, err
+	}
+	if config != nil && config.EnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &models.UserTwoFactor{UserID: userID}
+	}
+	config.Secret = secret
+	config.LastUsedStep = 0
+	if err := s.db.Save(config).Error; err != nil {
+		return nil, fmt.Errorf("failed to save two-factor secret: %v", err)
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+	return &TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, account, secret),
+	}, nil
+}
+
+// Enable 校验身份验证器生成的验证码并启用两步验证，返回只展示这一次的恢复码
+func (s *TwoFactorService) Enable(userID uint, req TwoFactorCodeRequest) ([]string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.getConfig(userID)
+	if err != nil {
+		return nil, err
+	}
+	if config.EnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		ok, err := s.consumeTOTP(tx, config, req.Code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		if err := tx.Model(config).Update("enabled_at", s.now()).Error; err != nil {
+			return fmt.Errorf("failed to enable two-factor authentication: %v", err)
+		}
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(user, AuditEvent{Action: "启用两步验证", Client: req.Client})
+	return codes, nil
+}
+
+// Disable 关闭两步验证，删除密钥和恢复码
+func (s *TwoFactorService) Disable(userID uint, req DisableTwoFactorRequest) error {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return err
+	}
+	if !auth.CheckPassword(req.Password, user.Password) {
+		return ErrInvalidPassword
+	}
+	config, err := s.getEnabledConfig(userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		method, err := s.verifyCode(tx, config, req.Code)
+		if err != nil {
+			return err
+		}
+		if method == "" {
+			return ErrInvalidTwoFactorCode
+		}
+		return s.deleteConfig(tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.audit(user, AuditEvent{Action: "关闭两步验证", Client: req.Client})
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部失效
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, req TwoFactorCodeRequest) ([]string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := s.getEnabledConfig(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		ok, err := s.consumeTOTP(tx, config, req.Code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(user, AuditEvent{Action: "重新生成恢复码", Client: req.Client})
+	return codes, nil
+}
+
+// AdminReset 管理员为丢失设备的用户关闭两步验证，用户之后可重新启用
+func (s *TwoFactorService) AdminReset(adminID, userID uint, client ClientInfo) error {
+	admin, err := s.getUser(adminID)
+	if err != nil {
+		return err
+	}
+	user, err := s.getUser(userID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.getEnabledConfig(userID); err != nil {
+		return err
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.deleteConfig(tx, userID)
+	}); err != nil {
+		return err
+	}
+
+	s.audit(admin, AuditEvent{
+		Action: "重置两步验证",
+		Client: client,
+		Details: map[string]interface{}{
+			"target_user_id":  user.ID,
+			"target_username": user.Username,
+		},
+	})
+	s.logger.WithFields(map[string]any{"admin_id": adminID, "user_id": userID}).Warn("Two-factor authentication reset by admin")
+	return nil
+}
+
+// CreateChallenge 密码校验通过后创建登录第二步的挑战令牌，顺带清理过期的挑战
+func (s *TwoFactorService) CreateChallenge(userID uint) (string, error) {
+	now := s.now()
+	if err := s.db.Where("expires_at < ?", now).Delete(&models.LoginChallenge{}).Error; err != nil {
+		s.logger.Errorf("Failed to delete expired login challenges: %v", err)
+	}
+
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	challenge := &models.LoginChallenge{
+		UserID:    userID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(loginChallengeTTL),
+	}
+	if err := s.db.Create(challenge).Error; err != nil {
+		return "", fmt.Errorf("failed to create login challenge: %v", err)
+	}
+	return token, nil
+}
+
+// ChallengeUserID 返回挑战令牌所属的用户 ID，不校验验证码也不计入尝试次数。
+// 令牌不存在或已过期时返回 ErrInvalidLoginChallenge
+func (s *TwoFactorService) ChallengeUserID(token string) (uint, error) {
+	var challenge models.LoginChallenge
+	if err := s.db.Where("token_hash = ?", auth.HashToken(token)).First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrInvalidLoginChallenge
+		}
+		return 0, fmt.Errorf("failed to get login challenge: %v", err)
+	}
+	if !challenge.ExpiresAt.After(s.now()) {
+		return 0, ErrInvalidLoginChallenge
+	}
+	return challenge.UserID, nil
+}
+
+// VerifyChallenge 校验登录第二步，成功后挑战令牌失效并返回用户 ID。
+// 每个挑战最多尝试 loginChallengeMaxAttempts 次，超过后需要重新输入密码
+func (s *TwoFactorService) VerifyChallenge(req TwoFactorLoginRequest) (uint, error) {
+	var (
+		userID uint
+		method string
+		failed bool
+	)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var challenge models.LoginChallenge
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", auth.HashToken(req.ChallengeToken)).
+			First(&challenge).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidLoginChallenge
+			}
+			return fmt.Errorf("failed to get login challenge: %v", err)
+		}
+		userID = challenge.UserID
+		if !challenge.ExpiresAt.After(s.now()) || challenge.Attempts >= loginChallengeMaxAttempts {
+			return ErrInvalidLoginChallenge
+		}
+
+		config, err := s.getEnabledConfigTx(tx, challenge.UserID)
+		if err != nil {
+			return err
+		}
+		method, err = s.verifyCode(tx, config, req.Code)
+		if err != nil {
+			return err
+		}
+		if method == "" {
+			// 失败次数需要提交，不能通过返回错误回滚
+			failed = true
+			return tx.Model(&challenge).Update("attempts", gorm.Expr("attempts + 1")).Error
+		}
+		return tx.Delete(&challenge).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := s.getUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	if failed {
+		s.audit(user, AuditEvent{Action: "两步验证失败", Client: req.Client, StatusCode: http.StatusUnauthorized})
+		return 0, ErrInvalidTwoFactorCode
+	}
+	s.audit(user, AuditEvent{Action: "两步验证登录", Client: req.Client, Details: map[string]interface{}{"method": method}})
+	return userID, nil
+}
+
+// verifyCode 校验验证码或恢复码，通过时返回使用的方式（totp 或 recovery_code），未通过时返回空字符串
+func (s *TwoFactorService) verifyCode(tx *gorm.DB, config *models.UserTwoFactor, code string) (string, error) {
+	ok, err := s.consumeTOTP(tx, config, code)
+	if err != nil || ok {
+		return "totp", err
+	}
+	ok, err = s.consumeRecoveryCode(tx, config.UserID, code)
+	if err != nil || ok {
+		return "recovery_code", err
+	}
+	return "", nil
+}
+
+// consumeTOTP 校验验证码并记录所在时间步，同一个验证码只能使用一次
+func (s *TwoFactorService) consumeTOTP(tx *gorm.DB, config *models.UserTwoFactor, code string) (bool, error) {
+	step, ok := auth.ValidateTOTP(config.Secret, code, s.now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	result := tx.Model(&models.UserTwoFactor{}).
+		Where("id = ? AND last_used_step < ?", config.ID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update two-factor state: %v", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// consumeRecoveryCode 校验并作废恢复码
+func (s *TwoFactorService) consumeRecoveryCode(tx *gorm.DB, userID uint, code string) (bool, error) {
+	code = auth.NormalizeRecoveryCode(code)
+	if code == "" {
+		return false, nil
+	}
+
+	var codes []models.RecoveryCode
+	if err := tx.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error; err != nil {
+		return false, fmt.Errorf("failed to get recovery codes: %v", err)
+	}
+	for _, recovery := range codes {
+		if !auth.CheckPassword(code, recovery.CodeHash) {
+			continue
+		}
+		result := tx.Model(&models.RecoveryCode{}).
+			Where("id = ? AND used_at IS NULL", recovery.ID).
+			Update("used_at", s.now())
+		if result.Error != nil {
+			return false, fmt.Errorf("failed to use recovery code: %v", result.Error)
+		}
+		return result.RowsAffected > 0, nil
+	}
+	return false, nil
+}
+
+// replaceRecoveryCodes 删除旧的恢复码并生成新的一组
+func (s *TwoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	codes, err := auth.NewRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]models.RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		hash, err := auth.HashPassword(auth.NormalizeRecoveryCode(code))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash recovery code: %v", err)
+		}
+		records = append(records, models.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %v", err)
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %v", err)
+	}
+	return codes, nil
+}
+
+func (s *TwoFactorService) deleteConfig(tx *gorm.DB, userID uint) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %v", err)
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.LoginChallenge{}).Error; err != nil {
+		return fmt.Errorf("failed to delete login challenges: %v", err)
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error; err != nil {
+		return fmt.Errorf("failed to delete two-factor config: %v", err)
+	}
+	return nil
+}
+
+func (s *TwoFactorService) getConfig(userID uint) (*models.UserTwoFactor, error) {
+	var config models.UserTwoFactor
+	if err := s.db.Where("user_id = ?", userID).First(&config).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotSetUp
+		}
+		return nil, fmt.Errorf("failed to get two-factor config: %v", err)
+	}
+	return &config, nil
+}
+
+func (s *TwoFactorService) getEnabledConfig(userID uint) (*models.UserTwoFactor, error) {
+	return s.getEnabledConfigTx(s.db, userID)
+}
+
+func (s *TwoFactorService) getEnabledConfigTx(tx *gorm.DB, userID uint) (*models.UserTwoFactor, error) {
+	var config models.UserTwoFactor
+	if err := tx.Where("user_id = ? AND enabled_at IS NOT NULL", userID).First(&config).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnabled
+		}
+		return nil, fmt.Errorf("failed to get two-factor config: %v", err)
+	}
+	return &config, nil
+}
+
+func (s *TwoFactorService) getUser(userID uint) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %v", err)
+	}
+	return &user, nil
+}
+
+// audit 以 user 的身份记录审计事件
+func (s *TwoFactorService) audit(user *models.User, event AuditEvent) {
+	event.UserID = user.ID
+	event.Username = user.Username
+	recordAuditEvent(s.db, s.logger, event)
+}
@@ -16,14 +16,24 @@ import (
 )
 
 type UserService struct {
-	db     *gorm.DB
-	logger logger.LoggerInterface
+	db        *gorm.DB
+	tokens    *TokenService
+	sessions  *SessionService
+	twoFactor *TwoFactorService
+	accounts  *AccountService
+	guard     *LoginGuard
+	logger    logger.LoggerInterface
 }
 
-func NewUserService(db *gorm.DB, logger logger.LoggerInterface) *UserService {
+func NewUserService(db *gorm.DB, tokens *TokenService, sessions *SessionService, twoFactor *TwoFactorService, accounts *AccountService, guard *LoginGuard, logger logger.LoggerInterface) *UserService {
 	return &UserService{
-		db:     db,
-		logger: logger,
+		db:        db,
+		tokens:    tokens,
+		sessions:  sessions,
+		twoFactor: twoFactor,
+		accounts:  accounts,
+		guard:     guard,
+		logger:    logger,
 	}
 }
 
@@ -36,11 +46,19 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+
+	Client ClientInfo `json:"-"` // 由处理器从请求中填写，用于记录登录设备
 }
 
 type LoginResponse struct {
-	Token string      `json:"token"`
-	User  models.User `json:"user"`
+	Token        string      `json:"token"`         // 访问令牌
+	RefreshToken string      `json:"refresh_token"` // 刷新令牌，只能使用一次
+	ExpiresIn    int64       `json:"expires_in"`    // 访问令牌的有效秒数
+	User         models.User `json:"user"`
+
+	// 启用两步验证时密码校验通过后只返回挑战令牌，提交验证码后才签发上面的令牌
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
 type UpdateProfileRequest struct {
@@ -54,10 +72,14 @@ type UpdateProfileRequest struct {
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" validate:"required"`
 	NewPassword string `json:"new_password" validate:"required,min=6"`
+
+	SessionID string `json:"-"` // 发起修改的会话，由处理器从访问令牌中填写，修改后保留该会话
 }
 
 type RefreshTokenRequest struct {
-	Token string `json:"token" binding:"required"`
+	RefreshToken string `json:"refresh_token" binding:"required"`
+
+	Client ClientInfo `json:"-"`
 }
 
 type PaginatedUsers struct {
@@ -103,16 +125,26 @@ func (s *UserService) Register(req RegisterRequest) (*models.User, error) {
 	}
 
 	s.logger.WithFields(map[string]any{"user_id": user.ID, "username": user.Username}).Info("User registered successfully")
+	s.sendVerification(user.ID)
 	return &user, nil
 }
 
 // Login 用户登录
 func (s *UserService) Login(req LoginRequest) (*LoginResponse, error) {
 
+	// 失败次数过多的账号和 IP 在锁定或延迟期内直接拒绝，不校验密码
+	if err := s.guard.Check(req.Username, req.Client); err != nil {
+		s.logger.WithFields(map[string]any{"username": req.Username, "ip": req.Client.IP, "error": err}).Warn("Login rejected by login guard")
+		return nil, err
+	}
+
 	// 查找用户
 	var user models.User
 	if err := s.db.Where("username = ?", req.Username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := s.guard.RecordFailure(nil, req.Username, req.Client); err != nil {
+				return nil, err
+			}
 			return nil, pkgerrors.NewUnauthorizedError("用户名或密码错误")
 		}
 		return nil, fmt.Errorf("database error: %v", err)
@@ -121,22 +153,90 @@ func (s *UserService) Login(req LoginRequest) (*LoginResponse, error) {
 	// 验证密码
 	if !auth.CheckPassword(req.Password, user.Password) {
 		s.logger.WithFields(map[string]any{"username": req.Username}).Warn("Login failed: invalid password")
+		if err := s.guard.RecordFailure(&user, req.Username, req.Client); err != nil {
+			return nil, err
+		}
 		return nil, pkgerrors.NewUnauthorizedError("用户名或密码错误")
 	}
 
-	// 生成JWT token
-	token, err := jwt.GenerateToken(user.ID, user.Username, user.Email, user.Role)
+	// 启用了两步验证的用户还需要提交验证码，通过后才清除失败计数
+	enabled, err := s.twoFactor.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		challenge, err := s.twoFactor.CreateChallenge(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.logger.WithFields(map[string]any{"user_id": user.ID, "username": user.Username}).Info("Login requires two-factor verification")
+		return &LoginResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	s.guard.RecordSuccess(req.Username)
+	return s.startSession(&user, req.Client)
+}
+
+// VerifyTwoFactorLogin 登录第二步：校验验证码或恢复码后签发令牌。
+// 验证码错误与密码错误一样计入账号和 IP 的失败次数
+func (s *UserService) VerifyTwoFactorLogin(req TwoFactorLoginRequest) (*LoginResponse, error) {
+	challengeUserID, err := s.twoFactor.ChallengeUserID(req.ChallengeToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %v", err)
+		return nil, err
+	}
+	var user models.User
+	if err := s.db.First(&user, challengeUserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidLoginChallenge
+		}
+		return nil, fmt.Errorf("database error: %v", err)
+	}
+
+	// 第一步之后账号或 IP 被锁定时同样拒绝
+	if err := s.guard.Check(user.Username, req.Client); err != nil {
+		s.logger.WithFields(map[string]any{"username": user.Username, "ip": req.Client.IP, "error": err}).Warn("Two-factor login rejected by login guard")
+		return nil, err
+	}
+
+	if _, err := s.twoFactor.VerifyChallenge(req); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			if lockErr := s.guard.RecordFailure(&user, user.Username, req.Client); lockErr != nil {
+				return nil, lockErr
+			}
+		}
+		return nil, err
+	}
+
+	s.guard.RecordSuccess(user.Username)
+	return s.startSession(&user, req.Client)
+}
+
+// startSession 开启新会话并签发令牌
+func (s *UserService) startSession(user *models.User, client ClientInfo) (*LoginResponse, error) {
+	tokens, err := s.tokens.IssueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessions.Start(user.ID, tokens.SessionID, client); err != nil {
+		s.logger.WithFields(map[string]any{"user_id": user.ID, "error": err}).Warn("Failed to record login session")
 	}
 
 	s.logger.WithFields(map[string]any{"user_id": user.ID, "username": user.Username}).Info("User logged in successfully")
 	return &LoginResponse{
-		Token: token,
-		User:  user,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         *user,
 	}, nil
 }
 
+// sendVerification 发送验证邮件，失败不影响注册或修改资料，用户之后可以重新发送
+func (s *UserService) sendVerification(userID uint) {
+	if err := s.accounts.SendVerification(userID); err != nil {
+		s.logger.WithFields(map[string]any{"user_id": userID, "error": err}).Warn("Failed to send verification email")
+	}
+}
+
 // GetUserByID 根据ID获取用户
 func (s *UserService) GetUserByID(userID uint) (*models.User, error) {
 
@@ -180,8 +280,11 @@ func (s *UserService) UpdateProfile(userID uint, req UpdateProfileRequest) (*mod
 	if req.Username != "" {
 		user.Username = req.Username
 	}
-	if req.Email != "" {
+	emailChanged := req.Email != "" && req.Email != user.Email
+	if emailChanged {
+		// 新邮箱需要重新验证
 		user.Email = req.Email
+		user.EmailVerifiedAt = nil
 	}
 	if req.Nickname != "" {
 		user.Nickname = req.Nickname
@@ -190,6 +293,9 @@ func (s *UserService) UpdateProfile(userID uint, req UpdateProfileRequest) (*mod
 	if err := s.db.Save(&user).Error; err != nil {
 		return nil, fmt.Errorf("failed to update user: %v", err)
 	}
+	if emailChanged {
+		s.sendVerification(user.ID)
+	}
 
 	return &user, nil
 }
@@ -283,6 +389,11 @@ func (s *UserService) ChangePassword(userID uint, req ChangePasswordRequest) err
 		return fmt.Errorf("failed to update password: %v", err)
 	}
 
+	// 旧密码可能已泄露，除当前会话外的其它设备都需要用新密码重新登录
+	if err := s.tokens.LogoutOthers(user.ID, req.SessionID); err != nil {
+		s.logger.WithFields(map[string]any{"user_id": user.ID, "error": err}).Error("Failed to revoke sessions after password change")
+	}
+
 	return nil
 }
 
@@ -303,6 +414,11 @@ func (s *UserService) DeleteUser(id uint) error {
 		return errors.New("user not found")
 	}
 
+	// 已删除用户的令牌立即失效
+	if err := s.tokens.LogoutAll(id); err != nil {
+		s.logger.WithFields(map[string]any{"user_id": id, "error": err}).Error("Failed to revoke tokens of deleted user")
+	}
+
 	return nil
 }
 
@@ -320,36 +436,41 @@ func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-// RefreshToken 刷新token（实现UserServiceInterface接口）
-func (s *UserService) RefreshToken(tokenString string) (*LoginResponse, error) {
-	// 使用JWT包的RefreshToken方法
-	newToken, err := jwt.RefreshToken(tokenString)
+// RefreshToken 使用刷新令牌换取新的令牌，刷新令牌每次使用后轮换（实现UserServiceInterface接口）
+func (s *UserService) RefreshToken(req RefreshTokenRequest) (*LoginResponse, error) {
+	tokens, user, err := s.tokens.Refresh(req.RefreshToken)
 	if err != nil {
 		s.logger.WithFields(map[string]any{"error": err}).Warn("Token refresh failed")
-		return nil, fmt.Errorf("token refresh failed: %v", err)
+		return nil, err
 	}
-
-	// 解析新token获取用户信息
-	claims, err := jwt.ParseToken(newToken)
-	if err != nil {
-		s.logger.WithFields(map[string]any{"error": err}).Error("Failed to parse new token")
-		return nil, fmt.Errorf("failed to parse new token: %v", err)
+	if err := s.sessions.Touch(user.ID, tokens.SessionID, req.Client); err != nil {
+		s.logger.WithFields(map[string]any{"user_id": user.ID, "error": err}).Warn("Failed to update session activity")
 	}
 
-	// 获取用户信息
-	user, err := s.GetUserByID(claims.UserID)
-	if err != nil {
-		s.logger.WithFields(map[string]any{"user_id": claims.UserID, "error": err}).Error("Failed to get user during token refresh")
-		return nil, fmt.Errorf("failed to get user: %v", err)
-	}
-
-	// 清除密码字段（安全考虑）
-	user.Password = ""
-
-	s.logger.WithFields(map[string]any{"user_id": claims.UserID, "username": claims.Username}).Info("Token refreshed successfully")
+	s.logger.WithFields(map[string]any{"user_id": user.ID, "username": user.Username}).Info("Token refreshed successfully")
 
 	return &LoginResponse{
-		Token: newToken,
-		User:  *user,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         *user,
 	}, nil
 }
+
+// Logout 退出当前会话，当前访问令牌和会话的刷新令牌立即失效
+func (s *UserService) Logout(claims *jwt.Claims) error {
+	if err := s.tokens.Logout(claims); err != nil {
+		return err
+	}
+	s.logger.WithFields(map[string]any{"user_id": claims.UserID, "session_id": claims.SessionID}).Info("User logged out")
+	return nil
+}
+
+// LogoutAll 退出所有设备上的登录
+func (s *UserService) LogoutAll(userID uint) error {
+	if err := s.tokens.LogoutAll(userID); err != nil {
+		return err
+	}
+	s.logger.WithFields(map[string]any{"user_id": userID}).Info("User logged out from all devices")
+	return nil
+}
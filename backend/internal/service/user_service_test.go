@@ -2,8 +2,15 @@ package service
 
 import (
 	"testing"
+	"time"
+
+	"gin-web-framework/config"
+	"gin-web-framework/internal/models"
+	"gin-web-framework/pkg/auth"
+	"gin-web-framework/pkg/logger"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserService_ValidateEmail(t *testing.T) {
@@ -301,4 +308,40 @@ func isSpecialChar(char rune) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+func TestUserService_ChangePasswordRevokesOtherSessions(t *testing.T) {
+	require.NoError(t, config.Load())
+	db := openTestDB(t, &models.User{}, &models.RefreshToken{}, &models.RevokedToken{})
+	password, err := auth.HashPassword("secret1")
+	require.NoError(t, err)
+	user := &models.User{Username: "alice", Email: "alice@example.com", Password: password}
+	require.NoError(t, db.Create(user).Error)
+
+	log := logger.NewLogger(logger.DefaultLoggerConfig())
+	tokens := NewTokenService(db, time.Hour, log)
+	users := NewUserService(db, tokens, nil, nil, nil, nil, log)
+	current, err := tokens.IssueTokens(user)
+	require.NoError(t, err)
+	other, err := tokens.IssueTokens(user)
+	require.NoError(t, err)
+
+	require.NoError(t, users.ChangePassword(user.ID, ChangePasswordRequest{
+		OldPassword: "secret1",
+		NewPassword: "secret2",
+		SessionID:   current.SessionID,
+	}))
+
+	// 其它设备的刷新令牌失效，发起修改的会话仍可继续使用
+	_, _, err = tokens.Refresh(other.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, _, err = tokens.Refresh(current.RefreshToken)
+	assert.NoError(t, err)
+
+	// 没有当前会话时吊销全部会话
+	next, err := tokens.IssueTokens(user)
+	require.NoError(t, err)
+	require.NoError(t, users.ChangePassword(user.ID, ChangePasswordRequest{OldPassword: "secret2", NewPassword: "secret3"}))
+	_, _, err = tokens.Refresh(next.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
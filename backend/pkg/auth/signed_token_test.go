@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenSigner(t *testing.T) {
+	signer := NewTokenSigner("test-secret-key-with-enough-length")
+	now := time.Unix(1700000000, 0)
+
+	token, claims, err := signer.Sign("password_reset", 42, now.Add(30*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, uint(42), claims.Subject)
+	assert.Len(t, claims.Nonce, 32)
+
+	verified, err := signer.Verify("password_reset", token, now)
+	require.NoError(t, err)
+	assert.Equal(t, claims, verified)
+
+	// 其他用途、过期、篡改和其他密钥签发的令牌都无效
+	_, err = signer.Verify("email_verification", token, now)
+	assert.ErrorIs(t, err, ErrSignedTokenInvalid)
+
+	_, err = signer.Verify("password_reset", token, now.Add(30*time.Minute))
+	assert.ErrorIs(t, err, ErrSignedTokenExpired)
+
+	tampered := "43" + strings.TrimPrefix(token, "42")
+	_, err = signer.Verify("password_reset", tampered, now)
+	assert.ErrorIs(t, err, ErrSignedTokenInvalid)
+
+	_, err = NewTokenSigner("another-secret-key-with-enough-length").Verify("password_reset", token, now)
+	assert.ErrorIs(t, err, ErrSignedTokenInvalid)
+
+	_, err = signer.Verify("password_reset", "garbage", now)
+	assert.ErrorIs(t, err, ErrSignedTokenInvalid)
+}
+
+func TestDeriveKey(t *testing.T) {
+	secret := "test-secret-key-with-enough-length"
+	fileKey := DeriveKey(secret, KeyPurposeFileURL)
+	accountKey := DeriveKey(secret, KeyPurposeAccountToken)
+
+	assert.Len(t, fileKey, 64)
+	assert.Equal(t, fileKey, DeriveKey(secret, KeyPurposeFileURL))
+	assert.NotEqual(t, fileKey, accountKey)
+	assert.NotEqual(t, secret, fileKey)
+	assert.NotEqual(t, fileKey, DeriveKey("another-secret-key-with-enough-length", KeyPurposeFileURL))
+
+	// 用派生密钥签发的令牌不能用主密钥或其它用途的密钥校验
+	token, _, err := NewTokenSigner(accountKey).Sign("password_reset", 1, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = NewTokenSigner(secret).Verify("password_reset", token, time.Now())
+	assert.ErrorIs(t, err, ErrSignedTokenInvalid)
+	_, err = NewTokenSigner(fileKey).Verify("password_reset", token, time.Now())
+	assert.ErrorIs(t, err, ErrSignedTokenInvalid)
+}
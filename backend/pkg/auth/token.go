@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// opaqueTokenBytes 不透明令牌的随机字节数
+const opaqueTokenBytes = 32
+
+// NewOpaqueToken 生成随机的不透明令牌（如刷新令牌），返回令牌本身和用于存储的哈希。
+// 令牌只交给客户端，服务端只保存哈希
+func NewOpaqueToken() (token, hash string, err error) {
+	buf := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken 计算不透明令牌的哈希。令牌本身是高熵随机值，使用 SHA-256 即可，无需 bcrypt
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
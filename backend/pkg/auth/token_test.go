@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOpaqueToken(t *testing.T) {
+	token, hash, err := NewOpaqueToken()
+	require.NoError(t, err)
+	assert.Len(t, token, 43)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashToken(token))
+	assert.NotContains(t, hash, token)
+
+	other, otherHash, err := NewOpaqueToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+	assert.NotEqual(t, hash, otherHash)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 附录 B 中 SHA1 的测试向量，取 8 位验证码的后 6 位
+func TestTOTPCode_RFC6238(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "time %d", tt.unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	previous, err := TOTPCode(secret, TOTPStep(now)-1)
+	require.NoError(t, err)
+	step, ok := ValidateTOTP(secret, previous, now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, TOTPStep(now)-1, step)
+
+	old, err := TOTPCode(secret, TOTPStep(now)-2)
+	require.NoError(t, err)
+	_, ok = ValidateTOTP(secret, old, now, 1)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "12345", now, 1)
+	assert.False(t, ok)
+	_, ok = ValidateTOTP("not base32!", "123456", now, 1)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("My App", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/My App:alice@example.com", parsed.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", parsed.Query().Get("secret"))
+	assert.Equal(t, "My App", parsed.Query().Get("issuer"))
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Len(t, code, 11)
+		assert.Equal(t, "-", code[5:6])
+		assert.False(t, strings.ContainsAny(code, "01ilo"))
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+	assert.Equal(t, NormalizeRecoveryCode(codes[0]), NormalizeRecoveryCode(" "+strings.ToUpper(codes[0])+" "))
+}
@@ -0,0 +1,227 @@
+// Package diff 基于 Myers 算法的文本差异比较，支持按行和按词两种粒度，
+// 用于文章修订历史等需要展示版本差异的场景
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 差异片段类型
+const (
+	Equal  = "equal"
+	Insert = "insert"
+	Delete = "delete"
+)
+
+// maxEditDistance 编辑距离上限，超出时退化为整体删除再插入，避免大文本完全改写时占用过多内存
+const maxEditDistance = 4000
+
+// Op 差异片段，相邻的同类片段会被合并
+type Op struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+// Result 差异结果，Added/Removed 为新增和删除的行数（按行比较）或词数（按词比较，不含空白）
+type Result struct {
+	Ops     []Op `json:"ops"`
+	Added   int  `json:"added"`
+	Removed int  `json:"removed"`
+}
+
+// Lines 按行比较
+func Lines(a, b string) Result {
+	return compare(splitLines(a), splitLines(b), func(string) bool { return true })
+}
+
+// Words 按词比较，中日韩字符逐字比较
+func Words(a, b string) Result {
+	return compare(splitWords(a), splitWords(b), func(token string) bool {
+		return strings.TrimSpace(token) != ""
+	})
+}
+
+// compare 比较两个词元序列，counted 判断词元是否计入增删统计
+func compare(a, b []string, counted func(string) bool) Result {
+	result := Result{Ops: []Op{}}
+	for _, e := range edits(a, b) {
+		if e.kind == Insert && counted(e.token) {
+			result.Added++
+		}
+		if e.kind == Delete && counted(e.token) {
+			result.Removed++
+		}
+		if n := len(result.Ops); n > 0 && result.Ops[n-1].Kind == e.kind {
+			result.Ops[n-1].Text += e.token
+			continue
+		}
+		result.Ops = append(result.Ops, Op{Kind: e.kind, Text: e.token})
+	}
+	return result
+}
+
+type edit struct {
+	kind  string
+	token string
+}
+
+// edits 计算最短编辑脚本，先去掉公共前后缀以减少计算量
+func edits(a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]edit, 0, len(a)+len(b))
+	for _, token := range a[:prefix] {
+		result = append(result, edit{Equal, token})
+	}
+	result = append(result, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, token := range a[len(a)-suffix:] {
+		result = append(result, edit{Equal, token})
+	}
+	return result
+}
+
+// myers 实现 Myers O(ND) 差分算法，trace 记录每一步开始前各对角线到达的最远位置用于回溯
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		if d > maxEditDistance {
+			return replaceAll(a, b)
+		}
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+func backtrack(trace [][]int, a, b []string) []edit {
+	x, y := len(a), len(b)
+	var reversed []edit
+	for d := len(trace) - 1; d >= 0; d-- {
+		if d == 0 {
+			for x > 0 && y > 0 {
+				reversed = append(reversed, edit{Equal, a[x-1]})
+				x--
+				y--
+			}
+			break
+		}
+
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, edit{Equal, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, edit{Insert, b[y-1]})
+		} else {
+			reversed = append(reversed, edit{Delete, a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+
+	result := make([]edit, len(reversed))
+	for i, e := range reversed {
+		result[len(reversed)-1-i] = e
+	}
+	return result
+}
+
+func replaceAll(a, b []string) []edit {
+	result := make([]edit, 0, len(a)+len(b))
+	for _, token := range a {
+		result = append(result, edit{Delete, token})
+	}
+	for _, token := range b {
+		result = append(result, edit{Insert, token})
+	}
+	return result
+}
+
+// splitLines 按行切分，保留行尾换行符以便拼接还原
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// splitWords 切分为词元：连续的字母数字、连续的空白、单个中日韩字符或标点
+func splitWords(s string) []string {
+	var tokens []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		case isWordRune(r):
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '\''
+}
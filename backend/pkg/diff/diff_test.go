@@ -0,0 +1,104 @@
+package diff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		ops     []Op
+		added   int
+		removed int
+	}{
+		{
+			name: "Identical",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			ops:  []Op{{Equal, "a\nb\n"}},
+		},
+		{
+			name:  "From empty",
+			a:     "",
+			b:     "a\nb",
+			ops:   []Op{{Insert, "a\nb"}},
+			added: 2,
+		},
+		{
+			name:    "Replace middle line",
+			a:       "one\ntwo\nthree\n",
+			b:       "one\n2\nthree\n",
+			ops:     []Op{{Equal, "one\n"}, {Delete, "two\n"}, {Insert, "2\n"}, {Equal, "three\n"}},
+			added:   1,
+			removed: 1,
+		},
+		{
+			name:    "Insert and delete",
+			a:       "a\nb\nc\nd\n",
+			b:       "b\nc\nx\nd\n",
+			ops:     []Op{{Delete, "a\n"}, {Equal, "b\nc\n"}, {Insert, "x\n"}, {Equal, "d\n"}},
+			added:   1,
+			removed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Lines(tt.a, tt.b)
+			assert.Equal(t, tt.ops, result.Ops)
+			assert.Equal(t, tt.added, result.Added)
+			assert.Equal(t, tt.removed, result.Removed)
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	result := Words("The quick brown fox", "The slow brown fox!")
+	assert.Equal(t, []Op{
+		{Equal, "The "},
+		{Delete, "quick"},
+		{Insert, "slow"},
+		{Equal, " brown fox"},
+		{Insert, "!"},
+	}, result.Ops)
+	assert.Equal(t, 2, result.Added)
+	assert.Equal(t, 1, result.Removed)
+
+	result = Words("准备季度汇报", "准备年度汇报")
+	assert.Equal(t, []Op{{Equal, "准备"}, {Delete, "季"}, {Insert, "年"}, {Equal, "度汇报"}}, result.Ops)
+}
+
+// TestRoundTrip 随机文本的差异结果应能分别还原出新旧文本
+func TestRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "d", "\n"}
+	random := func() string {
+		var sb strings.Builder
+		for i := rng.Intn(40); i > 0; i-- {
+			sb.WriteString(words[rng.Intn(len(words))])
+		}
+		return sb.String()
+	}
+
+	for i := 0; i < 200; i++ {
+		a, b := random(), random()
+		for _, result := range []Result{Lines(a, b), Words(a, b)} {
+			var oldText, newText strings.Builder
+			for _, op := range result.Ops {
+				if op.Kind != Insert {
+					oldText.WriteString(op.Text)
+				}
+				if op.Kind != Delete {
+					newText.WriteString(op.Text)
+				}
+			}
+			assert.Equal(t, a, oldText.String())
+			assert.Equal(t, b, newText.String())
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Package feed 生成 RSS 2.0 和 Atom 1.0 订阅源。调用方只需填充与格式无关的 Feed，
+// 由 RSS、Atom 分别序列化
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+// 订阅源的 Content-Type
+const (
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+)
+
+// Feed 订阅源
+type Feed struct {
+	Title       string
+	Link        string // 对应网页的地址
+	Self        string // 订阅源自身的地址
+	Description string
+	Language    string
+	Author      string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item 订阅源条目，ID 应在订阅源中唯一且不随内容变化，通常使用条目的永久链接
+type Item struct {
+	ID         string
+	Title      string
+	Link       string
+	Summary    string
+	Content    string // HTML 内容
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// RSS 序列化为 RSS 2.0
+func RSS(f *Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		Language:    f.Language,
+		Generator:   "gin-web-framework",
+	}
+	if f.Self != "" {
+		channel.AtomLink = &atomLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"}
+	}
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range f.Items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Summary,
+			Author:      item.Author,
+			Categories:  item.Categories,
+			GUID:        rssGUID{Value: item.ID, IsPermaLink: item.ID == item.Link},
+		}
+		if item.Content != "" {
+			entry.Content = &cdata{Value: item.Content}
+		}
+		if !item.Published.IsZero() {
+			entry.PubDate = item.Published.UTC().Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, entry)
+	}
+
+	return marshal(rss{
+		Version:   "2.0",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel:   channel,
+	})
+}
+
+// Atom 序列化为 Atom 1.0 (RFC 4287)
+func Atom(f *Feed) ([]byte, error) {
+	id := f.Self
+	if id == "" {
+		id = f.Link
+	}
+	doc := atomFeed{
+		NS:       "http://www.w3.org/2005/Atom",
+		ID:       id,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  atomTime(f.Updated),
+		Links:    []atomLink{{Href: f.Link, Rel: "alternate", Type: "text/html"}},
+	}
+	if f.Self != "" {
+		doc.Links = append(doc.Links, atomLink{Href: f.Self, Rel: "self", Type: "application/atom+xml"})
+	}
+	if f.Author != "" {
+		doc.Author = &atomPerson{Name: f.Author}
+	}
+	for _, item := range f.Items {
+		updated := item.Updated
+		if updated.IsZero() {
+			updated = item.Published
+		}
+		entry := atomEntry{
+			ID:      item.ID,
+			Title:   item.Title,
+			Updated: atomTime(updated),
+			Links:   []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+		}
+		if !item.Published.IsZero() {
+			entry.Published = atomTime(item.Published)
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		if item.Author != "" {
+			entry.Author = &atomPerson{Name: item.Author}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshal(doc)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func atomTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	Generator     string    `xml:"generator,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      *atomLink `xml:"atom:link,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description,omitempty"`
+	Content     *cdata   `xml:"content:encoded,omitempty"`
+	Author      string   `xml:"author,omitempty"`
+	Categories  []string `xml:"category"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	NS       string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomPerson `xml:"author,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
@@ -0,0 +1,84 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2024, 3, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	return &Feed{
+		Title:       "alice 的文章",
+		Link:        "https://example.com/authors/alice",
+		Self:        "https://example.com/api/v1/public/authors/alice/feed.atom",
+		Description: "Posts & notes",
+		Author:      "alice",
+		Updated:     published.Add(time.Hour),
+		Items: []Item{{
+			ID:         "https://example.com/posts/hello",
+			Title:      "Hello <World>",
+			Link:       "https://example.com/posts/hello",
+			Summary:    "first post",
+			Content:    "<p>hi ]]> there</p>",
+			Author:     "alice",
+			Categories: []string{"Go", "Web"},
+			Published:  published,
+			Updated:    published.Add(time.Hour),
+		}},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	out, err := RSS(testFeed())
+	require.NoError(t, err)
+	body := string(out)
+
+	assert.True(t, strings.HasPrefix(body, xml.Header))
+	assert.Contains(t, body, `<rss version="2.0"`)
+	assert.Contains(t, body, `<title>Hello &lt;World&gt;</title>`)
+	assert.Contains(t, body, `<guid isPermaLink="true">https://example.com/posts/hello</guid>`)
+	assert.Contains(t, body, `<pubDate>Fri, 01 Mar 2024 00:00:00 +0000</pubDate>`)
+	assert.Contains(t, body, `<atom:link href="https://example.com/api/v1/public/authors/alice/feed.atom" rel="self"`)
+
+	var parsed struct {
+		Items []struct {
+			Content    string   `xml:"encoded"`
+			Categories []string `xml:"category"`
+		} `xml:"channel>item"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &parsed))
+	require.Len(t, parsed.Items, 1)
+	assert.Equal(t, "<p>hi ]]> there</p>", parsed.Items[0].Content)
+	assert.Equal(t, []string{"Go", "Web"}, parsed.Items[0].Categories)
+}
+
+func TestAtom(t *testing.T) {
+	out, err := Atom(testFeed())
+	require.NoError(t, err)
+
+	var parsed struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Content   struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(out, &parsed))
+	assert.Equal(t, "https://example.com/api/v1/public/authors/alice/feed.atom", parsed.ID)
+	assert.Equal(t, "2024-03-01T01:00:00Z", parsed.Updated)
+	require.Len(t, parsed.Entries, 1)
+	assert.Equal(t, "2024-03-01T00:00:00Z", parsed.Entries[0].Published)
+	assert.Equal(t, "html", parsed.Entries[0].Content.Type)
+	assert.Equal(t, "<p>hi ]]> there</p>", parsed.Entries[0].Content.Value)
+}
@@ -0,0 +1,87 @@
+// Package frontmatter 读写以 YAML front matter 开头的 Markdown 文档（Jekyll、Hugo 等静态站点使用的格式）：
+//
+//	---
+//	title: Hello
+//	tags: [go, web]
+//	---
+//	正文
+package frontmatter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+const delimiter = "---"
+
+// ErrUnterminated front matter 缺少结束的 ---
+var ErrUnterminated = errors.New("front matter is not terminated")
+
+// Split 拆分 front matter 和正文。文档不以 --- 开头时 meta 为 nil，body 为整个文档
+func Split(doc []byte) (meta, body []byte, err error) {
+	doc = bytes.TrimPrefix(doc, []byte("\ufeff"))
+	first, rest, found := cutLine(doc)
+	if !found || string(bytes.TrimRight(first, " \t\r")) != delimiter {
+		return nil, doc, nil
+	}
+
+	var metaBuf bytes.Buffer
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = cutLine(rest)
+		trimmed := string(bytes.TrimRight(line, " \t\r"))
+		if trimmed == delimiter || trimmed == "..." {
+			return metaBuf.Bytes(), bytes.TrimLeft(rest, "\r\n"), nil
+		}
+		metaBuf.Write(line)
+		metaBuf.WriteByte('\n')
+	}
+	return nil, nil, ErrUnterminated
+}
+
+// Parse 将 front matter 解析到 v 并返回正文
+func Parse(doc []byte, v interface{}) ([]byte, error) {
+	meta, body, err := Split(doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(meta)) > 0 {
+		if err := yaml.Unmarshal(meta, v); err != nil {
+			return nil, fmt.Errorf("invalid front matter: %w", err)
+		}
+	}
+	return body, nil
+}
+
+// Marshal 生成带 front matter 的文档
+func Marshal(v interface{}, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(delimiter + "\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString(delimiter + "\n")
+	if len(body) > 0 {
+		buf.WriteByte('\n')
+		buf.Write(body)
+		if body[len(body)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func cutLine(b []byte) (line, rest []byte, found bool) {
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		return b[:i], b[i+1:], true
+	}
+	return b, nil, len(b) > 0
+}
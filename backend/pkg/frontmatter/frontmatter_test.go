@@ -0,0 +1,89 @@
+package frontmatter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type meta struct {
+	Title string     `yaml:"title"`
+	Tags  []string   `yaml:"tags,omitempty"`
+	Date  *time.Time `yaml:"date,omitempty"`
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		want     meta
+		wantBody string
+		wantErr  bool
+	}{
+		{
+			name:     "With front matter",
+			doc:      "---\ntitle: Hello\ntags: [go, web]\n---\n\n# Body\n",
+			want:     meta{Title: "Hello", Tags: []string{"go", "web"}},
+			wantBody: "# Body\n",
+		},
+		{
+			name:     "CRLF and BOM",
+			doc:      "\ufeff---\r\ntitle: Win\r\n---\r\nbody",
+			want:     meta{Title: "Win"},
+			wantBody: "body",
+		},
+		{
+			name:     "Without front matter",
+			doc:      "# Just markdown\n",
+			wantBody: "# Just markdown\n",
+		},
+		{
+			name:     "Thematic break is not front matter",
+			doc:      "text\n---\nmore",
+			wantBody: "text\n---\nmore",
+		},
+		{
+			name:    "Unterminated",
+			doc:     "---\ntitle: x\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid YAML",
+			doc:     "---\ntitle: [x\n---\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got meta
+			body, err := Parse([]byte(tt.doc), &got)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantBody, string(body))
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	date := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	in := meta{Title: "Hello: world", Tags: []string{"go"}, Date: &date}
+
+	doc, err := Marshal(in, []byte("# Body"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: 'Hello: world'\ntags:\n  - go\ndate: 2024-03-01T08:30:00Z\n---\n\n# Body\n", string(doc))
+
+	var out meta
+	body, err := Parse(doc, &out)
+	require.NoError(t, err)
+	assert.Equal(t, in.Title, out.Title)
+	assert.Equal(t, in.Tags, out.Tags)
+	assert.True(t, date.Equal(*out.Date))
+	assert.Equal(t, "# Body\n", string(body))
+}
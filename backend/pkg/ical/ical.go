@@ -0,0 +1,442 @@
+// Package ical 实现 iCalendar (RFC 5545) 的最小子集：组件树的序列化与解析，
+// 足以生成 VTODO/VEVENT 订阅源并导入常见日历客户端导出的 .ics 文件
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 常用组件名
+const (
+	ComponentCalendar = "VCALENDAR"
+	ComponentTodo     = "VTODO"
+	ComponentEvent    = "VEVENT"
+)
+
+const (
+	dateTimeFormat    = "20060102T150405"
+	dateTimeUTCFormat = "20060102T150405Z"
+	dateFormat        = "20060102"
+
+	// maxLineOctets 单行最大字节数（不含换行），超出部分需要折行
+	maxLineOctets = 75
+)
+
+var (
+	ErrInvalidCalendar = errors.New("invalid iCalendar data")
+	ErrInvalidDateTime = errors.New("invalid iCalendar date-time")
+)
+
+// Property 组件属性，例如 DUE;TZID=Asia/Shanghai:20240101T090000
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Param 获取属性参数，参数名不区分大小写
+func (p *Property) Param(name string) string {
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Component 日历组件，可以嵌套子组件
+type Component struct {
+	Name       string
+	Properties []*Property
+	Components []*Component
+}
+
+// NewCalendar 创建 VCALENDAR 根组件
+func NewCalendar(prodID string) *Component {
+	cal := &Component{Name: ComponentCalendar}
+	cal.Set("VERSION", "2.0")
+	cal.Set("PRODID", prodID)
+	cal.Set("CALSCALE", "GREGORIAN")
+	return cal
+}
+
+// Add 追加属性，params 按 key、value 成对传入
+func (c *Component) Add(name, value string, params ...string) *Property {
+	prop := &Property{Name: strings.ToUpper(name), Value: value}
+	if len(params) > 0 {
+		prop.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			prop.Params[strings.ToUpper(params[i])] = params[i+1]
+		}
+	}
+	c.Properties = append(c.Properties, prop)
+	return prop
+}
+
+// Set 设置属性，已存在的同名属性会被替换
+func (c *Component) Set(name, value string, params ...string) *Property {
+	c.Remove(name)
+	return c.Add(name, value, params...)
+}
+
+// SetText 设置文本属性，自动转义
+func (c *Component) SetText(name, value string) *Property {
+	return c.Set(name, EscapeText(value))
+}
+
+// SetDateTime 以UTC格式设置日期时间属性
+func (c *Component) SetDateTime(name string, t time.Time) *Property {
+	return c.Set(name, FormatDateTime(t))
+}
+
+// Remove 删除同名属性
+func (c *Component) Remove(name string) {
+	name = strings.ToUpper(name)
+	kept := c.Properties[:0]
+	for _, prop := range c.Properties {
+		if prop.Name != name {
+			kept = append(kept, prop)
+		}
+	}
+	c.Properties = kept
+}
+
+// Get 获取第一个同名属性，不存在时返回 nil
+func (c *Component) Get(name string) *Property {
+	name = strings.ToUpper(name)
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			return prop
+		}
+	}
+	return nil
+}
+
+// GetAll 获取所有同名属性
+func (c *Component) GetAll(name string) []*Property {
+	name = strings.ToUpper(name)
+	var props []*Property
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			props = append(props, prop)
+		}
+	}
+	return props
+}
+
+// Text 获取文本属性并反转义，不存在时返回空字符串
+func (c *Component) Text(name string) string {
+	if prop := c.Get(name); prop != nil {
+		return UnescapeText(prop.Value)
+	}
+	return ""
+}
+
+// AddComponent 添加子组件
+func (c *Component) AddComponent(child *Component) {
+	c.Components = append(c.Components, child)
+}
+
+// Children 获取指定名称的子组件
+func (c *Component) Children(name string) []*Component {
+	name = strings.ToUpper(name)
+	var children []*Component
+	for _, child := range c.Components {
+		if child.Name == name {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// Encode 按 RFC 5545 输出组件：CRLF 换行，超过75字节的行折行
+func (c *Component) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := c.encode(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// String 返回序列化后的文本
+func (c *Component) String() string {
+	var sb strings.Builder
+	_ = c.Encode(&sb)
+	return sb.String()
+}
+
+func (c *Component) encode(w *bufio.Writer) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, prop := range c.Properties {
+		if err := writeLine(w, prop.line()); err != nil {
+			return err
+		}
+	}
+	for _, child := range c.Components {
+		if err := child.encode(w); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+// line 生成属性行，参数按名称排序保证输出稳定
+func (p *Property) line() string {
+	var sb strings.Builder
+	sb.WriteString(p.Name)
+
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := p.Params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + strings.ReplaceAll(value, `"`, "") + `"`
+		}
+		sb.WriteString(";" + name + "=" + value)
+	}
+
+	sb.WriteString(":" + p.Value)
+	return sb.String()
+}
+
+// writeLine 写入一行内容，超长时在字符边界折行，续行以空格开头
+func writeLine(w *bufio.Writer, line string) error {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		// 续行开头的空格占用一个字节
+		limit = maxLineOctets - 1
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
+
+// Parse 解析 iCalendar 文本，返回最外层组件（通常为 VCALENDAR）
+func Parse(r io.Reader) (*Component, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *Component
+	var stack []*Component
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			component := &Component{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				stack[len(stack)-1].AddComponent(component)
+			} else if root == nil {
+				root = component
+			} else {
+				return nil, fmt.Errorf("%w: multiple root components", ErrInvalidCalendar)
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("%w: unexpected END:%s", ErrInvalidCalendar, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: property %s outside component", ErrInvalidCalendar, prop.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+
+	if root == nil || len(stack) > 0 {
+		return nil, fmt.Errorf("%w: unterminated component", ErrInvalidCalendar)
+	}
+	return root, nil
+}
+
+// unfoldLines 读取所有行并合并折行
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+
+	// 去掉 UTF-8 BOM
+	if len(lines) > 0 {
+		lines[0] = strings.TrimPrefix(lines[0], "\ufeff")
+	}
+	return lines, nil
+}
+
+// parseProperty 解析属性行：NAME;PARAM=VALUE;PARAM="QUOTED":VALUE
+func parseProperty(line string) (*Property, error) {
+	prop := &Property{}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return nil, fmt.Errorf("%w: malformed line %q", ErrInvalidCalendar, line)
+	}
+	prop.Name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("%w: malformed parameter in %q", ErrInvalidCalendar, line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		var consumed int
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote in %q", ErrInvalidCalendar, line)
+			}
+			value = rest[1 : end+1]
+			consumed = end + 2
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: missing value in %q", ErrInvalidCalendar, line)
+			}
+			value = rest[:end]
+			consumed = end
+		}
+
+		if prop.Params == nil {
+			prop.Params = make(map[string]string)
+		}
+		prop.Params[name] = value
+		i += 1 + eq + 1 + consumed
+		if i >= len(line) {
+			return nil, fmt.Errorf("%w: missing value in %q", ErrInvalidCalendar, line)
+		}
+	}
+
+	if line[i] != ':' {
+		return nil, fmt.Errorf("%w: malformed line %q", ErrInvalidCalendar, line)
+	}
+	prop.Value = line[i+1:]
+	return prop, nil
+}
+
+// EscapeText 转义 TEXT 类型的值
+func EscapeText(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(s)
+}
+
+// UnescapeText 反转义 TEXT 类型的值
+func UnescapeText(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// SplitText 拆分以逗号分隔的多值文本（如 CATEGORIES），忽略转义的逗号
+func SplitText(s string) []string {
+	var values []string
+	var current strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			current.WriteByte(s[i])
+			current.WriteByte(s[i+1])
+			i++
+		case s[i] == ',':
+			values = append(values, UnescapeText(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(s[i])
+		}
+	}
+	values = append(values, UnescapeText(current.String()))
+	return values
+}
+
+// FormatDateTime 格式化为UTC日期时间，例如 20240101T010000Z
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeUTCFormat)
+}
+
+// FormatDate 格式化为日期，例如 20240101
+func FormatDate(t time.Time) string {
+	return t.Format(dateFormat)
+}
+
+// ParseDateTime 解析 DATE 或 DATE-TIME 属性值。
+// 带 Z 后缀按UTC解析，带 TZID 参数按对应时区解析，浮动时间使用 loc；
+// 返回的 allDay 表示值为纯日期
+func ParseDateTime(prop *Property, loc *time.Location) (t time.Time, allDay bool, err error) {
+	if prop == nil {
+		return time.Time{}, false, ErrInvalidDateTime
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	if tzid := prop.Param("TZID"); tzid != "" {
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		}
+	}
+
+	value := strings.TrimSpace(prop.Value)
+	switch {
+	case strings.EqualFold(prop.Param("VALUE"), "DATE") || len(value) == len(dateFormat):
+		t, err = time.ParseInLocation(dateFormat, value, loc)
+		allDay = true
+	case strings.HasSuffix(value, "Z"):
+		t, err = time.Parse(dateTimeUTCFormat, value)
+	default:
+		t, err = time.ParseInLocation(dateTimeFormat, value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %q", ErrInvalidDateTime, prop.Value)
+	}
+	return t, allDay, nil
+}
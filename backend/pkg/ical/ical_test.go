@@ -0,0 +1,130 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode_FoldsLongLines(t *testing.T) {
+	cal := NewCalendar("-//test//EN")
+	todo := &Component{Name: ComponentTodo}
+	todo.SetText("SUMMARY", strings.Repeat("准备季度汇报材料，", 10))
+	cal.AddComponent(todo)
+
+	out := cal.String()
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets, "line %q too long", line)
+	}
+
+	parsed, err := Parse(strings.NewReader(out))
+	require.NoError(t, err)
+	require.Len(t, parsed.Children(ComponentTodo), 1)
+	assert.Equal(t, strings.Repeat("准备季度汇报材料，", 10), parsed.Children(ComponentTodo)[0].Text("SUMMARY"))
+}
+
+func TestParse(t *testing.T) {
+	data := "\ufeffBEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:abc@example.com\r\n" +
+		"SUMMARY:Write report\\, draft\\nsecond line\r\n" +
+		"DESCRIPTION:long desc\r\n" +
+		" ription continues\r\n" +
+		"DUE;TZID=\"Asia/Shanghai\":20240102T090000\r\n" +
+		"CATEGORIES:Work,Team\\, Ops\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"ACTION:DISPLAY\r\n" +
+		"END:VALARM\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal, err := Parse(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, ComponentCalendar, cal.Name)
+
+	todos := cal.Children(ComponentTodo)
+	require.Len(t, todos, 1)
+	todo := todos[0]
+	assert.Equal(t, "Write report, draft\nsecond line", todo.Text("SUMMARY"))
+	assert.Equal(t, "long description continues", todo.Text("DESCRIPTION"))
+	assert.Equal(t, []string{"Work", "Team, Ops"}, SplitText(todo.Get("CATEGORIES").Value))
+	assert.Len(t, todo.Children("VALARM"), 1)
+
+	due, allDay, err := ParseDateTime(todo.Get("DUE"), time.UTC)
+	require.NoError(t, err)
+	assert.False(t, allDay)
+	assert.Equal(t, time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC), due.UTC())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "Empty", data: ""},
+		{name: "Unterminated", data: "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VTODO\r\n"},
+		{name: "Mismatched END", data: "BEGIN:VCALENDAR\r\nEND:VTODO\r\n"},
+		{name: "Property outside component", data: "SUMMARY:x\r\n"},
+		{name: "Malformed line", data: "BEGIN:VCALENDAR\r\ngarbage\r\nEND:VCALENDAR\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data))
+			assert.ErrorIs(t, err, ErrInvalidCalendar)
+		})
+	}
+}
+
+func TestParseDateTime(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		prop       *Property
+		want       time.Time
+		wantAllDay bool
+		wantErr    bool
+	}{
+		{
+			name: "UTC",
+			prop: &Property{Value: "20240301T120000Z"},
+			want: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Floating uses default location",
+			prop: &Property{Value: "20240301T120000"},
+			want: time.Date(2024, 3, 1, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Date only",
+			prop:       &Property{Value: "20240301", Params: map[string]string{"VALUE": "DATE"}},
+			want:       time.Date(2024, 2, 29, 16, 0, 0, 0, time.UTC),
+			wantAllDay: true,
+		},
+		{
+			name: "Explicit TZID",
+			prop: &Property{Value: "20240301T120000", Params: map[string]string{"TZID": "America/New_York"}},
+			want: time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC),
+		},
+		{name: "Invalid", prop: &Property{Value: "tomorrow"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allDay, err := ParseDateTime(tt.prop, shanghai)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidDateTime)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.UTC())
+			assert.Equal(t, tt.wantAllDay, allDay)
+		})
+	}
+}
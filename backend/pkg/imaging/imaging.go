@@ -0,0 +1,241 @@
+// Package imaging 处理上传的图片：解码校验、按 EXIF 方向摆正、去除元数据和生成缩放版本。
+// 只使用纯 Go 编解码器，可读取 JPEG、PNG、GIF、WebP、BMP；由于没有纯 Go 的 WebP 编码器，
+// 缩放版本输出为 JPEG（不透明）或 PNG（带透明通道），WebP 原图在容器层面去除元数据后保留原格式
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// 支持的图片格式，与 image.Decode 返回的格式名一致
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+	FormatWebP = "webp"
+	FormatBMP  = "bmp"
+)
+
+const (
+	// MaxPixels 允许解码的最大像素数，防止很小的文件解码出超大图片耗尽内存
+	MaxPixels = 50_000_000
+	// MaxGIFFrames 允许解码的 GIF 最大帧数，所有帧的像素总数同样受 MaxPixels 限制
+	MaxGIFFrames = 1000
+	// JPEGQuality 重新编码 JPEG 时使用的质量
+	JPEGQuality = 85
+)
+
+var (
+	ErrInvalidImage = errors.New("invalid image")
+	ErrTooLarge     = errors.New("image dimensions too large")
+)
+
+// Image 解码后的图片
+type Image struct {
+	Image       image.Image // 已按 EXIF 方向摆正
+	Format      string
+	Width       int
+	Height      int
+	Orientation int // 原图的 EXIF 方向，1 为正常
+}
+
+// Decode 解码并校验图片，先读取尺寸拒绝超大图片再完整解码
+func Decode(data []byte) (*Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: empty image", ErrInvalidImage)
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	orientation := 1
+	if format == FormatJPEG {
+		orientation = jpegOrientation(data)
+		img = orient(img, orientation)
+	}
+	b := img.Bounds()
+	return &Image{
+		Image:       img,
+		Format:      format,
+		Width:       b.Dx(),
+		Height:      b.Dy(),
+		Orientation: orientation,
+	}, nil
+}
+
+// Clean 返回去除元数据后的原图及其格式。JPEG、PNG、WebP 直接删除元数据段，不重新编码；
+// 需要旋转的 JPEG 按摆正后的图像重新编码，GIF 逐帧重新编码，BMP 转为 PNG
+func Clean(data []byte, img *Image) ([]byte, string, error) {
+	switch img.Format {
+	case FormatJPEG:
+		if img.Orientation != 1 {
+			out, err := Encode(img.Image, FormatJPEG)
+			return out, FormatJPEG, err
+		}
+		out, err := stripJPEG(data)
+		return out, FormatJPEG, err
+	case FormatPNG:
+		out, err := stripPNG(data)
+		return out, FormatPNG, err
+	case FormatWebP:
+		out, err := stripWebP(data)
+		return out, FormatWebP, err
+	case FormatGIF:
+		// 逐帧解码前先检查帧数和总像素数，避免大量小帧的动图耗尽内存
+		frames, pixels, err := gifFrames(data)
+		if err != nil {
+			return nil, "", err
+		}
+		if frames > MaxGIFFrames || pixels > MaxPixels {
+			return nil, "", fmt.Errorf("%w: %d frames, %d pixels", ErrTooLarge, frames, pixels)
+		}
+		anim, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, anim); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), FormatGIF, nil
+	default:
+		out, err := Encode(img.Image, FormatPNG)
+		return out, FormatPNG, err
+	}
+}
+
+// Fit 等比缩小图片使其不超过 maxWidth×maxHeight，原图更小时原样返回
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxWidth && h <= maxHeight {
+		return img
+	}
+
+	scale := float64(maxWidth) / float64(w)
+	if s := float64(maxHeight) / float64(h); s < scale {
+		scale = s
+	}
+	dw := max(1, int(float64(w)*scale+0.5))
+	dh := max(1, int(float64(h)*scale+0.5))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// VariantFormat 缩放版本的输出格式：不透明的图片用 JPEG，否则用 PNG 保留透明通道
+func VariantFormat(img image.Image) string {
+	if isOpaque(img) {
+		return FormatJPEG
+	}
+	return FormatPNG
+}
+
+// Encode 以指定格式编码图片，仅支持 JPEG、PNG、GIF
+func Encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encode(&buf, img, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: JPEGQuality})
+	case FormatPNG:
+		return (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(w, img)
+	case FormatGIF:
+		return gif.Encode(w, img, nil)
+	}
+	return fmt.Errorf("encoding %s is not supported", format)
+}
+
+// ContentType 图片格式对应的 MIME 类型
+func ContentType(format string) string {
+	return "image/" + format
+}
+
+// Extension 图片格式对应的文件扩展名
+func Extension(format string) string {
+	if format == FormatJPEG {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// orient 按 EXIF 方向（1-8）旋转或翻转图片
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 水平翻转
+				sx, sy = w-1-x, y
+			case 3: // 旋转 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				sx, sy = x, h-1-y
+			case 5: // 沿主对角线翻转
+				sx, sy = y, x
+			case 6: // 顺时针旋转 90°
+				sx, sy = y, h-1-x
+			case 7: // 沿副对角线翻转
+				sx, sy = w-1-y, h-1-x
+			case 8: // 逆时针旋转 90°
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, color.NRGBAModel.Convert(img.At(b.Min.X+sx, b.Min.Y+sy)))
+		}
+	}
+	return dst
+}
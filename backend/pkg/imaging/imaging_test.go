@@ -0,0 +1,292 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	red  = color.NRGBA{R: 255, A: 255}
+	blue = color.NRGBA{B: 255, A: 255}
+)
+
+// halves 左半红、右半蓝的图片
+func halves(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, red)
+			} else {
+				img.Set(x, y, blue)
+			}
+		}
+	}
+	return img
+}
+
+// exifSegment 只包含方向标签的 APP1 段
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, exifTagOrientation)
+	tiff = binary.BigEndian.AppendUint16(tiff, exifTypeShort)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	seg := []byte{0xFF, jpegMarkerAPP1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// jpegWithSegments 在 SOI 之后插入额外的段
+func jpegWithSegments(t *testing.T, img image.Image, segments ...[]byte) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	for _, seg := range segments {
+		out = append(out, seg...)
+	}
+	return append(out, data[2:]...)
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	chunk = append(chunk, typ...)
+	chunk = append(chunk, data...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func assertColor(t *testing.T, want color.NRGBA, got color.Color) {
+	t.Helper()
+	c := color.NRGBAModel.Convert(got).(color.NRGBA)
+	assert.InDelta(t, want.R, c.R, 40)
+	assert.InDelta(t, want.G, c.G, 40)
+	assert.InDelta(t, want.B, c.B, 40)
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	_, err := Decode([]byte("<?php echo 1; ?>"))
+	assert.ErrorIs(t, err, ErrInvalidImage)
+
+	// 文件头合法但数据被截断
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, halves(8, 8)))
+	_, err = Decode(buf.Bytes()[:buf.Len()-20])
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestDecode_TooLarge(t *testing.T) {
+	// 只构造 IHDR，尺寸检查在解码像素之前完成
+	ihdr := binary.BigEndian.AppendUint32(nil, 100000)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, 100000)
+	ihdr = append(ihdr, 8, 6, 0, 0, 0)
+	data := append([]byte(pngSignature), pngChunk("IHDR", ihdr)...)
+
+	_, err := Decode(data)
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestDecodeAndClean_JPEGOrientation(t *testing.T) {
+	data := jpegWithSegments(t, halves(16, 8), exifSegment(6))
+
+	img, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJPEG, img.Format)
+	assert.Equal(t, 6, img.Orientation)
+	assert.Equal(t, 8, img.Width)
+	assert.Equal(t, 16, img.Height)
+	// 顺时针旋转 90° 后左边的红色在上方
+	assertColor(t, red, img.Image.At(4, 1))
+	assertColor(t, blue, img.Image.At(4, 14))
+
+	cleaned, format, err := Clean(data, img)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJPEG, format)
+	assert.NotContains(t, string(cleaned), "Exif")
+
+	again, err := Decode(cleaned)
+	require.NoError(t, err)
+	assert.Equal(t, 1, again.Orientation)
+	assert.Equal(t, 8, again.Width)
+	assert.Equal(t, 16, again.Height)
+}
+
+func TestClean_JPEGStripsSegmentsWithoutReencoding(t *testing.T) {
+	comment := append([]byte{0xFF, jpegMarkerCOM, 0x00, 0x07}, "GPS!!"...)
+	data := jpegWithSegments(t, halves(16, 8), exifSegment(1), comment)
+
+	img, err := Decode(data)
+	require.NoError(t, err)
+	cleaned, _, err := Clean(data, img)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(cleaned), "Exif")
+	assert.NotContains(t, string(cleaned), "GPS!!")
+	assert.Equal(t, len(data)-len(exifSegment(1))-len(comment), len(cleaned))
+	_, err = Decode(cleaned)
+	assert.NoError(t, err)
+}
+
+func TestClean_PNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, halves(8, 8)))
+	data := buf.Bytes()
+	// 在 IHDR 之后插入文本块
+	ihdrEnd := len(pngSignature) + 12 + 13
+	withText := append([]byte{}, data[:ihdrEnd]...)
+	withText = append(withText, pngChunk("tEXt", []byte("Author\x00someone"))...)
+	withText = append(withText, data[ihdrEnd:]...)
+
+	img, err := Decode(withText)
+	require.NoError(t, err)
+	cleaned, format, err := Clean(withText, img)
+	require.NoError(t, err)
+	assert.Equal(t, FormatPNG, format)
+	assert.Equal(t, data, cleaned)
+}
+
+// gifWithFrames 手工构造的 GIF，只包含帧头和空的图像数据，用于测试解码前的检查
+func gifWithFrames(frames int, w, h uint16) []byte {
+	data := []byte("GIF89a")
+	data = binary.LittleEndian.AppendUint16(data, w)
+	data = binary.LittleEndian.AppendUint16(data, h)
+	data = append(data, 0, 0, 0)
+	for i := 0; i < frames; i++ {
+		data = append(data, 0x21, 0xF9, 4, 0, 0, 0, 0, 0) // 图形控制扩展
+		data = append(data, 0x2C, 0, 0, 0, 0)
+		data = binary.LittleEndian.AppendUint16(data, w)
+		data = binary.LittleEndian.AppendUint16(data, h)
+		data = append(data, 0, 2, 0)
+	}
+	return append(data, 0x3B)
+}
+
+func TestClean_GIF(t *testing.T) {
+	palette := color.Palette{red, blue}
+	anim := &gif.GIF{}
+	for i := 0; i < 3; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+		frame.SetColorIndex(i, i, 1)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+
+	frames, pixels, err := gifFrames(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 3, frames)
+	assert.EqualValues(t, 48, pixels)
+
+	img, err := Decode(buf.Bytes())
+	require.NoError(t, err)
+	cleaned, format, err := Clean(buf.Bytes(), img)
+	require.NoError(t, err)
+	assert.Equal(t, FormatGIF, format)
+	decoded, err := gif.DecodeAll(bytes.NewReader(cleaned))
+	require.NoError(t, err)
+	assert.Len(t, decoded.Image, 3)
+}
+
+func TestClean_GIFLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"too many frames", gifWithFrames(MaxGIFFrames+1, 1, 1), ErrTooLarge},
+		{"too many pixels in total", gifWithFrames(3, 5000, 5000), ErrTooLarge},
+		{"truncated", gifWithFrames(1, 1, 1)[:20], ErrInvalidImage},
+		{"unknown block", append(gifWithFrames(1, 1, 1)[:13], 0x99), ErrInvalidImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Clean(tt.data, &Image{Format: FormatGIF})
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestStripWebP(t *testing.T) {
+	chunk := func(fourcc string, data []byte) []byte {
+		c := append([]byte(fourcc), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+		c = append(c, data...)
+		if len(data)%2 == 1 {
+			c = append(c, 0)
+		}
+		return c
+	}
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", []byte{webpFlagEXIF | webpFlagXMP | 0x10, 0, 0, 0, 7, 0, 0, 7, 0, 0})...)
+	body = append(body, chunk("VP8L", []byte{1, 2, 3})...)
+	body = append(body, chunk("EXIF", []byte("exif data"))...)
+	body = append(body, chunk("XMP ", []byte("<x/>"))...)
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	data = append(data, body...)
+
+	out, err := stripWebP(data)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "EXIF")
+	assert.NotContains(t, string(out), "XMP")
+	assert.Equal(t, uint32(len(out)-8), binary.LittleEndian.Uint32(out[4:]))
+	// 只清除 EXIF、XMP 标志，保留透明通道标志
+	assert.Equal(t, byte(0x10), out[20])
+	assert.Contains(t, string(out), "VP8L")
+}
+
+func TestFit(t *testing.T) {
+	src := halves(400, 200)
+
+	small := Fit(src, 100, 100)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), small.Bounds())
+	assertColor(t, red, small.At(10, 25))
+	assertColor(t, blue, small.At(90, 25))
+
+	assert.Same(t, src, Fit(src, 1000, 1000))
+}
+
+func TestVariantFormat(t *testing.T) {
+	assert.Equal(t, FormatJPEG, VariantFormat(halves(4, 4)))
+
+	transparent := halves(4, 4)
+	transparent.Set(0, 0, color.NRGBA{})
+	assert.Equal(t, FormatPNG, VariantFormat(transparent))
+}
+
+func TestOrient(t *testing.T) {
+	// 2×1 的图片：左红右蓝
+	src := halves(2, 1)
+	tests := []struct {
+		orientation int
+		size        image.Point
+		first       color.NRGBA // 结果左上角的像素
+	}{
+		{2, image.Pt(2, 1), blue},
+		{3, image.Pt(2, 1), blue},
+		{4, image.Pt(2, 1), red},
+		{5, image.Pt(1, 2), red},
+		{6, image.Pt(1, 2), red},
+		{7, image.Pt(1, 2), blue},
+		{8, image.Pt(1, 2), blue},
+	}
+	for _, tt := range tests {
+		got := orient(src, tt.orientation)
+		assert.Equal(t, tt.size, got.Bounds().Size(), "orientation %d", tt.orientation)
+		assert.Equal(t, tt.first, got.At(0, 0), "orientation %d", tt.orientation)
+	}
+	assert.Same(t, image.Image(src), orient(src, 1))
+}
@@ -0,0 +1,303 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	jpegMarkerSOS   = 0xDA
+	jpegMarkerEOI   = 0xD9
+	jpegMarkerCOM   = 0xFE
+	jpegMarkerAPP0  = 0xE0
+	jpegMarkerAPP1  = 0xE1
+	jpegMarkerAPP2  = 0xE2
+	jpegMarkerAPP14 = 0xEE
+	jpegMarkerAPP15 = 0xEF
+
+	exifTagOrientation = 0x0112
+	exifTypeShort      = 3
+
+	// VP8X 标志位中表示存在 EXIF、XMP 块的位
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// GIF 块的引导字节
+const (
+	gifExtension       = 0x21
+	gifImageDescriptor = 0x2C
+	gifTrailer         = 0x3B
+)
+
+// pngMetadataChunks 会被删除的 PNG 文本、EXIF 和时间块
+var pngMetadataChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+// jpegSegment 图像数据（SOS）之前的一个 JPEG 段
+type jpegSegment struct {
+	marker  byte
+	raw     []byte // 包含标记和长度的完整段
+	payload []byte
+}
+
+// jpegSegments 拆分 SOS 之前的所有段，rest 为从 SOS（或 EOI）开始的剩余数据
+func jpegSegments(data []byte) (segments []jpegSegment, rest []byte, err error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, nil, fmt.Errorf("%w: missing JPEG SOI marker", ErrInvalidImage)
+	}
+	i := 2
+	for i < len(data) {
+		if data[i] != 0xFF {
+			return nil, nil, fmt.Errorf("%w: malformed JPEG segment", ErrInvalidImage)
+		}
+		// 标记前可以有任意个 0xFF 填充字节
+		for i+1 < len(data) && data[i+1] == 0xFF {
+			i++
+		}
+		if i+1 >= len(data) {
+			break
+		}
+		marker := data[i+1]
+		switch {
+		case marker == jpegMarkerSOS || marker == jpegMarkerEOI:
+			return segments, data[i:], nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// TEM 和 RSTn 没有长度字段
+			segments = append(segments, jpegSegment{marker: marker, raw: data[i : i+2]})
+			i += 2
+			continue
+		}
+		if i+4 > len(data) {
+			break
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			break
+		}
+		segments = append(segments, jpegSegment{marker: marker, raw: data[i : i+2+n], payload: data[i+4 : i+2+n]})
+		i += 2 + n
+	}
+	return nil, nil, fmt.Errorf("%w: truncated JPEG", ErrInvalidImage)
+}
+
+// keepJPEGSegment 保留 JFIF、ICC 颜色配置和 Adobe 段，删除 EXIF、XMP、IPTC 等其它应用段和注释
+func keepJPEGSegment(seg jpegSegment) bool {
+	switch {
+	case seg.marker == jpegMarkerCOM:
+		return false
+	case seg.marker == jpegMarkerAPP0:
+		return true
+	case seg.marker == jpegMarkerAPP2:
+		return bytes.HasPrefix(seg.payload, []byte("ICC_PROFILE\x00"))
+	case seg.marker == jpegMarkerAPP14:
+		return bytes.HasPrefix(seg.payload, []byte("Adobe"))
+	case seg.marker >= jpegMarkerAPP1 && seg.marker <= jpegMarkerAPP15:
+		return false
+	}
+	return true
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	segments, rest, err := jpegSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xFF, 0xD8)
+	for _, seg := range segments {
+		if keepJPEGSegment(seg) {
+			out = append(out, seg.raw...)
+		}
+	}
+	return append(out, rest...), nil
+}
+
+// jpegOrientation 读取 JPEG 中 EXIF 的方向标签，缺失或无效时返回 1
+func jpegOrientation(data []byte) int {
+	segments, _, err := jpegSegments(data)
+	if err != nil {
+		return 1
+	}
+	for _, seg := range segments {
+		if seg.marker == jpegMarkerAPP1 && bytes.HasPrefix(seg.payload, []byte("Exif\x00\x00")) {
+			return exifOrientation(seg.payload[6:])
+		}
+	}
+	return 1
+}
+
+// exifOrientation 在 TIFF 结构的第一个 IFD 中查找方向标签
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < entries; k++ {
+		e := int(ifd) + 2 + k*12
+		if e+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[e:]) != exifTagOrientation {
+			continue
+		}
+		if order.Uint16(tiff[e+2:]) != exifTypeShort {
+			return 1
+		}
+		if v := int(order.Uint16(tiff[e+8:])); v >= 1 && v <= 8 {
+			return v
+		}
+		return 1
+	}
+	return 1
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil, fmt.Errorf("%w: missing PNG signature", ErrInvalidImage)
+	}
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	for i := len(pngSignature); i < len(data); {
+		if i+12 > len(data) {
+			return nil, fmt.Errorf("%w: truncated PNG chunk", ErrInvalidImage)
+		}
+		n := int64(binary.BigEndian.Uint32(data[i:]))
+		if n > int64(len(data)-i-12) {
+			return nil, fmt.Errorf("%w: truncated PNG chunk", ErrInvalidImage)
+		}
+		end := i + 12 + int(n)
+		typ := string(data[i+4 : i+8])
+		if !pngMetadataChunks[typ] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+		if typ == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
+
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, fmt.Errorf("%w: missing WebP header", ErrInvalidImage)
+	}
+	limit := len(data)
+	if size := int64(binary.LittleEndian.Uint32(data[4:])) + 8; size < int64(limit) {
+		limit = int(size)
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	for i := 12; i+8 <= limit; {
+		fourcc := string(data[i : i+4])
+		n := int64(binary.LittleEndian.Uint32(data[i+4:]))
+		if n > int64(limit-i-8) {
+			return nil, fmt.Errorf("%w: truncated WebP chunk", ErrInvalidImage)
+		}
+		// 块长度为奇数时后面有一个填充字节
+		end := min(i+8+int(n+n&1), limit)
+		switch fourcc {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if n > 0 {
+				out[start+8] &^= webpFlagEXIF | webpFlagXMP
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
+
+// gifFrames 不解码像素，只遍历 GIF 的块结构统计帧数和所有帧的像素总数。
+// 帧数或像素数超过上限后立即停止遍历
+func gifFrames(data []byte) (frames int, pixels int64, err error) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return 0, 0, fmt.Errorf("%w: missing GIF header", ErrInvalidImage)
+	}
+	i := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		i += 3 << ((flags & 0x07) + 1)
+	}
+
+	for frames <= MaxGIFFrames && pixels <= MaxPixels {
+		if i >= len(data) {
+			return 0, 0, fmt.Errorf("%w: truncated GIF", ErrInvalidImage)
+		}
+		switch data[i] {
+		case gifTrailer:
+			return frames, pixels, nil
+		case gifExtension:
+			if i+2 > len(data) {
+				return 0, 0, fmt.Errorf("%w: truncated GIF extension", ErrInvalidImage)
+			}
+			if i, err = skipGIFSubBlocks(data, i+2); err != nil {
+				return 0, 0, err
+			}
+		case gifImageDescriptor:
+			if i+11 > len(data) {
+				return 0, 0, fmt.Errorf("%w: truncated GIF image descriptor", ErrInvalidImage)
+			}
+			w := int64(binary.LittleEndian.Uint16(data[i+5:]))
+			h := int64(binary.LittleEndian.Uint16(data[i+7:]))
+			frames++
+			pixels += w * h
+			next := i + 10
+			if flags := data[i+9]; flags&0x80 != 0 {
+				next += 3 << ((flags & 0x07) + 1)
+			}
+			// 跳过 LZW 最小码长后的图像数据
+			if i, err = skipGIFSubBlocks(data, next+1); err != nil {
+				return 0, 0, err
+			}
+		default:
+			return 0, 0, fmt.Errorf("%w: unknown GIF block 0x%02x", ErrInvalidImage, data[i])
+		}
+	}
+	return frames, pixels, nil
+}
+
+// skipGIFSubBlocks 跳过从 i 开始的数据子块序列，返回结束块之后的位置
+func skipGIFSubBlocks(data []byte, i int) (int, error) {
+	for {
+		if i >= len(data) {
+			return 0, fmt.Errorf("%w: truncated GIF data", ErrInvalidImage)
+		}
+		n := int(data[i])
+		i++
+		if n == 0 {
+			return i, nil
+		}
+		i += n
+	}
+}
@@ -1,7 +1,11 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"gin-web-framework/config"
@@ -9,26 +13,53 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ErrTokenRevoked 访问令牌已被吊销（退出登录或刷新令牌被盗用）
+var ErrTokenRevoked = errors.New("token has been revoked")
+
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"` // 签发该令牌的登录会话，即刷新令牌所属的令牌族
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT token
-func GenerateToken(userID uint, username, email, role string) (string, error) {
+// Denylist 已吊销的访问令牌，按 jti 查询
+type Denylist interface {
+	IsRevoked(jti string) (bool, error)
+}
+
+var (
+	denylistMu sync.RWMutex
+	denylist   Denylist
+)
+
+// SetDenylist 设置吊销列表，之后 ValidateToken 会拒绝列表中的令牌
+func SetDenylist(d Denylist) {
+	denylistMu.Lock()
+	defer denylistMu.Unlock()
+	denylist = d
+}
+
+// GenerateToken 生成短期有效的访问令牌，每个令牌带有唯一的 jti 以便单独吊销
+func GenerateToken(userID uint, username, email, role, sessionID string) (string, *Claims, error) {
 	cfg := config.Get()
 	now := time.Now()
 
-	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
-		Role:     role,
+	jti, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
+	claims := &Claims{
+		UserID:    userID,
+		Username:  username,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(cfg.GetJWT().ExpireHours) * time.Hour)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.GetJWT().AccessExpire)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "gin-web-framework",
@@ -36,7 +67,11 @@ func GenerateToken(userID uint, username, email, role string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(cfg.GetJWT().Secret))
+	signed, err := token.SignedString([]byte(cfg.GetJWT().Secret))
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
 // ParseToken 解析JWT token
@@ -61,17 +96,34 @@ func ParseToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-// RefreshToken 刷新token
-func RefreshToken(tokenString string) (string, error) {
+// ValidateToken 解析访问令牌并检查是否已被吊销，认证中间件和 WebSocket 握手都应使用它。
+// 升级前签发的令牌没有 jti，无法单独吊销，只能等待过期
+func ValidateToken(tokenString string) (*Claims, error) {
 	claims, err := ParseToken(tokenString)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// 检查token是否即将过期（在1小时内）
-	if time.Until(claims.ExpiresAt.Time) > time.Hour {
-		return "", errors.New("token does not need refresh")
+	denylistMu.RLock()
+	d := denylist
+	denylistMu.RUnlock()
+	if d == nil || claims.ID == "" {
+		return claims, nil
 	}
+	revoked, err := d.IsRevoked(claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %v", err)
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
 
-	return GenerateToken(claims.UserID, claims.Username, claims.Email, claims.Role)
+func newTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
 }
@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File 把邮件写成 .eml 文件，可以直接用邮件客户端打开查看
+type File struct {
+	dir  string
+	from string
+}
+
+// NewFile 创建写入 dir 目录的发送器
+func NewFile(dir, from string) *File {
+	return &File{dir: dir, from: from}
+}
+
+// Send 写入邮件文件，文件名以发送时间开头，便于按时间排序
+func (f *File) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := msg.Bytes(f.from, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %v", err)
+	}
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	name := now.Format("20060102-150405.000") + "-" + hex.EncodeToString(suffix) + ".eml"
+	if err := os.WriteFile(filepath.Join(f.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+)
+
+// Log 只把邮件写入日志，不真正发送
+type Log struct {
+	from   string
+	logger Logger
+}
+
+// NewLog 创建写日志的发送器
+func NewLog(from string, logger Logger) *Log {
+	return &Log{from: from, logger: logger}
+}
+
+// Send 记录收件人、主题和纯文本正文
+func (l *Log) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+	body := msg.Text
+	if body == "" {
+		body = msg.HTML
+	}
+	l.logger.Infof("Mail from %s to %s: %s\n%s", l.from, strings.Join(msg.To, ", "), msg.Subject, body)
+	return nil
+}
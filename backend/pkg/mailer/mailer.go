@@ -0,0 +1,59 @@
+// Package mailer 邮件发送。smtp 驱动用于生产环境；file 驱动把邮件写成 .eml 文件，
+// log 驱动只把邮件内容写入日志，两者用于本地开发和测试
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 邮件驱动
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+var ErrNoRecipients = errors.New("mail has no recipients")
+
+// Message 待发送的邮件，Text 和 HTML 至少提供一个，都提供时以 multipart/alternative 发送
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer 邮件发送器
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Logger log 驱动使用的日志接口，pkg/logger 的日志器满足该接口
+type Logger interface {
+	Infof(format string, args ...interface{})
+}
+
+// Config 邮件配置
+type Config struct {
+	Driver string
+	// From 发件人，可以带名称，例如 "My App <no-reply@example.com>"
+	From string
+	SMTP SMTPConfig
+	// FileDir file 驱动写入邮件的目录
+	FileDir string
+}
+
+// New 按配置创建邮件发送器
+func New(cfg Config, logger Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case "", DriverLog:
+		return NewLog(cfg.From, logger), nil
+	case DriverFile:
+		return NewFile(cfg.FileDir, cfg.From), nil
+	case DriverSMTP:
+		return NewSMTP(cfg.SMTP, cfg.From)
+	}
+	return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+}
@@ -0,0 +1,140 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_Bytes(t *testing.T) {
+	msg := &Message{
+		To:      []string{"alice@example.com"},
+		Subject: "重置密码",
+		Text:    "你好，点击链接重置密码",
+		HTML:    "<p>你好</p>",
+	}
+	data, err := msg.Bytes("My App <no-reply@example.com>", time.Unix(1700000000, 0))
+	require.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "重置密码", subject)
+	assert.Equal(t, "<alice@example.com>", parsed.Header.Get("To"))
+	assert.Contains(t, parsed.Header.Get("Message-ID"), "@example.com>")
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		require.NoError(t, err)
+		bodies = append(bodies, part.Header.Get("Content-Type")+"|"+string(body))
+	}
+	assert.Equal(t, []string{
+		"text/plain; charset=UTF-8|你好，点击链接重置密码",
+		"text/html; charset=UTF-8|<p>你好</p>",
+	}, bodies)
+}
+
+func TestMessage_BytesSinglePart(t *testing.T) {
+	data, err := (&Message{To: []string{"bob@example.com"}, Subject: "Hi", Text: "plain"}).Bytes("no-reply@example.com", time.Now())
+	require.NoError(t, err)
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=UTF-8", parsed.Header.Get("Content-Type"))
+}
+
+func TestMessage_BytesInvalid(t *testing.T) {
+	_, err := (&Message{Subject: "Hi", Text: "x"}).Bytes("no-reply@example.com", time.Now())
+	assert.ErrorIs(t, err, ErrNoRecipients)
+
+	_, err = (&Message{To: []string{"not an address"}, Text: "x"}).Bytes("no-reply@example.com", time.Now())
+	assert.Error(t, err)
+
+	// 收件人中的换行不能注入额外的邮件头
+	_, err = (&Message{To: []string{"a@example.com\r\nBcc: evil@example.com"}, Text: "x"}).Bytes("no-reply@example.com", time.Now())
+	assert.Error(t, err)
+}
+
+func TestFile_Send(t *testing.T) {
+	dir := t.TempDir()
+	m, err := New(Config{Driver: DriverFile, From: "no-reply@example.com", FileDir: dir}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Send(context.Background(), &Message{To: []string{"alice@example.com"}, Subject: "Hi", Text: "hello"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "hello")
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := New(Config{Driver: "pigeon"}, nil)
+	assert.Error(t, err)
+
+	_, err = New(Config{Driver: DriverSMTP, From: "no-reply@example.com"}, nil)
+	assert.Error(t, err, "smtp host is required")
+}
+
+func TestTemplates_Render(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mail/welcome.zh-CN.tmpl": {Data: []byte(`{{define "subject"}}欢迎 {{.Name}}{{end}}
+{{define "text"}}你好 {{.Name}}{{end}}
+{{define "html"}}<p>你好 {{.Name}}</p>{{end}}`)},
+		"mail/welcome.en.tmpl": {Data: []byte(`{{define "subject"}}
+  Welcome {{.Name}}
+{{end}}
+{{define "text"}}Hello {{.Name}}{{end}}`)},
+	}
+	templates, err := ParseTemplates(fsys, "mail/*.tmpl", "zh-CN")
+	require.NoError(t, err)
+
+	data := map[string]string{"Name": "<Bob>"}
+
+	msg, err := templates.Render("welcome", "en-US", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome <Bob>", msg.Subject)
+	assert.Equal(t, "Hello <Bob>", msg.Text)
+	assert.Empty(t, msg.HTML)
+
+	msg, err = templates.Render("welcome", "fr", data)
+	require.NoError(t, err)
+	assert.Equal(t, "欢迎 <Bob>", msg.Subject)
+	assert.Equal(t, "<p>你好 &lt;Bob&gt;</p>", msg.HTML)
+
+	_, err = templates.Render("missing", "en", data)
+	assert.Error(t, err)
+}
+
+func TestParseTemplates_Invalid(t *testing.T) {
+	_, err := ParseTemplates(fstest.MapFS{"a.tmpl": {Data: []byte(`{{define "text"}}x{{end}}`)}}, "*.tmpl", "en")
+	assert.Error(t, err)
+
+	_, err = ParseTemplates(fstest.MapFS{"a.en.tmpl": {Data: []byte(`{{define "text"}}x{{end}}`)}}, "*.tmpl", "en")
+	assert.Error(t, err, "subject is required")
+}
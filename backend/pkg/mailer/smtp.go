@@ -0,0 +1,119 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// smtpTimeout 调用方没有设置截止时间时，单封邮件的发送超时
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig SMTP 服务器配置。端口为 465 时使用隐式 TLS，其他端口在服务器支持时升级为 STARTTLS
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// SMTP 通过 SMTP 服务器发送邮件
+type SMTP struct {
+	cfg  SMTPConfig
+	from string
+}
+
+// NewSMTP 创建 SMTP 发送器
+func NewSMTP(cfg SMTPConfig, from string) (*SMTP, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("smtp host is required")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %v", from, err)
+	}
+	return &SMTP{cfg: cfg, from: from}, nil
+}
+
+// Send 发送邮件
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	data, err := msg.Bytes(s.from, time.Now())
+	if err != nil {
+		return err
+	}
+	sender, _ := mail.ParseAddress(s.from)
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, smtpTimeout)
+		defer cancel()
+	}
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to smtp server: %v", err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start smtp session: %v", err)
+	}
+	defer client.Close()
+
+	if s.cfg.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+				return fmt.Errorf("failed to start tls: %v", err)
+			}
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("smtp authentication failed: %v", err)
+		}
+	}
+
+	if err := client.Mail(sender.Address); err != nil {
+		return fmt.Errorf("smtp MAIL FROM failed: %v", err)
+	}
+	for _, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", to, err)
+		}
+		if err := client.Rcpt(addr.Address); err != nil {
+			return fmt.Errorf("smtp RCPT TO %s failed: %v", addr.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp DATA failed: %v", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write mail: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+	return client.Quit()
+}
+
+func (s *SMTP) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{}
+	if s.cfg.Port == 465 {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.cfg.Host}}
+		return tlsDialer.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
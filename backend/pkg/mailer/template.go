@@ -0,0 +1,110 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates 多语言邮件模板。模板文件命名为 <名称>.<语言>.tmpl，例如 password_reset.zh-CN.tmpl，
+// 文件中用 define 定义 subject、text 和 html 三个模板，text 和 html 可以只提供一个。
+// html 模板按 html/template 规则转义
+type Templates struct {
+	text         map[string]*texttemplate.Template
+	html         map[string]*htmltemplate.Template
+	fallbackLang string
+}
+
+// ParseTemplates 读取 fsys 中匹配 pattern 的模板，找不到请求的语言时使用 fallbackLang
+func ParseTemplates(fsys fs.FS, pattern, fallbackLang string) (*Templates, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no mail templates match %q", pattern)
+	}
+
+	t := &Templates{
+		text:         make(map[string]*texttemplate.Template),
+		html:         make(map[string]*htmltemplate.Template),
+		fallbackLang: fallbackLang,
+	}
+	for _, file := range files {
+		key := strings.TrimSuffix(path.Base(file), ".tmpl")
+		if strings.Count(key, ".") != 1 {
+			return nil, fmt.Errorf("mail template %s must be named <name>.<lang>.tmpl", file)
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		if t.text[key], err = texttemplate.New(key).Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %v", file, err)
+		}
+		if t.html[key], err = htmltemplate.New(key).Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %v", file, err)
+		}
+		if t.text[key].Lookup("subject") == nil {
+			return nil, fmt.Errorf("mail template %s has no subject", file)
+		}
+	}
+	return t, nil
+}
+
+// Render 渲染邮件。语言依次尝试 lang（如 en-US）、基础语言（如 en）和默认语言
+func (t *Templates) Render(name, lang string, data interface{}) (*Message, error) {
+	key, ok := t.resolve(name, lang)
+	if !ok {
+		return nil, fmt.Errorf("mail template %q not found", name)
+	}
+
+	msg := &Message{}
+	var err error
+	if msg.Subject, err = t.execText(key, "subject", data); err != nil {
+		return nil, err
+	}
+	msg.Subject = strings.Join(strings.Fields(msg.Subject), " ")
+	if t.text[key].Lookup("text") != nil {
+		if msg.Text, err = t.execText(key, "text", data); err != nil {
+			return nil, err
+		}
+	}
+	if t.html[key].Lookup("html") != nil {
+		var buf bytes.Buffer
+		if err := t.html[key].ExecuteTemplate(&buf, "html", data); err != nil {
+			return nil, fmt.Errorf("failed to render mail template %s: %v", key, err)
+		}
+		msg.HTML = strings.TrimSpace(buf.String())
+	}
+	return msg, nil
+}
+
+func (t *Templates) resolve(name, lang string) (string, bool) {
+	candidates := []string{lang}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, t.fallbackLang)
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if _, ok := t.text[name+"."+candidate]; ok {
+			return name + "." + candidate, true
+		}
+	}
+	return "", false
+}
+
+func (t *Templates) execText(key, name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.text[key].ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render mail template %s: %v", key, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
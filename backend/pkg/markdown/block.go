@@ -0,0 +1,491 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gin-web-framework/pkg/utils"
+)
+
+// maxAnchorLength 标题锚点的最大长度
+const maxAnchorLength = 64
+
+var (
+	atxHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ ]+(.*?))?(?:[ ]+#+)?[ ]*$`)
+	fenceOpenRe  = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ ]*([^ `]*)[^`]*$")
+	listItemRe   = regexp.MustCompile(`^( {0,3})([-*+]|[0-9]{1,9}[.)])( +|$)`)
+	taskItemRe   = regexp.MustCompile(`^\[([ xX])\][ ]+`)
+	tableDelimRe = regexp.MustCompile(`^ {0,3}\|?[ ]*:?-+:?[ ]*(\|[ ]*:?-+:?[ ]*)*\|?[ ]*$`)
+	htmlBlockRe  = regexp.MustCompile(`^ {0,3}<(?:!--|/?([a-zA-Z][a-zA-Z0-9]*)(?:[ />]|$))`)
+	languageRe   = regexp.MustCompile(`^[a-zA-Z0-9_+#-]{1,32}$`)
+	tagRe        = regexp.MustCompile(`<[^>]*>`)
+)
+
+// htmlBlockTags 可以单独构成 HTML 块的标签，其余标签视为段落内的行内 HTML
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true,
+	"dl": true, "div": true, "figure": true, "footer": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"iframe": true, "nav": true, "ol": true, "p": true, "pre": true, "script": true,
+	"section": true, "style": true, "summary": true, "table": true, "ul": true,
+}
+
+type renderer struct {
+	headings []Heading
+	ids      map[string]int
+	inLink   int // 正在渲染的链接文本层数，链接内不再识别裸链接
+}
+
+func newRenderer() *renderer {
+	return &renderer{ids: make(map[string]int)}
+}
+
+// blocks 渲染块级元素。tight 为 true 时（紧凑列表项）段落不包裹 <p>
+func (r *renderer) blocks(lines []string, tight bool) string {
+	var b strings.Builder
+	var para []string
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		text := r.inline(strings.TrimSpace(strings.Join(para, "\n")))
+		if tight {
+			b.WriteString(text)
+			b.WriteByte('\n')
+		} else {
+			b.WriteString("<p>" + text + "</p>\n")
+		}
+		para = nil
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			flush()
+			i++
+			continue
+		}
+
+		// Setext 标题：段落后紧跟 === 或 ---
+		if len(para) > 0 {
+			if level := setextLevel(line); level > 0 {
+				r.heading(&b, level, strings.TrimSpace(strings.Join(para, "\n")))
+				para = nil
+				i++
+				continue
+			}
+		}
+
+		// 缩进代码块不能打断段落
+		if len(para) == 0 && indentWidth(line) >= 4 {
+			end := i
+			var code []string
+			for end < len(lines) && (isBlank(lines[end]) || indentWidth(lines[end]) >= 4) {
+				code = append(code, trimIndent(lines[end], 4))
+				end++
+			}
+			for len(code) > 0 && isBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "\n</code></pre>\n")
+			i = end
+			continue
+		}
+
+		if m := fenceOpenRe.FindStringSubmatch(line); m != nil {
+			flush()
+			i = r.fencedCode(&b, lines, i, len(m[1]), m[2], m[3])
+			continue
+		}
+		if m := atxHeadingRe.FindStringSubmatch(line); m != nil {
+			flush()
+			r.heading(&b, len(m[1]), strings.TrimSpace(m[2]))
+			i++
+			continue
+		}
+		if isThematicBreak(line) {
+			flush()
+			b.WriteString("<hr>\n")
+			i++
+			continue
+		}
+		if isBlockquote(line) {
+			flush()
+			i = r.blockquote(&b, lines, i)
+			continue
+		}
+		if listItemRe.MatchString(line) && (len(para) == 0 || canInterruptParagraph(line)) {
+			flush()
+			i = r.list(&b, lines, i)
+			continue
+		}
+		if isHTMLBlock(line) {
+			flush()
+			for i < len(lines) && !isBlank(lines[i]) {
+				b.WriteString(lines[i])
+				b.WriteByte('\n')
+				i++
+			}
+			continue
+		}
+		if len(para) == 0 && i+1 < len(lines) && strings.Contains(line, "|") && tableDelimRe.MatchString(lines[i+1]) {
+			if end, ok := r.table(&b, lines, i); ok {
+				i = end
+				continue
+			}
+		}
+
+		para = append(para, line)
+		i++
+	}
+	flush()
+	return b.String()
+}
+
+// heading 输出带锚点的标题并记录到目录
+func (r *renderer) heading(b *strings.Builder, level int, text string) {
+	inner := r.inline(text)
+	plain := strings.TrimSpace(html.UnescapeString(tagRe.ReplaceAllString(inner, "")))
+	id := r.anchor(plain)
+	r.headings = append(r.headings, Heading{Level: level, ID: id, Text: plain})
+	tag := "h" + strconv.Itoa(level)
+	b.WriteString("<" + tag + ` id="` + id + `">` + inner + "</" + tag + ">\n")
+}
+
+// anchor 生成文档内唯一的锚点
+func (r *renderer) anchor(text string) string {
+	base := utils.Slugify(text, maxAnchorLength)
+	if base == "" {
+		base = "section"
+	}
+	id := base
+	for n := 1; r.ids[id] > 0; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	r.ids[id]++
+	return id
+}
+
+func (r *renderer) fencedCode(b *strings.Builder, lines []string, start, indent int, fence, info string) int {
+	i := start + 1
+	var code []string
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if indentWidth(lines[i]) < 4 && len(trimmed) >= len(fence) &&
+			strings.Trim(trimmed, fence[:1]) == "" && strings.HasPrefix(trimmed, fence) {
+			i++
+			break
+		}
+		code = append(code, trimIndent(lines[i], indent))
+	}
+
+	b.WriteString("<pre><code")
+	if languageRe.MatchString(info) {
+		b.WriteString(` class="language-` + info + `"`)
+	}
+	b.WriteString(">")
+	if len(code) > 0 {
+		b.WriteString(html.EscapeString(strings.Join(code, "\n")) + "\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+func (r *renderer) blockquote(b *strings.Builder, lines []string, start int) int {
+	var inner []string
+	i := start
+	for i < len(lines) {
+		line := lines[i]
+		if isBlockquote(line) {
+			line = strings.TrimLeft(line, " ")[1:]
+			inner = append(inner, strings.TrimPrefix(line, " "))
+			i++
+			continue
+		}
+		// 懒惰续行：引用中的段落可以省略后续行的 >
+		if isBlank(line) || len(inner) == 0 || isBlank(inner[len(inner)-1]) || startsBlock(line) {
+			break
+		}
+		inner = append(inner, line)
+		i++
+	}
+	b.WriteString("<blockquote>\n" + r.blocks(inner, false) + "</blockquote>\n")
+	return i
+}
+
+type listItem struct {
+	lines []string
+	task  string // "" 表示普通列表项，否则为 " " 或 "x"
+}
+
+func (r *renderer) list(b *strings.Builder, lines []string, start int) int {
+	marker := listItemRe.FindStringSubmatch(lines[start])[2]
+	ordered := marker[0] >= '0' && marker[0] <= '9'
+	delim := marker[len(marker)-1:]
+
+	var items []listItem
+	loose := false
+	i := start
+	for i < len(lines) {
+		m := listItemRe.FindStringSubmatch(lines[i])
+		if m == nil || !sameListType(m[2], ordered, delim) {
+			break
+		}
+		contentIndent := len(m[0])
+		if len(m[3]) > 4 {
+			// 标记后空格过多时，多出的部分属于缩进代码块
+			contentIndent = len(m[1]) + len(m[2]) + 1
+		}
+		if m[3] == "" {
+			contentIndent = len(m[1]) + len(m[2]) + 1
+		}
+		first := ""
+		if contentIndent < len(lines[i]) {
+			first = lines[i][contentIndent:]
+		}
+		item := listItem{lines: []string{first}}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+			if isBlank(line) {
+				// 空行之后的内容仍有足够缩进时属于当前项
+				next := i
+				for next < len(lines) && isBlank(lines[next]) {
+					next++
+				}
+				if next < len(lines) && indentWidth(lines[next]) >= contentIndent {
+					item.lines = append(item.lines, "")
+					loose = true
+					i = next
+					continue
+				}
+				break
+			}
+			if indentWidth(line) >= contentIndent {
+				item.lines = append(item.lines, trimIndent(line, contentIndent))
+				i++
+				continue
+			}
+			prev := item.lines[len(item.lines)-1]
+			if isBlank(prev) || startsBlock(line) {
+				break
+			}
+			item.lines = append(item.lines, line)
+			i++
+		}
+
+		if tm := taskItemRe.FindStringSubmatch(item.lines[0]); tm != nil {
+			item.task = strings.ToLower(tm[1])
+			item.lines[0] = item.lines[0][len(tm[0]):]
+		}
+		items = append(items, item)
+
+		// 列表项之间有空行时为松散列表
+		if i < len(lines) && isBlank(lines[i]) {
+			next := i
+			for next < len(lines) && isBlank(lines[next]) {
+				next++
+			}
+			if next < len(lines) {
+				if nm := listItemRe.FindStringSubmatch(lines[next]); nm != nil && sameListType(nm[2], ordered, delim) {
+					loose = true
+					i = next
+					continue
+				}
+			}
+			break
+		}
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag)
+	if ordered {
+		if n, err := strconv.Atoi(marker[:len(marker)-1]); err == nil && n != 1 {
+			b.WriteString(` start="` + strconv.Itoa(n) + `"`)
+		}
+	}
+	b.WriteString(">\n")
+	for _, item := range items {
+		b.WriteString("<li>")
+		switch item.task {
+		case " ":
+			b.WriteString(`<input type="checkbox" disabled> `)
+		case "x":
+			b.WriteString(`<input type="checkbox" checked disabled> `)
+		}
+		content := r.blocks(item.lines, !loose)
+		if !loose {
+			content = strings.TrimSuffix(content, "\n")
+		}
+		b.WriteString(content)
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// table 渲染 GFM 表格，表头和分隔行列数不一致时不视为表格
+func (r *renderer) table(b *strings.Builder, lines []string, start int) (int, bool) {
+	header := splitTableRow(lines[start])
+	aligns := splitTableRow(lines[start+1])
+	if len(header) != len(aligns) {
+		return start, false
+	}
+	for i, cell := range aligns {
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns[i] = "center"
+		case strings.HasSuffix(cell, ":"):
+			aligns[i] = "right"
+		case strings.HasPrefix(cell, ":"):
+			aligns[i] = "left"
+		default:
+			aligns[i] = ""
+		}
+	}
+
+	writeRow := func(cells []string, tag string) {
+		b.WriteString("<tr>")
+		for i, align := range aligns {
+			b.WriteString("<" + tag)
+			if align != "" {
+				b.WriteString(` align="` + align + `"`)
+			}
+			b.WriteString(">")
+			if i < len(cells) {
+				b.WriteString(r.inline(cells[i]))
+			}
+			b.WriteString("</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	b.WriteString("</thead>\n")
+	i := start + 2
+	if i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]) {
+		b.WriteString("<tbody>\n")
+		for ; i < len(lines) && !isBlank(lines[i]) && !startsBlock(lines[i]); i++ {
+			writeRow(splitTableRow(lines[i]), "td")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+	return i, true
+}
+
+// splitTableRow 拆分表格行，忽略首尾的 | 和转义的 \|
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func sameListType(marker string, ordered bool, delim string) bool {
+	isOrdered := marker[0] >= '0' && marker[0] <= '9'
+	return isOrdered == ordered && marker[len(marker)-1:] == delim
+}
+
+// canInterruptParagraph 列表只有在首项非空、有序列表从1开始时才能打断段落
+func canInterruptParagraph(line string) bool {
+	m := listItemRe.FindStringSubmatch(line)
+	if m == nil || strings.TrimSpace(line[len(m[0]):]) == "" {
+		return false
+	}
+	marker := m[2]
+	if marker[0] >= '0' && marker[0] <= '9' {
+		return marker[:len(marker)-1] == "1"
+	}
+	return true
+}
+
+// startsBlock 判断该行是否开始一个新的块，用于结束懒惰续行
+func startsBlock(line string) bool {
+	return fenceOpenRe.MatchString(line) || atxHeadingRe.MatchString(line) || isThematicBreak(line) ||
+		isBlockquote(line) || listItemRe.MatchString(line) || isHTMLBlock(line)
+}
+
+func isHTMLBlock(line string) bool {
+	m := htmlBlockRe.FindStringSubmatch(line)
+	return m != nil && (m[1] == "" || htmlBlockTags[strings.ToLower(m[1])])
+}
+
+func isBlockquote(line string) bool {
+	return indentWidth(line) < 4 && strings.HasPrefix(strings.TrimLeft(line, " "), ">")
+}
+
+func isThematicBreak(line string) bool {
+	if indentWidth(line) >= 4 {
+		return false
+	}
+	var marker rune
+	count := 0
+	for _, c := range line {
+		switch {
+		case c == ' ':
+		case (c == '-' || c == '*' || c == '_') && (marker == 0 || c == marker):
+			marker = c
+			count++
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+func setextLevel(line string) int {
+	if indentWidth(line) >= 4 {
+		return 0
+	}
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "":
+		return 0
+	case strings.Trim(trimmed, "=") == "":
+		return 1
+	case strings.Trim(trimmed, "-") == "":
+		return 2
+	}
+	return 0
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentWidth(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent 去掉最多 n 个前导空格
+func trimIndent(line string, n int) string {
+	i := 0
+	for i < n && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return line[i:]
+}
@@ -0,0 +1,354 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	autolinkRe   = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)>`)
+	emailLinkRe  = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)+)>`)
+	inlineHTMLRe = regexp.MustCompile(`^(?:<!--[\s\S]*?-->|</?[a-zA-Z][a-zA-Z0-9-]*(?:\s+[a-zA-Z_:][a-zA-Z0-9_.:-]*(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>)`)
+	entityRe     = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+	bareURLRe    = regexp.MustCompile(`^https?://[^\s<]+`)
+)
+
+func (r *renderer) inline(s string) string {
+	var b strings.Builder
+	r.inlineTo(&b, s)
+	return b.String()
+}
+
+func (r *renderer) inlineTo(b *strings.Builder, s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '\\':
+			if i+1 < len(s) && isASCIIPunct(s[i+1]) {
+				b.WriteString(html.EscapeString(s[i+1 : i+2]))
+				i += 2
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\n' {
+				b.WriteString("<br>\n")
+				i += 2
+				continue
+			}
+		case ' ':
+			// 行尾两个及以上空格为硬换行
+			j := i
+			for j < len(s) && s[j] == ' ' {
+				j++
+			}
+			if j < len(s) && s[j] == '\n' {
+				if j-i >= 2 {
+					b.WriteString("<br>\n")
+				} else {
+					b.WriteByte('\n')
+				}
+				i = j + 1
+				continue
+			}
+		case '`':
+			if end, ok := codeSpan(b, s, i); ok {
+				i = end
+				continue
+			}
+			n := runLength(s, i, '`')
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				if text, dest, title, end, ok := parseLink(s, i+1); ok {
+					alt := html.UnescapeString(tagRe.ReplaceAllString(r.inline(text), ""))
+					b.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(alt) + `"`)
+					if title != "" {
+						b.WriteString(` title="` + html.EscapeString(title) + `"`)
+					}
+					b.WriteString(">")
+					i = end
+					continue
+				}
+			}
+		case '[':
+			if text, dest, title, end, ok := parseLink(s, i); ok {
+				b.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">")
+				r.inLink++
+				r.inlineTo(b, text)
+				r.inLink--
+				b.WriteString("</a>")
+				i = end
+				continue
+			}
+		case '<':
+			if m := autolinkRe.FindStringSubmatch(s[i:]); m != nil {
+				writeLink(b, m[1], m[1])
+				i += len(m[0])
+				continue
+			}
+			if m := emailLinkRe.FindStringSubmatch(s[i:]); m != nil {
+				writeLink(b, "mailto:"+m[1], m[1])
+				i += len(m[0])
+				continue
+			}
+			if m := inlineHTMLRe.FindString(s[i:]); m != "" {
+				// 原样保留，由 Sanitize 清洗
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+		case '&':
+			if m := entityRe.FindString(s[i:]); m != "" {
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+		case '*', '_', '~':
+			if end, ok := r.emphasis(b, s, i); ok {
+				i = end
+				continue
+			}
+			n := runLength(s, i, c)
+			b.WriteString(s[i : i+n])
+			i += n
+			continue
+		case 'h':
+			if r.inLink == 0 && (i == 0 || !isAlnum(s[i-1])) {
+				if m := bareURLRe.FindString(s[i:]); m != "" {
+					url := trimURLPunct(m)
+					writeLink(b, url, url)
+					i += len(url)
+					continue
+				}
+			}
+		}
+		b.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+}
+
+// codeSpan 渲染行内代码，开始和结束的反引号数量必须相同
+func codeSpan(b *strings.Builder, s string, start int) (int, bool) {
+	n := runLength(s, start, '`')
+	for j := start + n; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := runLength(s, j, '`')
+		if m != n {
+			j += m
+			continue
+		}
+		code := strings.ReplaceAll(s[start+n:j], "\n", " ")
+		if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		b.WriteString("<code>" + html.EscapeString(code) + "</code>")
+		return j + n, true
+	}
+	return start, false
+}
+
+// emphasis 渲染 *强调*、**加粗** 和 ~~删除线~~
+func (r *renderer) emphasis(b *strings.Builder, s string, start int) (int, bool) {
+	d := s[start]
+	run := runLength(s, start, d)
+	n, tag := 1, "em"
+	switch {
+	case d == '~':
+		if run < 2 {
+			return start, false
+		}
+		n, tag = 2, "del"
+	case run >= 2:
+		n, tag = 2, "strong"
+	}
+	open := start + n
+	if open >= len(s) || isSpace(s[open]) {
+		return start, false
+	}
+	if d == '_' && start > 0 && isAlnum(s[start-1]) {
+		return start, false
+	}
+
+	for j := open + 1; j < len(s); j++ {
+		if s[j] == '`' {
+			// 跳过行内代码，其中的分隔符不参与匹配
+			if end := matchingBackticks(s, j); end > j {
+				j = end - 1
+			}
+			continue
+		}
+		if s[j] != d || isSpace(s[j-1]) {
+			continue
+		}
+		m := runLength(s, j, d)
+		if m < n || (n == 1 && m == 2) {
+			j += m - 1
+			continue
+		}
+		// 连续的分隔符（如 ***）取最后 n 个作为结束
+		closeAt := j + m - n
+		if d == '_' && closeAt+n < len(s) && isAlnum(s[closeAt+n]) {
+			j += m - 1
+			continue
+		}
+		b.WriteString("<" + tag + ">")
+		r.inlineTo(b, s[open:closeAt])
+		b.WriteString("</" + tag + ">")
+		return closeAt + n, true
+	}
+	return start, false
+}
+
+// parseLink 解析 [text](dest "title")，start 指向 [
+func parseLink(s string, start int) (text, dest, title string, end int, ok bool) {
+	depth := 0
+	close := -1
+	for i := start; i < len(s) && close < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			if e := matchingBackticks(s, i); e > i {
+				i = e - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				close = i
+			}
+		}
+	}
+	if close < 0 || close+1 >= len(s) || s[close+1] != '(' {
+		return "", "", "", 0, false
+	}
+	text = s[start+1 : close]
+
+	p := skipSpaces(s, close+2)
+	if p < len(s) && s[p] == '<' {
+		e := strings.IndexAny(s[p+1:], ">\n")
+		if e < 0 || s[p+1+e] != '>' {
+			return "", "", "", 0, false
+		}
+		dest = s[p+1 : p+1+e]
+		p += e + 2
+	} else {
+		parens := 0
+		begin := p
+		for ; p < len(s); p++ {
+			c := s[p]
+			if c == '\\' && p+1 < len(s) {
+				p++
+				continue
+			}
+			if isSpace(c) || (c == ')' && parens == 0) {
+				break
+			}
+			if c == '(' {
+				parens++
+			} else if c == ')' {
+				parens--
+			}
+		}
+		dest = s[begin:p]
+	}
+
+	p = skipSpaces(s, p)
+	if p < len(s) && (s[p] == '"' || s[p] == '\'' || s[p] == '(') {
+		closer := s[p]
+		if closer == '(' {
+			closer = ')'
+		}
+		e := strings.IndexByte(s[p+1:], closer)
+		if e < 0 {
+			return "", "", "", 0, false
+		}
+		title = unescapePunct(s[p+1 : p+1+e])
+		p = skipSpaces(s, p+e+2)
+	}
+	if p >= len(s) || s[p] != ')' {
+		return "", "", "", 0, false
+	}
+	return text, unescapePunct(dest), title, p + 1, true
+}
+
+func writeLink(b *strings.Builder, href, text string) {
+	b.WriteString(`<a href="` + html.EscapeString(href) + `">` + html.EscapeString(text) + "</a>")
+}
+
+// trimURLPunct 去掉裸链接末尾的标点和不成对的右括号
+func trimURLPunct(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		if strings.IndexByte(".,:;!?'\"*_~", last) >= 0 {
+			url = url[:len(url)-1]
+			continue
+		}
+		if last == ')' && strings.Count(url, "(") < strings.Count(url, ")") {
+			url = url[:len(url)-1]
+			continue
+		}
+		break
+	}
+	return url
+}
+
+// matchingBackticks 返回从 start 开始的行内代码的结束位置，不构成行内代码时返回 start
+func matchingBackticks(s string, start int) int {
+	var discard strings.Builder
+	if end, ok := codeSpan(&discard, s, start); ok {
+		return end
+	}
+	return start
+}
+
+func unescapePunct(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func runLength(s string, start int, c byte) int {
+	n := 0
+	for start+n < len(s) && s[start+n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && isSpace(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t'
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
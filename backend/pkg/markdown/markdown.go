@@ -0,0 +1,104 @@
+// Package markdown 将 Markdown 渲染为安全的 HTML。支持 CommonMark 的常用语法和 GFM 的表格、
+// 删除线、任务列表与裸链接；正文中的 HTML 会保留，但输出统一经过白名单清洗。
+// 渲染时为标题生成锚点，并给出目录和阅读时间估算
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 阅读速度，用于估算阅读时间
+const (
+	WordsPerMinute    = 200 // 以空格分词的文字，每分钟词数
+	CJKCharsPerMinute = 400 // 中日韩文字，每分钟字数
+)
+
+// Heading 目录项，ID 与 HTML 中标题的 id 属性一致
+type Heading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+// Document 渲染结果
+type Document struct {
+	HTML        string    `json:"html"`
+	TOC         []Heading `json:"toc"`
+	WordCount   int       `json:"word_count"`
+	ReadingTime int       `json:"reading_time"` // 分钟
+}
+
+// Render 渲染 Markdown
+func Render(source string) *Document {
+	r := newRenderer()
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	lines := strings.Split(strings.ReplaceAll(source, "\t", "    "), "\n")
+
+	out := Sanitize(r.blocks(lines, false))
+	words := CountWords(TextContent(out))
+	toc := r.headings
+	if toc == nil {
+		toc = []Heading{}
+	}
+	return &Document{
+		HTML:        out,
+		TOC:         toc,
+		WordCount:   words.Total(),
+		ReadingTime: words.ReadingTime(),
+	}
+}
+
+// WordStats 字数统计，中日韩文字按字计，其他文字按词计
+type WordStats struct {
+	Words    int
+	CJKChars int
+}
+
+// Total 总字数
+func (w WordStats) Total() int {
+	return w.Words + w.CJKChars
+}
+
+// ReadingTime 估算阅读时间（分钟），有内容时至少为1分钟
+func (w WordStats) ReadingTime() int {
+	if w.Total() == 0 {
+		return 0
+	}
+	minutes := float64(w.Words)/WordsPerMinute + float64(w.CJKChars)/CJKCharsPerMinute
+	if rounded := int(minutes + 0.5); rounded > 1 {
+		return rounded
+	}
+	return 1
+}
+
+// CountWords 统计纯文本的字数
+func CountWords(text string) WordStats {
+	var stats WordStats
+	inWord := false
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			stats.CJKChars++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				stats.Words++
+				inWord = true
+			}
+		case r == '\'' || r == '’' || r == '-':
+			// 词内的撇号和连字符不拆分单词
+		default:
+			inWord = false
+		}
+	}
+	return stats
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
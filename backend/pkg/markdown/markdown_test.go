@@ -0,0 +1,121 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Paragraph", "hello *world*", "<p>hello <em>world</em></p>\n"},
+		{"Strong and strike", "**a** ~~b~~ ***c***", "<p><strong>a</strong> <del>b</del> <strong><em>c</em></strong></p>\n"},
+		{"Intraword underscore", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"Code span escapes", "`<b>` & co", "<p><code>&lt;b&gt;</code> &amp; co</p>\n"},
+		{"Escaped punctuation", `\*not em\*`, "<p>*not em*</p>\n"},
+		{"Hard break", "a  \nb", "<p>a<br>\nb</p>\n"},
+		{"Link", `[Go](https://go.dev "The Go site")`, `<p><a href="https://go.dev" title="The Go site" rel="nofollow noopener noreferrer">Go</a></p>` + "\n"},
+		{"Relative link", "[docs](/docs)", `<p><a href="/docs">docs</a></p>` + "\n"},
+		{"Bare URL", "see https://go.dev.", `<p>see <a href="https://go.dev" rel="nofollow noopener noreferrer">https://go.dev</a>.</p>` + "\n"},
+		{"Image", "![a *b*](/x.png)", `<p><img src="/x.png" alt="a b"></p>` + "\n"},
+		{"Heading", "## Hello World ##", `<h2 id="hello-world">Hello World</h2>` + "\n"},
+		{"Setext heading", "Title\n---", `<h2 id="title">Title</h2>` + "\n"},
+		{"Fenced code", "```go\nif a < b {}\n```", `<pre><code class="language-go">if a &lt; b {}` + "\n</code></pre>\n"},
+		{"Indented code", "    x := 1", "<pre><code>x := 1\n</code></pre>\n"},
+		{"Tight list", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"Loose list", "- a\n\n- b", "<ul>\n<li><p>a</p>\n</li>\n<li><p>b</p>\n</li>\n</ul>\n"},
+		{"Ordered list start", "3. a\n4. b", "<ol start=\"3\">\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"Task list", "- [x] done", "<ul>\n<li><input type=\"checkbox\" checked disabled> done</li>\n</ul>\n"},
+		{"Blockquote", "> quote", "<blockquote>\n<p>quote</p>\n</blockquote>\n"},
+		{"Thematic break", "***", "<hr>\n"},
+		{"Table", "| a | b |\n|:--|--:|\n| 1 | 2 |",
+			"<table>\n<thead>\n<tr><th align=\"left\">a</th><th align=\"right\">b</th></tr>\n</thead>\n" +
+				"<tbody>\n<tr><td align=\"left\">1</td><td align=\"right\">2</td></tr>\n</tbody>\n</table>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Render(tt.input).HTML)
+		})
+	}
+}
+
+func TestRenderTOC(t *testing.T) {
+	doc := Render("# Intro\n\ntext\n\n## Setup `go`\n\n## Intro\n\n### 安装\n")
+
+	assert.Equal(t, []Heading{
+		{Level: 1, ID: "intro", Text: "Intro"},
+		{Level: 2, ID: "setup-go", Text: "Setup go"},
+		{Level: 2, ID: "intro-1", Text: "Intro"},
+		{Level: 3, ID: "安装", Text: "安装"},
+	}, doc.TOC)
+	assert.Contains(t, doc.HTML, `<h2 id="intro-1">Intro</h2>`)
+	assert.Empty(t, Render("no headings").TOC)
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Script removed with content", "<p>a<script>alert(1)</script>b</p>", "<p>ab</p>"},
+		{"Event handler removed", `<div onclick="x()">hi</div>`, "<div>hi</div>"},
+		{"Javascript URL removed", `<a href="javascript:alert(1)">x</a>`, "<a>x</a>"},
+		{"Obfuscated scheme removed", "<a href=\"java\tscript:alert(1)\">x</a>", "<a>x</a>"},
+		{"Data image removed", `<img src="data:image/png;base64,xx" alt="">`, `<img alt="">`},
+		{"Unknown tag unwrapped", "<custom>text</custom>", "text"},
+		{"Unclosed tags closed", "<p><strong>x", "<p><strong>x</strong></p>"},
+		{"Stray close ignored", "x</div>", "x"},
+		{"Style attribute removed", `<span style="color:red">x</span>`, "<span>x</span>"},
+		{"Code class restricted", `<code class="language-go evil">x</code>`, "<code>x</code>"},
+		{"Text escaped", "<p>&lt;b&gt;</p>", "<p>&lt;b&gt;</p>"},
+		{"Only checkbox inputs", `<input type="text"><input type="checkbox">`, "<input type=\"checkbox\" disabled>"},
+		{"Dropped void tag keeps following content", "<p>a</p><embed src=x><p>after</p>", "<p>a</p><p>after</p>"},
+		{"Void end tag inside dropped tag", "<object><embed></embed>x</object><p>after</p>", "<p>after</p>"},
+		{"Unknown void tags skipped", `<p>a<wbr>b</p><link rel="stylesheet" href="x.css"><meta charset="utf-8"><p>c</p>`, "<p>ab</p><p>c</p>"},
+		{"Mailto allowed", `<a href="mailto:a@b.c">m</a>`, `<a href="mailto:a@b.c" rel="nofollow noopener noreferrer">m</a>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.input))
+		})
+	}
+}
+
+func TestRenderSanitizesInlineHTML(t *testing.T) {
+	doc := Render("hello <img src=x onerror=alert(1)> <b>bold</b>\n\n<iframe src=\"https://evil\"></iframe>\n")
+
+	assert.NotContains(t, doc.HTML, "onerror")
+	assert.NotContains(t, doc.HTML, "iframe")
+	assert.Contains(t, doc.HTML, "<b>bold</b>")
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantWords int
+		wantTime  int
+	}{
+		{"Empty", "", 0, 0},
+		{"Short", "just a few words", 4, 1},
+		{"Markup not counted", "[link](https://example.com/a/very/long/path) **bold**", 2, 1},
+		{"English", strings.Repeat("word ", 1000), 1000, 5},
+		{"CJK", strings.Repeat("中文", 600), 1200, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Render(tt.input)
+			assert.Equal(t, tt.wantWords, doc.WordCount)
+			assert.Equal(t, tt.wantTime, doc.ReadingTime)
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// allowedTags 允许的标签及其允许的属性，其余标签被移除但保留文本内容
+var allowedTags = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"abbr":       {"title": true},
+	"b":          {},
+	"blockquote": {},
+	"br":         {},
+	"caption":    {},
+	"cite":       {},
+	"code":       {"class": true},
+	"dd":         {},
+	"del":        {},
+	"details":    {"open": true},
+	"div":        {},
+	"dl":         {},
+	"dt":         {},
+	"em":         {},
+	"figcaption": {},
+	"figure":     {},
+	"h1":         {"id": true},
+	"h2":         {"id": true},
+	"h3":         {"id": true},
+	"h4":         {"id": true},
+	"h5":         {"id": true},
+	"h6":         {"id": true},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"input":      {"type": true, "checked": true, "disabled": true},
+	"ins":        {},
+	"kbd":        {},
+	"li":         {},
+	"mark":       {},
+	"ol":         {"start": true},
+	"p":          {},
+	"pre":        {},
+	"q":          {},
+	"s":          {},
+	"small":      {},
+	"span":       {},
+	"strong":     {},
+	"sub":        {},
+	"summary":    {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"align": true, "colspan": true, "rowspan": true},
+	"tfoot":      {},
+	"th":         {"align": true, "colspan": true, "rowspan": true},
+	"thead":      {},
+	"tr":         {},
+	"u":          {},
+	"ul":         {},
+}
+
+// droppedTags 连同内容一起移除的标签
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "textarea": true, "select": true, "title": true,
+	"head": true, "frame": true, "frameset": true, "svg": true, "math": true, "applet": true,
+}
+
+// voidTags 没有结束标签的元素，被移除时不能等待结束标签，否则后续内容会被一并丢弃
+var voidTags = map[string]bool{
+	"br": true, "hr": true, "img": true, "input": true, "embed": true, "frame": true,
+	"param": true, "source": true, "track": true, "wbr": true, "area": true, "base": true,
+	"link": true, "meta": true, "col": true,
+}
+
+var (
+	anchorIDRe   = regexp.MustCompile(`^[\p{L}\p{N}_-]{1,80}$`)
+	codeClassRe  = regexp.MustCompile(`^language-[a-zA-Z0-9_+#-]{1,32}$`)
+	numberAttrRe = regexp.MustCompile(`^[0-9]{1,4}$`)
+	urlSchemeRe  = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):`)
+)
+
+// Sanitize 按白名单清洗 HTML：移除不允许的标签和属性，链接只允许 http、https、mailto 和相对地址，
+// 图片只允许 http、https 和相对地址。未闭合的标签会被补全
+func Sanitize(input string) string {
+	var b strings.Builder
+	var open []string
+	skip := 0 // 处于需要丢弃内容的标签内的层数
+
+	z := nethtml.NewTokenizer(strings.NewReader(input))
+	for {
+		tt := z.Next()
+		switch tt {
+		case nethtml.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				b.WriteString("</" + open[i] + ">")
+			}
+			return b.String()
+
+		case nethtml.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(string(z.Text())))
+			}
+
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			token := z.Token()
+			name := token.Data
+			if droppedTags[name] {
+				if tt == nethtml.StartTagToken && !voidTags[name] {
+					skip++
+				}
+				continue
+			}
+			attrs, ok := allowedTags[name]
+			if skip > 0 || !ok {
+				continue
+			}
+			if name == "input" && !isCheckbox(token) {
+				continue
+			}
+			b.WriteString("<" + name)
+			writeAttrs(&b, name, token.Attr, attrs)
+			b.WriteString(">")
+			if !voidTags[name] {
+				open = append(open, name)
+			}
+
+		case nethtml.EndTagToken:
+			name := z.Token().Data
+			if droppedTags[name] {
+				if skip > 0 && !voidTags[name] {
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			// 关闭到最近的同名标签，中间未闭合的标签一并关闭
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != name {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+}
+
+func writeAttrs(b *strings.Builder, tag string, attrs []nethtml.Attribute, allowed map[string]bool) {
+	seen := make(map[string]bool)
+	external := false
+	for _, attr := range attrs {
+		key := strings.ToLower(attr.Key)
+		if !allowed[key] || seen[key] || attr.Namespace != "" {
+			continue
+		}
+		value := strings.TrimSpace(attr.Val)
+		switch key {
+		case "href":
+			if !safeURL(value, "http", "https", "mailto") {
+				continue
+			}
+			external = urlSchemeRe.MatchString(value) || strings.HasPrefix(value, "//")
+		case "src":
+			if !safeURL(value, "http", "https") {
+				continue
+			}
+		case "id":
+			if !anchorIDRe.MatchString(value) {
+				continue
+			}
+		case "class":
+			if !codeClassRe.MatchString(value) {
+				continue
+			}
+		case "align":
+			if value != "left" && value != "center" && value != "right" {
+				continue
+			}
+		case "start", "width", "height", "colspan", "rowspan":
+			if !numberAttrRe.MatchString(value) {
+				continue
+			}
+		case "type":
+			value = "checkbox"
+		case "checked", "disabled", "open":
+			value = ""
+		}
+		seen[key] = true
+		b.WriteString(" " + key)
+		if value != "" || key == "alt" {
+			b.WriteString(`="` + html.EscapeString(value) + `"`)
+		}
+	}
+	if tag == "input" && !seen["disabled"] {
+		b.WriteString(" disabled")
+	}
+	if tag == "a" && external {
+		b.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+}
+
+// safeURL 检查地址的协议是否在允许范围内，没有协议的相对地址视为安全
+func safeURL(value string, schemes ...string) bool {
+	// 浏览器会忽略地址中的空白和控制字符，检查前先去掉，避免 "java\tscript:" 之类的绕过
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, value)
+	m := urlSchemeRe.FindStringSubmatch(cleaned)
+	if m == nil {
+		return true
+	}
+	scheme := strings.ToLower(m[1])
+	for _, allowed := range schemes {
+		if scheme == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func isCheckbox(token nethtml.Token) bool {
+	for _, attr := range token.Attr {
+		if strings.ToLower(attr.Key) == "type" {
+			return strings.EqualFold(strings.TrimSpace(attr.Val), "checkbox")
+		}
+	}
+	return false
+}
+
+// TextContent 提取 HTML 中的纯文本
+func TextContent(input string) string {
+	var b strings.Builder
+	skip := 0
+	z := nethtml.NewTokenizer(strings.NewReader(input))
+	for {
+		switch z.Next() {
+		case nethtml.ErrorToken:
+			return b.String()
+		case nethtml.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+			}
+		case nethtml.StartTagToken:
+			name, _ := z.TagName()
+			if droppedTags[string(name)] {
+				skip++
+			} else {
+				b.WriteByte(' ')
+			}
+		case nethtml.EndTagToken:
+			name, _ := z.TagName()
+			if droppedTags[string(name)] && skip > 0 {
+				skip--
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+	}
+}
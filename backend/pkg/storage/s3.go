@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3Service         = "s3"
+	s3DefaultRegion   = "us-east-1"
+	s3DateFormat      = "20060102T150405Z"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3MaxPresignTTL 预签名地址的最长有效期（7 天）
+	s3MaxPresignTTL = 7 * 24 * time.Hour
+)
+
+// S3Config S3 兼容存储的配置
+type S3Config struct {
+	Endpoint  string // 例如 https://s3.us-east-1.amazonaws.com 或 http://minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle 使用 endpoint/bucket/key 形式的地址（MinIO 等通常需要），否则使用 bucket.endpoint/key
+	PathStyle bool
+}
+
+// S3 S3 兼容的对象存储，使用 AWS Signature Version 4 签名请求
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 创建 S3 存储
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("s3 endpoint, bucket and credentials are required")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = s3DefaultRegion
+	}
+	return &S3{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	// S3 的 PUT 需要 Content-Length，长度未知时先读入内存
+	if size < 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", key, err)
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, r, size, header)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, s.object(key, resp), nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (*Object, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return s.object(key, resp), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, nil)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SignedURL 生成预签名的 GET 地址，客户端直接从对象存储下载
+func (s *S3) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	ttl = min(max(ttl, time.Second), s3MaxPresignTTL)
+
+	u := s.objectURL(key)
+	amzDate := s.now().UTC().Format(s3DateFormat)
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+s.scope(amzDate))
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	creq := canonicalRequest(http.MethodGet, u.EscapedPath(), query, []string{"host:" + u.Host}, "host", s3UnsignedPayload)
+	u.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + s.signature(amzDate, creq)
+	return u.String(), nil
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil || size == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.sign(req, s3UnsignedPayload, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s: %v", method, key, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", method, key, s3Err.Code, s3Err.Message)
+	}
+	return nil, fmt.Errorf("s3 %s %s: %s", method, key, resp.Status)
+}
+
+func (s *S3) object(key string, resp *http.Response) *Object {
+	obj := &Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		obj.ModTime = modified
+	}
+	if obj.ContentType == "" {
+		obj.ContentType = ContentTypeByKey(key)
+	}
+	return obj
+}
+
+// objectURL 对象地址，路径按 SigV4 的规则转义
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	base := strings.TrimRight(u.Path, "/")
+	objectPath := base + "/" + key
+	if s.cfg.PathStyle {
+		objectPath = base + "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	u.Path = objectPath
+	u.RawPath = uriEncode(objectPath, false)
+	return &u
+}
+
+// sign 为请求添加 SigV4 Authorization 头，签名 Host 和请求中已有的所有头
+func (s *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(s3DateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, vals := range req.Header {
+		trimmed := make([]string, len(vals))
+		for i, v := range vals {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		values[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	headers := make([]string, len(names))
+	for i, name := range names {
+		headers[i] = name + ":" + values[name]
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	creq := canonicalRequest(req.Method, req.URL.EscapedPath(), req.URL.Query(), headers, signedHeaders, payloadHash)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKey, s.scope(amzDate), signedHeaders, s.signature(amzDate, creq)))
+}
+
+func (s *S3) scope(amzDate string) string {
+	return amzDate[:8] + "/" + s.cfg.Region + "/" + s3Service + "/aws4_request"
+}
+
+// signature 对规范请求计算签名
+func (s *S3) signature(amzDate, creq string) string {
+	hash := sha256.Sum256([]byte(creq))
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + s.scope(amzDate) + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), amzDate[:8])
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalRequest SigV4 规范请求，headers 为已排序的 "name:value"
+func canonicalRequest(method, uri string, query url.Values, headers []string, signedHeaders, payloadHash string) string {
+	if uri == "" {
+		uri = "/"
+	}
+	return strings.Join([]string{
+		method,
+		uri,
+		canonicalQuery(query),
+		strings.Join(headers, "\n") + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode 按 SigV4 的规则转义：只保留字母、数字和 -_.~，encodeSlash 为 false 时保留 /
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,92 @@
+// Package storage 上传文件的存储后端。对象以 "images/abc.jpg" 这样的 key 标识，
+// 可存放在本地磁盘或 S3 兼容的对象存储（AWS S3、MinIO 等），多实例部署时应使用后者
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// 存储驱动
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Object 对象的元信息
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage 存储后端
+type Storage interface {
+	// Put 写入对象，已存在时覆盖。size 未知时传 -1
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，调用方负责关闭返回的 ReadCloser；对象不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// Stat 读取对象的元信息，对象不存在时返回 ErrNotFound
+	Stat(ctx context.Context, key string) (*Object, error)
+	// Delete 删除对象，对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+	// SignedURL 生成在 ttl 内有效的下载地址，用于不公开的文件
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Config 存储配置
+type Config struct {
+	Driver string
+	// LocalDir 本地驱动的根目录
+	LocalDir string
+	S3       S3Config
+}
+
+// New 按配置创建存储后端。本地驱动没有自己的签名机制，用 signer 生成由应用校验并提供下载的地址
+func New(cfg Config, signer *URLSigner) (Storage, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocal(cfg.LocalDir, signer), nil
+	case DriverS3:
+		return NewS3(cfg.S3)
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+}
+
+// CleanKey 校验并规范化对象 key：使用 / 分隔的相对路径，不能跳出根目录或包含隐藏文件
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.ContainsAny(key, "\\\x00") {
+		return "", ErrInvalidKey
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key {
+		return "", ErrInvalidKey
+	}
+	for _, part := range strings.Split(cleaned, "/") {
+		if part == "" || strings.HasPrefix(part, ".") {
+			return "", ErrInvalidKey
+		}
+	}
+	return cleaned, nil
+}
+
+// ContentTypeByKey 根据扩展名推断内容类型
+func ContentTypeByKey(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanKey(t *testing.T) {
+	valid := map[string]string{
+		"images/a.jpg":    "images/a.jpg",
+		"/images/a.jpg":   "images/a.jpg",
+		"private/1/x.pdf": "private/1/x.pdf",
+	}
+	for in, want := range valid {
+		got, err := CleanKey(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got)
+	}
+
+	for _, key := range []string{"", "../etc/passwd", "images/../../x", "images//a.jpg", ".env", "images/.hidden", "a\\b", "images/"} {
+		_, err := CleanKey(key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner("secret", "/api/v1/files/")
+	now := time.Unix(1700000000, 0)
+
+	signed := signer.Sign("private/my file.pdf", now.Add(time.Minute))
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/files/private/my%20file.pdf", u.EscapedPath())
+
+	key := strings.TrimPrefix(u.Path, "/api/v1/files/")
+	expires, signature := u.Query().Get("expires"), u.Query().Get("signature")
+	assert.NoError(t, signer.Verify(key, expires, signature, now))
+	assert.ErrorIs(t, signer.Verify(key, expires, signature, now.Add(2*time.Minute)), ErrSignatureExpired)
+	assert.ErrorIs(t, signer.Verify("private/other.pdf", expires, signature, now), ErrSignatureInvalid)
+	assert.ErrorIs(t, signer.Verify(key, "9999999999", signature, now), ErrSignatureInvalid)
+	assert.ErrorIs(t, NewURLSigner("other", "").Verify(key, expires, signature, now), ErrSignatureInvalid)
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := New(Config{Driver: DriverLocal, LocalDir: dir}, NewURLSigner("secret", "/files"))
+	require.NoError(t, err)
+
+	_, err = store.Stat(ctx, "images/a.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, "images/a.png", strings.NewReader("png data"), -1, "image/png"))
+	data, err := os.ReadFile(filepath.Join(dir, "images", "a.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "png data", string(data))
+
+	obj, err := store.Stat(ctx, "images/a.png")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), obj.Size)
+	assert.Equal(t, "image/png", obj.ContentType)
+
+	rc, obj, err := store.Get(ctx, "images/a.png")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "png data", string(body))
+	assert.Equal(t, "images/a.png", obj.Key)
+
+	_, _, err = store.Get(ctx, "images")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, store.Put(ctx, "../a.png", strings.NewReader("x"), 1, ""), ErrInvalidKey)
+
+	signed, err := store.SignedURL(ctx, "images/a.png", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signed, "/files/images/a.png?expires="))
+
+	require.NoError(t, store.Delete(ctx, "images/a.png"))
+	require.NoError(t, store.Delete(ctx, "images/a.png"))
+	_, err = store.Stat(ctx, "images/a.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := New(Config{Driver: "ftp"}, nil)
+	assert.Error(t, err)
+}
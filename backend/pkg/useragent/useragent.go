@@ -0,0 +1,77 @@
+// Package useragent 从 User-Agent 中识别浏览器和操作系统，用于登录设备的展示和新设备判断。
+// 只区分常见的浏览器和系统，不解析版本号，同一设备升级浏览器后仍视为同一设备
+package useragent
+
+import (
+	"strings"
+)
+
+const unknown = "Unknown"
+
+// Device 识别出的设备信息
+type Device struct {
+	Browser string `json:"browser"`
+	OS      string `json:"os"`
+	Mobile  bool   `json:"mobile"`
+}
+
+// String 设备名称，例如 "Chrome (Windows)"
+func (d Device) String() string {
+	return d.Browser + " (" + d.OS + ")"
+}
+
+// 按顺序匹配，Edge、Opera 的 UA 中也包含 Chrome 和 Safari，Chrome 的 UA 中也包含 Safari
+var browsers = []struct {
+	token string
+	name  string
+}{
+	{"MicroMessenger/", "WeChat"},
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+	{"PostmanRuntime/", "Postman"},
+	{"okhttp/", "OkHttp"},
+	{"Go-http-client/", "Go HTTP client"},
+	{"python-requests/", "Python Requests"},
+}
+
+var systems = []struct {
+	token string
+	name  string
+}{
+	{"Windows", "Windows"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Android", "Android"},
+	{"CrOS", "ChromeOS"},
+	{"Macintosh", "macOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// Parse 解析 User-Agent，无法识别的部分为 Unknown
+func Parse(ua string) Device {
+	device := Device{Browser: unknown, OS: unknown}
+	for _, b := range browsers {
+		if strings.Contains(ua, b.token) {
+			device.Browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(ua, s.token) {
+			device.OS = s.name
+			break
+		}
+	}
+	device.Mobile = strings.Contains(ua, "Mobile") || device.OS == "iOS" || (device.OS == "Android" && !strings.Contains(ua, "Tablet"))
+	return device
+}
@@ -0,0 +1,71 @@
+package useragent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want Device
+	}{
+		{
+			name: "Chrome on Windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36",
+			want: Device{Browser: "Chrome", OS: "Windows"},
+		},
+		{
+			name: "Edge is not Chrome",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.2592.68",
+			want: Device{Browser: "Edge", OS: "Windows"},
+		},
+		{
+			name: "Safari on macOS",
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15",
+			want: Device{Browser: "Safari", OS: "macOS"},
+		},
+		{
+			name: "Safari on iPhone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+			want: Device{Browser: "Safari", OS: "iOS", Mobile: true},
+		},
+		{
+			name: "Chrome on Android",
+			ua:   "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.6478.122 Mobile Safari/537.36",
+			want: Device{Browser: "Chrome", OS: "Android", Mobile: true},
+		},
+		{
+			name: "Firefox on Linux",
+			ua:   "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0",
+			want: Device{Browser: "Firefox", OS: "Linux"},
+		},
+		{
+			name: "WeChat on iPhone",
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 MicroMessenger/8.0.49",
+			want: Device{Browser: "WeChat", OS: "iOS", Mobile: true},
+		},
+		{
+			name: "curl",
+			ua:   "curl/8.6.0",
+			want: Device{Browser: "curl", OS: "Unknown"},
+		},
+		{
+			name: "Empty",
+			ua:   "",
+			want: Device{Browser: "Unknown", OS: "Unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Parse(tt.ua))
+		})
+	}
+}
+
+func TestDevice_String(t *testing.T) {
+	assert.Equal(t, "Chrome (Windows)", Device{Browser: "Chrome", OS: "Windows"}.String())
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// rankDigits 排序键字符集，按ASCII顺序排列，数据库按字符串排序即可得到正确顺序
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxRankLength 排序键超过该长度时建议重新均匀分配
+const MaxRankLength = 32
+
+// ErrInvalidRank 无效的排序键
+var ErrInvalidRank = errors.New("invalid rank")
+
+// RankBetween 生成严格介于 prev 和 next 之间的排序键（分数索引）。
+// 空字符串表示无边界：RankBetween("", "") 返回初始键，RankBetween(last, "") 追加到末尾
+func RankBetween(prev, next string) (string, error) {
+	if !validRank(prev) || !validRank(next) {
+		return "", ErrInvalidRank
+	}
+	if next != "" && prev >= next {
+		return "", ErrInvalidRank
+	}
+	return rankMidpoint(prev, next), nil
+}
+
+// RankSequence 生成 n 个均匀分布且递增的排序键，用于重新分配整列顺序
+func RankSequence(n int) []string {
+	if n <= 0 {
+		return []string{}
+	}
+
+	base := len(rankDigits)
+	width, capacity := 1, base
+	for capacity <= n {
+		width++
+		capacity *= base
+	}
+	step := capacity / (n + 1)
+
+	ranks := make([]string, n)
+	for i := 0; i < n; i++ {
+		value := (i + 1) * step
+		digits := make([]byte, width)
+		for d := width - 1; d >= 0; d-- {
+			digits[d] = rankDigits[value%base]
+			value /= base
+		}
+		// 去掉末尾的最小字符，保持键的规范形式
+		ranks[i] = strings.TrimRight(string(digits), "0")
+	}
+	return ranks
+}
+
+// validRank 排序键只能包含合法字符且不能以最小字符结尾
+func validRank(rank string) bool {
+	if rank == "" {
+		return true
+	}
+	if rank[len(rank)-1] == rankDigits[0] {
+		return false
+	}
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(rankDigits, rank[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rankMidpoint 计算 a、b 之间的中点，b 为空表示正无穷
+func rankMidpoint(a, b string) string {
+	if b != "" {
+		// 跳过公共前缀
+		n := 0
+		for n < len(b) && rankDigitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(a) {
+				rest = a[n:]
+			}
+			return b[:n] + rankMidpoint(rest, b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(rankDigits, a[0])
+	}
+	digitB := len(rankDigits)
+	if b != "" {
+		digitB = strings.IndexByte(rankDigits, b[0])
+	}
+
+	if digitB-digitA > 1 {
+		return string(rankDigits[(digitA+digitB+1)/2])
+	}
+
+	// 首位相邻：b 更长时取 b 的首位即可，否则在 a 之后继续细分
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if len(a) > 1 {
+		rest = a[1:]
+	}
+	return string(rankDigits[digitA]) + rankMidpoint(rest, "")
+}
+
+// rankDigitAt 取第 i 位字符，超出长度视为最小字符
+func rankDigitAt(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+	return rankDigits[0]
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name    string
+		prev    string
+		next    string
+		wantErr bool
+	}{
+		{name: "Empty column", prev: "", next: ""},
+		{name: "Append to end", prev: "i", next: ""},
+		{name: "Insert at top", prev: "", next: "i"},
+		{name: "Between wide gap", prev: "1", next: "z"},
+		{name: "Between adjacent digits", prev: "a", next: "b"},
+		{name: "Between prefix and longer key", prev: "a", next: "a1"},
+		{name: "Top before smallest digit", prev: "", next: "1"},
+		{name: "Prev not less than next", prev: "b", next: "a", wantErr: true},
+		{name: "Equal keys", prev: "b", next: "b", wantErr: true},
+		{name: "Trailing zero", prev: "a0", next: "", wantErr: true},
+		{name: "Invalid character", prev: "A", next: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RankBetween(tt.prev, tt.next)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRank)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, validRank(got), "rank %q should be canonical", got)
+			if tt.prev != "" {
+				assert.Greater(t, got, tt.prev)
+			}
+			if tt.next != "" {
+				assert.Less(t, got, tt.next)
+			}
+		})
+	}
+}
+
+func TestRankBetween_RepeatedInsertions(t *testing.T) {
+	// 反复插入到同一位置，键应保持有序且长度缓慢增长
+	prev, next := "a", "b"
+	for i := 0; i < 50; i++ {
+		mid, err := RankBetween(prev, next)
+		require.NoError(t, err)
+		require.Greater(t, mid, prev)
+		require.Less(t, mid, next)
+		next = mid
+	}
+	assert.LessOrEqual(t, len(next), MaxRankLength)
+}
+
+func TestRankSequence(t *testing.T) {
+	for _, n := range []int{0, 1, 5, 35, 36, 1000} {
+		ranks := RankSequence(n)
+		require.Len(t, ranks, n)
+		assert.True(t, sort.StringsAreSorted(ranks))
+		for i, rank := range ranks {
+			assert.True(t, validRank(rank), "rank %q should be canonical", rank)
+			if i > 0 {
+				assert.NotEqual(t, ranks[i-1], rank)
+			}
+		}
+	}
+}
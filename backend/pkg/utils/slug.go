@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify 生成 URL 友好的标识：字母转小写，保留各语言的字母和数字，其余字符合并为单个连字符，
+// 结果最多 maxLen 个字符（maxLen <= 0 时不限制）
+func Slugify(s string, maxLen int) string {
+	var b strings.Builder
+	count := 0
+	pendingDash := false
+	for _, r := range strings.ToLower(s) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingDash = count > 0
+			continue
+		}
+		if pendingDash {
+			if maxLen > 0 && count+1 >= maxLen {
+				break
+			}
+			b.WriteByte('-')
+			count++
+			pendingDash = false
+		}
+		if maxLen > 0 && count >= maxLen {
+			break
+		}
+		b.WriteRune(r)
+		count++
+	}
+	return b.String()
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"Basic", "Hello, World!", 0, "hello-world"},
+		{"Trims separators", "  --Go 1.22 released--  ", 0, "go-1-22-released"},
+		{"Keeps CJK", "Gin 入门：路由与中间件", 0, "gin-入门-路由与中间件"},
+		{"Accents kept as letters", "Café Olé", 0, "café-olé"},
+		{"Only punctuation", "!!!", 0, ""},
+		{"Truncates", "abc def ghi", 7, "abc-def"},
+		{"No trailing dash after truncation", "abc def", 4, "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Slugify(tt.input, tt.maxLen))
+		})
+	}
+}
@@ -42,7 +42,7 @@ const parseJWT = (token) => {
   }
 }
 
-// 检查token是否即将过期（30分钟内）
+// 检查token是否即将过期（下次检查之前）
 const isTokenExpiringSoon = (token) => {
   const payload = parseJWT(token)
   if (!payload || !payload.exp) return false
@@ -50,8 +50,25 @@ const isTokenExpiringSoon = (token) => {
   const now = Date.now() / 1000
   const timeToExpiry = payload.exp - now
   
-  // 如果30分钟内过期，返回true
-  return timeToExpiry < 30 * 60
+  // 访问令牌有效期较短，在下次检查前过期就提前刷新
+  return timeToExpiry < 6 * 60
+}
+
+// 使用刷新令牌换取新令牌，刷新令牌每次使用后都会轮换，需要保存新的刷新令牌
+const refreshTokens = async () => {
+  const refreshToken = localStorage.getItem('refresh_token')
+  if (!refreshToken) {
+    throw new Error('未找到刷新令牌')
+  }
+
+  const response = await axios.post('/api/v1/auth/refresh', { refresh_token: refreshToken })
+  const { token: newToken, refresh_token: newRefreshToken, user } = response.data.data
+
+  localStorage.setItem('token', newToken)
+  localStorage.setItem('refresh_token', newRefreshToken)
+  localStorage.setItem('user', JSON.stringify(user))
+  api.defaults.headers.common['Authorization'] = `Bearer ${newToken}`
+  return newToken
 }
 
 // 主动刷新token
@@ -62,13 +79,7 @@ const proactiveRefreshToken = async () => {
   if (isTokenExpiringSoon(token)) {
     try {
       isRefreshing = true
-      const response = await axios.post('/api/v1/auth/refresh', { token })
-      const newToken = response.data.data.token
-      const user = response.data.data.user
-      
-      localStorage.setItem('token', newToken)
-      localStorage.setItem('user', JSON.stringify(user))
-      api.defaults.headers.common['Authorization'] = `Bearer ${newToken}`
+      await refreshTokens()
       
       console.log('Token主动刷新成功')
     } catch (error) {
@@ -107,7 +118,10 @@ api.interceptors.response.use(
     if (error.response) {
       const { status, data } = error.response
 
-      if (status === 401 && !originalRequest._retry) {
+      // 登录接口的 401 表示凭据或验证码错误，不需要刷新令牌
+      const isCredentialRequest = ['/users/login', '/auth/2fa/login'].includes(originalRequest.url)
+
+      if (status === 401 && !originalRequest._retry && !isCredentialRequest) {
         if (isRefreshing) {
           // 如果正在刷新token，将请求加入队列
           return new Promise((resolve, reject) => {
@@ -123,10 +137,10 @@ api.interceptors.response.use(
         originalRequest._retry = true
         isRefreshing = true
 
-        const token = localStorage.getItem('token')
+        const refreshToken = localStorage.getItem('refresh_token')
         
-        if (!token) {
-          // 没有token，直接跳转登录
+        if (!refreshToken) {
+          // 没有刷新令牌，直接跳转登录
           processQueue(error, null)
           ElMessage.error('登录已过期，请重新登录')
           localStorage.removeItem('token')
@@ -137,16 +151,7 @@ api.interceptors.response.use(
 
         try {
           // 尝试刷新token
-          const response = await axios.post('/api/v1/auth/refresh', { token })
-          const newToken = response.data.data.token
-          const user = response.data.data.user
-
-          // 更新localStorage
-          localStorage.setItem('token', newToken)
-          localStorage.setItem('user', JSON.stringify(user))
-
-          // 更新axios默认header
-          api.defaults.headers.common['Authorization'] = `Bearer ${newToken}`
+          const newToken = await refreshTokens()
           
           // 处理队列中的请求
           processQueue(null, newToken)
@@ -160,6 +165,7 @@ api.interceptors.response.use(
           processQueue(refreshError, null)
           ElMessage.error('登录已过期，请重新登录')
           localStorage.removeItem('token')
+          localStorage.removeItem('refresh_token')
           localStorage.removeItem('user')
           window.location.href = '/login'
           return Promise.reject(refreshError)
@@ -39,6 +39,63 @@ export const authApi = {
     })
   },
 
+  /**
+   * 两步验证登录
+   * @param {string} challengeToken - 登录接口返回的挑战令牌
+   * @param {string} code - 身份验证器中的验证码或恢复码
+   * @returns {Promise} 登录结果包含token和用户信息
+   */
+  verifyTwoFactorLogin(challengeToken, code) {
+    return api.post('/auth/2fa/login', {
+      challenge_token: challengeToken,
+      code
+    })
+  },
+
+  /**
+   * 获取两步验证状态
+   * @returns {Promise} 是否启用及剩余恢复码数量
+   */
+  getTwoFactorStatus() {
+    return api.get('/auth/2fa')
+  },
+
+  /**
+   * 开始启用两步验证
+   * @returns {Promise} 密钥和用于生成二维码的 otpauth 地址
+   */
+  setupTwoFactor() {
+    return api.post('/auth/2fa/setup')
+  },
+
+  /**
+   * 提交验证码完成启用
+   * @param {string} code - 身份验证器中的验证码
+   * @returns {Promise} 只展示一次的恢复码
+   */
+  enableTwoFactor(code) {
+    return api.post('/auth/2fa/enable', { code })
+  },
+
+  /**
+   * 关闭两步验证
+   * @param {string} password - 当前密码
+   * @param {string} code - 验证码或恢复码
+   * @returns {Promise} 关闭结果
+   */
+  disableTwoFactor(password, code) {
+    return api.post('/auth/2fa/disable', { password, code })
+  },
+
+  /**
+   * 重新生成恢复码
+   * @param {string} code - 身份验证器中的验证码
+   * @returns {Promise} 新的恢复码
+   */
+  regenerateRecoveryCodes(code) {
+    return api.post('/auth/2fa/recovery-codes', { code })
+  },
+
   /**
    * 用户登出
    * @returns {Promise} 登出结果
@@ -112,40 +169,36 @@ export const authApi = {
   },
 
   /**
-   * 发送密码重置邮件
+   * 发送密码重置邮件，邮箱未注册时同样返回成功
    * @param {string} email - 邮箱地址
    * @returns {Promise} 发送结果
    */
   sendPasswordResetEmail(email) {
-    return api.post('/auth/password/reset', {
+    return api.post('/auth/forgot-password', {
       email: email
     })
   },
 
   /**
-   * 重置密码
+   * 使用邮件中的令牌重置密码，成功后所有设备需要重新登录
    * @param {Object} resetData - 重置数据
    * @param {string} resetData.token - 重置令牌
-   * @param {string} resetData.email - 邮箱
    * @param {string} resetData.password - 新密码
-   * @param {string} resetData.confirmPassword - 确认密码
    * @returns {Promise} 重置结果
    */
   resetPassword(resetData) {
-    return api.post('/auth/password/reset/confirm', {
+    return api.post('/auth/reset-password', {
       token: resetData.token,
-      email: resetData.email,
-      password: resetData.password,
-      confirm_password: resetData.confirmPassword
+      password: resetData.password
     })
   },
 
   /**
-   * 发送邮箱验证邮件
+   * 重新发送邮箱验证邮件
    * @returns {Promise} 发送结果
    */
   sendEmailVerification() {
-    return api.post('/auth/email/verify')
+    return api.post('/auth/verify-email/send')
   },
 
   /**
@@ -154,7 +207,7 @@ export const authApi = {
    * @returns {Promise} 验证结果
    */
   verifyEmail(token) {
-    return api.post('/auth/email/verify/confirm', {
+    return api.post('/auth/verify-email', {
       token: token
     })
   },
@@ -182,7 +235,7 @@ export const authApi = {
    * @returns {Promise} 会话列表
    */
   getUserSessions() {
-    return api.get('/auth/sessions')
+    return api.get('/users/sessions')
   },
 
   /**
@@ -191,7 +244,7 @@ export const authApi = {
    * @returns {Promise} 终止结果
    */
   terminateSession(sessionId) {
-    return api.delete(`/auth/sessions/${sessionId}`)
+    return api.delete(`/users/sessions/${sessionId}`)
   },
 
   /**
@@ -5,5 +5,17 @@ export const authRoutes = [
     name: 'Login',
     component: () => import('@/features/auth/views/Login.vue'),
     meta: { requiresGuest: true }
+  },
+  {
+    path: '/reset-password',
+    name: 'ResetPassword',
+    component: () => import('@/features/auth/views/ResetPassword.vue'),
+    meta: { requiresGuest: true }
+  },
+  {
+    // 已登录和未登录时都可以打开邮件中的验证链接
+    path: '/verify-email',
+    name: 'VerifyEmail',
+    component: () => import('@/features/auth/views/VerifyEmail.vue')
   }
 ]
\ No newline at end of file
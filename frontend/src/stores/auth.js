@@ -6,36 +6,78 @@ import { computed, ref } from 'vue'
 export const useAuthStore = defineStore('auth', () => {
   const user = ref(null)
   const token = ref(localStorage.getItem('token') || '')
+  // 启用两步验证时登录接口返回的挑战令牌，提交验证码后清空
+  const twoFactorChallenge = ref('')
 
   // 计算属性
   const isAuthenticated = computed(() => !!token.value)
 
-  // 登录
-  const login = async (credentials) => {
-    try {
-      const response = await authApi.login(credentials)
-      const { token: newToken, user: userData } = response.data
+  // 保存登录结果
+  const completeLogin = async (data) => {
+    const { token: newToken, refresh_token: refreshToken, user: userData } = data
 
-      token.value = newToken
-      user.value = userData
+    token.value = newToken
+    user.value = userData
 
-      localStorage.setItem('token', newToken)
-      localStorage.setItem('user', JSON.stringify(userData))
+    localStorage.setItem('token', newToken)
+    localStorage.setItem('refresh_token', refreshToken)
+    localStorage.setItem('user', JSON.stringify(userData))
 
-      ElMessage.success('登录成功')
-      
-      // 登录成功后初始化设置
-      const { useSettingsStore } = await import('./settings')
-      const settingsStore = useSettingsStore()
-      settingsStore.initializeSettings()
-      
+    ElMessage.success('登录成功')
+
+    // 登录成功后初始化设置
+    const { useSettingsStore } = await import('./settings')
+    const settingsStore = useSettingsStore()
+    settingsStore.initializeSettings()
+  }
+
+  // 连续失败后服务端会要求等待一段时间或临时锁定账号，返回是否已提示
+  const showLoginThrottled = (error) => {
+    if (error.response?.status !== 429) {
+      return false
+    }
+    const seconds = Number(error.response.headers?.['retry-after']) || 0
+    ElMessage.error(seconds > 60
+      ? `登录失败次数过多，请 ${Math.ceil(seconds / 60)} 分钟后再试`
+      : `登录失败次数过多，请 ${seconds || 1} 秒后再试`)
+    return true
+  }
+
+  // 登录，启用两步验证时返回 false 并设置 twoFactorChallenge，等待提交验证码
+  const login = async (credentials) => {
+    try {
+      const response = await authApi.login(credentials)
+      if (response.data.two_factor_required) {
+        twoFactorChallenge.value = response.data.challenge_token
+        return false
+      }
+      await completeLogin(response.data)
       return true
     } catch (error) {
+      if (showLoginThrottled(error)) {
+        return false
+      }
       ElMessage.error('登录失败')
       return false
     }
   }
 
+  // 登录第二步：提交身份验证器中的验证码或恢复码
+  const verifyTwoFactor = async (code) => {
+    try {
+      const response = await authApi.verifyTwoFactorLogin(twoFactorChallenge.value, code)
+      twoFactorChallenge.value = ''
+      await completeLogin(response.data)
+      return true
+    } catch (error) {
+      if (showLoginThrottled(error)) {
+        return false
+      }
+      ElMessage.error('验证码错误或已过期')
+      return false
+    }
+  }
+
   // 注册
   const register = async (userData) => {
     try {
@@ -48,11 +90,19 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
-  // 登出
-  const logout = () => {
+  // 登出，同时让服务端吊销当前会话的令牌
+  const logout = async () => {
+    if (token.value) {
+      try {
+        await authApi.logout()
+      } catch (error) {
+        // 令牌已失效时服务端会拒绝，本地状态照常清除
+      }
+    }
     token.value = ''
     user.value = null
     localStorage.removeItem('token')
+    localStorage.removeItem('refresh_token')
     localStorage.removeItem('user')
     ElMessage.success('已退出登录')
   }
@@ -85,7 +135,9 @@ export const useAuthStore = defineStore('auth', () => {
     user,
     token,
     isAuthenticated,
+    twoFactorChallenge,
     login,
+    verifyTwoFactor,
     register,
     logout,
     fetchProfile,